@@ -11,11 +11,16 @@ import (
 
 	"github.com/touros-platform/api/internal/config"
 	"github.com/touros-platform/api/internal/database"
+	"github.com/touros-platform/api/internal/document"
+	"github.com/touros-platform/api/internal/events"
 	"github.com/touros-platform/api/internal/handler"
+	"github.com/touros-platform/api/internal/mailer"
 	"github.com/touros-platform/api/internal/observability"
 	"github.com/touros-platform/api/internal/repository"
 	"github.com/touros-platform/api/internal/router"
+	"github.com/touros-platform/api/internal/scheduler"
 	"github.com/touros-platform/api/internal/service"
+	"github.com/touros-platform/api/internal/storage"
 	tracesdk "go.opentelemetry.io/otel/sdk/trace"
 	"go.uber.org/zap"
 )
@@ -55,36 +60,156 @@ func main() {
 		logger.Fatal("Failed to connect to database", zap.Error(err))
 	}
 
-	if err := database.AutoMigrate(db); err != nil {
-		logger.Fatal("Failed to run migrations", zap.Error(err))
+	if cfg.Database.MigrateOnStart {
+		migrator, err := database.NewMigrator(db)
+		if err != nil {
+			logger.Fatal("Failed to load migrations", zap.Error(err))
+		}
+		applied, err := migrator.Up(context.Background())
+		if err != nil {
+			logger.Fatal("Failed to run migrations", zap.Error(err))
+		}
+		for _, m := range applied {
+			logger.Info("Applied migration", zap.Int64("version", m.Version), zap.String("name", m.Name))
+		}
 	}
 
 	userRepo := repository.NewUserRepository(db)
 	agencyRepo := repository.NewAgencyRepository(db)
 	guideRepo := repository.NewGuideRepository(db)
 	permitRepo := repository.NewPermitRepository(db)
+	trekkerRepo := repository.NewTrekkerRepository(db)
+	routeRepo := repository.NewRouteRepository(db)
+	checkpointRepo := repository.NewCheckpointRepository(db)
+	quotaRepo := repository.NewQuotaRepository(db)
+	tokenRepo := repository.NewTokenRepository(db)
+	userTokenRepo := repository.NewUserTokenRepository(db)
 	checkInRepo := repository.NewSafetyCheckInRepository(db)
 	incidentRepo := repository.NewIncidentRepository(db)
+	mfaRepo := repository.NewMFARepository(db)
+	loginAttemptRepo := repository.NewLoginAttemptRepository(db)
+	serviceAccountRepo := repository.NewServiceAccountRepository(db)
+	externalIdentityRepo := repository.NewExternalIdentityRepository(db)
+	agencyDocumentRepo := repository.NewAgencyDocumentRepository(db)
+	licenseReminderRepo := repository.NewLicenseReminderRepository(db)
+	agencyInvitationRepo := repository.NewAgencyInvitationRepository(db)
+	guideCertificationRepo := repository.NewGuideCertificationRepository(db)
+	guideAvailabilityRepo := repository.NewGuideAvailabilityRepository(db)
+
+	broker := events.NewBroker()
+
+	permitSigner, err := service.NewPermitSigner(cfg.Permit)
+	if err != nil {
+		logger.Fatal("Failed to load permit signing keys", zap.Error(err))
+	}
+	if permitSigner.Ephemeral() {
+		logger.Warn("PERMIT_SIGNING_KEY not set, permits are signed with a temporary key")
+	}
 
-	authService := service.NewAuthService(userRepo, cfg)
-	guideService := service.NewGuideService(guideRepo, userRepo)
-	agencyService := service.NewAgencyService(agencyRepo)
-	permitService := service.NewPermitService(permitRepo, guideRepo)
-	safetyService := service.NewSafetyService(checkInRepo, incidentRepo, guideRepo)
+	documentFonts, err := document.LoadFonts(cfg.Permit.DocumentFonts)
+	if err != nil {
+		logger.Fatal("Failed to load permit document fonts", zap.Error(err))
+	}
+
+	tokenKeyring, err := service.NewTokenKeyring(cfg.JWT)
+	if err != nil {
+		logger.Fatal("Failed to load JWT signing keys", zap.Error(err))
+	}
+	logger.Info("Loaded JWT signing keys", zap.String("algorithm", tokenKeyring.Algorithm()))
+
+	mail, err := mailer.New(cfg.Mail, logger)
+	if err != nil {
+		logger.Fatal("Failed to configure mailer", zap.Error(err))
+	}
+
+	blobStore, err := storage.New(cfg.Storage)
+	if err != nil {
+		logger.Fatal("Failed to configure storage", zap.Error(err))
+	}
+
+	mfaService := service.NewMFAService(mfaRepo, userRepo, cfg.MFA)
+	authService := service.NewAuthService(userRepo, tokenRepo, loginAttemptRepo, mfaService, tokenKeyring, cfg)
+	oidcService, err := service.NewOIDCService(externalIdentityRepo, userRepo, agencyRepo, tokenRepo, authService, mfaService, cfg.OIDC)
+	if err != nil {
+		logger.Fatal("Failed to configure OIDC providers", zap.Error(err))
+	}
+	identityService := service.NewIdentityService(userRepo, guideRepo, agencyRepo)
+	serviceAccountService := service.NewServiceAccountService(serviceAccountRepo, agencyRepo, userRepo, cfg.APIKey)
+	accountService := service.NewAccountService(userRepo, userTokenRepo, tokenRepo, mail, cfg.Account, logger)
+	guideService := service.NewGuideService(guideRepo, userRepo, permitRepo)
+	guideAvailabilityService := service.NewGuideAvailabilityService(guideAvailabilityRepo, guideRepo, permitRepo)
+	guideCertificationService := service.NewGuideCertificationService(guideCertificationRepo, guideRepo, permitRepo, blobStore, cfg.Storage)
+	agencyService := service.NewAgencyService(agencyRepo, agencyDocumentRepo, userRepo)
+	agencyDocumentService := service.NewAgencyDocumentService(agencyDocumentRepo, agencyRepo, userRepo, blobStore, cfg.Storage)
+	permitService := service.NewPermitService(permitRepo, guideRepo, trekkerRepo, routeRepo, agencyRepo, guideCertificationRepo, permitSigner)
+	trekkerService := service.NewTrekkerService(trekkerRepo, permitRepo, guideRepo, userRepo)
+	routeService := service.NewRouteService(routeRepo)
+	checkpointService := service.NewCheckpointService(checkpointRepo, permitRepo, guideRepo, routeRepo, permitSigner)
+	quotaService := service.NewQuotaService(quotaRepo, routeRepo)
+	userService := service.NewUserService(userRepo, agencyRepo, tokenRepo, loginAttemptRepo, accountService)
+	safetyService := service.NewSafetyService(checkInRepo, incidentRepo, guideRepo, permitRepo, broker, logger)
+	licenseService := service.NewLicenseService(guideRepo, agencyRepo, licenseReminderRepo, mail, cfg.License, logger)
+	agencyMemberService := service.NewAgencyMemberService(userRepo, agencyRepo, agencyInvitationRepo, tokenRepo, mail, cfg.Account, logger)
 
 	guideHandler := handler.NewGuideHandler(guideService)
+	guideCertificationHandler := handler.NewGuideCertificationHandler(guideCertificationService)
+	guideAvailabilityHandler := handler.NewGuideAvailabilityHandler(guideAvailabilityService)
 	agencyHandler := handler.NewAgencyHandler(agencyService)
-	permitHandler := handler.NewPermitHandler(permitService)
-	safetyHandler := handler.NewSafetyHandler(safetyService)
+	agencyDocumentHandler := handler.NewAgencyDocumentHandler(agencyDocumentService)
+	licenseHandler := handler.NewLicenseHandler(licenseService)
+	agencyMemberHandler := handler.NewAgencyMemberHandler(agencyMemberService)
+	permitHandler := handler.NewPermitHandler(permitService, documentFonts)
+	trekkerHandler := handler.NewTrekkerHandler(trekkerService)
+	routeHandler := handler.NewRouteHandler(routeService)
+	checkpointHandler := handler.NewCheckpointHandler(checkpointService)
+	quotaHandler := handler.NewQuotaHandler(quotaService)
+	userHandler := handler.NewUserHandler(userService)
+	mfaHandler := handler.NewMFAHandler(mfaService)
+	oidcHandler := handler.NewOIDCHandler(oidcService)
+	serviceAccountHandler := handler.NewServiceAccountHandler(serviceAccountService)
+	safetyHandler := handler.NewSafetyHandler(safetyService, broker, cfg.Safety.StreamOrigins)
 	healthHandler := handler.NewHealthHandler(db)
 
+	checkInMonitor := service.NewCheckInMonitor(guideRepo, permitRepo, checkInRepo, incidentRepo, broker, cfg.Safety, logger)
+	cleanupJob := service.NewCleanupJob(tokenRepo, userTokenRepo, loginAttemptRepo, externalIdentityRepo, cfg.Cleanup, logger)
+
+	jobs := scheduler.New(logger)
+	if cfg.Safety.MonitorEnabled {
+		jobs.Every("checkin-monitor", cfg.Safety.MonitorInterval, checkInMonitor.Scan)
+	}
+	if cfg.License.CheckEnabled {
+		jobs.Every("license-expiry", cfg.License.CheckInterval, licenseService.CheckExpiries)
+	}
+	if cfg.Cleanup.Enabled {
+		jobs.Every("cleanup", cfg.Cleanup.Interval, cleanupJob.Run)
+	}
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	jobs.Start(jobsCtx)
+
 	r := router.SetupRouter(
 		cfg,
 		logger,
 		authService,
+		accountService,
+		identityService,
+		serviceAccountService,
 		guideHandler,
+		guideCertificationHandler,
+		guideAvailabilityHandler,
 		agencyHandler,
+		agencyDocumentHandler,
+		licenseHandler,
+		agencyMemberHandler,
 		permitHandler,
+		trekkerHandler,
+		routeHandler,
+		checkpointHandler,
+		quotaHandler,
+		userHandler,
+		mfaHandler,
+		oidcHandler,
+		serviceAccountHandler,
 		safetyHandler,
 		healthHandler,
 	)
@@ -109,6 +234,7 @@ func main() {
 	<-quit
 
 	logger.Info("Shutting down server...")
+	stopJobs()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
@@ -116,6 +242,7 @@ func main() {
 	if err := srv.Shutdown(ctx); err != nil {
 		logger.Error("Server forced to shutdown", zap.Error(err))
 	}
+	jobs.Wait()
 
 	logger.Info("Server exited")
 }
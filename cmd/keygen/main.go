@@ -0,0 +1,27 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"os"
+)
+
+func main() {
+	keyID := "k1"
+	if len(os.Args) > 1 {
+		keyID = os.Args[1]
+	}
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to generate key: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("PERMIT_SIGNING_KEY_ID=%s\n", keyID)
+	fmt.Printf("PERMIT_SIGNING_KEY=%s\n", base64.StdEncoding.EncodeToString(priv.Seed()))
+	fmt.Printf("# Public key, add to PERMIT_VERIFICATION_KEYS when this key is retired:\n")
+	fmt.Printf("# %s:%s\n", keyID, base64.StdEncoding.EncodeToString(pub))
+}
@@ -1,19 +1,55 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"os"
+	"strconv"
 
 	"github.com/touros-platform/api/internal/config"
 	"github.com/touros-platform/api/internal/database"
 )
 
+const usage = `Usage: go run cmd/migrate/main.go [-dir path] <command>
+
+Commands:
+  up             Apply all pending migrations
+  down [N]       Roll back the last N applied migrations (default 1)
+  status         Show applied and pending migrations
+  redo           Roll back and re-apply the last applied migration
+  create <name>  Create a new empty up/down migration pair
+`
+
 func main() {
-	if len(os.Args) < 2 {
-		fmt.Println("Usage: go run cmd/migrate/main.go [up|down]")
+	dir := flag.String("dir", database.MigrationsDir, "migrations directory used by create")
+	flag.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) < 1 {
+		flag.Usage()
 		os.Exit(1)
 	}
 
+	command := args[0]
+
+	if command == "create" {
+		if len(args) < 2 {
+			fmt.Fprintln(os.Stderr, "Usage: go run cmd/migrate/main.go create <name>")
+			os.Exit(1)
+		}
+		paths, err := database.CreateMigration(*dir, args[1])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to create migration: %v\n", err)
+			os.Exit(1)
+		}
+		for _, path := range paths {
+			fmt.Printf("Created %s\n", path)
+		}
+		return
+	}
+
 	cfg, err := config.Load()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
@@ -26,19 +62,66 @@ func main() {
 		os.Exit(1)
 	}
 
-	command := os.Args[1]
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load migrations: %v\n", err)
+		os.Exit(1)
+	}
+
+	ctx := context.Background()
+
 	switch command {
 	case "up":
-		if err := database.AutoMigrate(db); err != nil {
+		applied, err := migrator.Up(ctx)
+		if err != nil {
 			fmt.Fprintf(os.Stderr, "Failed to run migrations: %v\n", err)
 			os.Exit(1)
 		}
-		fmt.Println("Migrations completed successfully")
+		for _, m := range applied {
+			fmt.Printf("Applied %04d_%s\n", m.Version, m.Name)
+		}
+		fmt.Printf("Migrations completed successfully (%d applied)\n", len(applied))
 	case "down":
-		fmt.Println("Down migrations not implemented - use manual rollback if needed")
+		n := 1
+		if len(args) > 1 {
+			n, err = strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				fmt.Fprintf(os.Stderr, "Invalid number of migrations: %s\n", args[1])
+				os.Exit(1)
+			}
+		}
+		reverted, err := migrator.Down(ctx, n)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to roll back migrations: %v\n", err)
+			os.Exit(1)
+		}
+		for _, m := range reverted {
+			fmt.Printf("Rolled back %04d_%s\n", m.Version, m.Name)
+		}
+		fmt.Printf("Rollback completed successfully (%d rolled back)\n", len(reverted))
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to read migration status: %v\n", err)
+			os.Exit(1)
+		}
+		for _, s := range statuses {
+			state := "pending"
+			if s.AppliedAt != nil {
+				state = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05 MST")
+			}
+			fmt.Printf("%04d_%-40s %s\n", s.Version, s.Name, state)
+		}
+	case "redo":
+		m, err := migrator.Redo(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to redo migration: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Redid %04d_%s\n", m.Version, m.Name)
 	default:
-		fmt.Printf("Unknown command: %s\n", command)
+		fmt.Fprintf(os.Stderr, "Unknown command: %s\n", command)
+		flag.Usage()
 		os.Exit(1)
 	}
 }
-
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -13,6 +14,17 @@ type Config struct {
 	JWT      JWTConfig
 	App      AppConfig
 	OTEL     OTELConfig
+	Safety   SafetyConfig
+	Permit   PermitConfig
+	Mail     MailConfig
+	Account  AccountConfig
+	MFA      MFAConfig
+	Login    LoginConfig
+	APIKey   APIKeyConfig
+	OIDC     OIDCConfig
+	Storage  StorageConfig
+	License  LicenseConfig
+	Cleanup  CleanupConfig
 }
 
 type ServerConfig struct {
@@ -29,13 +41,26 @@ type DatabaseConfig struct {
 	Password string
 	DBName   string
 	SSLMode  string
+	// MigrateOnStart runs pending SQL migrations when the API boots. Turn it
+	// off when migrations are applied by a separate release step.
+	MigrateOnStart bool
 }
 
+// JWTConfig holds the token secrets and keys. Access tokens are signed with
+// HS256 and AccessSecret unless SigningKeyFile names a PEM encoded RSA or
+// Ed25519 private key, in which case they are signed with RS256 or EdDSA
+// under SigningKeyID and their public keys are published as a JWKS.
+// VerificationKeys lists retired public keys as comma separated
+// "kid:path" pairs so tokens signed before a rotation keep verifying.
+// Refresh tokens are only read by the API and always use RefreshSecret.
 type JWTConfig struct {
-	AccessSecret  string
-	RefreshSecret string
-	AccessTTL     time.Duration
-	RefreshTTL    time.Duration
+	AccessSecret     string
+	RefreshSecret    string
+	AccessTTL        time.Duration
+	RefreshTTL       time.Duration
+	SigningKeyID     string
+	SigningKeyFile   string
+	VerificationKeys string
 }
 
 type AppConfig struct {
@@ -49,6 +74,143 @@ type OTELConfig struct {
 	ServiceName string
 }
 
+// SafetyConfig holds the check-in monitor thresholds. StreamOrigins lists,
+// comma separated, the browser origins (such as https://ops.example.com)
+// allowed to open the safety WebSocket; requests from other origins are
+// refused.
+type SafetyConfig struct {
+	MonitorEnabled  bool
+	MonitorInterval time.Duration
+	WarningAfter    time.Duration
+	OverdueAfter    time.Duration
+	MissingAfter    time.Duration
+	StreamOrigins   string
+}
+
+// PermitConfig holds the Ed25519 keys used to sign permit QR payloads.
+// SigningKey is a base64 encoded seed or private key. VerificationKeys lists
+// retired public keys as comma separated "kid:base64" pairs so permits signed
+// before a rotation keep verifying. DocumentFonts lists, comma separated,
+// TrueType font files used in permit documents for scripts the bundled font
+// does not cover.
+type PermitConfig struct {
+	SigningKeyID     string
+	SigningKey       string
+	VerificationKeys string
+	DocumentFonts    string
+}
+
+// MailConfig selects how outgoing email is delivered. Driver is "smtp",
+// "file" (one .eml file per message in FileDir) or "log".
+type MailConfig struct {
+	Driver       string
+	From         string
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	FileDir      string
+}
+
+// AccountConfig controls password reset, email verification and agency
+// invitations. PublicURL is the base of the links sent by email.
+type AccountConfig struct {
+	PublicURL            string
+	PasswordResetTTL     time.Duration
+	EmailVerificationTTL time.Duration
+	InvitationTTL        time.Duration
+}
+
+// MFAConfig controls TOTP multi-factor authentication. RequiredRoles lists
+// the roles that must enroll before they can log in, comma separated.
+type MFAConfig struct {
+	Issuer        string
+	RequiredRoles string
+	ChallengeTTL  time.Duration
+}
+
+// LoginConfig controls brute-force protection on login. After
+// LockoutThreshold consecutive failures an account is locked for
+// LockoutBase, doubling with every further failure up to LockoutMax. A client
+// address with IPMaxFailures failures within IPWindow is refused until the
+// window has passed. A threshold of zero turns that check off.
+type LoginConfig struct {
+	LockoutThreshold int
+	LockoutBase      time.Duration
+	LockoutMax       time.Duration
+	IPMaxFailures    int
+	IPWindow         time.Duration
+}
+
+// APIKeyConfig controls service account API keys. A rotated key keeps
+// working for RotationGrace, or until its own expiry if that comes first, so
+// that integrations can switch to the new key without downtime.
+type APIKeyConfig struct {
+	RotationGrace time.Duration
+}
+
+// OIDCConfig lists the OpenID Connect providers users can log in with.
+// Providers are named in OIDC_PROVIDERS and configured with OIDC_<NAME>_*
+// variables. A login started at a provider must be completed within
+// StateTTL.
+type OIDCConfig struct {
+	Providers []OIDCProviderConfig
+	StateTTL  time.Duration
+}
+
+// OIDCProviderConfig is one provider. RoleMap maps values of the RoleClaim
+// claim to roles as comma separated "value:role" pairs; the first pair whose
+// value the user has decides the role, and users without one get
+// DefaultRole, or are refused when it is empty. AgencyClaim names a claim
+// holding the agency's registration number or ID. LinkDomains lists, comma
+// separated, the email domains whose existing local accounts may be linked
+// on a first login; when empty, no existing account is linked.
+type OIDCProviderConfig struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       string
+	RoleClaim    string
+	RoleMap      string
+	DefaultRole  string
+	AgencyClaim  string
+	LinkDomains  string
+}
+
+// StorageConfig selects where uploaded files are kept. Driver is "local"
+// (files under LocalDir) or "s3" (a bucket of an S3-compatible store such as
+// MinIO; set S3PathStyle for stores that do not support bucket host names).
+// Uploads larger than MaxUploadSize bytes are refused.
+type StorageConfig struct {
+	Driver            string
+	LocalDir          string
+	S3Endpoint        string
+	S3Region          string
+	S3Bucket          string
+	S3AccessKeyID     string
+	S3SecretAccessKey string
+	S3PathStyle       bool
+	MaxUploadSize     int64
+}
+
+// LicenseConfig drives the license expiry job. ReminderDays lists how many
+// days before a guide's or agency's license expires a reminder is sent.
+type LicenseConfig struct {
+	CheckEnabled  bool
+	CheckInterval time.Duration
+	ReminderDays  []int
+}
+
+// CleanupConfig drives the job that deletes expired tokens and abandoned
+// single sign-on logins. Login attempts are kept for LoginAttemptRetention.
+type CleanupConfig struct {
+	Enabled               bool
+	Interval              time.Duration
+	LoginAttemptRetention time.Duration
+}
+
 func Load() (*Config, error) {
 	cfg := &Config{
 		Server: ServerConfig{
@@ -58,18 +220,22 @@ func Load() (*Config, error) {
 			IdleTimeout:  getDurationEnv("SERVER_IDLE_TIMEOUT", 60*time.Second),
 		},
 		Database: DatabaseConfig{
-			Host:     getEnv("DB_HOST", "localhost"),
-			Port:     getEnv("DB_PORT", "5432"),
-			User:     getEnv("DB_USER", "touros"),
-			Password: getEnv("DB_PASSWORD", "touros123"),
-			DBName:   getEnv("DB_NAME", "touros"),
-			SSLMode:  getEnv("DB_SSLMODE", "disable"),
+			Host:           getEnv("DB_HOST", "localhost"),
+			Port:           getEnv("DB_PORT", "5432"),
+			User:           getEnv("DB_USER", "touros"),
+			Password:       getEnv("DB_PASSWORD", "touros123"),
+			DBName:         getEnv("DB_NAME", "touros"),
+			SSLMode:        getEnv("DB_SSLMODE", "disable"),
+			MigrateOnStart: getBoolEnv("DB_MIGRATE_ON_START", true),
 		},
 		JWT: JWTConfig{
-			AccessSecret:  getEnv("JWT_ACCESS_SECRET", ""),
-			RefreshSecret: getEnv("JWT_REFRESH_SECRET", ""),
-			AccessTTL:     getDurationEnv("JWT_ACCESS_TTL", 15*time.Minute),
-			RefreshTTL:    getDurationEnv("JWT_REFRESH_TTL", 7*24*time.Hour),
+			AccessSecret:     getEnv("JWT_ACCESS_SECRET", ""),
+			RefreshSecret:    getEnv("JWT_REFRESH_SECRET", ""),
+			AccessTTL:        getDurationEnv("JWT_ACCESS_TTL", 15*time.Minute),
+			RefreshTTL:       getDurationEnv("JWT_REFRESH_TTL", 7*24*time.Hour),
+			SigningKeyID:     getEnv("JWT_SIGNING_KEY_ID", "k1"),
+			SigningKeyFile:   getEnv("JWT_SIGNING_KEY_FILE", ""),
+			VerificationKeys: getEnv("JWT_VERIFICATION_KEYS", ""),
 		},
 		App: AppConfig{
 			Environment: getEnv("APP_ENV", "development"),
@@ -80,15 +246,169 @@ func Load() (*Config, error) {
 			Endpoint:    getEnv("OTEL_ENDPOINT", "http://localhost:14268/api/traces"),
 			ServiceName: getEnv("OTEL_SERVICE_NAME", "touros-api"),
 		},
+		Safety: SafetyConfig{
+			MonitorEnabled:  getBoolEnv("CHECKIN_MONITOR_ENABLED", true),
+			MonitorInterval: getDurationEnv("CHECKIN_MONITOR_INTERVAL", 5*time.Minute),
+			WarningAfter:    getDurationEnv("CHECKIN_WARNING_AFTER", 24*time.Hour),
+			OverdueAfter:    getDurationEnv("CHECKIN_OVERDUE_AFTER", 36*time.Hour),
+			MissingAfter:    getDurationEnv("CHECKIN_MISSING_AFTER", 48*time.Hour),
+			StreamOrigins:   getEnv("SAFETY_STREAM_ORIGINS", ""),
+		},
+		Permit: PermitConfig{
+			SigningKeyID:     getEnv("PERMIT_SIGNING_KEY_ID", "k1"),
+			SigningKey:       getEnv("PERMIT_SIGNING_KEY", ""),
+			VerificationKeys: getEnv("PERMIT_VERIFICATION_KEYS", ""),
+			DocumentFonts:    getEnv("PERMIT_DOCUMENT_FONTS", ""),
+		},
+		Mail: MailConfig{
+			Driver:       getEnv("MAIL_DRIVER", "log"),
+			From:         getEnv("MAIL_FROM", "Touros <no-reply@touros.gov.np>"),
+			SMTPHost:     getEnv("SMTP_HOST", "localhost"),
+			SMTPPort:     getEnv("SMTP_PORT", "1025"),
+			SMTPUsername: getEnv("SMTP_USERNAME", ""),
+			SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+			FileDir:      getEnv("MAIL_FILE_DIR", "tmp/mail"),
+		},
+		Account: AccountConfig{
+			PublicURL:            getEnv("APP_PUBLIC_URL", "http://localhost:8080"),
+			PasswordResetTTL:     getDurationEnv("PASSWORD_RESET_TTL", time.Hour),
+			EmailVerificationTTL: getDurationEnv("EMAIL_VERIFICATION_TTL", 48*time.Hour),
+			InvitationTTL:        getDurationEnv("AGENCY_INVITATION_TTL", 7*24*time.Hour),
+		},
+		MFA: MFAConfig{
+			Issuer:        getEnv("MFA_ISSUER", "Touros"),
+			RequiredRoles: getEnv("MFA_REQUIRED_ROLES", ""),
+			ChallengeTTL:  getDurationEnv("MFA_CHALLENGE_TTL", 5*time.Minute),
+		},
+		Login: LoginConfig{
+			LockoutThreshold: getIntEnv("LOGIN_LOCKOUT_THRESHOLD", 5),
+			LockoutBase:      getDurationEnv("LOGIN_LOCKOUT_BASE", time.Minute),
+			LockoutMax:       getDurationEnv("LOGIN_LOCKOUT_MAX", time.Hour),
+			IPMaxFailures:    getIntEnv("LOGIN_IP_MAX_FAILURES", 20),
+			IPWindow:         getDurationEnv("LOGIN_IP_WINDOW", 15*time.Minute),
+		},
+		APIKey: APIKeyConfig{
+			RotationGrace: getDurationEnv("API_KEY_ROTATION_GRACE", 24*time.Hour),
+		},
+		OIDC: OIDCConfig{
+			StateTTL: getDurationEnv("OIDC_STATE_TTL", 10*time.Minute),
+		},
+		Storage: StorageConfig{
+			Driver:            getEnv("STORAGE_DRIVER", "local"),
+			LocalDir:          getEnv("STORAGE_LOCAL_DIR", "data/blobs"),
+			S3Endpoint:        getEnv("S3_ENDPOINT", ""),
+			S3Region:          getEnv("S3_REGION", "us-east-1"),
+			S3Bucket:          getEnv("S3_BUCKET", ""),
+			S3AccessKeyID:     getEnv("S3_ACCESS_KEY_ID", ""),
+			S3SecretAccessKey: getEnv("S3_SECRET_ACCESS_KEY", ""),
+			S3PathStyle:       getBoolEnv("S3_PATH_STYLE", true),
+			MaxUploadSize:     int64(getIntEnv("STORAGE_MAX_UPLOAD_BYTES", 10<<20)),
+		},
+		License: LicenseConfig{
+			CheckEnabled:  getBoolEnv("LICENSE_CHECK_ENABLED", true),
+			CheckInterval: getDurationEnv("LICENSE_CHECK_INTERVAL", 24*time.Hour),
+		},
+		Cleanup: CleanupConfig{
+			Enabled:               getBoolEnv("CLEANUP_ENABLED", true),
+			Interval:              getDurationEnv("CLEANUP_INTERVAL", time.Hour),
+			LoginAttemptRetention: getDurationEnv("LOGIN_ATTEMPT_RETENTION", 90*24*time.Hour),
+		},
+	}
+
+	reminderDays, err := parseDays(getEnv("LICENSE_REMINDER_DAYS", "60,30,7"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid LICENSE_REMINDER_DAYS: %w", err)
+	}
+	cfg.License.ReminderDays = reminderDays
+
+	providers, err := loadOIDCProviders(cfg.Account.PublicURL)
+	if err != nil {
+		return nil, err
 	}
+	cfg.OIDC.Providers = providers
 
 	if cfg.JWT.AccessSecret == "" || cfg.JWT.RefreshSecret == "" {
 		return nil, fmt.Errorf("JWT_ACCESS_SECRET and JWT_REFRESH_SECRET must be set")
 	}
 
+	if cfg.Safety.WarningAfter >= cfg.Safety.OverdueAfter || cfg.Safety.OverdueAfter >= cfg.Safety.MissingAfter {
+		return nil, fmt.Errorf("CHECKIN_WARNING_AFTER < CHECKIN_OVERDUE_AFTER < CHECKIN_MISSING_AFTER must hold")
+	}
+
+	if cfg.Permit.SigningKey == "" && cfg.App.Environment == "production" {
+		return nil, fmt.Errorf("PERMIT_SIGNING_KEY must be set in production")
+	}
+
+	if cfg.Mail.Driver == "log" && cfg.App.Environment == "production" {
+		return nil, fmt.Errorf("MAIL_DRIVER=log would write reset links to the logs; configure smtp in production")
+	}
+
 	return cfg, nil
 }
 
+func loadOIDCProviders(publicURL string) ([]OIDCProviderConfig, error) {
+	var providers []OIDCProviderConfig
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(getEnv("OIDC_PROVIDERS", ""), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if !validProviderName(name) {
+			return nil, fmt.Errorf("invalid OIDC provider name %q: use lowercase letters, digits and dashes", name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("OIDC provider %q is listed twice", name)
+		}
+		seen[name] = true
+
+		prefix := "OIDC_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+		provider := OIDCProviderConfig{
+			Name:         name,
+			Issuer:       getEnv(prefix+"ISSUER", ""),
+			ClientID:     getEnv(prefix+"CLIENT_ID", ""),
+			ClientSecret: getEnv(prefix+"CLIENT_SECRET", ""),
+			RedirectURL:  getEnv(prefix+"REDIRECT_URL", strings.TrimSuffix(publicURL, "/")+"/auth/oidc/"+name+"/callback"),
+			Scopes:       getEnv(prefix+"SCOPES", "openid email profile"),
+			RoleClaim:    getEnv(prefix+"ROLE_CLAIM", "roles"),
+			RoleMap:      getEnv(prefix+"ROLE_MAP", ""),
+			DefaultRole:  getEnv(prefix+"DEFAULT_ROLE", ""),
+			AgencyClaim:  getEnv(prefix+"AGENCY_CLAIM", ""),
+			LinkDomains:  getEnv(prefix+"LINK_DOMAINS", ""),
+		}
+		if provider.Issuer == "" || provider.ClientID == "" {
+			return nil, fmt.Errorf("%sISSUER and %sCLIENT_ID must be set", prefix, prefix)
+		}
+		providers = append(providers, provider)
+	}
+	return providers, nil
+}
+
+func validProviderName(name string) bool {
+	for _, r := range name {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' {
+			return false
+		}
+	}
+	return len(name) <= 64
+}
+
+// parseDays parses a comma separated list of positive day counts.
+func parseDays(value string) ([]int, error) {
+	var days []int
+	for _, field := range strings.Split(value, ",") {
+		if field = strings.TrimSpace(field); field == "" {
+			continue
+		}
+		n, err := strconv.Atoi(field)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("%q is not a positive number of days", field)
+		}
+		days = append(days, n)
+	}
+	return days, nil
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
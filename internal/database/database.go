@@ -4,7 +4,6 @@ import (
 	"fmt"
 
 	"github.com/touros-platform/api/internal/config"
-	"github.com/touros-platform/api/internal/domain"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -45,14 +44,3 @@ func NewConnection(cfg *config.Config) (*gorm.DB, error) {
 
 	return db, nil
 }
-
-func AutoMigrate(db *gorm.DB) error {
-	return db.AutoMigrate(
-		&domain.User{},
-		&domain.Agency{},
-		&domain.Guide{},
-		&domain.Permit{},
-		&domain.SafetyCheckIn{},
-		&domain.Incident{},
-	)
-}
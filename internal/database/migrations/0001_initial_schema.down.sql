@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS incidents;
+DROP TABLE IF EXISTS safety_check_ins;
+DROP TABLE IF EXISTS permits;
+DROP TABLE IF EXISTS guides;
+DROP TABLE IF EXISTS users;
+DROP TABLE IF EXISTS agencies;
@@ -0,0 +1,149 @@
+-- Baseline schema. Written with IF NOT EXISTS so databases previously
+-- created by GORM auto-migration can adopt the versioned migrations.
+
+CREATE TABLE IF NOT EXISTS agencies (
+    id                  uuid DEFAULT gen_random_uuid(),
+    name                text NOT NULL,
+    registration_number text NOT NULL,
+    license_number      text NOT NULL,
+    contact_email       text NOT NULL,
+    contact_phone       text NOT NULL,
+    address             text,
+    status              varchar(20) DEFAULT 'pending',
+    license_expiry      timestamptz,
+    verified_at         timestamptz,
+    verified_by         uuid,
+    created_at          timestamptz,
+    updated_at          timestamptz,
+    deleted_at          timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_agencies_registration_number ON agencies (registration_number);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_agencies_license_number ON agencies (license_number);
+CREATE INDEX IF NOT EXISTS idx_agencies_status ON agencies (status);
+CREATE INDEX IF NOT EXISTS idx_agencies_license_expiry ON agencies (license_expiry);
+CREATE INDEX IF NOT EXISTS idx_agencies_deleted_at ON agencies (deleted_at);
+
+CREATE TABLE IF NOT EXISTS users (
+    id            uuid DEFAULT gen_random_uuid(),
+    email         text NOT NULL,
+    password_hash text NOT NULL,
+    role          varchar(20) NOT NULL,
+    full_name     text NOT NULL,
+    is_active     boolean DEFAULT true,
+    agency_id     uuid,
+    created_at    timestamptz,
+    updated_at    timestamptz,
+    deleted_at    timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_users_agency FOREIGN KEY (agency_id) REFERENCES agencies (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users (email);
+CREATE INDEX IF NOT EXISTS idx_users_role ON users (role);
+CREATE INDEX IF NOT EXISTS idx_users_is_active ON users (is_active);
+CREATE INDEX IF NOT EXISTS idx_users_agency_id ON users (agency_id);
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at);
+
+CREATE TABLE IF NOT EXISTS guides (
+    id                uuid DEFAULT gen_random_uuid(),
+    user_id           uuid NOT NULL,
+    agency_id         uuid,
+    license_number    text NOT NULL,
+    phone_number      text NOT NULL,
+    emergency_contact text NOT NULL,
+    status            varchar(20) DEFAULT 'pending',
+    license_expiry    timestamptz,
+    verified_at       timestamptz,
+    verified_by       uuid,
+    last_check_in     timestamptz,
+    created_at        timestamptz,
+    updated_at        timestamptz,
+    deleted_at        timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_guides_user FOREIGN KEY (user_id) REFERENCES users (id),
+    CONSTRAINT fk_guides_agency FOREIGN KEY (agency_id) REFERENCES agencies (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_guides_user_id ON guides (user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_guides_license_number ON guides (license_number);
+CREATE INDEX IF NOT EXISTS idx_guides_agency_id ON guides (agency_id);
+CREATE INDEX IF NOT EXISTS idx_guides_status ON guides (status);
+CREATE INDEX IF NOT EXISTS idx_guides_license_expiry ON guides (license_expiry);
+CREATE INDEX IF NOT EXISTS idx_guides_last_check_in ON guides (last_check_in);
+CREATE INDEX IF NOT EXISTS idx_guides_deleted_at ON guides (deleted_at);
+
+CREATE TABLE IF NOT EXISTS permits (
+    id            uuid DEFAULT gen_random_uuid(),
+    permit_number text NOT NULL,
+    guide_id      uuid NOT NULL,
+    client_id     uuid NOT NULL,
+    client_name   text NOT NULL,
+    client_email  text,
+    client_phone  text,
+    start_date    timestamptz NOT NULL,
+    end_date      timestamptz NOT NULL,
+    route         text NOT NULL,
+    status        varchar(20) DEFAULT 'active',
+    qr_code       text,
+    issued_by     uuid NOT NULL,
+    issued_at     timestamptz DEFAULT CURRENT_TIMESTAMP,
+    revoked_at    timestamptz,
+    revoked_by    uuid,
+    created_at    timestamptz,
+    updated_at    timestamptz,
+    deleted_at    timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_permits_guide FOREIGN KEY (guide_id) REFERENCES guides (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_permits_permit_number ON permits (permit_number);
+CREATE INDEX IF NOT EXISTS idx_permits_guide_id ON permits (guide_id);
+CREATE INDEX IF NOT EXISTS idx_permits_start_date ON permits (start_date);
+CREATE INDEX IF NOT EXISTS idx_permits_end_date ON permits (end_date);
+CREATE INDEX IF NOT EXISTS idx_permits_status ON permits (status);
+CREATE INDEX IF NOT EXISTS idx_permits_deleted_at ON permits (deleted_at);
+
+CREATE TABLE IF NOT EXISTS safety_check_ins (
+    id            uuid DEFAULT gen_random_uuid(),
+    guide_id      uuid NOT NULL,
+    permit_id     uuid,
+    latitude      decimal(10,8) NOT NULL,
+    longitude     decimal(11,8) NOT NULL,
+    location      text,
+    notes         text,
+    check_in_time timestamptz DEFAULT CURRENT_TIMESTAMP,
+    created_at    timestamptz,
+    updated_at    timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_safety_check_ins_guide FOREIGN KEY (guide_id) REFERENCES guides (id),
+    CONSTRAINT fk_safety_check_ins_permit FOREIGN KEY (permit_id) REFERENCES permits (id)
+);
+CREATE INDEX IF NOT EXISTS idx_safety_check_ins_guide_id ON safety_check_ins (guide_id);
+CREATE INDEX IF NOT EXISTS idx_safety_check_ins_permit_id ON safety_check_ins (permit_id);
+CREATE INDEX IF NOT EXISTS idx_safety_check_ins_check_in_time ON safety_check_ins (check_in_time);
+
+CREATE TABLE IF NOT EXISTS incidents (
+    id               uuid DEFAULT gen_random_uuid(),
+    incident_type    varchar(20) NOT NULL,
+    guide_id         uuid NOT NULL,
+    permit_id        uuid,
+    status           varchar(20) DEFAULT 'open',
+    latitude         decimal(10,8) NOT NULL,
+    longitude        decimal(11,8) NOT NULL,
+    location         text,
+    description      text NOT NULL,
+    reported_at      timestamptz DEFAULT CURRENT_TIMESTAMP,
+    resolved_at      timestamptz,
+    resolved_by      uuid,
+    resolution_notes text,
+    created_at       timestamptz,
+    updated_at       timestamptz,
+    deleted_at       timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_incidents_guide FOREIGN KEY (guide_id) REFERENCES guides (id),
+    CONSTRAINT fk_incidents_permit FOREIGN KEY (permit_id) REFERENCES permits (id)
+);
+CREATE INDEX IF NOT EXISTS idx_incidents_incident_type ON incidents (incident_type);
+CREATE INDEX IF NOT EXISTS idx_incidents_guide_id ON incidents (guide_id);
+CREATE INDEX IF NOT EXISTS idx_incidents_permit_id ON incidents (permit_id);
+CREATE INDEX IF NOT EXISTS idx_incidents_status ON incidents (status);
+CREATE INDEX IF NOT EXISTS idx_incidents_reported_at ON incidents (reported_at);
+CREATE INDEX IF NOT EXISTS idx_incidents_deleted_at ON incidents (deleted_at);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_incidents_escalation_level;
+ALTER TABLE incidents DROP COLUMN IF EXISTS escalated_at;
+ALTER TABLE incidents DROP COLUMN IF EXISTS escalation_level;
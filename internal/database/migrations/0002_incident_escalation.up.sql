@@ -0,0 +1,3 @@
+ALTER TABLE incidents ADD COLUMN IF NOT EXISTS escalation_level varchar(20);
+ALTER TABLE incidents ADD COLUMN IF NOT EXISTS escalated_at timestamptz;
+CREATE INDEX IF NOT EXISTS idx_incidents_escalation_level ON incidents (escalation_level);
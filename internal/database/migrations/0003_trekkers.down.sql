@@ -0,0 +1,18 @@
+DROP INDEX IF EXISTS idx_permits_trekker_id;
+ALTER TABLE permits DROP CONSTRAINT IF EXISTS fk_permits_trekker;
+ALTER TABLE permits ADD COLUMN client_name text;
+ALTER TABLE permits ADD COLUMN client_email text;
+ALTER TABLE permits ADD COLUMN client_phone text;
+
+UPDATE permits p
+SET client_name = t.full_name,
+    client_email = t.email,
+    client_phone = t.phone
+FROM trekkers t
+WHERE t.id = p.trekker_id;
+
+ALTER TABLE permits ALTER COLUMN client_name SET NOT NULL;
+ALTER TABLE permits RENAME COLUMN trekker_id TO client_id;
+
+DROP TABLE IF EXISTS trekker_emergency_contacts;
+DROP TABLE IF EXISTS trekkers;
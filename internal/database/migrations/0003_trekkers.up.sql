@@ -0,0 +1,60 @@
+-- Trekkers become first-class records. Existing permits carried a
+-- caller-supplied client_id plus copied contact details; each distinct
+-- client_id is turned into a trekker (keeping the same id) from its most
+-- recent permit, and permits.client_id becomes the trekker foreign key.
+
+CREATE TABLE trekkers (
+    id                      uuid DEFAULT gen_random_uuid(),
+    full_name               text NOT NULL,
+    email                   text,
+    phone                   text,
+    passport_number         text,
+    nationality             varchar(2),
+    date_of_birth           date,
+    insurance_provider      text,
+    insurance_policy_number text,
+    insurance_expiry        date,
+    agency_id               uuid,
+    created_by              uuid,
+    created_at              timestamptz,
+    updated_at              timestamptz,
+    deleted_at              timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_trekkers_agency FOREIGN KEY (agency_id) REFERENCES agencies (id)
+);
+CREATE INDEX idx_trekkers_email ON trekkers (email);
+CREATE INDEX idx_trekkers_passport_number ON trekkers (passport_number);
+CREATE INDEX idx_trekkers_nationality ON trekkers (nationality);
+CREATE INDEX idx_trekkers_agency_id ON trekkers (agency_id);
+CREATE INDEX idx_trekkers_deleted_at ON trekkers (deleted_at);
+CREATE UNIQUE INDEX idx_trekkers_passport ON trekkers (nationality, passport_number)
+    WHERE passport_number IS NOT NULL AND passport_number <> '' AND deleted_at IS NULL;
+
+CREATE TABLE trekker_emergency_contacts (
+    id           uuid DEFAULT gen_random_uuid(),
+    trekker_id   uuid NOT NULL,
+    name         text NOT NULL,
+    relationship text,
+    phone        text NOT NULL,
+    email        text,
+    created_at   timestamptz,
+    updated_at   timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_trekkers_emergency_contacts FOREIGN KEY (trekker_id) REFERENCES trekkers (id) ON DELETE CASCADE
+);
+CREATE INDEX idx_trekker_emergency_contacts_trekker_id ON trekker_emergency_contacts (trekker_id);
+
+INSERT INTO trekkers (id, full_name, email, phone, agency_id, created_by, created_at, updated_at)
+SELECT DISTINCT ON (p.client_id)
+       p.client_id, p.client_name, NULLIF(p.client_email, ''), NULLIF(p.client_phone, ''),
+       g.agency_id, p.issued_by, p.created_at, p.updated_at
+FROM permits p
+JOIN guides g ON g.id = p.guide_id
+ORDER BY p.client_id, p.created_at DESC;
+
+ALTER TABLE permits RENAME COLUMN client_id TO trekker_id;
+ALTER TABLE permits DROP COLUMN client_name;
+ALTER TABLE permits DROP COLUMN client_email;
+ALTER TABLE permits DROP COLUMN client_phone;
+ALTER TABLE permits ADD CONSTRAINT fk_permits_trekker FOREIGN KEY (trekker_id) REFERENCES trekkers (id);
+CREATE INDEX idx_permits_trekker_id ON permits (trekker_id);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS routes;
+DROP TABLE IF EXISTS permit_members;
@@ -0,0 +1,45 @@
+-- Permits cover a whole party. Every existing permit gets its trekker as
+-- the first party member.
+
+CREATE TABLE permit_members (
+    id          uuid DEFAULT gen_random_uuid(),
+    permit_id   uuid NOT NULL,
+    role        varchar(20) NOT NULL,
+    trekker_id  uuid,
+    full_name   text,
+    phone       text,
+    id_number   text,
+    status      varchar(20) DEFAULT 'pending',
+    status_note text,
+    status_at   timestamptz,
+    status_by   uuid,
+    created_at  timestamptz,
+    updated_at  timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_permits_members FOREIGN KEY (permit_id) REFERENCES permits (id) ON DELETE CASCADE,
+    CONSTRAINT fk_permit_members_trekker FOREIGN KEY (trekker_id) REFERENCES trekkers (id)
+);
+CREATE INDEX idx_permit_members_permit_id ON permit_members (permit_id);
+CREATE INDEX idx_permit_members_role ON permit_members (role);
+CREATE INDEX idx_permit_members_trekker_id ON permit_members (trekker_id);
+CREATE INDEX idx_permit_members_status ON permit_members (status);
+
+INSERT INTO permit_members (permit_id, role, trekker_id, status, created_at, updated_at)
+SELECT id, 'trekker', trekker_id, 'pending', created_at, updated_at
+FROM permits;
+
+-- Routes carry the party size limits. Permits still name their route as
+-- text and are matched to a route by name, ignoring case.
+CREATE TABLE routes (
+    id                uuid DEFAULT gen_random_uuid(),
+    name              text NOT NULL,
+    min_trekkers      bigint NOT NULL DEFAULT 1,
+    max_trekkers      bigint NOT NULL DEFAULT 15,
+    max_support_staff bigint NOT NULL DEFAULT 15,
+    created_at        timestamptz,
+    updated_at        timestamptz,
+    deleted_at        timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX idx_routes_name ON routes (lower(btrim(name))) WHERE deleted_at IS NULL;
+CREATE INDEX idx_routes_deleted_at ON routes (deleted_at);
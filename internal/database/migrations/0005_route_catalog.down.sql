@@ -0,0 +1,38 @@
+ALTER TABLE permits ADD COLUMN route text;
+UPDATE permits p
+SET route = CASE
+        WHEN p.custom_itinerary IS NULL OR p.custom_itinerary = '' THEN r.name
+        ELSE r.name || ': ' || p.custom_itinerary
+    END
+FROM routes r
+WHERE r.id = p.route_id;
+ALTER TABLE permits ALTER COLUMN route SET NOT NULL;
+
+DROP INDEX IF EXISTS idx_permits_route_id;
+ALTER TABLE permits DROP CONSTRAINT IF EXISTS fk_permits_route;
+ALTER TABLE permits DROP COLUMN custom_itinerary;
+ALTER TABLE permits DROP COLUMN route_id;
+
+DROP TABLE IF EXISTS route_checkpoints;
+
+-- Route names were unique ignoring case before the catalog; retire all but
+-- the oldest live route of each name.
+UPDATE routes r
+SET deleted_at = now()
+WHERE r.deleted_at IS NULL
+  AND EXISTS (
+    SELECT 1 FROM routes o
+    WHERE o.deleted_at IS NULL
+      AND lower(btrim(o.name)) = lower(btrim(r.name))
+      AND (o.created_at, o.id) < (r.created_at, r.id)
+  );
+
+DROP INDEX IF EXISTS idx_routes_is_active;
+DROP INDEX IF EXISTS idx_routes_region;
+DROP INDEX IF EXISTS idx_routes_code;
+ALTER TABLE routes DROP COLUMN is_active;
+ALTER TABLE routes DROP COLUMN duration_days;
+ALTER TABLE routes DROP COLUMN description;
+ALTER TABLE routes DROP COLUMN region;
+ALTER TABLE routes DROP COLUMN code;
+CREATE UNIQUE INDEX idx_routes_name ON routes (lower(btrim(name))) WHERE deleted_at IS NULL;
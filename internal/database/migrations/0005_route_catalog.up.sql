@@ -0,0 +1,70 @@
+-- Route catalog. Routes gain a code, region and checkpoints. Every free-text
+-- route used on a permit without a matching route becomes a catalog entry,
+-- and every route without a code gets LEGACY-n in region Unassigned for
+-- admins to tidy up. Permits then reference routes by id. Names are matched
+-- ignoring case and surrounding spaces, as permits were matched to routes
+-- before.
+
+INSERT INTO routes (name, created_at, updated_at)
+SELECT DISTINCT ON (lower(btrim(p.route))) btrim(p.route), now(), now()
+FROM permits p
+WHERE NOT EXISTS (
+    SELECT 1 FROM routes r
+    WHERE r.deleted_at IS NULL AND lower(btrim(r.name)) = lower(btrim(p.route))
+)
+ORDER BY lower(btrim(p.route)), btrim(p.route);
+
+ALTER TABLE routes ADD COLUMN code varchar(32);
+ALTER TABLE routes ADD COLUMN region text;
+ALTER TABLE routes ADD COLUMN description text;
+ALTER TABLE routes ADD COLUMN duration_days bigint;
+ALTER TABLE routes ADD COLUMN is_active boolean DEFAULT true;
+
+UPDATE routes r
+SET code = legacy.code,
+    region = 'Unassigned',
+    duration_days = COALESCE((SELECT max(ceil(extract(epoch FROM p.end_date - p.start_date) / 86400))::bigint
+                              FROM permits p WHERE lower(btrim(p.route)) = lower(btrim(r.name))), 1),
+    is_active = r.deleted_at IS NULL
+FROM (
+    SELECT id, 'LEGACY-' || row_number() OVER (ORDER BY lower(btrim(name)), created_at) AS code
+    FROM routes
+) legacy
+WHERE legacy.id = r.id;
+
+ALTER TABLE routes ALTER COLUMN code SET NOT NULL;
+ALTER TABLE routes ALTER COLUMN region SET NOT NULL;
+ALTER TABLE routes ALTER COLUMN duration_days SET NOT NULL;
+DROP INDEX IF EXISTS idx_routes_name;
+CREATE UNIQUE INDEX idx_routes_code ON routes (code);
+CREATE INDEX idx_routes_region ON routes (region);
+CREATE INDEX idx_routes_is_active ON routes (is_active);
+
+CREATE TABLE route_checkpoints (
+    id                  uuid DEFAULT gen_random_uuid(),
+    route_id            uuid NOT NULL,
+    sequence            bigint NOT NULL,
+    name                text NOT NULL,
+    latitude            decimal(10,8) NOT NULL,
+    longitude           decimal(11,8) NOT NULL,
+    altitude_meters     bigint NOT NULL,
+    expected_day_offset bigint NOT NULL,
+    radius_meters       bigint NOT NULL DEFAULT 500,
+    created_at          timestamptz,
+    updated_at          timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_routes_checkpoints FOREIGN KEY (route_id) REFERENCES routes (id) ON DELETE CASCADE
+);
+CREATE INDEX idx_route_checkpoints_route_id ON route_checkpoints (route_id);
+CREATE UNIQUE INDEX idx_route_checkpoints_sequence ON route_checkpoints (route_id, sequence);
+
+ALTER TABLE permits ADD COLUMN route_id uuid;
+ALTER TABLE permits ADD COLUMN custom_itinerary text;
+UPDATE permits p
+SET route_id = r.id
+FROM routes r
+WHERE r.deleted_at IS NULL AND lower(btrim(r.name)) = lower(btrim(p.route));
+ALTER TABLE permits ALTER COLUMN route_id SET NOT NULL;
+ALTER TABLE permits ADD CONSTRAINT fk_permits_route FOREIGN KEY (route_id) REFERENCES routes (id);
+CREATE INDEX idx_permits_route_id ON permits (route_id);
+ALTER TABLE permits DROP COLUMN route;
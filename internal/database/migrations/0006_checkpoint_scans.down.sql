@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS permit_scans;
+DROP TABLE IF EXISTS checkpoints;
@@ -0,0 +1,45 @@
+CREATE TABLE checkpoints (
+    id                  uuid DEFAULT gen_random_uuid(),
+    code                varchar(32) NOT NULL,
+    name                text NOT NULL,
+    route_checkpoint_id uuid,
+    latitude            decimal(10,8) NOT NULL,
+    longitude           decimal(11,8) NOT NULL,
+    device_id           text,
+    is_active           boolean DEFAULT true,
+    registered_by       uuid NOT NULL,
+    last_scan_at        timestamptz,
+    created_at          timestamptz,
+    updated_at          timestamptz,
+    deleted_at          timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_checkpoints_route_checkpoint FOREIGN KEY (route_checkpoint_id) REFERENCES route_checkpoints (id) ON DELETE SET NULL
+);
+CREATE UNIQUE INDEX idx_checkpoints_code ON checkpoints (code);
+CREATE INDEX idx_checkpoints_route_checkpoint_id ON checkpoints (route_checkpoint_id);
+CREATE INDEX idx_checkpoints_device_id ON checkpoints (device_id);
+CREATE INDEX idx_checkpoints_is_active ON checkpoints (is_active);
+CREATE INDEX idx_checkpoints_deleted_at ON checkpoints (deleted_at);
+
+CREATE TABLE permit_scans (
+    id                 uuid DEFAULT gen_random_uuid(),
+    checkpoint_id      uuid NOT NULL,
+    permit_id          uuid,
+    permit_number      text,
+    result             varchar(20) NOT NULL,
+    reason             text,
+    headcount          bigint NOT NULL,
+    expected_headcount bigint NOT NULL,
+    notes              text,
+    scanned_by         uuid NOT NULL,
+    scanned_at         timestamptz NOT NULL,
+    created_at         timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_permit_scans_checkpoint FOREIGN KEY (checkpoint_id) REFERENCES checkpoints (id),
+    CONSTRAINT fk_permit_scans_permit FOREIGN KEY (permit_id) REFERENCES permits (id)
+);
+CREATE INDEX idx_permit_scans_checkpoint_id ON permit_scans (checkpoint_id);
+CREATE INDEX idx_permit_scans_permit_id ON permit_scans (permit_id);
+CREATE INDEX idx_permit_scans_permit_number ON permit_scans (permit_number);
+CREATE INDEX idx_permit_scans_result ON permit_scans (result);
+CREATE INDEX idx_permit_scans_scanned_at ON permit_scans (scanned_at);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS quota_reservations;
+DROP TABLE IF EXISTS quotas;
@@ -0,0 +1,40 @@
+CREATE TABLE quotas (
+    id         uuid DEFAULT gen_random_uuid(),
+    route_id   uuid,
+    region     text,
+    period     varchar(20) NOT NULL,
+    start_date date NOT NULL,
+    end_date   date NOT NULL,
+    capacity   bigint NOT NULL,
+    notes      text,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_quotas_route FOREIGN KEY (route_id) REFERENCES routes (id),
+    CONSTRAINT chk_quotas_target CHECK ((route_id IS NULL) <> (region IS NULL OR region = '')),
+    CONSTRAINT chk_quotas_dates CHECK (end_date >= start_date),
+    CONSTRAINT chk_quotas_capacity CHECK (capacity >= 0)
+);
+CREATE INDEX idx_quotas_route_id ON quotas (route_id);
+CREATE INDEX idx_quotas_region ON quotas (region);
+CREATE INDEX idx_quotas_start_date ON quotas (start_date);
+CREATE INDEX idx_quotas_end_date ON quotas (end_date);
+CREATE INDEX idx_quotas_deleted_at ON quotas (deleted_at);
+
+CREATE TABLE quota_reservations (
+    id          uuid DEFAULT gen_random_uuid(),
+    quota_id    uuid NOT NULL,
+    permit_id   uuid NOT NULL,
+    entry_date  date NOT NULL,
+    trekkers    bigint NOT NULL,
+    released_at timestamptz,
+    created_at  timestamptz,
+    updated_at  timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_quota_reservations_quota FOREIGN KEY (quota_id) REFERENCES quotas (id),
+    CONSTRAINT fk_quota_reservations_permit FOREIGN KEY (permit_id) REFERENCES permits (id)
+);
+CREATE UNIQUE INDEX idx_quota_reservations_quota_permit ON quota_reservations (quota_id, permit_id);
+CREATE INDEX idx_quota_reservations_permit_id ON quota_reservations (permit_id);
+CREATE INDEX idx_quota_reservations_entry_date ON quota_reservations (entry_date);
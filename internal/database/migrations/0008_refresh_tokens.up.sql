@@ -0,0 +1,29 @@
+CREATE TABLE refresh_tokens (
+    id             uuid NOT NULL,
+    user_id        uuid NOT NULL,
+    family_id      uuid NOT NULL,
+    parent_id      uuid,
+    expires_at     timestamptz NOT NULL,
+    used_at        timestamptz,
+    revoked_at     timestamptz,
+    revoked_reason text,
+    created_at     timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_refresh_tokens_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+CREATE INDEX idx_refresh_tokens_user_id ON refresh_tokens (user_id);
+CREATE INDEX idx_refresh_tokens_family_id ON refresh_tokens (family_id);
+CREATE INDEX idx_refresh_tokens_expires_at ON refresh_tokens (expires_at);
+
+CREATE TABLE revoked_access_tokens (
+    jti        text NOT NULL,
+    user_id    uuid NOT NULL,
+    expires_at timestamptz NOT NULL,
+    revoked_at timestamptz NOT NULL,
+    PRIMARY KEY (jti)
+);
+CREATE INDEX idx_revoked_access_tokens_user_id ON revoked_access_tokens (user_id);
+CREATE INDEX idx_revoked_access_tokens_expires_at ON revoked_access_tokens (expires_at);
+
+-- Access tokens issued before this instant are rejected (logout everywhere).
+ALTER TABLE users ADD COLUMN tokens_revoked_at timestamptz;
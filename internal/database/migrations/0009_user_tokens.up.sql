@@ -0,0 +1,17 @@
+CREATE TABLE user_tokens (
+    id         uuid DEFAULT gen_random_uuid(),
+    user_id    uuid NOT NULL,
+    purpose    varchar(32) NOT NULL,
+    token_hash text NOT NULL,
+    expires_at timestamptz NOT NULL,
+    used_at    timestamptz,
+    created_at timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_user_tokens_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX idx_user_tokens_token_hash ON user_tokens (token_hash);
+CREATE INDEX idx_user_tokens_user_id ON user_tokens (user_id);
+
+-- Accounts that existed before verification was introduced are trusted.
+ALTER TABLE users ADD COLUMN email_verified_at timestamptz;
+UPDATE users SET email_verified_at = created_at;
@@ -0,0 +1,23 @@
+CREATE TABLE mfa_factors (
+    id             uuid DEFAULT gen_random_uuid(),
+    user_id        uuid NOT NULL,
+    secret         text NOT NULL,
+    confirmed_at   timestamptz,
+    last_used_step bigint NOT NULL DEFAULT 0,
+    created_at     timestamptz,
+    updated_at     timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_mfa_factors_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX idx_mfa_factors_user_id ON mfa_factors (user_id);
+
+CREATE TABLE mfa_recovery_codes (
+    id         uuid DEFAULT gen_random_uuid(),
+    user_id    uuid NOT NULL,
+    code_hash  text NOT NULL,
+    used_at    timestamptz,
+    created_at timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_mfa_recovery_codes_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+CREATE INDEX idx_mfa_recovery_codes_user_id ON mfa_recovery_codes (user_id);
@@ -0,0 +1,17 @@
+CREATE TABLE login_attempts (
+    id         uuid DEFAULT gen_random_uuid(),
+    email      text NOT NULL,
+    user_id    uuid,
+    ip_address text NOT NULL,
+    result     varchar(20) NOT NULL,
+    created_at timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_login_attempts_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+CREATE INDEX idx_login_attempts_email ON login_attempts (email);
+CREATE INDEX idx_login_attempts_user_id ON login_attempts (user_id);
+CREATE INDEX idx_login_attempts_ip_address ON login_attempts (ip_address);
+CREATE INDEX idx_login_attempts_created_at ON login_attempts (created_at);
+
+ALTER TABLE users ADD COLUMN failed_login_attempts integer NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN locked_until timestamptz;
@@ -0,0 +1 @@
+ALTER TABLE permits DROP COLUMN IF EXISTS agency_id;
@@ -0,0 +1,9 @@
+-- Permits belong to the agency that issued them. Existing permits are
+-- assigned to their guide's agency, or failing that the lead trekker's.
+ALTER TABLE permits ADD COLUMN agency_id uuid;
+ALTER TABLE permits ADD CONSTRAINT fk_permits_agency FOREIGN KEY (agency_id) REFERENCES agencies (id);
+UPDATE permits SET agency_id = COALESCE(
+    (SELECT guides.agency_id FROM guides WHERE guides.id = permits.guide_id),
+    (SELECT trekkers.agency_id FROM trekkers WHERE trekkers.id = permits.trekker_id)
+);
+CREATE INDEX idx_permits_agency_id ON permits (agency_id);
@@ -0,0 +1,34 @@
+CREATE TABLE service_accounts (
+    id          uuid DEFAULT gen_random_uuid(),
+    agency_id   uuid NOT NULL,
+    name        text NOT NULL,
+    description text,
+    is_active   boolean NOT NULL DEFAULT true,
+    created_by  uuid NOT NULL,
+    created_at  timestamptz,
+    updated_at  timestamptz,
+    deleted_at  timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_service_accounts_agency FOREIGN KEY (agency_id) REFERENCES agencies (id)
+);
+CREATE INDEX idx_service_accounts_agency_id ON service_accounts (agency_id);
+CREATE INDEX idx_service_accounts_deleted_at ON service_accounts (deleted_at);
+
+CREATE TABLE api_keys (
+    id                 uuid DEFAULT gen_random_uuid(),
+    service_account_id uuid NOT NULL,
+    prefix             text NOT NULL,
+    key_hash           text NOT NULL,
+    scopes             text NOT NULL,
+    expires_at         timestamptz,
+    last_used_at       timestamptz,
+    last_used_ip       text,
+    rotated_to         uuid,
+    revoked_at         timestamptz,
+    created_by         uuid NOT NULL,
+    created_at         timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_api_keys_service_account FOREIGN KEY (service_account_id) REFERENCES service_accounts (id) ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX idx_api_keys_prefix ON api_keys (prefix);
+CREATE INDEX idx_api_keys_service_account_id ON api_keys (service_account_id);
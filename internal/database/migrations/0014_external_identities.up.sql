@@ -0,0 +1,27 @@
+CREATE TABLE external_identities (
+    id            uuid DEFAULT gen_random_uuid(),
+    user_id       uuid NOT NULL,
+    provider      varchar(64) NOT NULL,
+    issuer        text NOT NULL,
+    subject       text NOT NULL,
+    email         text NOT NULL,
+    last_login_at timestamptz,
+    created_at    timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_external_identities_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX idx_external_identities_subject ON external_identities (issuer, subject);
+CREATE INDEX idx_external_identities_user_id ON external_identities (user_id);
+
+CREATE TABLE oidc_login_states (
+    id            uuid DEFAULT gen_random_uuid(),
+    state_hash    text NOT NULL,
+    provider      varchar(64) NOT NULL,
+    nonce         text NOT NULL,
+    code_verifier text NOT NULL,
+    expires_at    timestamptz NOT NULL,
+    created_at    timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX idx_oidc_login_states_state_hash ON oidc_login_states (state_hash);
+CREATE INDEX idx_oidc_login_states_expires_at ON oidc_login_states (expires_at);
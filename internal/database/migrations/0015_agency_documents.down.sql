@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS agency_documents;
+
+ALTER TABLE agencies DROP COLUMN IF EXISTS rejection_reason;
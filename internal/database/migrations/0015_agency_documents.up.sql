@@ -0,0 +1,25 @@
+ALTER TABLE agencies ADD COLUMN rejection_reason text;
+
+CREATE TABLE agency_documents (
+    id               uuid DEFAULT gen_random_uuid(),
+    agency_id        uuid NOT NULL,
+    type             varchar(32) NOT NULL,
+    status           varchar(20) NOT NULL DEFAULT 'pending',
+    file_name        text NOT NULL,
+    content_type     text NOT NULL,
+    size             bigint NOT NULL,
+    sha256           text NOT NULL,
+    storage_key      text NOT NULL,
+    uploaded_by      uuid NOT NULL,
+    reviewed_by      uuid,
+    reviewed_at      timestamptz,
+    rejection_reason text,
+    created_at       timestamptz,
+    updated_at       timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_agency_documents_agency FOREIGN KEY (agency_id) REFERENCES agencies (id)
+);
+CREATE INDEX idx_agency_documents_agency_id ON agency_documents (agency_id);
+CREATE INDEX idx_agency_documents_status ON agency_documents (status);
+-- Only one document of each type is current; older uploads are superseded.
+CREATE UNIQUE INDEX idx_agency_documents_current ON agency_documents (agency_id, type) WHERE status <> 'superseded';
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS license_reminders;
+
+ALTER TABLE agencies DROP COLUMN IF EXISTS suspension_reason;
+ALTER TABLE guides DROP COLUMN IF EXISTS suspension_reason;
@@ -0,0 +1,15 @@
+ALTER TABLE guides ADD COLUMN suspension_reason text;
+ALTER TABLE agencies ADD COLUMN suspension_reason text;
+
+CREATE TABLE license_reminders (
+    id             uuid DEFAULT gen_random_uuid(),
+    holder_type    varchar(20) NOT NULL,
+    holder_id      uuid NOT NULL,
+    license_expiry timestamptz NOT NULL,
+    days_before    bigint NOT NULL,
+    sent_at        timestamptz NOT NULL,
+    PRIMARY KEY (id)
+);
+-- One reminder per holder, expiry date and reminder day, even when several
+-- instances run the job at once.
+CREATE UNIQUE INDEX idx_license_reminders_once ON license_reminders (holder_type, holder_id, license_expiry, days_before);
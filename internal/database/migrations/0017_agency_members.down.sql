@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS agency_invitations;
+
+ALTER TABLE users DROP COLUMN IF EXISTS agency_role;
@@ -0,0 +1,22 @@
+ALTER TABLE users ADD COLUMN agency_role varchar(20);
+
+-- Agencies so far shared one login; it becomes the agency's owner.
+UPDATE users SET agency_role = 'owner' WHERE role = 'agency' AND agency_id IS NOT NULL;
+
+CREATE TABLE agency_invitations (
+    id          uuid DEFAULT gen_random_uuid(),
+    agency_id   uuid NOT NULL,
+    email       text NOT NULL,
+    agency_role varchar(20) NOT NULL,
+    token_hash  text NOT NULL,
+    invited_by  uuid NOT NULL,
+    expires_at  timestamptz NOT NULL,
+    accepted_at timestamptz,
+    user_id     uuid,
+    revoked_at  timestamptz,
+    created_at  timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_agency_invitations_agency FOREIGN KEY (agency_id) REFERENCES agencies (id)
+);
+CREATE UNIQUE INDEX idx_agency_invitations_token_hash ON agency_invitations (token_hash);
+CREATE INDEX idx_agency_invitations_agency_id ON agency_invitations (agency_id);
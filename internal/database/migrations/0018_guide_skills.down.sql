@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS route_required_certifications;
+DROP TABLE IF EXISTS guide_certifications;
+DROP TABLE IF EXISTS guide_regions;
+DROP TABLE IF EXISTS guide_languages;
+
+ALTER TABLE guides DROP COLUMN IF EXISTS max_altitude_meters;
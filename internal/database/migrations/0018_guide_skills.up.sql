@@ -0,0 +1,60 @@
+ALTER TABLE guides ADD COLUMN max_altitude_meters bigint NOT NULL DEFAULT 0;
+
+CREATE TABLE guide_languages (
+    id         uuid DEFAULT gen_random_uuid(),
+    guide_id   uuid NOT NULL,
+    language   varchar(8) NOT NULL,
+    created_at timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_guides_languages FOREIGN KEY (guide_id) REFERENCES guides (id) ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX idx_guide_languages_guide_language ON guide_languages (guide_id, language);
+CREATE INDEX idx_guide_languages_language ON guide_languages (language);
+
+CREATE TABLE guide_regions (
+    id               uuid DEFAULT gen_random_uuid(),
+    guide_id         uuid NOT NULL,
+    region           text NOT NULL,
+    years_experience bigint NOT NULL DEFAULT 0,
+    created_at       timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_guides_regions FOREIGN KEY (guide_id) REFERENCES guides (id) ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX idx_guide_regions_guide_region ON guide_regions (guide_id, lower(region));
+CREATE INDEX idx_guide_regions_region ON guide_regions (lower(region));
+
+CREATE TABLE guide_certifications (
+    id                 uuid DEFAULT gen_random_uuid(),
+    guide_id           uuid NOT NULL,
+    type               varchar(32) NOT NULL,
+    issuer             text NOT NULL,
+    certificate_number text,
+    issue_date         date NOT NULL,
+    expiry_date        date,
+    status             varchar(20) NOT NULL DEFAULT 'pending',
+    file_name          text NOT NULL,
+    content_type       text NOT NULL,
+    size               bigint NOT NULL,
+    sha256             text NOT NULL,
+    storage_key        text NOT NULL,
+    uploaded_by        uuid NOT NULL,
+    reviewed_by        uuid,
+    reviewed_at        timestamptz,
+    rejection_reason   text,
+    created_at         timestamptz,
+    updated_at         timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_guide_certifications_guide FOREIGN KEY (guide_id) REFERENCES guides (id)
+);
+CREATE INDEX idx_guide_certifications_guide_id ON guide_certifications (guide_id);
+CREATE INDEX idx_guide_certifications_type_status ON guide_certifications (type, status);
+
+CREATE TABLE route_required_certifications (
+    id                 uuid DEFAULT gen_random_uuid(),
+    route_id           uuid NOT NULL,
+    certification_type varchar(32) NOT NULL,
+    created_at         timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_routes_required_certifications FOREIGN KEY (route_id) REFERENCES routes (id) ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX idx_route_required_certifications_type ON route_required_certifications (route_id, certification_type);
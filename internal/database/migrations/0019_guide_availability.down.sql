@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_permits_guide_dates;
+
+DROP TABLE IF EXISTS guide_blocked_periods;
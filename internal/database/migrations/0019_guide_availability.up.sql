@@ -0,0 +1,18 @@
+CREATE TABLE guide_blocked_periods (
+    id         uuid DEFAULT gen_random_uuid(),
+    guide_id   uuid NOT NULL,
+    reason     varchar(20) NOT NULL,
+    start_date date NOT NULL,
+    end_date   date NOT NULL,
+    note       text,
+    created_by uuid NOT NULL,
+    created_at timestamptz,
+    updated_at timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_guide_blocked_periods_guide FOREIGN KEY (guide_id) REFERENCES guides (id) ON DELETE CASCADE,
+    CONSTRAINT chk_guide_blocked_periods_dates CHECK (end_date >= start_date)
+);
+CREATE INDEX idx_guide_blocked_periods_guide_dates ON guide_blocked_periods (guide_id, start_date, end_date);
+
+-- Overlap checks look up a guide's active permits by date.
+CREATE INDEX idx_permits_guide_dates ON permits (guide_id, start_date, end_date) WHERE status = 'active' AND deleted_at IS NULL;
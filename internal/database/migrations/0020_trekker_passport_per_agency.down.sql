@@ -0,0 +1,17 @@
+-- Passports were unique per nationality across agencies; retire all but the
+-- oldest live trekker of each passport.
+UPDATE trekkers t
+SET deleted_at = now()
+WHERE t.deleted_at IS NULL
+  AND t.passport_number IS NOT NULL AND t.passport_number <> ''
+  AND EXISTS (
+    SELECT 1 FROM trekkers o
+    WHERE o.deleted_at IS NULL
+      AND o.nationality = t.nationality
+      AND o.passport_number = t.passport_number
+      AND (o.created_at, o.id) < (t.created_at, t.id)
+  );
+
+DROP INDEX IF EXISTS idx_trekkers_agency_passport;
+CREATE UNIQUE INDEX idx_trekkers_passport ON trekkers (nationality, passport_number)
+    WHERE passport_number IS NOT NULL AND passport_number <> '' AND deleted_at IS NULL;
@@ -0,0 +1,7 @@
+-- Agencies only see their own trekkers, so a traveller booked through two
+-- agencies is registered by each of them. Passports are unique within an
+-- agency; trekkers without an agency form one more scope.
+DROP INDEX IF EXISTS idx_trekkers_passport;
+CREATE UNIQUE INDEX idx_trekkers_agency_passport
+    ON trekkers ((COALESCE(agency_id, '00000000-0000-0000-0000-000000000000'::uuid)), nationality, passport_number)
+    WHERE passport_number IS NOT NULL AND passport_number <> '' AND deleted_at IS NULL;
@@ -0,0 +1,199 @@
+package document
+
+import (
+	_ "embed"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const baseFamily = "DejaVuSans"
+
+var (
+	//go:embed fonts/DejaVuSans.ttf
+	dejaVuSans []byte
+	//go:embed fonts/DejaVuSans-Bold.ttf
+	dejaVuSansBold []byte
+)
+
+// Fonts are the TrueType fonts permit documents are set in. The bundled
+// DejaVu Sans covers Latin, Greek and Cyrillic. Fallback fonts cover other
+// scripts, such as Noto Sans Devanagari or Noto Sans CJK; each text value
+// is set in the first font with a glyph for every character in it.
+type Fonts struct {
+	base      coverage
+	fallbacks []fallbackFont
+}
+
+type fallbackFont struct {
+	family string
+	data   []byte
+	cover  coverage
+}
+
+// LoadFonts reads the comma separated fallback TrueType font files in
+// paths. An empty list uses the bundled font only.
+func LoadFonts(paths string) (*Fonts, error) {
+	base, err := parseCoverage(dejaVuSans)
+	if err != nil {
+		return nil, fmt.Errorf("bundled font: %w", err)
+	}
+	fonts := &Fonts{base: base}
+
+	for _, path := range strings.Split(paths, ",") {
+		if path = strings.TrimSpace(path); path == "" {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read font: %w", err)
+		}
+		cover, err := parseCoverage(data)
+		if err != nil {
+			return nil, fmt.Errorf("font %s: %w", path, err)
+		}
+		fonts.fallbacks = append(fonts.fallbacks, fallbackFont{
+			family: fmt.Sprintf("Fallback%d", len(fonts.fallbacks)+1),
+			data:   data,
+			cover:  cover,
+		})
+	}
+
+	return fonts, nil
+}
+
+// family returns the font family to set text in.
+func (f *Fonts) family(text string) string {
+	if f.base.covers(text) {
+		return baseFamily
+	}
+	for _, font := range f.fallbacks {
+		if font.cover.covers(text) {
+			return font.family
+		}
+	}
+	return baseFamily
+}
+
+// coverage is the sorted, non-overlapping ranges of characters a font has
+// glyphs for.
+type coverage []runeRange
+
+type runeRange struct {
+	lo, hi rune
+}
+
+func (c coverage) has(r rune) bool {
+	i := sort.Search(len(c), func(i int) bool { return c[i].hi >= r })
+	return i < len(c) && c[i].lo <= r
+}
+
+func (c coverage) covers(text string) bool {
+	for _, r := range text {
+		if !unicode.IsSpace(r) && !c.has(r) {
+			return false
+		}
+	}
+	return true
+}
+
+var errNoUnicodeCmap = errors.New("font has no Unicode BMP character map (cmap format 4)")
+
+// parseCoverage reads the Unicode BMP character map of a TrueType font,
+// which is the one gofpdf lays out text with.
+func parseCoverage(font []byte) (coverage, error) {
+	if len(font) < 12 {
+		return nil, errors.New("not a TrueType font")
+	}
+	numTables := int(binary.BigEndian.Uint16(font[4:]))
+	var cmap []byte
+	for i := 0; i < numTables; i++ {
+		rec := 12 + 16*i
+		if rec+16 > len(font) {
+			return nil, errors.New("truncated table directory")
+		}
+		if string(font[rec:rec+4]) != "cmap" {
+			continue
+		}
+		offset := int(binary.BigEndian.Uint32(font[rec+8:]))
+		length := int(binary.BigEndian.Uint32(font[rec+12:]))
+		if offset+length > len(font) {
+			return nil, errors.New("truncated cmap table")
+		}
+		cmap = font[offset : offset+length]
+	}
+	if len(cmap) < 4 {
+		return nil, errNoUnicodeCmap
+	}
+
+	numSubtables := int(binary.BigEndian.Uint16(cmap[2:]))
+	for i := 0; i < numSubtables; i++ {
+		rec := 4 + 8*i
+		if rec+8 > len(cmap) {
+			break
+		}
+		platform := binary.BigEndian.Uint16(cmap[rec:])
+		encoding := binary.BigEndian.Uint16(cmap[rec+2:])
+		if !(platform == 3 && encoding == 1) && platform != 0 {
+			continue
+		}
+		offset := int(binary.BigEndian.Uint32(cmap[rec+4:]))
+		if offset+2 <= len(cmap) && binary.BigEndian.Uint16(cmap[offset:]) == 4 {
+			return parseCmapFormat4(cmap[offset:])
+		}
+	}
+	return nil, errNoUnicodeCmap
+}
+
+func parseCmapFormat4(table []byte) (coverage, error) {
+	if len(table) < 14 {
+		return nil, errors.New("truncated cmap subtable")
+	}
+	segCount := int(binary.BigEndian.Uint16(table[6:])) / 2
+	endCodes := 14
+	startCodes := endCodes + 2*segCount + 2
+	idDeltas := startCodes + 2*segCount
+	idRangeOffsets := idDeltas + 2*segCount
+	if idRangeOffsets+2*segCount > len(table) {
+		return nil, errors.New("truncated cmap subtable")
+	}
+	u16 := func(at int) uint16 {
+		if at+2 > len(table) {
+			return 0
+		}
+		return binary.BigEndian.Uint16(table[at:])
+	}
+
+	var cover coverage
+	for seg := 0; seg < segCount; seg++ {
+		end := int(u16(endCodes + 2*seg))
+		start := int(u16(startCodes + 2*seg))
+		delta := u16(idDeltas + 2*seg)
+		rangeOffsetAt := idRangeOffsets + 2*seg
+		rangeOffset := int(u16(rangeOffsetAt))
+
+		for code := start; code <= end && code != 0xFFFF; code++ {
+			glyph := uint16(code) + delta
+			if rangeOffset != 0 {
+				glyph = u16(rangeOffsetAt + rangeOffset + 2*(code-start))
+				if glyph != 0 {
+					glyph += delta
+				}
+			}
+			if glyph == 0 {
+				continue
+			}
+			r := rune(code)
+			if n := len(cover); n > 0 && cover[n-1].hi == r-1 {
+				cover[n-1].hi = r
+			} else {
+				cover = append(cover, runeRange{lo: r, hi: r})
+			}
+		}
+	}
+	return cover, nil
+}
@@ -0,0 +1,147 @@
+package document
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/jung-kurt/gofpdf"
+	"github.com/touros-platform/api/internal/domain"
+)
+
+const dateLayout = "02 Jan 2006"
+
+// PermitPDF lays out a printable A4 trekking permit with the permit's signed
+// QR code. Text is set in fonts, so names and places in any script they
+// cover print as entered.
+func PermitPDF(permit *domain.Permit, qrPNG []byte, fonts *Fonts) ([]byte, error) {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	w := &permitWriter{pdf: pdf, fonts: fonts, added: make(map[string]bool)}
+	pdf.AddUTF8FontFromBytes(baseFamily, "", dejaVuSans)
+	pdf.AddUTF8FontFromBytes(baseFamily, "B", dejaVuSansBold)
+
+	pdf.SetTitle(fmt.Sprintf("Trekking Permit %s", permit.PermitNumber), true)
+	pdf.SetCreator("TourOS", true)
+	pdf.SetMargins(20, 20, 20)
+	pdf.AddPage()
+
+	pdf.SetFont(baseFamily, "B", 20)
+	pdf.CellFormat(0, 12, "Trekking Permit", "", 1, "C", false, 0, "")
+	pdf.SetFont(baseFamily, "", 11)
+	pdf.CellFormat(0, 6, "Issued through TourOS - Tourism Operating System, Nepal", "", 1, "C", false, 0, "")
+	pdf.Ln(4)
+
+	w.setFont(permit.PermitNumber, "B", 14)
+	pdf.CellFormat(0, 10, "Permit No. "+permit.PermitNumber, "TB", 1, "L", false, 0, "")
+	pdf.Ln(4)
+
+	agencyName := "-"
+	if permit.Guide.Agency != nil {
+		agencyName = permit.Guide.Agency.Name
+	}
+
+	w.section("Trekker")
+	w.row("Name", permit.Trekker.FullName)
+	w.row("Nationality", orDash(permit.Trekker.Nationality))
+	w.row("Passport", orDash(permit.Trekker.PassportNumber))
+	w.row("Email", orDash(permit.Trekker.Email))
+	w.row("Phone", orDash(permit.Trekker.Phone))
+
+	w.section("Guide & Agency")
+	w.row("Guide", permit.Guide.User.FullName)
+	w.row("Guide license", permit.Guide.LicenseNumber)
+	w.row("Agency", agencyName)
+
+	w.section("Trek")
+	w.row("Valid from", permit.StartDate.Format(dateLayout))
+	w.row("Valid until", permit.EndDate.Format(dateLayout))
+	w.row("Status", string(permit.Status))
+	w.row("Issued", permit.IssuedAt.Format(dateLayout))
+	party := permit.PartySummary()
+	w.row("Party", fmt.Sprintf("%d trekkers, %d support staff", party.Trekkers, party.SupportStaff))
+	w.row("Route", fmt.Sprintf("%s (%s)", permit.Route.Name, permit.Route.Code))
+	w.row("Region", permit.Route.Region)
+	if permit.CustomItinerary != "" {
+		w.multiRow("Itinerary", permit.CustomItinerary)
+	}
+	if len(permit.Route.Checkpoints) > 0 {
+		names := make([]string, 0, len(permit.Route.Checkpoints))
+		for _, cp := range permit.Route.Checkpoints {
+			names = append(names, cp.Name)
+		}
+		w.multiRow("Checkpoints", strings.Join(names, " - "))
+	}
+
+	pdf.Ln(6)
+	if len(qrPNG) > 0 {
+		opts := gofpdf.ImageOptions{ImageType: "PNG"}
+		pdf.RegisterImageOptionsReader("qr", opts, bytes.NewReader(qrPNG))
+		x := (210 - 60) / 2.0
+		pdf.ImageOptions("qr", x, pdf.GetY(), 60, 60, true, opts, 0, "")
+		pdf.SetFont(baseFamily, "", 9)
+		pdf.CellFormat(0, 5, "Scan at checkpoints to verify this permit. Verification works offline.", "", 1, "C", false, 0, "")
+	}
+
+	if err := pdf.Error(); err != nil {
+		return nil, fmt.Errorf("failed to render permit document: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, fmt.Errorf("failed to render permit document: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// permitWriter sets each value in a font that covers it, registering
+// fallback fonts with the document the first time they are needed.
+type permitWriter struct {
+	pdf   *gofpdf.Fpdf
+	fonts *Fonts
+	added map[string]bool
+}
+
+func (w *permitWriter) setFont(text, style string, size float64) {
+	family := w.fonts.family(text)
+	if family != baseFamily && !w.added[family] {
+		for _, font := range w.fonts.fallbacks {
+			if font.family == family {
+				// Fallback fonts have no bold face; bold text uses the
+				// regular one.
+				w.pdf.AddUTF8FontFromBytes(family, "", font.data)
+				w.pdf.AddUTF8FontFromBytes(family, "B", font.data)
+			}
+		}
+		w.added[family] = true
+	}
+	w.pdf.SetFont(family, style, size)
+}
+
+func (w *permitWriter) section(title string) {
+	w.pdf.Ln(2)
+	w.pdf.SetFont(baseFamily, "B", 12)
+	w.pdf.SetFillColor(235, 235, 235)
+	w.pdf.CellFormat(0, 8, title, "", 1, "L", true, 0, "")
+	w.pdf.Ln(1)
+}
+
+func (w *permitWriter) row(label, value string) {
+	w.pdf.SetFont(baseFamily, "B", 11)
+	w.pdf.CellFormat(45, 7, label, "", 0, "L", false, 0, "")
+	w.setFont(value, "", 11)
+	w.pdf.CellFormat(0, 7, value, "", 1, "L", false, 0, "")
+}
+
+func (w *permitWriter) multiRow(label, value string) {
+	w.pdf.SetFont(baseFamily, "B", 11)
+	w.pdf.CellFormat(45, 7, label, "", 0, "L", false, 0, "")
+	w.setFont(value, "", 11)
+	w.pdf.MultiCell(0, 7, value, "", "L", false)
+}
+
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
@@ -0,0 +1,121 @@
+package document
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/touros-platform/api/internal/domain"
+)
+
+func TestBundledFontCoverage(t *testing.T) {
+	fonts, err := LoadFonts("")
+	if err != nil {
+		t.Fatalf("LoadFonts: %v", err)
+	}
+
+	tests := []struct {
+		text string
+		want bool
+	}{
+		{"Annapurna Circuit", true},
+		{"Jürgen Müller", true},
+		{"Анна Петрова", true},
+		{"राम शर्मा", false},
+		{"王小明", false},
+	}
+	for _, tt := range tests {
+		if got := fonts.base.covers(tt.text); got != tt.want {
+			t.Errorf("bundled font covers %q = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestFallbackFontChosenForUncoveredText(t *testing.T) {
+	fonts, err := LoadFonts("")
+	if err != nil {
+		t.Fatalf("LoadFonts: %v", err)
+	}
+	fonts.fallbacks = []fallbackFont{
+		{family: "Fallback1", data: dejaVuSans, cover: coverage{{lo: 0x0900, hi: 0x097F}}},
+		{family: "Fallback2", data: dejaVuSans, cover: coverage{{lo: 0x4E00, hi: 0x9FFF}}},
+	}
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Анна Петрова", baseFamily},
+		{"राम शर्मा", "Fallback1"},
+		{"王小明", "Fallback2"},
+		{"राम 王", baseFamily},
+	}
+	for _, tt := range tests {
+		if got := fonts.family(tt.text); got != tt.want {
+			t.Errorf("family(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+
+	permit := testPermit()
+	permit.Trekker.FullName = "राम शर्मा"
+	permit.Guide.User.FullName = "王小明"
+	if _, err := PermitPDF(permit, nil, fonts); err != nil {
+		t.Fatalf("PermitPDF with fallback fonts: %v", err)
+	}
+}
+
+func TestPermitPDFEmbedsUnicodeFont(t *testing.T) {
+	fonts, err := LoadFonts("")
+	if err != nil {
+		t.Fatalf("LoadFonts: %v", err)
+	}
+
+	pdf, err := PermitPDF(testPermit(), nil, fonts)
+	if err != nil {
+		t.Fatalf("PermitPDF: %v", err)
+	}
+	if !bytes.HasPrefix(pdf, []byte("%PDF-")) {
+		t.Fatal("output is not a PDF")
+	}
+	if !bytes.Contains(pdf, []byte("/FontFile2")) || !bytes.Contains(pdf, []byte("/BaseFont /utf8dejavusans")) {
+		t.Fatal("PDF does not embed the TrueType font")
+	}
+	if bytes.Contains(pdf, []byte("/Helvetica")) {
+		t.Fatal("PDF still uses the Helvetica core font")
+	}
+}
+
+func TestLoadFontsRejectsNonFonts(t *testing.T) {
+	if _, err := LoadFonts("permit_pdf_test.go"); err == nil {
+		t.Fatal("loaded a Go source file as a font")
+	}
+	if _, err := LoadFonts("missing.ttf"); err == nil {
+		t.Fatal("loaded a missing font file")
+	}
+}
+
+func testPermit() *domain.Permit {
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	return &domain.Permit{
+		PermitNumber: "TP-2026-000123",
+		StartDate:    start,
+		EndDate:      start.AddDate(0, 0, 14),
+		IssuedAt:     start.AddDate(0, 0, -7),
+		Status:       domain.PermitStatusActive,
+		Trekker: domain.Trekker{
+			FullName:       "Анна Петрова",
+			Nationality:    "RU",
+			PassportNumber: "751234567",
+		},
+		Guide: domain.Guide{
+			LicenseNumber: "GL-4411",
+			User:          domain.User{FullName: "Pemba Sherpa"},
+		},
+		Route: domain.Route{
+			Code:   "ACAP-01",
+			Name:   "Annapurna Circuit",
+			Region: "Annapurna",
+		},
+		CustomItinerary: "Manang – Thorong La – Muktinath",
+	}
+}
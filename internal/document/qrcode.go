@@ -0,0 +1,69 @@
+package document
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+const (
+	DefaultQRSize = 256
+	MaxQRSize     = 2048
+)
+
+// ParseRecoveryLevel maps the L/M/Q/H error-correction letters to a QR
+// recovery level. An empty string selects M.
+func ParseRecoveryLevel(level string) (qrcode.RecoveryLevel, error) {
+	switch strings.ToUpper(level) {
+	case "L":
+		return qrcode.Low, nil
+	case "", "M":
+		return qrcode.Medium, nil
+	case "Q":
+		return qrcode.High, nil
+	case "H":
+		return qrcode.Highest, nil
+	default:
+		return 0, fmt.Errorf("invalid error-correction level %q, expected L, M, Q or H", level)
+	}
+}
+
+// QRCodePNG renders content as a square PNG of size pixels.
+func QRCodePNG(content string, level qrcode.RecoveryLevel, size int) ([]byte, error) {
+	code, err := qrcode.New(content, level)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode QR code: %w", err)
+	}
+	return code.PNG(size)
+}
+
+// QRCodeSVG renders content as a square SVG of size user units, with one
+// path covering all dark modules.
+func QRCodeSVG(content string, level qrcode.RecoveryLevel, size int) ([]byte, error) {
+	code, err := qrcode.New(content, level)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode QR code: %w", err)
+	}
+
+	bitmap := code.Bitmap()
+	modules := len(bitmap)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<?xml version="1.0" encoding="UTF-8"?>`+"\n")
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`,
+		size, size, modules, modules)
+	fmt.Fprintf(&buf, `<rect width="%d" height="%d" fill="#ffffff"/>`, modules, modules)
+	buf.WriteString(`<path fill="#000000" d="`)
+	for y, row := range bitmap {
+		for x, dark := range row {
+			if dark {
+				fmt.Fprintf(&buf, "M%d %dh1v1h-1z", x, y)
+			}
+		}
+	}
+	buf.WriteString(`"/></svg>`)
+
+	return buf.Bytes(), nil
+}
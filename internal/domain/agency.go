@@ -28,9 +28,15 @@ type Agency struct {
 	LicenseExpiry      *time.Time   `gorm:"column:license_expiry;index"`
 	VerifiedAt         *time.Time   `gorm:"column:verified_at"`
 	VerifiedBy         *uuid.UUID   `gorm:"type:uuid;column:verified_by"`
-	CreatedAt          time.Time
-	UpdatedAt          time.Time
-	DeletedAt          gorm.DeletedAt `gorm:"index"`
+	// RejectionReason tells a rejected agency what to fix before it
+	// resubmits its documents.
+	RejectionReason string `gorm:"column:rejection_reason;type:text"`
+	// SuspensionReason records why the agency was suspended, such as an
+	// expired license.
+	SuspensionReason string `gorm:"column:suspension_reason;type:text"`
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	DeletedAt        gorm.DeletedAt `gorm:"index"`
 }
 
 func (Agency) TableName() string {
@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AgencyDocumentType string
+
+const (
+	AgencyDocumentRegistrationCertificate AgencyDocumentType = "registration_certificate"
+	AgencyDocumentTaxClearance            AgencyDocumentType = "tax_clearance"
+	AgencyDocumentLicense                 AgencyDocumentType = "license"
+	AgencyDocumentInsurance               AgencyDocumentType = "insurance"
+)
+
+// RequiredAgencyDocuments must all be approved before an agency can be
+// verified, in the order reviewers see them on the checklist.
+var RequiredAgencyDocuments = []AgencyDocumentType{
+	AgencyDocumentRegistrationCertificate,
+	AgencyDocumentTaxClearance,
+	AgencyDocumentLicense,
+	AgencyDocumentInsurance,
+}
+
+func (t AgencyDocumentType) Valid() bool {
+	for _, required := range RequiredAgencyDocuments {
+		if t == required {
+			return true
+		}
+	}
+	return false
+}
+
+type AgencyDocumentStatus string
+
+const (
+	AgencyDocumentPending  AgencyDocumentStatus = "pending"
+	AgencyDocumentApproved AgencyDocumentStatus = "approved"
+	AgencyDocumentRejected AgencyDocumentStatus = "rejected"
+	// AgencyDocumentSuperseded marks a document replaced by a later upload
+	// of the same type. It is kept as review history.
+	AgencyDocumentSuperseded AgencyDocumentStatus = "superseded"
+)
+
+// AgencyDocument is a file an agency submitted as evidence for
+// verification. The file itself lives in the blob store under StorageKey.
+type AgencyDocument struct {
+	ID              uuid.UUID            `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	AgencyID        uuid.UUID            `gorm:"type:uuid;not null;index"`
+	Type            AgencyDocumentType   `gorm:"type:varchar(32);not null"`
+	Status          AgencyDocumentStatus `gorm:"type:varchar(20);not null;default:'pending';index"`
+	FileName        string               `gorm:"column:file_name;not null"`
+	ContentType     string               `gorm:"column:content_type;not null"`
+	Size            int64                `gorm:"not null"`
+	SHA256          string               `gorm:"column:sha256;not null"`
+	StorageKey      string               `gorm:"column:storage_key;not null" json:"-"`
+	UploadedBy      uuid.UUID            `gorm:"type:uuid;not null"`
+	ReviewedBy      *uuid.UUID           `gorm:"type:uuid"`
+	ReviewedAt      *time.Time           `gorm:"column:reviewed_at"`
+	RejectionReason string               `gorm:"column:rejection_reason;type:text"`
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+func (AgencyDocument) TableName() string {
+	return "agency_documents"
+}
@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AgencyRole is what an agency user may do within their agency. Owners
+// manage all staff, managers manage clerks, and clerks issue permits.
+type AgencyRole string
+
+const (
+	AgencyRoleOwner   AgencyRole = "owner"
+	AgencyRoleManager AgencyRole = "manager"
+	AgencyRoleClerk   AgencyRole = "clerk"
+)
+
+func (r AgencyRole) Valid() bool {
+	switch r {
+	case AgencyRoleOwner, AgencyRoleManager, AgencyRoleClerk:
+		return true
+	}
+	return false
+}
+
+// AgencyInvitation invites someone by email to join an agency with an
+// agency role. Only the SHA-256 hash of the emailed token is stored.
+type AgencyInvitation struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	AgencyID   uuid.UUID  `gorm:"type:uuid;not null;index"`
+	Email      string     `gorm:"not null"`
+	AgencyRole AgencyRole `gorm:"column:agency_role;type:varchar(20);not null"`
+	TokenHash  string     `gorm:"column:token_hash;uniqueIndex;not null" json:"-"`
+	InvitedBy  uuid.UUID  `gorm:"type:uuid;not null"`
+	ExpiresAt  time.Time  `gorm:"column:expires_at;not null"`
+	AcceptedAt *time.Time `gorm:"column:accepted_at"`
+	// UserID is the account created when the invitation was accepted.
+	UserID    *uuid.UUID `gorm:"type:uuid"`
+	RevokedAt *time.Time `gorm:"column:revoked_at"`
+	CreatedAt time.Time
+}
+
+func (AgencyInvitation) TableName() string {
+	return "agency_invitations"
+}
+
+// Pending reports whether the invitation can still be accepted.
+func (i *AgencyInvitation) Pending(now time.Time) bool {
+	return i.AcceptedAt == nil && i.RevokedAt == nil && now.Before(i.ExpiresAt)
+}
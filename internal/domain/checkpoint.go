@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Checkpoint is a staffed station or scanning device where permits are
+// checked. It may be tied to a checkpoint in the route catalog.
+type Checkpoint struct {
+	ID                uuid.UUID        `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	Code              string           `gorm:"type:varchar(32);uniqueIndex;not null"`
+	Name              string           `gorm:"not null"`
+	RouteCheckpointID *uuid.UUID       `gorm:"type:uuid;index"`
+	RouteCheckpoint   *RouteCheckpoint `gorm:"foreignKey:RouteCheckpointID"`
+	Latitude          float64          `gorm:"type:decimal(10,8);not null"`
+	Longitude         float64          `gorm:"type:decimal(11,8);not null"`
+	DeviceID          string           `gorm:"column:device_id;index"`
+	IsActive          bool             `gorm:"column:is_active;default:true;index"`
+	RegisteredBy      uuid.UUID        `gorm:"type:uuid;column:registered_by;not null"`
+	LastScanAt        *time.Time       `gorm:"column:last_scan_at"`
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+	DeletedAt         gorm.DeletedAt `gorm:"index"`
+}
+
+func (Checkpoint) TableName() string {
+	return "checkpoints"
+}
+
+type ScanResult string
+
+const (
+	ScanResultValid       ScanResult = "valid"
+	ScanResultInvalid     ScanResult = "invalid_signature"
+	ScanResultNotFound    ScanResult = "not_found"
+	ScanResultNotActive   ScanResult = "not_active"
+	ScanResultNotYetValid ScanResult = "not_yet_valid"
+	ScanResultExpired     ScanResult = "expired"
+)
+
+// PermitScan records one validation of a permit at a checkpoint. PermitID is
+// empty when the scanned code could not be matched to a permit.
+type PermitScan struct {
+	ID                uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	CheckpointID      uuid.UUID  `gorm:"type:uuid;not null;index"`
+	Checkpoint        Checkpoint `gorm:"foreignKey:CheckpointID"`
+	PermitID          *uuid.UUID `gorm:"type:uuid;index"`
+	PermitNumber      string     `gorm:"column:permit_number;index"`
+	Result            ScanResult `gorm:"type:varchar(20);not null;index"`
+	Reason            string     `gorm:"type:text"`
+	Headcount         int        `gorm:"column:headcount;not null"`
+	ExpectedHeadcount int        `gorm:"column:expected_headcount;not null"`
+	Notes             string     `gorm:"type:text"`
+	ScannedBy         uuid.UUID  `gorm:"type:uuid;column:scanned_by;not null"`
+	ScannedAt         time.Time  `gorm:"column:scanned_at;not null;index"`
+	CreatedAt         time.Time
+}
+
+func (PermitScan) TableName() string {
+	return "permit_scans"
+}
+
+// HeadcountMismatch reports whether the party counted at the checkpoint
+// differs from the party on the permit.
+func (s *PermitScan) HeadcountMismatch() bool {
+	return s.PermitID != nil && s.Headcount != s.ExpectedHeadcount
+}
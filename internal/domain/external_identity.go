@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ExternalIdentity links a user to their account at an OpenID Connect
+// provider, identified by the provider's issuer and subject.
+type ExternalIdentity struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID      uuid.UUID  `gorm:"type:uuid;not null;index"`
+	Provider    string     `gorm:"type:varchar(64);not null"`
+	Issuer      string     `gorm:"not null;uniqueIndex:idx_external_identities_subject"`
+	Subject     string     `gorm:"not null;uniqueIndex:idx_external_identities_subject"`
+	Email       string     `gorm:"not null"`
+	LastLoginAt *time.Time `gorm:"column:last_login_at"`
+	CreatedAt   time.Time
+}
+
+func (ExternalIdentity) TableName() string {
+	return "external_identities"
+}
+
+// OIDCLoginState holds the secrets of an OIDC login between the redirect to
+// the provider and the callback. Only the SHA-256 hash of the state is
+// stored; the code verifier never leaves the server.
+type OIDCLoginState struct {
+	ID           uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	StateHash    string    `gorm:"column:state_hash;uniqueIndex;not null"`
+	Provider     string    `gorm:"type:varchar(64);not null"`
+	Nonce        string    `gorm:"not null"`
+	CodeVerifier string    `gorm:"column:code_verifier;not null" json:"-"`
+	ExpiresAt    time.Time `gorm:"column:expires_at;not null"`
+	CreatedAt    time.Time
+}
+
+func (OIDCLoginState) TableName() string {
+	return "oidc_login_states"
+}
@@ -30,11 +30,46 @@ type Guide struct {
 	VerifiedAt       *time.Time  `gorm:"column:verified_at"`
 	VerifiedBy       *uuid.UUID  `gorm:"type:uuid;column:verified_by"`
 	LastCheckIn      *time.Time  `gorm:"column:last_check_in;index"`
-	CreatedAt        time.Time
-	UpdatedAt        time.Time
-	DeletedAt        gorm.DeletedAt `gorm:"index"`
+	// SuspensionReason records why the guide was suspended, such as an
+	// expired license.
+	SuspensionReason string `gorm:"column:suspension_reason;type:text"`
+	// MaxAltitudeMeters is the highest altitude the guide is qualified to
+	// lead at; 0 means not recorded.
+	MaxAltitudeMeters int             `gorm:"column:max_altitude_meters;not null;default:0"`
+	Languages         []GuideLanguage `gorm:"foreignKey:GuideID"`
+	Regions           []GuideRegion   `gorm:"foreignKey:GuideID"`
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+	DeletedAt         gorm.DeletedAt `gorm:"index"`
 }
 
 func (Guide) TableName() string {
 	return "guides"
 }
+
+// GuideLanguage is a language the guide speaks, as a lowercase ISO 639-1
+// code such as "en" or "ne".
+type GuideLanguage struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	GuideID   uuid.UUID `gorm:"type:uuid;not null;index"`
+	Language  string    `gorm:"type:varchar(8);not null"`
+	CreatedAt time.Time
+}
+
+func (GuideLanguage) TableName() string {
+	return "guide_languages"
+}
+
+// GuideRegion is a region the guide has experience in. Region names match
+// the route catalog's regions, ignoring case.
+type GuideRegion struct {
+	ID              uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	GuideID         uuid.UUID `gorm:"type:uuid;not null;index"`
+	Region          string    `gorm:"not null"`
+	YearsExperience int       `gorm:"column:years_experience;not null;default:0"`
+	CreatedAt       time.Time
+}
+
+func (GuideRegion) TableName() string {
+	return "guide_regions"
+}
@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type BlockedPeriodReason string
+
+const (
+	BlockedPeriodLeave    BlockedPeriodReason = "leave"
+	BlockedPeriodTraining BlockedPeriodReason = "training"
+	BlockedPeriodMedical  BlockedPeriodReason = "medical"
+	BlockedPeriodOther    BlockedPeriodReason = "other"
+)
+
+func (r BlockedPeriodReason) Valid() bool {
+	switch r {
+	case BlockedPeriodLeave, BlockedPeriodTraining, BlockedPeriodMedical, BlockedPeriodOther:
+		return true
+	}
+	return false
+}
+
+// GuideBlockedPeriod is a stretch of days, StartDate through EndDate
+// inclusive, when the guide cannot be assigned to permits.
+type GuideBlockedPeriod struct {
+	ID        uuid.UUID           `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	GuideID   uuid.UUID           `gorm:"type:uuid;not null;index"`
+	Reason    BlockedPeriodReason `gorm:"type:varchar(20);not null"`
+	StartDate time.Time           `gorm:"column:start_date;type:date;not null"`
+	EndDate   time.Time           `gorm:"column:end_date;type:date;not null"`
+	Note      string              `gorm:"type:text"`
+	CreatedBy uuid.UUID           `gorm:"type:uuid;not null"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (GuideBlockedPeriod) TableName() string {
+	return "guide_blocked_periods"
+}
@@ -0,0 +1,77 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CertificationType string
+
+const (
+	CertificationWildernessFirstAid CertificationType = "wilderness_first_aid"
+	CertificationHighAltitudeRescue CertificationType = "high_altitude_rescue"
+	CertificationMountaineering     CertificationType = "mountaineering"
+)
+
+var CertificationTypes = []CertificationType{
+	CertificationWildernessFirstAid,
+	CertificationHighAltitudeRescue,
+	CertificationMountaineering,
+}
+
+func (t CertificationType) Valid() bool {
+	for _, known := range CertificationTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+type CertificationStatus string
+
+const (
+	CertificationPending  CertificationStatus = "pending"
+	CertificationApproved CertificationStatus = "approved"
+	CertificationRejected CertificationStatus = "rejected"
+)
+
+// GuideCertification is a qualification a guide holds, backed by a scanned
+// certificate that an admin reviews. The file lives in the blob store under
+// StorageKey.
+type GuideCertification struct {
+	ID                uuid.UUID           `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	GuideID           uuid.UUID           `gorm:"type:uuid;not null;index"`
+	Type              CertificationType   `gorm:"type:varchar(32);not null"`
+	Issuer            string              `gorm:"not null"`
+	CertificateNumber string              `gorm:"column:certificate_number"`
+	IssueDate         time.Time           `gorm:"column:issue_date;type:date;not null"`
+	ExpiryDate        *time.Time          `gorm:"column:expiry_date;type:date"`
+	Status            CertificationStatus `gorm:"type:varchar(20);not null;default:'pending'"`
+	FileName          string              `gorm:"column:file_name;not null"`
+	ContentType       string              `gorm:"column:content_type;not null"`
+	Size              int64               `gorm:"not null"`
+	SHA256            string              `gorm:"column:sha256;not null"`
+	StorageKey        string              `gorm:"column:storage_key;not null" json:"-"`
+	UploadedBy        uuid.UUID           `gorm:"type:uuid;not null"`
+	ReviewedBy        *uuid.UUID          `gorm:"type:uuid"`
+	ReviewedAt        *time.Time          `gorm:"column:reviewed_at"`
+	RejectionReason   string              `gorm:"column:rejection_reason;type:text"`
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+func (GuideCertification) TableName() string {
+	return "guide_certifications"
+}
+
+// ValidThrough reports whether the certification is approved and has not
+// expired by the given time. A certification without an expiry date does
+// not expire.
+func (c *GuideCertification) ValidThrough(t time.Time) bool {
+	if c.Status != CertificationApproved {
+		return false
+	}
+	return c.ExpiryDate == nil || c.ExpiryDate.After(t)
+}
@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LicenseHolderType is the kind of record a license belongs to.
+type LicenseHolderType string
+
+const (
+	LicenseHolderGuide  LicenseHolderType = "guide"
+	LicenseHolderAgency LicenseHolderType = "agency"
+)
+
+// LicenseReminder records a reminder sent ahead of a license expiry, so
+// that each reminder goes out once per expiry date. Renewing the license
+// moves the expiry date and starts the reminders over.
+type LicenseReminder struct {
+	ID            uuid.UUID         `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	HolderType    LicenseHolderType `gorm:"type:varchar(20);not null"`
+	HolderID      uuid.UUID         `gorm:"type:uuid;not null"`
+	LicenseExpiry time.Time         `gorm:"column:license_expiry;not null"`
+	DaysBefore    int               `gorm:"column:days_before;not null"`
+	SentAt        time.Time         `gorm:"column:sent_at;not null"`
+}
+
+func (LicenseReminder) TableName() string {
+	return "license_reminders"
+}
@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type LoginAttemptResult string
+
+const (
+	LoginSucceeded    LoginAttemptResult = "success"
+	LoginBadPassword  LoginAttemptResult = "bad_password"
+	LoginUnknownEmail LoginAttemptResult = "unknown_email"
+	LoginBadMFACode   LoginAttemptResult = "bad_mfa_code"
+	LoginLocked       LoginAttemptResult = "locked"
+	LoginIPThrottled  LoginAttemptResult = "ip_throttled"
+)
+
+// LoginAttempt records one password or MFA step of a login. UserID is nil
+// when the email did not match an account.
+type LoginAttempt struct {
+	ID        uuid.UUID          `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	Email     string             `gorm:"not null;index"`
+	UserID    *uuid.UUID         `gorm:"type:uuid;index"`
+	IPAddress string             `gorm:"column:ip_address;not null;index"`
+	Result    LoginAttemptResult `gorm:"type:varchar(20);not null"`
+	CreatedAt time.Time          `gorm:"index"`
+}
+
+func (LoginAttempt) TableName() string {
+	return "login_attempts"
+}
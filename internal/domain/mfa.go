@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MFAFactor is a user's TOTP authenticator. It is enrolled unconfirmed and
+// only protects the account once a first code has been confirmed.
+// LastUsedStep holds the time step of the last accepted code so that codes
+// cannot be replayed.
+type MFAFactor struct {
+	ID           uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID       uuid.UUID  `gorm:"type:uuid;uniqueIndex;not null"`
+	Secret       string     `gorm:"not null" json:"-"`
+	ConfirmedAt  *time.Time `gorm:"column:confirmed_at"`
+	LastUsedStep int64      `gorm:"column:last_used_step;not null;default:0" json:"-"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+func (MFAFactor) TableName() string {
+	return "mfa_factors"
+}
+
+// MFARecoveryCode is a single-use code that stands in for a TOTP code when
+// the authenticator is lost. Only its SHA-256 hash is stored.
+type MFARecoveryCode struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null;index"`
+	CodeHash  string     `gorm:"column:code_hash;not null"`
+	UsedAt    *time.Time `gorm:"column:used_at"`
+	CreatedAt time.Time
+}
+
+func (MFARecoveryCode) TableName() string {
+	return "mfa_recovery_codes"
+}
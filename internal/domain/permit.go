@@ -16,26 +16,28 @@ const (
 )
 
 type Permit struct {
-	ID           uuid.UUID    `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
-	PermitNumber string       `gorm:"column:permit_number;uniqueIndex;not null"`
-	GuideID      uuid.UUID    `gorm:"type:uuid;not null;index"`
-	Guide        Guide        `gorm:"foreignKey:GuideID"`
-	ClientID     uuid.UUID    `gorm:"type:uuid;not null"`
-	ClientName   string       `gorm:"column:client_name;not null"`
-	ClientEmail  string       `gorm:"column:client_email"`
-	ClientPhone  string       `gorm:"column:client_phone"`
-	StartDate    time.Time    `gorm:"column:start_date;not null;index"`
-	EndDate      time.Time    `gorm:"column:end_date;not null;index"`
-	Route        string       `gorm:"type:text;not null"`
-	Status       PermitStatus `gorm:"type:varchar(20);default:'active';index"`
-	QRCode       string       `gorm:"column:qr_code;type:text"`
-	IssuedBy     uuid.UUID    `gorm:"type:uuid;column:issued_by;not null"`
-	IssuedAt     time.Time    `gorm:"column:issued_at;default:CURRENT_TIMESTAMP"`
-	RevokedAt    *time.Time   `gorm:"column:revoked_at"`
-	RevokedBy    *uuid.UUID   `gorm:"type:uuid;column:revoked_by"`
-	CreatedAt    time.Time
-	UpdatedAt    time.Time
-	DeletedAt    gorm.DeletedAt `gorm:"index"`
+	ID              uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	PermitNumber    string         `gorm:"column:permit_number;uniqueIndex;not null"`
+	GuideID         uuid.UUID      `gorm:"type:uuid;not null;index"`
+	Guide           Guide          `gorm:"foreignKey:GuideID"`
+	TrekkerID       uuid.UUID      `gorm:"type:uuid;not null;index"`
+	Trekker         Trekker        `gorm:"foreignKey:TrekkerID"`
+	AgencyID        *uuid.UUID     `gorm:"type:uuid;index"`
+	Members         []PermitMember `gorm:"foreignKey:PermitID"`
+	StartDate       time.Time      `gorm:"column:start_date;not null;index"`
+	EndDate         time.Time      `gorm:"column:end_date;not null;index"`
+	RouteID         uuid.UUID      `gorm:"type:uuid;not null;index"`
+	Route           Route          `gorm:"foreignKey:RouteID"`
+	CustomItinerary string         `gorm:"column:custom_itinerary;type:text"`
+	Status          PermitStatus   `gorm:"type:varchar(20);default:'active';index"`
+	QRCode          string         `gorm:"column:qr_code;type:text"`
+	IssuedBy        uuid.UUID      `gorm:"type:uuid;column:issued_by;not null"`
+	IssuedAt        time.Time      `gorm:"column:issued_at;default:CURRENT_TIMESTAMP"`
+	RevokedAt       *time.Time     `gorm:"column:revoked_at"`
+	RevokedBy       *uuid.UUID     `gorm:"type:uuid;column:revoked_by"`
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	DeletedAt       gorm.DeletedAt `gorm:"index"`
 }
 
 func (Permit) TableName() string {
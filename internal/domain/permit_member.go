@@ -0,0 +1,89 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PartyRole string
+
+const (
+	PartyRoleTrekker PartyRole = "trekker"
+	PartyRolePorter  PartyRole = "porter"
+	PartyRoleSupport PartyRole = "support"
+)
+
+func (r PartyRole) Valid() bool {
+	switch r {
+	case PartyRoleTrekker, PartyRolePorter, PartyRoleSupport:
+		return true
+	}
+	return false
+}
+
+type MemberStatus string
+
+const (
+	MemberStatusPending   MemberStatus = "pending"
+	MemberStatusValidated MemberStatus = "validated"
+	MemberStatusRejected  MemberStatus = "rejected"
+	MemberStatusWithdrawn MemberStatus = "withdrawn"
+)
+
+// PermitMember is one person travelling under a permit. Trekkers reference
+// the trekker registry; porters and other support staff are recorded by name
+// and identity document only.
+type PermitMember struct {
+	ID         uuid.UUID    `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	PermitID   uuid.UUID    `gorm:"type:uuid;not null;index"`
+	Role       PartyRole    `gorm:"type:varchar(20);not null;index"`
+	TrekkerID  *uuid.UUID   `gorm:"type:uuid;index"`
+	Trekker    *Trekker     `gorm:"foreignKey:TrekkerID"`
+	FullName   string       `gorm:"column:full_name"`
+	Phone      string       `gorm:"column:phone"`
+	IDNumber   string       `gorm:"column:id_number"`
+	Status     MemberStatus `gorm:"type:varchar(20);default:'pending';index"`
+	StatusNote string       `gorm:"column:status_note;type:text"`
+	StatusAt   *time.Time   `gorm:"column:status_at"`
+	StatusBy   *uuid.UUID   `gorm:"type:uuid;column:status_by"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+func (PermitMember) TableName() string {
+	return "permit_members"
+}
+
+// PartySummary counts the members of a permit's party, ignoring withdrawn
+// members.
+type PartySummary struct {
+	Trekkers     int
+	SupportStaff int
+	Validated    int
+	Pending      int
+	Rejected     int
+}
+
+func (p *Permit) PartySummary() PartySummary {
+	var summary PartySummary
+	for _, m := range p.Members {
+		if m.Status == MemberStatusWithdrawn {
+			continue
+		}
+		if m.Role == PartyRoleTrekker {
+			summary.Trekkers++
+		} else {
+			summary.SupportStaff++
+		}
+		switch m.Status {
+		case MemberStatusValidated:
+			summary.Validated++
+		case MemberStatusRejected:
+			summary.Rejected++
+		default:
+			summary.Pending++
+		}
+	}
+	return summary
+}
@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type QuotaPeriod string
+
+const (
+	// QuotaPeriodDaily limits the trekkers entering on each day of the range.
+	QuotaPeriodDaily QuotaPeriod = "daily"
+	// QuotaPeriodSeason limits the trekkers entering over the whole range.
+	QuotaPeriodSeason QuotaPeriod = "season"
+)
+
+// Quota caps the number of trekkers entering a route, or every route in a
+// region, between StartDate and EndDate (inclusive dates). Exactly one of
+// RouteID and Region is set.
+type Quota struct {
+	ID        uuid.UUID   `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	RouteID   *uuid.UUID  `gorm:"type:uuid;index"`
+	Route     *Route      `gorm:"foreignKey:RouteID"`
+	Region    string      `gorm:"index"`
+	Period    QuotaPeriod `gorm:"type:varchar(20);not null"`
+	StartDate time.Time   `gorm:"column:start_date;type:date;not null;index"`
+	EndDate   time.Time   `gorm:"column:end_date;type:date;not null;index"`
+	Capacity  int         `gorm:"not null"`
+	Notes     string      `gorm:"type:text"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
+
+func (Quota) TableName() string {
+	return "quotas"
+}
+
+// QuotaReservation holds capacity in a quota for the trekkers on a permit.
+// Reservations are released when the permit is revoked.
+type QuotaReservation struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	QuotaID    uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_quota_reservations_quota_permit"`
+	PermitID   uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_quota_reservations_quota_permit;index"`
+	EntryDate  time.Time  `gorm:"column:entry_date;type:date;not null;index"`
+	Trekkers   int        `gorm:"not null"`
+	ReleasedAt *time.Time `gorm:"column:released_at"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+func (QuotaReservation) TableName() string {
+	return "quota_reservations"
+}
+
+// QuotaUsage is the state of one quota on one entry date.
+type QuotaUsage struct {
+	Quota     Quota
+	Date      time.Time
+	Reserved  int
+	Remaining int
+}
+
+// EntryDate is the calendar date a permit's party enters its route, which is
+// the date quotas are charged against.
+func (p *Permit) EntryDate() time.Time {
+	y, m, d := p.StartDate.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
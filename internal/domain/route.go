@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Route is a trekking route in the catalog, such as the Annapurna Circuit.
+// It carries the party size limits that permits on the route must respect.
+type Route struct {
+	ID              uuid.UUID         `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	Code            string            `gorm:"type:varchar(32);uniqueIndex;not null"`
+	Name            string            `gorm:"not null"`
+	Region          string            `gorm:"not null;index"`
+	Description     string            `gorm:"type:text"`
+	DurationDays    int               `gorm:"column:duration_days;not null"`
+	MinTrekkers     int               `gorm:"column:min_trekkers;not null;default:1"`
+	MaxTrekkers     int               `gorm:"column:max_trekkers;not null;default:15"`
+	MaxSupportStaff int               `gorm:"column:max_support_staff;not null;default:15"`
+	IsActive        bool              `gorm:"column:is_active;default:true;index"`
+	Checkpoints     []RouteCheckpoint `gorm:"foreignKey:RouteID"`
+	// RequiredCertifications lists the certifications a guide must hold,
+	// approved and valid for the whole trek, to lead a permit on the route.
+	RequiredCertifications []RouteCertification `gorm:"foreignKey:RouteID"`
+	CreatedAt              time.Time
+	UpdatedAt              time.Time
+	DeletedAt              gorm.DeletedAt `gorm:"index"`
+}
+
+func (Route) TableName() string {
+	return "routes"
+}
+
+// RouteCheckpoint is a point along a route where parties are expected to
+// pass, ordered by Sequence. ExpectedDayOffset is the trek day (counted from
+// the permit start date, starting at 0) on which a party should reach it.
+type RouteCheckpoint struct {
+	ID                uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	RouteID           uuid.UUID `gorm:"type:uuid;not null;index"`
+	Sequence          int       `gorm:"not null"`
+	Name              string    `gorm:"not null"`
+	Latitude          float64   `gorm:"type:decimal(10,8);not null"`
+	Longitude         float64   `gorm:"type:decimal(11,8);not null"`
+	AltitudeMeters    int       `gorm:"column:altitude_meters;not null"`
+	ExpectedDayOffset int       `gorm:"column:expected_day_offset;not null"`
+	RadiusMeters      int       `gorm:"column:radius_meters;not null;default:500"`
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+func (RouteCheckpoint) TableName() string {
+	return "route_checkpoints"
+}
+
+// RouteCertification is a certification the route requires of guides.
+type RouteCertification struct {
+	ID                uuid.UUID         `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	RouteID           uuid.UUID         `gorm:"type:uuid;not null;index"`
+	CertificationType CertificationType `gorm:"column:certification_type;type:varchar(32);not null"`
+	CreatedAt         time.Time
+}
+
+func (RouteCertification) TableName() string {
+	return "route_required_certifications"
+}
+
+// MaxAltitude returns the highest checkpoint altitude on the route.
+func (r *Route) MaxAltitude() int {
+	highest := 0
+	for _, cp := range r.Checkpoints {
+		if cp.AltitudeMeters > highest {
+			highest = cp.AltitudeMeters
+		}
+	}
+	return highest
+}
@@ -15,6 +15,7 @@ const (
 	IncidentTypeMedical IncidentType = "medical"
 	IncidentTypeWeather IncidentType = "weather"
 	IncidentTypeOther   IncidentType = "other"
+	IncidentTypeOverdue IncidentType = "overdue_check_in"
 )
 
 type IncidentStatus string
@@ -26,6 +27,17 @@ const (
 	IncidentStatusClosed     IncidentStatus = "closed"
 )
 
+// EscalationLevel tracks how far an overdue check-in incident has been
+// escalated by the check-in monitor.
+type EscalationLevel string
+
+const (
+	EscalationNone    EscalationLevel = ""
+	EscalationWarning EscalationLevel = "warning"
+	EscalationOverdue EscalationLevel = "overdue"
+	EscalationMissing EscalationLevel = "missing"
+)
+
 type SafetyCheckIn struct {
 	ID          uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
 	GuideID     uuid.UUID  `gorm:"type:uuid;not null;index"`
@@ -46,21 +58,23 @@ func (SafetyCheckIn) TableName() string {
 }
 
 type Incident struct {
-	ID              uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
-	IncidentType    IncidentType   `gorm:"column:incident_type;type:varchar(20);not null;index"`
-	GuideID         uuid.UUID      `gorm:"type:uuid;not null;index"`
-	Guide           Guide          `gorm:"foreignKey:GuideID"`
-	PermitID        *uuid.UUID     `gorm:"type:uuid;index"`
-	Permit          *Permit        `gorm:"foreignKey:PermitID"`
-	Status          IncidentStatus `gorm:"type:varchar(20);default:'open';index"`
-	Latitude        float64        `gorm:"type:decimal(10,8);not null"`
-	Longitude       float64        `gorm:"type:decimal(11,8);not null"`
-	Location        string         `gorm:"type:text"`
-	Description     string         `gorm:"type:text;not null"`
-	ReportedAt      time.Time      `gorm:"column:reported_at;default:CURRENT_TIMESTAMP;index"`
-	ResolvedAt      *time.Time     `gorm:"column:resolved_at"`
-	ResolvedBy      *uuid.UUID     `gorm:"type:uuid;column:resolved_by"`
-	ResolutionNotes string         `gorm:"column:resolution_notes;type:text"`
+	ID              uuid.UUID       `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	IncidentType    IncidentType    `gorm:"column:incident_type;type:varchar(20);not null;index"`
+	GuideID         uuid.UUID       `gorm:"type:uuid;not null;index"`
+	Guide           Guide           `gorm:"foreignKey:GuideID"`
+	PermitID        *uuid.UUID      `gorm:"type:uuid;index"`
+	Permit          *Permit         `gorm:"foreignKey:PermitID"`
+	Status          IncidentStatus  `gorm:"type:varchar(20);default:'open';index"`
+	Latitude        float64         `gorm:"type:decimal(10,8);not null"`
+	Longitude       float64         `gorm:"type:decimal(11,8);not null"`
+	Location        string          `gorm:"type:text"`
+	Description     string          `gorm:"type:text;not null"`
+	ReportedAt      time.Time       `gorm:"column:reported_at;default:CURRENT_TIMESTAMP;index"`
+	ResolvedAt      *time.Time      `gorm:"column:resolved_at"`
+	ResolvedBy      *uuid.UUID      `gorm:"type:uuid;column:resolved_by"`
+	ResolutionNotes string          `gorm:"column:resolution_notes;type:text"`
+	EscalationLevel EscalationLevel `gorm:"column:escalation_level;type:varchar(20);index"`
+	EscalatedAt     *time.Time      `gorm:"column:escalated_at"`
 	CreatedAt       time.Time
 	UpdatedAt       time.Time
 	DeletedAt       gorm.DeletedAt `gorm:"index"`
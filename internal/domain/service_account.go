@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Scopes an API key can be granted. Each resource has a read scope for GET
+// requests and a write scope for everything else.
+const (
+	ScopeGuidesRead     = "guides:read"
+	ScopeGuidesWrite    = "guides:write"
+	ScopePermitsRead    = "permits:read"
+	ScopePermitsWrite   = "permits:write"
+	ScopeTrekkersRead   = "trekkers:read"
+	ScopeTrekkersWrite  = "trekkers:write"
+	ScopeIncidentsRead  = "incidents:read"
+	ScopeIncidentsWrite = "incidents:write"
+	ScopeCatalogRead    = "catalog:read"
+)
+
+// ValidAPIKeyScope reports whether scope is one that keys can be granted.
+func ValidAPIKeyScope(scope string) bool {
+	switch scope {
+	case ScopeGuidesRead, ScopeGuidesWrite,
+		ScopePermitsRead, ScopePermitsWrite,
+		ScopeTrekkersRead, ScopeTrekkersWrite,
+		ScopeIncidentsRead, ScopeIncidentsWrite,
+		ScopeCatalogRead:
+		return true
+	}
+	return false
+}
+
+// ServiceAccount is a non-human identity owned by an agency, used by the
+// agency's own systems to call the API with API keys. It is subject to the
+// same agency scoping as the agency's users.
+type ServiceAccount struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	AgencyID    uuid.UUID `gorm:"type:uuid;not null;index"`
+	Agency      *Agency   `gorm:"foreignKey:AgencyID"`
+	Name        string    `gorm:"not null"`
+	Description string    `gorm:"type:text"`
+	IsActive    bool      `gorm:"column:is_active;not null;default:true"`
+	CreatedBy   uuid.UUID `gorm:"type:uuid;not null"`
+	Keys        []APIKey  `gorm:"foreignKey:ServiceAccountID"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	DeletedAt   gorm.DeletedAt `gorm:"index"`
+}
+
+func (ServiceAccount) TableName() string {
+	return "service_accounts"
+}
+
+// APIKey authenticates a service account. The key is shown once when it is
+// created; only its Prefix, used to look it up, and the SHA-256 hash of the
+// whole key are stored. Scopes are space separated.
+type APIKey struct {
+	ID               uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	ServiceAccountID uuid.UUID  `gorm:"type:uuid;not null;index"`
+	Prefix           string     `gorm:"uniqueIndex;not null"`
+	KeyHash          string     `gorm:"column:key_hash;not null" json:"-"`
+	Scopes           string     `gorm:"not null"`
+	ExpiresAt        *time.Time `gorm:"column:expires_at"`
+	LastUsedAt       *time.Time `gorm:"column:last_used_at"`
+	LastUsedIP       string     `gorm:"column:last_used_ip"`
+	RotatedTo        *uuid.UUID `gorm:"type:uuid;column:rotated_to"`
+	RevokedAt        *time.Time `gorm:"column:revoked_at"`
+	CreatedBy        uuid.UUID  `gorm:"type:uuid;not null"`
+	CreatedAt        time.Time
+}
+
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// ScopeList returns the key's scopes.
+func (k *APIKey) ScopeList() []string {
+	return strings.Fields(k.Scopes)
+}
+
+// Usable reports whether the key can still authenticate at the given time.
+func (k *APIKey) Usable(at time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || at.Before(*k.ExpiresAt)
+}
@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RefreshToken records an issued refresh token by its JWT ID. Every refresh
+// rotates the token: the presented one is marked used and a new one is issued
+// in the same family. Presenting a used or revoked token again is treated as
+// theft and revokes the whole family, ending the session everywhere.
+type RefreshToken struct {
+	ID            uuid.UUID  `gorm:"type:uuid;primary_key"`
+	UserID        uuid.UUID  `gorm:"type:uuid;not null;index"`
+	FamilyID      uuid.UUID  `gorm:"type:uuid;not null;index"`
+	ParentID      *uuid.UUID `gorm:"type:uuid"`
+	ExpiresAt     time.Time  `gorm:"column:expires_at;not null;index"`
+	UsedAt        *time.Time `gorm:"column:used_at"`
+	RevokedAt     *time.Time `gorm:"column:revoked_at"`
+	RevokedReason string     `gorm:"column:revoked_reason"`
+	CreatedAt     time.Time
+}
+
+func (RefreshToken) TableName() string {
+	return "refresh_tokens"
+}
+
+// Reasons recorded when refresh tokens are revoked.
+const (
+	TokenRevokedLogout    = "logout"
+	TokenRevokedLogoutAll = "logout_all"
+	TokenRevokedReuse     = "reuse_detected"
+)
+
+// RevokedAccessToken denylists an access token by its JWT ID until it would
+// have expired anyway.
+type RevokedAccessToken struct {
+	JTI       string    `gorm:"column:jti;primary_key"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;index"`
+	ExpiresAt time.Time `gorm:"column:expires_at;not null;index"`
+	RevokedAt time.Time `gorm:"column:revoked_at;not null"`
+}
+
+func (RevokedAccessToken) TableName() string {
+	return "revoked_access_tokens"
+}
@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type Trekker struct {
+	ID                    uuid.UUID          `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	FullName              string             `gorm:"column:full_name;not null"`
+	Email                 string             `gorm:"column:email;index"`
+	Phone                 string             `gorm:"column:phone"`
+	PassportNumber        string             `gorm:"column:passport_number;index"`
+	Nationality           string             `gorm:"type:varchar(2);index"`
+	DateOfBirth           *time.Time         `gorm:"column:date_of_birth;type:date"`
+	InsuranceProvider     string             `gorm:"column:insurance_provider"`
+	InsurancePolicyNumber string             `gorm:"column:insurance_policy_number"`
+	InsuranceExpiry       *time.Time         `gorm:"column:insurance_expiry;type:date"`
+	AgencyID              *uuid.UUID         `gorm:"type:uuid;index"`
+	Agency                *Agency            `gorm:"foreignKey:AgencyID"`
+	EmergencyContacts     []EmergencyContact `gorm:"foreignKey:TrekkerID"`
+	CreatedBy             *uuid.UUID         `gorm:"type:uuid;column:created_by"`
+	CreatedAt             time.Time
+	UpdatedAt             time.Time
+	DeletedAt             gorm.DeletedAt `gorm:"index"`
+}
+
+func (Trekker) TableName() string {
+	return "trekkers"
+}
+
+type EmergencyContact struct {
+	ID           uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	TrekkerID    uuid.UUID `gorm:"type:uuid;not null;index"`
+	Name         string    `gorm:"not null"`
+	Relationship string    `gorm:"column:relationship"`
+	Phone        string    `gorm:"column:phone;not null"`
+	Email        string    `gorm:"column:email"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+func (EmergencyContact) TableName() string {
+	return "trekker_emergency_contacts"
+}
@@ -10,23 +10,45 @@ import (
 type Role string
 
 const (
-	RoleAdmin  Role = "admin"
-	RoleAgency Role = "agency"
-	RoleGuide  Role = "guide"
+	RoleAdmin   Role = "admin"
+	RoleAgency  Role = "agency"
+	RoleGuide   Role = "guide"
+	RoleOfficer Role = "officer"
 )
 
+func (r Role) Valid() bool {
+	switch r {
+	case RoleAdmin, RoleAgency, RoleGuide, RoleOfficer:
+		return true
+	}
+	return false
+}
+
 type User struct {
 	ID           uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
 	Email        string     `gorm:"uniqueIndex;not null"`
-	PasswordHash string     `gorm:"column:password_hash;not null"`
+	PasswordHash string     `gorm:"column:password_hash;not null" json:"-"`
 	Role         Role       `gorm:"type:varchar(20);not null;index"`
 	FullName     string     `gorm:"column:full_name;not null"`
 	IsActive     bool       `gorm:"column:is_active;default:true;index"`
 	AgencyID     *uuid.UUID `gorm:"type:uuid;index"`
 	Agency       *Agency    `gorm:"foreignKey:AgencyID"`
-	CreatedAt    time.Time
-	UpdatedAt    time.Time
-	DeletedAt    gorm.DeletedAt `gorm:"index"`
+	// AgencyRole is the user's role within their agency, for agency users.
+	AgencyRole AgencyRole `gorm:"column:agency_role;type:varchar(20)"`
+	// EmailVerifiedAt is set once the user follows a verification or
+	// password reset link; unverified users cannot log in.
+	EmailVerifiedAt *time.Time `gorm:"column:email_verified_at"`
+	// TokensRevokedAt rejects every access token not issued after it; set
+	// when the user logs out of all sessions.
+	TokensRevokedAt *time.Time `gorm:"column:tokens_revoked_at"`
+	// FailedLoginAttempts counts failed logins since the last successful
+	// one; past the lockout threshold the account is locked until
+	// LockedUntil.
+	FailedLoginAttempts int        `gorm:"column:failed_login_attempts;not null;default:0"`
+	LockedUntil         *time.Time `gorm:"column:locked_until"`
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+	DeletedAt           gorm.DeletedAt `gorm:"index"`
 }
 
 func (User) TableName() string {
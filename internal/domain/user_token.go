@@ -0,0 +1,30 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type TokenPurpose string
+
+const (
+	TokenPurposePasswordReset     TokenPurpose = "password_reset"
+	TokenPurposeEmailVerification TokenPurpose = "email_verification"
+)
+
+// UserToken is a single-use token emailed to a user. Only the SHA-256 hash
+// of the token is stored.
+type UserToken struct {
+	ID        uuid.UUID    `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID    uuid.UUID    `gorm:"type:uuid;not null;index"`
+	Purpose   TokenPurpose `gorm:"type:varchar(32);not null"`
+	TokenHash string       `gorm:"column:token_hash;uniqueIndex;not null"`
+	ExpiresAt time.Time    `gorm:"column:expires_at;not null"`
+	UsedAt    *time.Time   `gorm:"column:used_at"`
+	CreatedAt time.Time
+}
+
+func (UserToken) TableName() string {
+	return "user_tokens"
+}
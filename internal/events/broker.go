@@ -0,0 +1,225 @@
+package events
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/touros-platform/api/internal/domain"
+)
+
+type Type string
+
+const (
+	IncidentCreated  Type = "incident.created"
+	IncidentUpdated  Type = "incident.updated"
+	IncidentResolved Type = "incident.resolved"
+	CheckInCreated   Type = "checkin.created"
+
+	// StreamReset tells a resuming client that events after its last event
+	// ID are no longer held, so it must reload the current state instead of
+	// relying on the replay. It carries no data.
+	StreamReset Type = "stream.reset"
+)
+
+// Event is a safety event fanned out to live subscribers. Data holds the
+// *domain.Incident or *domain.SafetyCheckIn the event is about. AgencyID is
+// the agency of the permit the event concerns, or else the guide's agency;
+// both agencies receive the event.
+type Event struct {
+	ID            uint64                `json:"id"`
+	Type          Type                  `json:"type"`
+	GuideID       uuid.UUID             `json:"guide_id"`
+	AgencyID      *uuid.UUID            `json:"agency_id,omitempty"`
+	GuideAgencyID *uuid.UUID            `json:"-"`
+	IncidentType  domain.IncidentType   `json:"incident_type,omitempty"`
+	Status        domain.IncidentStatus `json:"status,omitempty"`
+	Data          interface{}           `json:"data"`
+	CreatedAt     time.Time             `json:"created_at"`
+}
+
+// Filter narrows a subscription. Zero-valued fields match everything. The
+// incident type and status filters only match incident events, so setting
+// either of them excludes check-ins.
+type Filter struct {
+	AgencyID     *uuid.UUID
+	GuideID      *uuid.UUID
+	IncidentType *domain.IncidentType
+	Status       *domain.IncidentStatus
+}
+
+func (f Filter) Matches(evt *Event) bool {
+	if f.AgencyID != nil && !sameID(evt.AgencyID, f.AgencyID) && !sameID(evt.GuideAgencyID, f.AgencyID) {
+		return false
+	}
+	if f.GuideID != nil && evt.GuideID != *f.GuideID {
+		return false
+	}
+	if f.IncidentType != nil && evt.IncidentType != *f.IncidentType {
+		return false
+	}
+	if f.Status != nil && (evt.IncidentType == "" || evt.Status != *f.Status) {
+		return false
+	}
+	return true
+}
+
+type Publisher interface {
+	Publish(evt Event)
+}
+
+const (
+	defaultHistorySize = 1024
+	subscriberBuffer   = 64
+)
+
+// Broker is an in-process pub/sub for safety events. It keeps a bounded
+// history so that reconnecting clients can resume from their last event ID.
+// Event IDs are derived from the wall clock so that a new process normally
+// continues above the IDs clients already hold, but nothing guarantees it:
+// the clock may be set back and the history does not survive a restart.
+// Clients resuming from before the retained history get a StreamReset event.
+type Broker struct {
+	mu     sync.Mutex
+	lastID uint64
+	// floorID is the newest event ID no longer in history: the ID of the
+	// last evicted event, or the last ID before the broker started.
+	floorID     uint64
+	history     []Event
+	historySize int
+	subscribers map[*Subscription]struct{}
+}
+
+func NewBroker() *Broker {
+	startID := uint64(time.Now().UnixMicro())
+	return &Broker{
+		lastID:      startID,
+		floorID:     startID,
+		historySize: defaultHistorySize,
+		subscribers: make(map[*Subscription]struct{}),
+	}
+}
+
+// Subscription delivers matching events on C. C is closed when the
+// subscription is closed or when the subscriber falls too far behind, in
+// which case the client is expected to reconnect with its last event ID.
+type Subscription struct {
+	C      <-chan Event
+	ch     chan Event
+	filter Filter
+	broker *Broker
+	closed bool
+}
+
+func (b *Broker) Publish(evt Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	id := uint64(now.UnixMicro())
+	if id <= b.lastID {
+		id = b.lastID + 1
+	}
+	b.lastID = id
+
+	evt.ID = id
+	if evt.CreatedAt.IsZero() {
+		evt.CreatedAt = now
+	}
+
+	b.history = append(b.history, evt)
+	if evicted := len(b.history) - b.historySize; evicted > 0 {
+		b.floorID = b.history[evicted-1].ID
+		b.history = b.history[evicted:]
+	}
+
+	for sub := range b.subscribers {
+		if !sub.filter.Matches(&evt) {
+			continue
+		}
+		select {
+		case sub.ch <- evt:
+		default:
+			b.closeLocked(sub)
+		}
+	}
+}
+
+// Subscribe registers a subscriber and returns the buffered events newer than
+// lastEventID that match the filter. Pass zero to skip the replay. When
+// events after lastEventID may have been dropped from the history, the
+// replay starts with a StreamReset event.
+func (b *Broker) Subscribe(filter Filter, lastEventID uint64) (*Subscription, []Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ch := make(chan Event, subscriberBuffer)
+	sub := &Subscription{C: ch, ch: ch, filter: filter, broker: b}
+	b.subscribers[sub] = struct{}{}
+
+	var replay []Event
+	if lastEventID > 0 && lastEventID < b.floorID {
+		replay = append(replay, Event{ID: b.floorID, Type: StreamReset, CreatedAt: time.Now()})
+	}
+	if lastEventID > 0 {
+		for i := range b.history {
+			if b.history[i].ID > lastEventID && filter.Matches(&b.history[i]) {
+				replay = append(replay, b.history[i])
+			}
+		}
+	}
+
+	return sub, replay
+}
+
+func (s *Subscription) Close() {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+	s.broker.closeLocked(s)
+}
+
+func (b *Broker) closeLocked(sub *Subscription) {
+	if sub.closed {
+		return
+	}
+	sub.closed = true
+	delete(b.subscribers, sub)
+	close(sub.ch)
+}
+
+// NewIncidentEvent builds an incident event. permitAgencyID is the agency of
+// the incident's permit, or nil when it has none.
+func NewIncidentEvent(eventType Type, incident *domain.Incident, guideAgencyID, permitAgencyID *uuid.UUID) Event {
+	return Event{
+		Type:          eventType,
+		GuideID:       incident.GuideID,
+		AgencyID:      eventAgency(guideAgencyID, permitAgencyID),
+		GuideAgencyID: guideAgencyID,
+		IncidentType:  incident.IncidentType,
+		Status:        incident.Status,
+		Data:          incident,
+	}
+}
+
+// NewCheckInEvent builds a check-in event. permitAgencyID is the agency of
+// the check-in's permit, or nil when it has none.
+func NewCheckInEvent(checkIn *domain.SafetyCheckIn, guideAgencyID, permitAgencyID *uuid.UUID) Event {
+	return Event{
+		Type:          CheckInCreated,
+		GuideID:       checkIn.GuideID,
+		AgencyID:      eventAgency(guideAgencyID, permitAgencyID),
+		GuideAgencyID: guideAgencyID,
+		Data:          checkIn,
+	}
+}
+
+func eventAgency(guideAgencyID, permitAgencyID *uuid.UUID) *uuid.UUID {
+	if permitAgencyID != nil {
+		return permitAgencyID
+	}
+	return guideAgencyID
+}
+
+func sameID(a, b *uuid.UUID) bool {
+	return a != nil && b != nil && *a == *b
+}
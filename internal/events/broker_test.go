@@ -0,0 +1,76 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func publishN(b *Broker, n int) {
+	for i := 0; i < n; i++ {
+		b.Publish(Event{Type: CheckInCreated, GuideID: uuid.New()})
+	}
+}
+
+func TestSubscribeReplaysFromHistory(t *testing.T) {
+	b := NewBroker()
+	publishN(b, 5)
+	lastEventID := b.history[1].ID
+
+	sub, replay := b.Subscribe(Filter{}, lastEventID)
+	defer sub.Close()
+
+	if len(replay) != 3 {
+		t.Fatalf("replayed %d events, want 3", len(replay))
+	}
+	for _, evt := range replay {
+		if evt.Type == StreamReset {
+			t.Fatal("replay within the history starts with a reset")
+		}
+		if evt.ID <= lastEventID {
+			t.Fatalf("replayed event %d, not after %d", evt.ID, lastEventID)
+		}
+	}
+}
+
+func TestSubscribeResetsWhenHistoryEvicted(t *testing.T) {
+	b := NewBroker()
+	b.historySize = 3
+	publishN(b, 2)
+	lastEventID := b.history[0].ID
+	publishN(b, 3)
+
+	sub, replay := b.Subscribe(Filter{}, lastEventID)
+	defer sub.Close()
+
+	if len(replay) != 4 {
+		t.Fatalf("replayed %d events, want a reset and 3 events", len(replay))
+	}
+	if replay[0].Type != StreamReset {
+		t.Fatalf("first replayed event is %q, want %q", replay[0].Type, StreamReset)
+	}
+	if replay[0].ID >= replay[1].ID {
+		t.Fatalf("reset ID %d is not before the replayed event %d", replay[0].ID, replay[1].ID)
+	}
+
+	// Resuming from the reset replays the retained history without
+	// another reset.
+	sub2, replay := b.Subscribe(Filter{}, replay[0].ID)
+	defer sub2.Close()
+	if len(replay) != 3 || replay[0].Type == StreamReset {
+		t.Fatalf("resuming from the reset replayed %d events starting with %q", len(replay), replay[0].Type)
+	}
+}
+
+func TestSubscribeResetsAfterRestart(t *testing.T) {
+	b := NewBroker()
+	// An ID handed out by the previous process.
+	lastEventID := b.floorID - 1
+
+	sub, replay := b.Subscribe(Filter{}, lastEventID)
+	defer sub.Close()
+
+	if len(replay) != 1 || replay[0].Type != StreamReset {
+		t.Fatalf("resuming on a new broker replayed %+v, want a reset", replay)
+	}
+}
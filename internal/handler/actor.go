@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/touros-platform/api/internal/service"
+)
+
+// currentActor returns the authenticated user set by middleware.AuthMiddleware.
+func currentActor(c *gin.Context) *service.Actor {
+	actor, _ := c.Get("actor")
+	return actor.(*service.Actor)
+}
+
+// accessStatus reports a record outside the actor's scope as 403 Forbidden
+// and any other error with the given status. Reads use readFailed instead.
+func accessStatus(err error, status int) int {
+	if errors.Is(err, service.ErrForbidden) {
+		return http.StatusForbidden
+	}
+	return status
+}
+
+// readFailed answers a failed read of the records under a path's agency or
+// guide. Records outside the actor's scope are reported with notFound, like
+// missing ones, so that one agency cannot probe for another's; any other
+// error is answered with the given status.
+func readFailed(c *gin.Context, err error, status int, notFound string) {
+	if errors.Is(err, service.ErrForbidden) {
+		c.JSON(http.StatusNotFound, gin.H{"error": notFound})
+		return
+	}
+	c.JSON(status, gin.H{"error": err.Error()})
+}
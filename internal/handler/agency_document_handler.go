@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/touros-platform/api/internal/domain"
+	"github.com/touros-platform/api/internal/service"
+)
+
+type AgencyDocumentHandler struct {
+	documentService service.AgencyDocumentService
+}
+
+func NewAgencyDocumentHandler(documentService service.AgencyDocumentService) *AgencyDocumentHandler {
+	return &AgencyDocumentHandler{
+		documentService: documentService,
+	}
+}
+
+type RejectRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// Upload takes a multipart form with the document type and the file.
+func (h *AgencyDocumentHandler) Upload(c *gin.Context) {
+	agencyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	docType := domain.AgencyDocumentType(c.PostForm("type"))
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	document, err := h.documentService.Upload(currentActor(c), agencyID, docType, header.Filename, file, header.Size)
+	if err != nil {
+		c.JSON(accessStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, document)
+}
+
+// List returns the agency's current documents; ?history=true includes the
+// documents they replaced.
+func (h *AgencyDocumentHandler) List(c *gin.Context) {
+	agencyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	documents, err := h.documentService.List(currentActor(c), agencyID, c.Query("history") == "true")
+	if err != nil {
+		readFailed(c, err, http.StatusInternalServerError, "agency not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": documents})
+}
+
+func (h *AgencyDocumentHandler) Checklist(c *gin.Context) {
+	agencyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	checklist, err := h.documentService.Checklist(currentActor(c), agencyID)
+	if err != nil {
+		readFailed(c, err, http.StatusNotFound, "agency not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, checklist)
+}
+
+func (h *AgencyDocumentHandler) Download(c *gin.Context) {
+	agencyID, id, ok := documentParams(c)
+	if !ok {
+		return
+	}
+
+	document, file, err := h.documentService.Open(currentActor(c), agencyID, id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "document not found"})
+		return
+	}
+	defer file.Close()
+
+	c.DataFromReader(http.StatusOK, document.Size, document.ContentType, file, map[string]string{
+		"Content-Disposition":    fmt.Sprintf("attachment; filename*=UTF-8''%s", url.PathEscape(document.FileName)),
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+func (h *AgencyDocumentHandler) Approve(c *gin.Context) {
+	agencyID, id, ok := documentParams(c)
+	if !ok {
+		return
+	}
+
+	document, err := h.documentService.Approve(agencyID, id, currentActor(c).UserID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, document)
+}
+
+func (h *AgencyDocumentHandler) Reject(c *gin.Context) {
+	agencyID, id, ok := documentParams(c)
+	if !ok {
+		return
+	}
+
+	var req RejectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	document, err := h.documentService.Reject(agencyID, id, currentActor(c).UserID, req.Reason)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, document)
+}
+
+func documentParams(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	agencyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	id, err := uuid.Parse(c.Param("doc_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid document id"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	return agencyID, id, true
+}
@@ -3,6 +3,7 @@ package handler
 import (
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -34,6 +35,7 @@ type UpdateAgencyRequest struct {
 	ContactEmail  *string `json:"contact_email"`
 	ContactPhone  *string `json:"contact_phone"`
 	Address       *string `json:"address"`
+	LicenseExpiry *time.Time `json:"license_expiry"`
 }
 
 func (h *AgencyHandler) Create(c *gin.Context) {
@@ -95,11 +97,12 @@ func (h *AgencyHandler) Update(c *gin.Context) {
 		ContactEmail: req.ContactEmail,
 		ContactPhone: req.ContactPhone,
 		Address:      req.Address,
+		LicenseExpiry: req.LicenseExpiry,
 	}
 
-	agency, err := h.agencyService.Update(id, updates)
+	agency, err := h.agencyService.Update(currentActor(c), id, updates)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(accessStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -166,3 +169,24 @@ func (h *AgencyHandler) Suspend(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "agency suspended"})
 }
 
+
+func (h *AgencyHandler) Reject(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req RejectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.agencyService.Reject(id, currentActor(c).UserID, req.Reason); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "agency rejected"})
+}
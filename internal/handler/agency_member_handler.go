@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/touros-platform/api/internal/domain"
+	"github.com/touros-platform/api/internal/service"
+)
+
+type AgencyMemberHandler struct {
+	memberService service.AgencyMemberService
+}
+
+func NewAgencyMemberHandler(memberService service.AgencyMemberService) *AgencyMemberHandler {
+	return &AgencyMemberHandler{
+		memberService: memberService,
+	}
+}
+
+type InviteMemberRequest struct {
+	Email      string `json:"email" binding:"required,email"`
+	AgencyRole string `json:"agency_role" binding:"required"`
+}
+
+type SetAgencyRoleRequest struct {
+	AgencyRole string `json:"agency_role" binding:"required"`
+}
+
+type AcceptInvitationRequest struct {
+	Token    string `json:"token" binding:"required"`
+	FullName string `json:"full_name" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+func (h *AgencyMemberHandler) List(c *gin.Context) {
+	agencyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	members, err := h.memberService.List(currentActor(c), agencyID)
+	if err != nil {
+		readFailed(c, err, http.StatusInternalServerError, "agency not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": members})
+}
+
+func (h *AgencyMemberHandler) Invite(c *gin.Context) {
+	agencyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req InviteMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	invitation, err := h.memberService.Invite(currentActor(c), agencyID, req.Email, domain.AgencyRole(req.AgencyRole))
+	if err != nil {
+		c.JSON(accessStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, invitation)
+}
+
+func (h *AgencyMemberHandler) ListInvitations(c *gin.Context) {
+	agencyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	invitations, err := h.memberService.ListInvitations(currentActor(c), agencyID)
+	if err != nil {
+		c.JSON(accessStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": invitations})
+}
+
+func (h *AgencyMemberHandler) RevokeInvitation(c *gin.Context) {
+	agencyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	invitationID, err := uuid.Parse(c.Param("invitation_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invitation id"})
+		return
+	}
+
+	if err := h.memberService.RevokeInvitation(currentActor(c), agencyID, invitationID); err != nil {
+		c.JSON(accessStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "invitation revoked"})
+}
+
+func (h *AgencyMemberHandler) SetRole(c *gin.Context) {
+	agencyID, userID, ok := memberParams(c)
+	if !ok {
+		return
+	}
+
+	var req SetAgencyRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	member, err := h.memberService.SetRole(currentActor(c), agencyID, userID, domain.AgencyRole(req.AgencyRole))
+	if err != nil {
+		c.JSON(accessStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, member)
+}
+
+func (h *AgencyMemberHandler) Activate(c *gin.Context) {
+	h.setActive(c, true)
+}
+
+// Deactivate blocks the member's login and ends their sessions.
+func (h *AgencyMemberHandler) Deactivate(c *gin.Context) {
+	h.setActive(c, false)
+}
+
+func (h *AgencyMemberHandler) setActive(c *gin.Context, active bool) {
+	agencyID, userID, ok := memberParams(c)
+	if !ok {
+		return
+	}
+
+	member, err := h.memberService.SetActive(currentActor(c), agencyID, userID, active)
+	if err != nil {
+		c.JSON(accessStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, member)
+}
+
+// Remove takes the member out of the agency and deactivates the account.
+func (h *AgencyMemberHandler) Remove(c *gin.Context) {
+	agencyID, userID, ok := memberParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.memberService.Remove(currentActor(c), agencyID, userID); err != nil {
+		c.JSON(accessStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "member removed"})
+}
+
+// AcceptInvitation creates the invited user's account. It is public: the
+// emailed token is the credential.
+func (h *AgencyMemberHandler) AcceptInvitation(c *gin.Context) {
+	var req AcceptInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := h.memberService.AcceptInvitation(req.Token, req.FullName, req.Password)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, user)
+}
+
+func memberParams(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	agencyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	userID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	return agencyID, userID, true
+}
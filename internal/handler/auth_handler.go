@@ -1,19 +1,25 @@
 package handler
 
 import (
+	"errors"
+	"math"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/touros-platform/api/internal/service"
 )
 
 type AuthHandler struct {
-	authService service.AuthService
+	authService    service.AuthService
+	accountService service.AccountService
 }
 
-func NewAuthHandler(authService service.AuthService) *AuthHandler {
+func NewAuthHandler(authService service.AuthService, accountService service.AccountService) *AuthHandler {
 	return &AuthHandler{
-		authService: authService,
+		authService:    authService,
+		accountService: accountService,
 	}
 }
 
@@ -26,6 +32,34 @@ type RefreshTokenRequest struct {
 	RefreshToken string `json:"refresh_token" binding:"required"`
 }
 
+type EmailRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=8"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type MFAVerifyRequest struct {
+	MFAToken     string `json:"mfa_token" binding:"required"`
+	Code         string `json:"code" binding:"required_without=RecoveryCode"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+type MFAChallengeRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+}
+
+type MFAEnrollConfirmRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -33,9 +67,42 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	tokens, err := h.authService.Login(req.Email, req.Password)
+	result, err := h.authService.Login(req.Email, req.Password, c.ClientIP())
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		loginError(c, err)
+		return
+	}
+
+	if result.Challenge != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"mfa_required":        true,
+			"mfa_token":           result.Challenge.Token,
+			"expires_in":          result.Challenge.ExpiresIn,
+			"enrollment_required": result.Challenge.EnrollmentRequired,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"access_token":  result.Tokens.AccessToken,
+		"refresh_token": result.Tokens.RefreshToken,
+		"expires_in":    result.Tokens.ExpiresIn,
+		"token_type":    "Bearer",
+	})
+}
+
+// VerifyMFA completes a login that returned an MFA challenge, using a code
+// from the authenticator app or one of the user's recovery codes.
+func (h *AuthHandler) VerifyMFA(c *gin.Context) {
+	var req MFAVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tokens, err := h.authService.VerifyMFA(req.MFAToken, req.Code, req.RecoveryCode, c.ClientIP())
+	if err != nil {
+		loginError(c, err)
 		return
 	}
 
@@ -47,6 +114,54 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	})
 }
 
+// EnrollMFA starts authenticator enrollment for a user whose role requires
+// MFA but who has not set it up yet.
+func (h *AuthHandler) EnrollMFA(c *gin.Context) {
+	var req MFAChallengeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	enrollment, err := h.authService.BeginMFAEnrollment(req.MFAToken)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := enrollmentResponse(enrollment)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ConfirmMFAEnrollment finishes enrollment started by EnrollMFA and logs
+// the user in. The recovery codes are only shown this once.
+func (h *AuthHandler) ConfirmMFAEnrollment(c *gin.Context) {
+	var req MFAEnrollConfirmRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tokens, recoveryCodes, err := h.authService.CompleteMFAEnrollment(req.MFAToken, req.Code, c.ClientIP())
+	if err != nil {
+		loginError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"access_token":   tokens.AccessToken,
+		"refresh_token":  tokens.RefreshToken,
+		"expires_in":     tokens.ExpiresIn,
+		"token_type":     "Bearer",
+		"recovery_codes": recoveryCodes,
+	})
+}
+
 func (h *AuthHandler) RefreshToken(c *gin.Context) {
 	var req RefreshTokenRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -68,3 +183,107 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 	})
 }
 
+// Logout ends the session of the access token used for the request.
+func (h *AuthHandler) Logout(c *gin.Context) {
+	claims, _ := c.Get("claims")
+
+	if err := h.authService.Logout(claims.(*service.Claims)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "logged out"})
+}
+
+// LogoutAll ends every session of the authenticated user.
+func (h *AuthHandler) LogoutAll(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	if err := h.authService.LogoutAll(userID.(uuid.UUID)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "logged out of all sessions"})
+}
+
+// JWKS serves the public keys that verify access tokens. The cache lifetime
+// is short so that verifiers pick up a new key soon after a rotation.
+func (h *AuthHandler) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.authService.KeySet())
+}
+
+// ForgotPassword emails a password reset link. The response is the same
+// whether or not the address is registered.
+func (h *AuthHandler) ForgotPassword(c *gin.Context) {
+	var req EmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.accountService.ForgotPassword(req.Email); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "if the address is registered, a reset link has been sent"})
+}
+
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	var req ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.accountService.ResetPassword(req.Token, req.NewPassword); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "password reset, please log in"})
+}
+
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	var req VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.accountService.VerifyEmail(req.Token); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "email address verified"})
+}
+
+func (h *AuthHandler) ResendVerification(c *gin.Context) {
+	var req EmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.accountService.ResendVerification(req.Email); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "if the address awaits verification, a new link has been sent"})
+}
+
+// loginError answers a failed login step. Clients locked out after repeated
+// failures get 429 and a Retry-After header.
+func loginError(c *gin.Context, err error) {
+	var throttled *service.LoginThrottledError
+	if errors.As(err, &throttled) {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+}
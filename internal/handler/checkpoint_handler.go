@@ -0,0 +1,243 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/touros-platform/api/internal/domain"
+	"github.com/touros-platform/api/internal/middleware"
+	"github.com/touros-platform/api/internal/service"
+)
+
+type CheckpointHandler struct {
+	checkpointService service.CheckpointService
+}
+
+func NewCheckpointHandler(checkpointService service.CheckpointService) *CheckpointHandler {
+	return &CheckpointHandler{
+		checkpointService: checkpointService,
+	}
+}
+
+type RegisterCheckpointRequest struct {
+	Code              string     `json:"code" binding:"required"`
+	Name              string     `json:"name" binding:"required"`
+	RouteCheckpointID *uuid.UUID `json:"route_checkpoint_id"`
+	Latitude          float64    `json:"latitude" binding:"required"`
+	Longitude         float64    `json:"longitude" binding:"required"`
+	DeviceID          string     `json:"device_id"`
+}
+
+type UpdateCheckpointRequest struct {
+	Name              *string    `json:"name"`
+	RouteCheckpointID *uuid.UUID `json:"route_checkpoint_id"`
+	Latitude          *float64   `json:"latitude"`
+	Longitude         *float64   `json:"longitude"`
+	DeviceID          *string    `json:"device_id"`
+	IsActive          *bool      `json:"is_active"`
+}
+
+type RecordScanRequest struct {
+	Token        string     `json:"token"`
+	PermitNumber string     `json:"permit_number"`
+	Headcount    *int       `json:"headcount" binding:"omitempty,min=0"`
+	Notes        string     `json:"notes"`
+	ScannedAt    *time.Time `json:"scanned_at"`
+}
+
+func (h *CheckpointHandler) Register(c *gin.Context) {
+	var req RegisterCheckpointRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	registeredBy := userID.(uuid.UUID)
+
+	checkpoint := &domain.Checkpoint{
+		Code:              req.Code,
+		Name:              req.Name,
+		RouteCheckpointID: req.RouteCheckpointID,
+		Latitude:          req.Latitude,
+		Longitude:         req.Longitude,
+		DeviceID:          req.DeviceID,
+	}
+
+	if err := h.checkpointService.Register(checkpoint, registeredBy); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, checkpoint)
+}
+
+func (h *CheckpointHandler) GetByID(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	checkpoint, err := h.checkpointService.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "checkpoint not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, checkpoint)
+}
+
+func (h *CheckpointHandler) Update(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req UpdateCheckpointRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updates := &service.UpdateCheckpointRequest{
+		Name:              req.Name,
+		RouteCheckpointID: req.RouteCheckpointID,
+		Latitude:          req.Latitude,
+		Longitude:         req.Longitude,
+		DeviceID:          req.DeviceID,
+		IsActive:          req.IsActive,
+	}
+
+	checkpoint, err := h.checkpointService.Update(id, updates)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, checkpoint)
+}
+
+func (h *CheckpointHandler) List(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	activeOnly := c.Query("active") == "true"
+
+	checkpoints, total, err := h.checkpointService.List(limit, offset, activeOnly)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":   checkpoints,
+		"total":  total,
+		"limit":  limit,
+		"offset": offset,
+	})
+}
+
+// RecordScan validates a scanned permit at the checkpoint and logs the scan.
+// Failed validations are still logged and returned with 200; the result
+// field carries the outcome.
+func (h *CheckpointHandler) RecordScan(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req RecordScanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	scannedBy := userID.(uuid.UUID)
+
+	serviceReq := &service.RecordScanRequest{
+		CheckpointID: id,
+		Token:        req.Token,
+		PermitNumber: req.PermitNumber,
+		Headcount:    req.Headcount,
+		Notes:        req.Notes,
+		ScannedBy:    scannedBy,
+		ScannedAt:    req.ScannedAt,
+	}
+
+	scan, permit, err := h.checkpointService.RecordScan(serviceReq)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	middleware.IncrementPermitScans(string(scan.Result))
+
+	response := gin.H{
+		"scan":               scan,
+		"result":             scan.Result,
+		"headcount_mismatch": scan.HeadcountMismatch(),
+	}
+	if permit != nil {
+		response["permit"] = permit
+		response["party"] = permit.PartySummary()
+	}
+	c.JSON(http.StatusCreated, response)
+}
+
+func (h *CheckpointHandler) ListScans(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	var since *time.Time
+	if sinceStr := c.Query("since"); sinceStr != "" {
+		t, err := time.Parse(time.RFC3339, sinceStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid since, expected RFC 3339"})
+			return
+		}
+		since = &t
+	}
+
+	scans, total, err := h.checkpointService.ListScans(id, limit, offset, since)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":   scans,
+		"total":  total,
+		"limit":  limit,
+		"offset": offset,
+	})
+}
+
+// PermitScans returns the permit's movement trail: every checkpoint scan in
+// chronological order.
+func (h *CheckpointHandler) PermitScans(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	scans, err := h.checkpointService.PermitTimeline(currentActor(c), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": scans})
+}
@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/touros-platform/api/internal/domain"
+	"github.com/touros-platform/api/internal/service"
+)
+
+type GuideAvailabilityHandler struct {
+	availabilityService service.GuideAvailabilityService
+}
+
+func NewGuideAvailabilityHandler(availabilityService service.GuideAvailabilityService) *GuideAvailabilityHandler {
+	return &GuideAvailabilityHandler{
+		availabilityService: availabilityService,
+	}
+}
+
+type BlockPeriodRequest struct {
+	Reason    string `json:"reason" binding:"required"`
+	StartDate string `json:"start_date" binding:"required"`
+	EndDate   string `json:"end_date" binding:"required"`
+	Note      string `json:"note"`
+}
+
+// Calendar returns the guide's bookings and blocked periods between
+// ?start_date and ?end_date.
+func (h *GuideAvailabilityHandler) Calendar(c *gin.Context) {
+	guideID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	from, to, ok := dateRangeQuery(c)
+	if !ok {
+		return
+	}
+
+	calendar, err := h.availabilityService.Calendar(currentActor(c), guideID, from, to)
+	if err != nil {
+		readFailed(c, err, http.StatusBadRequest, "guide not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, calendar)
+}
+
+func (h *GuideAvailabilityHandler) Block(c *gin.Context) {
+	guideID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req BlockPeriodRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	input := &service.BlockedPeriodInput{
+		Reason: domain.BlockedPeriodReason(req.Reason),
+		Note:   req.Note,
+	}
+	input.StartDate, err = time.Parse(dateLayout, req.StartDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid start_date, expected YYYY-MM-DD"})
+		return
+	}
+	input.EndDate, err = time.Parse(dateLayout, req.EndDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid end_date, expected YYYY-MM-DD"})
+		return
+	}
+
+	period, err := h.availabilityService.Block(currentActor(c), guideID, input)
+	if err != nil {
+		c.JSON(conflictStatus(err, accessStatus(err, http.StatusBadRequest)), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, period)
+}
+
+func (h *GuideAvailabilityHandler) Unblock(c *gin.Context) {
+	guideID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	id, err := uuid.Parse(c.Param("period_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid blocked period id"})
+		return
+	}
+
+	if err := h.availabilityService.Unblock(currentActor(c), guideID, id); err != nil {
+		c.JSON(accessStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "blocked period removed"})
+}
+
+// Available lists verified guides free on every day from ?start_date to
+// ?end_date. It takes the same filters as the guide list, such as
+// agency_id, language and certification.
+func (h *GuideAvailabilityHandler) Available(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	from, to, ok := dateRangeQuery(c)
+	if !ok {
+		return
+	}
+	filter, ok := guideFilter(c)
+	if !ok {
+		return
+	}
+
+	guides, total, err := h.availabilityService.Available(currentActor(c), limit, offset, from, to, filter)
+	if err != nil {
+		c.JSON(accessStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":   guides,
+		"total":  total,
+		"limit":  limit,
+		"offset": offset,
+	})
+}
+
+// dateRangeQuery reads ?start_date and ?end_date (YYYY-MM-DD). end_date
+// defaults to start_date.
+func dateRangeQuery(c *gin.Context) (time.Time, time.Time, bool) {
+	from, err := time.Parse(dateLayout, c.Query("start_date"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid start_date, expected YYYY-MM-DD"})
+		return time.Time{}, time.Time{}, false
+	}
+
+	to := from
+	if endStr := c.Query("end_date"); endStr != "" {
+		to, err = time.Parse(dateLayout, endStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid end_date, expected YYYY-MM-DD"})
+			return time.Time{}, time.Time{}, false
+		}
+	}
+	return from, to, true
+}
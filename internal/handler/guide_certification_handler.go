@@ -0,0 +1,172 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/touros-platform/api/internal/domain"
+	"github.com/touros-platform/api/internal/service"
+)
+
+type GuideCertificationHandler struct {
+	certificationService service.GuideCertificationService
+}
+
+func NewGuideCertificationHandler(certificationService service.GuideCertificationService) *GuideCertificationHandler {
+	return &GuideCertificationHandler{
+		certificationService: certificationService,
+	}
+}
+
+// Upload takes a multipart form with the certification's type, issuer,
+// certificate_number, issue_date and expiry_date (YYYY-MM-DD) and the
+// scanned certificate as file.
+func (h *GuideCertificationHandler) Upload(c *gin.Context) {
+	guideID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	input := &service.CertificationInput{
+		Type:              domain.CertificationType(c.PostForm("type")),
+		Issuer:            c.PostForm("issuer"),
+		CertificateNumber: c.PostForm("certificate_number"),
+	}
+	input.IssueDate, err = time.Parse(dateLayout, c.PostForm("issue_date"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "issue_date must be a date in YYYY-MM-DD format"})
+		return
+	}
+	if expiry := c.PostForm("expiry_date"); expiry != "" {
+		expiryDate, err := time.Parse(dateLayout, expiry)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "expiry_date must be a date in YYYY-MM-DD format"})
+			return
+		}
+		input.ExpiryDate = &expiryDate
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	certification, err := h.certificationService.Upload(currentActor(c), guideID, input, header.Filename, file, header.Size)
+	if err != nil {
+		c.JSON(accessStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, certification)
+}
+
+func (h *GuideCertificationHandler) List(c *gin.Context) {
+	guideID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	certifications, err := h.certificationService.List(currentActor(c), guideID)
+	if err != nil {
+		readFailed(c, err, http.StatusNotFound, "guide not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": certifications})
+}
+
+func (h *GuideCertificationHandler) Download(c *gin.Context) {
+	guideID, id, ok := certificationParams(c)
+	if !ok {
+		return
+	}
+
+	certification, file, err := h.certificationService.Open(currentActor(c), guideID, id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "certification not found"})
+		return
+	}
+	defer file.Close()
+
+	c.DataFromReader(http.StatusOK, certification.Size, certification.ContentType, file, map[string]string{
+		"Content-Disposition":    fmt.Sprintf("attachment; filename*=UTF-8''%s", url.PathEscape(certification.FileName)),
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+func (h *GuideCertificationHandler) Delete(c *gin.Context) {
+	guideID, id, ok := certificationParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.certificationService.Delete(currentActor(c), guideID, id); err != nil {
+		c.JSON(accessStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "certification deleted"})
+}
+
+func (h *GuideCertificationHandler) Approve(c *gin.Context) {
+	guideID, id, ok := certificationParams(c)
+	if !ok {
+		return
+	}
+
+	certification, err := h.certificationService.Approve(guideID, id, currentActor(c).UserID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, certification)
+}
+
+func (h *GuideCertificationHandler) Reject(c *gin.Context) {
+	guideID, id, ok := certificationParams(c)
+	if !ok {
+		return
+	}
+
+	var req RejectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	certification, err := h.certificationService.Reject(guideID, id, currentActor(c).UserID, req.Reason)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, certification)
+}
+
+func certificationParams(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	guideID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	id, err := uuid.Parse(c.Param("cert_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid certification id"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	return guideID, id, true
+}
@@ -3,10 +3,13 @@ package handler
 import (
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/touros-platform/api/internal/domain"
+	"github.com/touros-platform/api/internal/repository"
 	"github.com/touros-platform/api/internal/service"
 )
 
@@ -26,12 +29,42 @@ type CreateGuideRequest struct {
 	LicenseNumber   string    `json:"license_number" binding:"required"`
 	PhoneNumber     string    `json:"phone_number" binding:"required"`
 	EmergencyContact string   `json:"emergency_contact" binding:"required"`
+	Languages        []string             `json:"languages"`
+	Regions          []GuideRegionRequest `json:"regions" binding:"dive"`
+}
+
+type GuideRegionRequest struct {
+	Region          string `json:"region" binding:"required"`
+	YearsExperience int    `json:"years_experience"`
 }
 
 type UpdateGuideRequest struct {
 	PhoneNumber      *string `json:"phone_number"`
 	EmergencyContact *string `json:"emergency_contact"`
 	AgencyID         *uuid.UUID `json:"agency_id"`
+	LicenseExpiry    *time.Time `json:"license_expiry"`
+	MaxAltitudeMeters *int                  `json:"max_altitude_meters"`
+	Languages         *[]string             `json:"languages"`
+	Regions           *[]GuideRegionRequest `json:"regions" binding:"omitempty,dive"`
+}
+
+func toGuideLanguages(codes []string) []domain.GuideLanguage {
+	languages := make([]domain.GuideLanguage, 0, len(codes))
+	for _, code := range codes {
+		languages = append(languages, domain.GuideLanguage{Language: code})
+	}
+	return languages
+}
+
+func toGuideRegions(reqs []GuideRegionRequest) []domain.GuideRegion {
+	regions := make([]domain.GuideRegion, 0, len(reqs))
+	for _, req := range reqs {
+		regions = append(regions, domain.GuideRegion{
+			Region:          req.Region,
+			YearsExperience: req.YearsExperience,
+		})
+	}
+	return regions
 }
 
 func (h *GuideHandler) Create(c *gin.Context) {
@@ -48,10 +81,12 @@ func (h *GuideHandler) Create(c *gin.Context) {
 		PhoneNumber:     req.PhoneNumber,
 		EmergencyContact: req.EmergencyContact,
 		Status:          domain.GuideStatusPending,
+		Languages:       toGuideLanguages(req.Languages),
+		Regions:         toGuideRegions(req.Regions),
 	}
 
-	if err := h.guideService.Create(guide); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := h.guideService.Create(currentActor(c), guide); err != nil {
+		c.JSON(accessStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -65,7 +100,7 @@ func (h *GuideHandler) GetByID(c *gin.Context) {
 		return
 	}
 
-	guide, err := h.guideService.GetByID(id)
+	guide, err := h.guideService.GetByID(currentActor(c), id)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "guide not found"})
 		return
@@ -91,11 +126,21 @@ func (h *GuideHandler) Update(c *gin.Context) {
 		PhoneNumber:      req.PhoneNumber,
 		EmergencyContact: req.EmergencyContact,
 		AgencyID:         req.AgencyID,
+		LicenseExpiry:    req.LicenseExpiry,
+		MaxAltitude:      req.MaxAltitudeMeters,
+	}
+	if req.Languages != nil {
+		languages := toGuideLanguages(*req.Languages)
+		updates.Languages = &languages
+	}
+	if req.Regions != nil {
+		regions := toGuideRegions(*req.Regions)
+		updates.Regions = &regions
 	}
 
-	guide, err := h.guideService.Update(id, updates)
+	guide, err := h.guideService.Update(currentActor(c), id, updates)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(accessStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -106,22 +151,14 @@ func (h *GuideHandler) List(c *gin.Context) {
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
 	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
 
-	var status *domain.GuideStatus
-	if statusStr := c.Query("status"); statusStr != "" {
-		s := domain.GuideStatus(statusStr)
-		status = &s
-	}
-
-	var agencyID *uuid.UUID
-	if agencyIDStr := c.Query("agency_id"); agencyIDStr != "" {
-		if id, err := uuid.Parse(agencyIDStr); err == nil {
-			agencyID = &id
-		}
+	filter, ok := guideFilter(c)
+	if !ok {
+		return
 	}
 
-	guides, total, err := h.guideService.List(limit, offset, status, agencyID)
+	guides, total, err := h.guideService.List(currentActor(c), limit, offset, filter)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(accessStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -133,6 +170,51 @@ func (h *GuideHandler) List(c *gin.Context) {
 	})
 }
 
+// guideFilter reads the guide search filters from the query string. It
+// writes the error response and returns false when one is invalid.
+func guideFilter(c *gin.Context) (repository.GuideFilter, bool) {
+	filter := repository.GuideFilter{
+		Languages: queryList(c, "language"),
+		Region:    strings.TrimSpace(c.Query("region")),
+	}
+	if statusStr := c.Query("status"); statusStr != "" {
+		s := domain.GuideStatus(statusStr)
+		filter.Status = &s
+	}
+	if agencyIDStr := c.Query("agency_id"); agencyIDStr != "" {
+		if id, err := uuid.Parse(agencyIDStr); err == nil {
+			filter.AgencyID = &id
+		}
+	}
+	for _, certification := range queryList(c, "certification") {
+		filter.Certifications = append(filter.Certifications, domain.CertificationType(certification))
+	}
+	if altitudeStr := c.Query("min_altitude"); altitudeStr != "" {
+		altitude, err := strconv.Atoi(altitudeStr)
+		if err != nil || altitude < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "min_altitude must be a non-negative number of meters"})
+			return filter, false
+		}
+		filter.MinAltitude = altitude
+	}
+	return filter, true
+}
+
+// queryList collects the lowercased values of a query parameter given
+// either repeatedly or as a comma separated list, such as ?language=en,de or
+// ?language=en&language=de.
+func queryList(c *gin.Context, key string) []string {
+	var values []string
+	for _, param := range c.QueryArray(key) {
+		for _, value := range strings.Split(param, ",") {
+			if value = strings.ToLower(strings.TrimSpace(value)); value != "" {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
 func (h *GuideHandler) Verify(c *gin.Context) {
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/touros-platform/api/internal/service"
+)
+
+type LicenseHandler struct {
+	licenseService service.LicenseService
+}
+
+func NewLicenseHandler(licenseService service.LicenseService) *LicenseHandler {
+	return &LicenseHandler{
+		licenseService: licenseService,
+	}
+}
+
+// Expiring reports guide and agency licenses that expire within ?days
+// (default 60), including expired ones not yet suspended.
+func (h *LicenseHandler) Expiring(c *gin.Context) {
+	days, err := strconv.Atoi(c.DefaultQuery("days", "60"))
+	if err != nil || days < 0 || days > 366 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "days must be between 0 and 366"})
+		return
+	}
+
+	report, err := h.licenseService.UpcomingExpiries(days)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"encoding/base64"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/skip2/go-qrcode"
+	"github.com/touros-platform/api/internal/document"
+	"github.com/touros-platform/api/internal/service"
+)
+
+type MFAHandler struct {
+	mfaService service.MFAService
+}
+
+func NewMFAHandler(mfaService service.MFAService) *MFAHandler {
+	return &MFAHandler{
+		mfaService: mfaService,
+	}
+}
+
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type DisableMFARequest struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+func (h *MFAHandler) Status(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	status, err := h.mfaService.Status(userID.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"enabled":             status.Enabled,
+		"required":            status.Required,
+		"recovery_codes_left": status.RecoveryCodesLeft,
+	})
+}
+
+// Enroll starts adding an authenticator app to the user's account. MFA is
+// not enabled until Confirm succeeds with a code from the app.
+func (h *MFAHandler) Enroll(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	enrollment, err := h.mfaService.BeginEnrollment(userID.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := enrollmentResponse(enrollment)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Confirm enables MFA and returns the recovery codes, which are only shown
+// this once.
+func (h *MFAHandler) Confirm(c *gin.Context) {
+	var req MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	recoveryCodes, err := h.mfaService.ConfirmEnrollment(userID.(uuid.UUID), req.Code)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"recovery_codes": recoveryCodes})
+}
+
+func (h *MFAHandler) Disable(c *gin.Context) {
+	var req DisableMFARequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	if err := h.mfaService.Disable(userID.(uuid.UUID), req.Password, req.Code); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "MFA disabled"})
+}
+
+// RegenerateRecoveryCodes replaces all of the user's recovery codes, used or
+// not, with a new set.
+func (h *MFAHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	var req MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	recoveryCodes, err := h.mfaService.RegenerateRecoveryCodes(userID.(uuid.UUID), req.Code)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"recovery_codes": recoveryCodes})
+}
+
+// enrollmentResponse includes the otpauth URI as a QR code data URI so
+// clients can show it without a QR library of their own.
+func enrollmentResponse(enrollment *service.MFAEnrollment) (gin.H, error) {
+	png, err := document.QRCodePNG(enrollment.URI, qrcode.Medium, document.DefaultQRSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return gin.H{
+		"secret":  enrollment.Secret,
+		"uri":     enrollment.URI,
+		"qr_code": "data:image/png;base64," + base64.StdEncoding.EncodeToString(png),
+	}, nil
+}
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/touros-platform/api/internal/service"
+)
+
+type OIDCHandler struct {
+	oidcService service.OIDCService
+}
+
+func NewOIDCHandler(oidcService service.OIDCService) *OIDCHandler {
+	return &OIDCHandler{
+		oidcService: oidcService,
+	}
+}
+
+type OIDCCallbackRequest struct {
+	Code  string `json:"code" binding:"required"`
+	State string `json:"state" binding:"required"`
+}
+
+func (h *OIDCHandler) Providers(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"data": h.oidcService.Providers()})
+}
+
+// Authorize starts a login at the provider. The client sends the user to
+// authorization_url; the provider redirects back to the client, which
+// completes the login at Callback.
+func (h *OIDCHandler) Authorize(c *gin.Context) {
+	authorization, err := h.oidcService.Authorize(c.Param("provider"))
+	if err != nil {
+		if errors.Is(err, service.ErrUnknownProvider) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"authorization_url": authorization.URL,
+		"state":             authorization.State,
+		"expires_in":        authorization.ExpiresIn,
+	})
+}
+
+// Callback exchanges the code and state the provider redirected back with
+// for session tokens.
+func (h *OIDCHandler) Callback(c *gin.Context) {
+	var req OIDCCallbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tokens, err := h.oidcService.Callback(c.Param("provider"), req.Code, req.State, c.ClientIP())
+	if err != nil {
+		if errors.Is(err, service.ErrUnknownProvider) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		loginError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"access_token":  tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
+		"token_type":    "Bearer",
+	})
+}
@@ -1,12 +1,16 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	qrcode "github.com/skip2/go-qrcode"
+	"github.com/touros-platform/api/internal/document"
 	"github.com/touros-platform/api/internal/domain"
 	"github.com/touros-platform/api/internal/middleware"
 	"github.com/touros-platform/api/internal/service"
@@ -14,23 +18,51 @@ import (
 
 type PermitHandler struct {
 	permitService service.PermitService
+	documentFonts *document.Fonts
 }
 
-func NewPermitHandler(permitService service.PermitService) *PermitHandler {
+func NewPermitHandler(permitService service.PermitService, documentFonts *document.Fonts) *PermitHandler {
 	return &PermitHandler{
 		permitService: permitService,
+		documentFonts: documentFonts,
 	}
 }
 
+type VerifyPermitRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type PartyMemberRequest struct {
+	Role      domain.PartyRole `json:"role" binding:"required"`
+	TrekkerID *uuid.UUID       `json:"trekker_id"`
+	FullName  string           `json:"full_name"`
+	Phone     string           `json:"phone"`
+	IDNumber  string           `json:"id_number"`
+}
+
+type SetMemberStatusRequest struct {
+	Status domain.MemberStatus `json:"status" binding:"required"`
+	Note   string              `json:"note"`
+}
+
 type CreatePermitRequest struct {
-	GuideID     uuid.UUID `json:"guide_id" binding:"required"`
-	ClientID    uuid.UUID `json:"client_id" binding:"required"`
-	ClientName  string    `json:"client_name" binding:"required"`
-	ClientEmail string    `json:"client_email" binding:"email"`
-	ClientPhone string    `json:"client_phone"`
-	StartDate   time.Time `json:"start_date" binding:"required"`
-	EndDate     time.Time `json:"end_date" binding:"required"`
-	Route       string    `json:"route" binding:"required"`
+	GuideID         uuid.UUID            `json:"guide_id" binding:"required"`
+	TrekkerID       uuid.UUID            `json:"trekker_id" binding:"required"`
+	Members         []PartyMemberRequest `json:"members" binding:"dive"`
+	StartDate       time.Time            `json:"start_date" binding:"required"`
+	EndDate         time.Time            `json:"end_date" binding:"required"`
+	RouteID         uuid.UUID            `json:"route_id" binding:"required"`
+	CustomItinerary string               `json:"custom_itinerary"`
+}
+
+func (r *PartyMemberRequest) toInput() service.PartyMemberInput {
+	return service.PartyMemberInput{
+		Role:      r.Role,
+		TrekkerID: r.TrekkerID,
+		FullName:  r.FullName,
+		Phone:     r.Phone,
+		IDNumber:  r.IDNumber,
+	}
 }
 
 func (h *PermitHandler) Create(c *gin.Context) {
@@ -44,20 +76,22 @@ func (h *PermitHandler) Create(c *gin.Context) {
 	issuedBy := userID.(uuid.UUID)
 
 	serviceReq := &service.CreatePermitRequest{
-		GuideID:     req.GuideID,
-		ClientID:    req.ClientID,
-		ClientName:  req.ClientName,
-		ClientEmail: req.ClientEmail,
-		ClientPhone: req.ClientPhone,
-		StartDate:   req.StartDate,
-		EndDate:     req.EndDate,
-		Route:       req.Route,
-		IssuedBy:    issuedBy,
+		GuideID:         req.GuideID,
+		TrekkerID:       req.TrekkerID,
+		Members:         make([]service.PartyMemberInput, 0, len(req.Members)),
+		StartDate:       req.StartDate,
+		EndDate:         req.EndDate,
+		RouteID:         req.RouteID,
+		CustomItinerary: req.CustomItinerary,
+		IssuedBy:        issuedBy,
+	}
+	for i := range req.Members {
+		serviceReq.Members = append(serviceReq.Members, req.Members[i].toInput())
 	}
 
-	permit, err := h.permitService.Create(serviceReq)
+	permit, err := h.permitService.Create(currentActor(c), serviceReq)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(conflictStatus(err, accessStatus(err, http.StatusBadRequest)), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -72,7 +106,7 @@ func (h *PermitHandler) GetByID(c *gin.Context) {
 		return
 	}
 
-	permit, err := h.permitService.GetByID(id)
+	permit, err := h.permitService.GetByID(currentActor(c), id)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "permit not found"})
 		return
@@ -90,7 +124,34 @@ func (h *PermitHandler) Validate(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, permit)
+	c.JSON(http.StatusOK, gin.H{
+		"permit": permit,
+		"party":  permit.PartySummary(),
+	})
+}
+
+func (h *PermitHandler) Verify(c *gin.Context) {
+	var req VerifyPermitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	permit, err := h.permitService.VerifyQRCode(req.Token)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"permit": permit,
+		"party":  permit.PartySummary(),
+	})
+}
+
+func (h *PermitHandler) PublicKeys(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=3600")
+	c.JSON(http.StatusOK, h.permitService.PublicKeys())
 }
 
 func (h *PermitHandler) Revoke(c *gin.Context) {
@@ -133,9 +194,9 @@ func (h *PermitHandler) List(c *gin.Context) {
 		permitStatus = &s
 	}
 
-	permits, total, err := h.permitService.List(limit, offset, guideID, permitStatus)
+	permits, total, err := h.permitService.List(currentActor(c), limit, offset, guideID, permitStatus)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(accessStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -147,3 +208,178 @@ func (h *PermitHandler) List(c *gin.Context) {
 	})
 }
 
+func (h *PermitHandler) QRCodePNG(c *gin.Context) {
+	h.renderQRCode(c, "image/png", document.QRCodePNG)
+}
+
+func (h *PermitHandler) QRCodeSVG(c *gin.Context) {
+	h.renderQRCode(c, "image/svg+xml", document.QRCodeSVG)
+}
+
+func (h *PermitHandler) renderQRCode(c *gin.Context, contentType string, render func(string, qrcode.RecoveryLevel, int) ([]byte, error)) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	level, err := document.ParseRecoveryLevel(c.Query("ecc"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	size, err := strconv.Atoi(c.DefaultQuery("size", strconv.Itoa(document.DefaultQRSize)))
+	if err != nil || size <= 0 || size > document.MaxQRSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("size must be between 1 and %d", document.MaxQRSize)})
+		return
+	}
+
+	permit, err := h.permitService.GetByID(currentActor(c), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "permit not found"})
+		return
+	}
+
+	data, err := render(permit.QRCode, level, size)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Data(http.StatusOK, contentType, data)
+}
+
+func (h *PermitHandler) DocumentPDF(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	permit, err := h.permitService.GetByID(currentActor(c), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "permit not found"})
+		return
+	}
+
+	qrPNG, err := document.QRCodePNG(permit.QRCode, qrcode.Medium, 512)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	pdf, err := document.PermitPDF(permit, qrPNG, h.documentFonts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="permit-%s.pdf"`, permit.PermitNumber))
+	c.Data(http.StatusOK, "application/pdf", pdf)
+}
+
+func (h *PermitHandler) ListMembers(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	permit, err := h.permitService.GetByID(currentActor(c), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "permit not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":  permit.Members,
+		"party": permit.PartySummary(),
+	})
+}
+
+func (h *PermitHandler) AddMember(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req PartyMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	input := req.toInput()
+	member, err := h.permitService.AddMember(currentActor(c), id, &input)
+	if err != nil {
+		c.JSON(conflictStatus(err, accessStatus(err, http.StatusBadRequest)), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, member)
+}
+
+func (h *PermitHandler) WithdrawMember(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	memberID, err := uuid.Parse(c.Param("member_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid member id"})
+		return
+	}
+
+	if err := h.permitService.WithdrawMember(currentActor(c), id, memberID); err != nil {
+		c.JSON(accessStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "party member withdrawn"})
+}
+
+func (h *PermitHandler) SetMemberStatus(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	memberID, err := uuid.Parse(c.Param("member_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid member id"})
+		return
+	}
+
+	var req SetMemberStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	member, err := h.permitService.SetMemberStatus(currentActor(c), id, memberID, req.Status, req.Note)
+	if err != nil {
+		c.JSON(accessStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, member)
+}
+
+// conflictStatus reports a full quota or a double-booked guide as 409
+// Conflict and any other error with the given status.
+func conflictStatus(err error, status int) int {
+	var quotaErr *service.QuotaExceededError
+	if errors.As(err, &quotaErr) {
+		return http.StatusConflict
+	}
+	var unavailableErr *service.GuideUnavailableError
+	if errors.As(err, &unavailableErr) {
+		return http.StatusConflict
+	}
+	return status
+}
@@ -0,0 +1,222 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/touros-platform/api/internal/domain"
+	"github.com/touros-platform/api/internal/service"
+)
+
+const dateLayout = "2006-01-02"
+
+type QuotaHandler struct {
+	quotaService service.QuotaService
+}
+
+func NewQuotaHandler(quotaService service.QuotaService) *QuotaHandler {
+	return &QuotaHandler{
+		quotaService: quotaService,
+	}
+}
+
+type CreateQuotaRequest struct {
+	RouteID   *uuid.UUID         `json:"route_id"`
+	Region    string             `json:"region"`
+	Period    domain.QuotaPeriod `json:"period" binding:"required"`
+	StartDate string             `json:"start_date" binding:"required"`
+	EndDate   string             `json:"end_date" binding:"required"`
+	Capacity  int                `json:"capacity" binding:"min=0"`
+	Notes     string             `json:"notes"`
+}
+
+type UpdateQuotaRequest struct {
+	Period    *domain.QuotaPeriod `json:"period"`
+	StartDate *string             `json:"start_date"`
+	EndDate   *string             `json:"end_date"`
+	Capacity  *int                `json:"capacity" binding:"omitempty,min=0"`
+	Notes     *string             `json:"notes"`
+}
+
+func (h *QuotaHandler) Create(c *gin.Context) {
+	var req CreateQuotaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	startDate, err := time.Parse(dateLayout, req.StartDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid start_date, expected YYYY-MM-DD"})
+		return
+	}
+	endDate, err := time.Parse(dateLayout, req.EndDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid end_date, expected YYYY-MM-DD"})
+		return
+	}
+
+	quota := &domain.Quota{
+		RouteID:   req.RouteID,
+		Region:    req.Region,
+		Period:    req.Period,
+		StartDate: startDate,
+		EndDate:   endDate,
+		Capacity:  req.Capacity,
+		Notes:     req.Notes,
+	}
+
+	if err := h.quotaService.Create(quota); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, quota)
+}
+
+func (h *QuotaHandler) GetByID(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	quota, err := h.quotaService.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "quota not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, quota)
+}
+
+func (h *QuotaHandler) Update(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req UpdateQuotaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updates := &service.UpdateQuotaRequest{
+		Period:   req.Period,
+		Capacity: req.Capacity,
+		Notes:    req.Notes,
+	}
+	if req.StartDate != nil {
+		startDate, err := time.Parse(dateLayout, *req.StartDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid start_date, expected YYYY-MM-DD"})
+			return
+		}
+		updates.StartDate = &startDate
+	}
+	if req.EndDate != nil {
+		endDate, err := time.Parse(dateLayout, *req.EndDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid end_date, expected YYYY-MM-DD"})
+			return
+		}
+		updates.EndDate = &endDate
+	}
+
+	quota, err := h.quotaService.Update(id, updates)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, quota)
+}
+
+func (h *QuotaHandler) Delete(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	if err := h.quotaService.Delete(id); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "quota deleted"})
+}
+
+func (h *QuotaHandler) List(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	var routeID *uuid.UUID
+	if routeIDStr := c.Query("route_id"); routeIDStr != "" {
+		id, err := uuid.Parse(routeIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid route_id"})
+			return
+		}
+		routeID = &id
+	}
+
+	var region *string
+	if regionStr := c.Query("region"); regionStr != "" {
+		region = &regionStr
+	}
+
+	quotas, total, err := h.quotaService.List(limit, offset, routeID, region)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":   quotas,
+		"total":  total,
+		"limit":  limit,
+		"offset": offset,
+	})
+}
+
+// Availability reports the remaining capacity on a route for each entry date
+// from date to end_date (defaulting to date alone).
+func (h *QuotaHandler) Availability(c *gin.Context) {
+	routeID, err := uuid.Parse(c.Query("route_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "route_id is required"})
+		return
+	}
+
+	from, err := time.Parse(dateLayout, c.Query("date"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid date, expected YYYY-MM-DD"})
+		return
+	}
+
+	to := from
+	if endStr := c.Query("end_date"); endStr != "" {
+		to, err = time.Parse(dateLayout, endStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid end_date, expected YYYY-MM-DD"})
+			return
+		}
+	}
+
+	days, err := h.quotaService.Availability(routeID, from, to)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"route_id": routeID,
+		"data":     days,
+	})
+}
@@ -0,0 +1,216 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/touros-platform/api/internal/domain"
+	"github.com/touros-platform/api/internal/service"
+)
+
+type RouteHandler struct {
+	routeService service.RouteService
+}
+
+func NewRouteHandler(routeService service.RouteService) *RouteHandler {
+	return &RouteHandler{
+		routeService: routeService,
+	}
+}
+
+type RouteCheckpointRequest struct {
+	Name              string  `json:"name" binding:"required"`
+	Latitude          float64 `json:"latitude" binding:"required"`
+	Longitude         float64 `json:"longitude" binding:"required"`
+	AltitudeMeters    int     `json:"altitude_meters"`
+	ExpectedDayOffset int     `json:"expected_day_offset"`
+	RadiusMeters      int     `json:"radius_meters"`
+}
+
+type CreateRouteRequest struct {
+	Code                   string                   `json:"code" binding:"required"`
+	Name                   string                   `json:"name" binding:"required"`
+	Region                 string                   `json:"region" binding:"required"`
+	Description            string                   `json:"description"`
+	DurationDays           int                      `json:"duration_days" binding:"required"`
+	MinTrekkers            *int                     `json:"min_trekkers"`
+	MaxTrekkers            *int                     `json:"max_trekkers"`
+	MaxSupportStaff        *int                     `json:"max_support_staff"`
+	Checkpoints            []RouteCheckpointRequest `json:"checkpoints" binding:"dive"`
+	RequiredCertifications []string                 `json:"required_certifications"`
+}
+
+type UpdateRouteRequest struct {
+	Code                   *string                   `json:"code"`
+	Name                   *string                   `json:"name"`
+	Region                 *string                   `json:"region"`
+	Description            *string                   `json:"description"`
+	DurationDays           *int                      `json:"duration_days"`
+	MinTrekkers            *int                      `json:"min_trekkers"`
+	MaxTrekkers            *int                      `json:"max_trekkers"`
+	MaxSupportStaff        *int                      `json:"max_support_staff"`
+	IsActive               *bool                     `json:"is_active"`
+	Checkpoints            *[]RouteCheckpointRequest `json:"checkpoints" binding:"omitempty,dive"`
+	RequiredCertifications *[]string                 `json:"required_certifications"`
+}
+
+func toRouteCheckpoints(reqs []RouteCheckpointRequest) []domain.RouteCheckpoint {
+	checkpoints := make([]domain.RouteCheckpoint, 0, len(reqs))
+	for _, req := range reqs {
+		checkpoints = append(checkpoints, domain.RouteCheckpoint{
+			Name:              req.Name,
+			Latitude:          req.Latitude,
+			Longitude:         req.Longitude,
+			AltitudeMeters:    req.AltitudeMeters,
+			ExpectedDayOffset: req.ExpectedDayOffset,
+			RadiusMeters:      req.RadiusMeters,
+		})
+	}
+	return checkpoints
+}
+
+func toRouteCertifications(types []string) []domain.RouteCertification {
+	required := make([]domain.RouteCertification, 0, len(types))
+	for _, t := range types {
+		required = append(required, domain.RouteCertification{CertificationType: domain.CertificationType(t)})
+	}
+	return required
+}
+
+func (h *RouteHandler) Create(c *gin.Context) {
+	var req CreateRouteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	route := &domain.Route{
+		Code:                   req.Code,
+		Name:                   req.Name,
+		Region:                 req.Region,
+		Description:            req.Description,
+		DurationDays:           req.DurationDays,
+		MinTrekkers:            1,
+		MaxTrekkers:            15,
+		MaxSupportStaff:        15,
+		IsActive:               true,
+		Checkpoints:            toRouteCheckpoints(req.Checkpoints),
+		RequiredCertifications: toRouteCertifications(req.RequiredCertifications),
+	}
+	if req.MinTrekkers != nil {
+		route.MinTrekkers = *req.MinTrekkers
+	}
+	if req.MaxTrekkers != nil {
+		route.MaxTrekkers = *req.MaxTrekkers
+	}
+	if req.MaxSupportStaff != nil {
+		route.MaxSupportStaff = *req.MaxSupportStaff
+	}
+
+	if err := h.routeService.Create(route); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, route)
+}
+
+func (h *RouteHandler) GetByID(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	route, err := h.routeService.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "route not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, route)
+}
+
+func (h *RouteHandler) Update(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req UpdateRouteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updates := &service.UpdateRouteRequest{
+		Code:            req.Code,
+		Name:            req.Name,
+		Region:          req.Region,
+		Description:     req.Description,
+		DurationDays:    req.DurationDays,
+		MinTrekkers:     req.MinTrekkers,
+		MaxTrekkers:     req.MaxTrekkers,
+		MaxSupportStaff: req.MaxSupportStaff,
+		IsActive:        req.IsActive,
+	}
+	if req.Checkpoints != nil {
+		checkpoints := toRouteCheckpoints(*req.Checkpoints)
+		updates.Checkpoints = &checkpoints
+	}
+	if req.RequiredCertifications != nil {
+		required := toRouteCertifications(*req.RequiredCertifications)
+		updates.RequiredCertifications = &required
+	}
+
+	route, err := h.routeService.Update(id, updates)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, route)
+}
+
+func (h *RouteHandler) Delete(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	if err := h.routeService.Delete(id); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "route deleted"})
+}
+
+func (h *RouteHandler) List(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	var region *string
+	if regionStr := c.Query("region"); regionStr != "" {
+		region = &regionStr
+	}
+
+	activeOnly := c.Query("active") == "true"
+
+	routes, total, err := h.routeService.List(limit, offset, region, activeOnly)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":   routes,
+		"total":  total,
+		"limit":  limit,
+		"offset": offset,
+	})
+}
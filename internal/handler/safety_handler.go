@@ -6,22 +6,35 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
 	"github.com/touros-platform/api/internal/domain"
+	"github.com/touros-platform/api/internal/events"
 	"github.com/touros-platform/api/internal/middleware"
 	"github.com/touros-platform/api/internal/service"
 )
 
 type SafetyHandler struct {
 	safetyService service.SafetyService
+	broker        *events.Broker
+	wsUpgrader    websocket.Upgrader
 }
 
-func NewSafetyHandler(safetyService service.SafetyService) *SafetyHandler {
+// NewSafetyHandler builds the safety handler. streamOrigins is the comma
+// separated list of browser origins allowed to open the WebSocket feed.
+func NewSafetyHandler(safetyService service.SafetyService, broker *events.Broker, streamOrigins string) *SafetyHandler {
 	return &SafetyHandler{
 		safetyService: safetyService,
+		broker:        broker,
+		wsUpgrader: websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 4096,
+			CheckOrigin:     allowOrigins(streamOrigins),
+		},
 	}
 }
 
 type CreateCheckInRequest struct {
+	GuideID    *uuid.UUID `json:"guide_id"`
 	PermitID   *uuid.UUID `json:"permit_id"`
 	Latitude   float64    `json:"latitude" binding:"required"`
 	Longitude  float64    `json:"longitude" binding:"required"`
@@ -31,6 +44,7 @@ type CreateCheckInRequest struct {
 
 type CreateIncidentRequest struct {
 	IncidentType string     `json:"incident_type" binding:"required"`
+	GuideID      *uuid.UUID `json:"guide_id"`
 	PermitID     *uuid.UUID `json:"permit_id"`
 	Latitude     float64    `json:"latitude" binding:"required"`
 	Longitude    float64    `json:"longitude" binding:"required"`
@@ -50,11 +64,8 @@ func (h *SafetyHandler) CreateCheckIn(c *gin.Context) {
 		return
 	}
 
-	userID, _ := c.Get("user_id")
-	guideID := userID.(uuid.UUID)
-
 	serviceReq := &service.CreateCheckInRequest{
-		GuideID:   guideID,
+		GuideID:   req.GuideID,
 		PermitID:  req.PermitID,
 		Latitude:  req.Latitude,
 		Longitude: req.Longitude,
@@ -62,9 +73,9 @@ func (h *SafetyHandler) CreateCheckIn(c *gin.Context) {
 		Notes:     req.Notes,
 	}
 
-	checkIn, err := h.safetyService.CreateCheckIn(serviceReq)
+	checkIn, err := h.safetyService.CreateCheckIn(currentActor(c), serviceReq)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(accessStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -79,7 +90,7 @@ func (h *SafetyHandler) GetCheckInByID(c *gin.Context) {
 		return
 	}
 
-	checkIn, err := h.safetyService.GetCheckInByID(id)
+	checkIn, err := h.safetyService.GetCheckInByID(currentActor(c), id)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "check-in not found"})
 		return
@@ -98,9 +109,9 @@ func (h *SafetyHandler) ListCheckIns(c *gin.Context) {
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
 	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
 
-	checkIns, total, err := h.safetyService.ListCheckIns(guideID, limit, offset)
+	checkIns, total, err := h.safetyService.ListCheckIns(currentActor(c), guideID, limit, offset)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		readFailed(c, err, http.StatusNotFound, "guide not found")
 		return
 	}
 
@@ -119,12 +130,9 @@ func (h *SafetyHandler) CreateIncident(c *gin.Context) {
 		return
 	}
 
-	userID, _ := c.Get("user_id")
-	guideID := userID.(uuid.UUID)
-
 	serviceReq := &service.CreateIncidentRequest{
 		IncidentType: req.IncidentType,
-		GuideID:      guideID,
+		GuideID:      req.GuideID,
 		PermitID:     req.PermitID,
 		Latitude:     req.Latitude,
 		Longitude:    req.Longitude,
@@ -132,9 +140,9 @@ func (h *SafetyHandler) CreateIncident(c *gin.Context) {
 		Description:  req.Description,
 	}
 
-	incident, err := h.safetyService.CreateIncident(serviceReq)
+	incident, err := h.safetyService.CreateIncident(currentActor(c), serviceReq)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(accessStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -152,7 +160,7 @@ func (h *SafetyHandler) GetIncidentByID(c *gin.Context) {
 		return
 	}
 
-	incident, err := h.safetyService.GetIncidentByID(id)
+	incident, err := h.safetyService.GetIncidentByID(currentActor(c), id)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "incident not found"})
 		return
@@ -189,9 +197,9 @@ func (h *SafetyHandler) UpdateIncident(c *gin.Context) {
 		ResolvedBy:      &resolvedBy,
 	}
 
-	incident, err := h.safetyService.UpdateIncident(id, serviceReq)
+	incident, err := h.safetyService.UpdateIncident(currentActor(c), id, serviceReq)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(accessStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -215,9 +223,9 @@ func (h *SafetyHandler) ListIncidents(c *gin.Context) {
 		}
 	}
 
-	incidents, total, err := h.safetyService.ListIncidents(limit, offset, status, guideID)
+	incidents, total, err := h.safetyService.ListIncidents(currentActor(c), limit, offset, status, guideID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(accessStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -236,9 +244,9 @@ func (h *SafetyHandler) GetActiveSOS(c *gin.Context) {
 		return
 	}
 
-	incidents, err := h.safetyService.GetActiveSOS(guideID)
+	incidents, err := h.safetyService.GetActiveSOS(currentActor(c), guideID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		readFailed(c, err, http.StatusNotFound, "guide not found")
 		return
 	}
 
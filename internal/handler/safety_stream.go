@@ -0,0 +1,224 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/touros-platform/api/internal/domain"
+	"github.com/touros-platform/api/internal/events"
+)
+
+const (
+	streamHeartbeatInterval = 25 * time.Second
+	wsWriteTimeout          = 10 * time.Second
+)
+
+// allowOrigins returns a WebSocket origin check that accepts the listed
+// origins. Requests without an Origin header do not come from a browser, so
+// they cannot be used for cross-site WebSocket hijacking and are accepted.
+func allowOrigins(origins string) func(r *http.Request) bool {
+	allowed := make(map[string]bool)
+	for _, origin := range strings.Split(origins, ",") {
+		if origin = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(origin)), "/"); origin != "" {
+			allowed[origin] = true
+		}
+	}
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		return origin == "" || allowed[strings.ToLower(origin)]
+	}
+}
+
+// Stream pushes safety events to the client as Server-Sent Events. Clients
+// resume after a disconnect by sending the standard Last-Event-ID header (or
+// the last_event_id query parameter).
+func (h *SafetyHandler) Stream(c *gin.Context) {
+	filter, lastEventID, err := parseStreamParams(c, c.GetHeader("Last-Event-ID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	filter, err = h.safetyService.StreamFilter(currentActor(c), filter)
+	if err != nil {
+		c.JSON(accessStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+
+	// The server-wide write timeout would otherwise cut long-lived streams.
+	rc := http.NewResponseController(c.Writer)
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	sub, replay := h.broker.Subscribe(filter, lastEventID)
+	defer sub.Close()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	for i := range replay {
+		if err := writeSSE(c, &replay[i]); err != nil {
+			return
+		}
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case evt, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			if err := writeSSE(c, &evt); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(c.Writer, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+	}
+}
+
+// WebSocket pushes the same safety events as Stream over a WebSocket
+// connection, one JSON-encoded event per message.
+func (h *SafetyHandler) WebSocket(c *gin.Context) {
+	filter, lastEventID, err := parseStreamParams(c, "")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	filter, err = h.safetyService.StreamFilter(currentActor(c), filter)
+	if err != nil {
+		c.JSON(accessStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+
+	conn, err := h.wsUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	sub, replay := h.broker.Subscribe(filter, lastEventID)
+	defer sub.Close()
+
+	// Drain client frames so control messages are processed and a closed
+	// connection is noticed.
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	for i := range replay {
+		if err := writeWS(conn, &replay[i]); err != nil {
+			return
+		}
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case evt, ok := <-sub.C:
+			if !ok {
+				conn.WriteControl(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "subscriber too slow"),
+					time.Now().Add(wsWriteTimeout))
+				return
+			}
+			if err := writeWS(conn, &evt); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteTimeout)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func parseStreamParams(c *gin.Context, lastEventIDHeader string) (events.Filter, uint64, error) {
+	var filter events.Filter
+
+	if agencyIDStr := c.Query("agency_id"); agencyIDStr != "" {
+		id, err := uuid.Parse(agencyIDStr)
+		if err != nil {
+			return filter, 0, fmt.Errorf("invalid agency_id")
+		}
+		filter.AgencyID = &id
+	}
+
+	if guideIDStr := c.Query("guide_id"); guideIDStr != "" {
+		id, err := uuid.Parse(guideIDStr)
+		if err != nil {
+			return filter, 0, fmt.Errorf("invalid guide_id")
+		}
+		filter.GuideID = &id
+	}
+
+	if typeStr := c.Query("incident_type"); typeStr != "" {
+		t := domain.IncidentType(typeStr)
+		filter.IncidentType = &t
+	}
+
+	if statusStr := c.Query("status"); statusStr != "" {
+		s := domain.IncidentStatus(statusStr)
+		filter.Status = &s
+	}
+
+	lastEventIDStr := lastEventIDHeader
+	if lastEventIDStr == "" {
+		lastEventIDStr = c.Query("last_event_id")
+	}
+
+	var lastEventID uint64
+	if lastEventIDStr != "" {
+		id, err := strconv.ParseUint(lastEventIDStr, 10, 64)
+		if err != nil {
+			return filter, 0, fmt.Errorf("invalid last event id")
+		}
+		lastEventID = id
+	}
+
+	return filter, lastEventID, nil
+}
+
+func writeSSE(c *gin.Context, evt *events.Event) error {
+	data, err := json.Marshal(evt)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(c.Writer, "id: %d\nevent: %s\ndata: %s\n\n", evt.ID, evt.Type, data)
+	return err
+}
+
+func writeWS(conn *websocket.Conn, evt *events.Event) error {
+	conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+	return conn.WriteJSON(evt)
+}
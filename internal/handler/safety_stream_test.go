@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAllowOrigins(t *testing.T) {
+	check := allowOrigins(" https://ops.example.com/, https://Field.example.com ")
+
+	tests := []struct {
+		origin string
+		want   bool
+	}{
+		{"", true},
+		{"https://ops.example.com", true},
+		{"https://field.example.com", true},
+		{"HTTPS://OPS.EXAMPLE.COM", true},
+		{"http://ops.example.com", false},
+		{"https://ops.example.com.evil.test", false},
+		{"https://evil.test", false},
+		{"null", false},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "/api/v1/safety/ws", nil)
+		if tt.origin != "" {
+			r.Header.Set("Origin", tt.origin)
+		}
+		if got := check(r); got != tt.want {
+			t.Errorf("origin %q: allowed = %v, want %v", tt.origin, got, tt.want)
+		}
+	}
+
+	if check := allowOrigins(""); !check(httptest.NewRequest("GET", "/", nil)) {
+		t.Error("request without an Origin refused by an empty allowlist")
+	}
+}
@@ -0,0 +1,203 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/touros-platform/api/internal/domain"
+	"github.com/touros-platform/api/internal/service"
+)
+
+type ServiceAccountHandler struct {
+	serviceAccountService service.ServiceAccountService
+}
+
+func NewServiceAccountHandler(serviceAccountService service.ServiceAccountService) *ServiceAccountHandler {
+	return &ServiceAccountHandler{
+		serviceAccountService: serviceAccountService,
+	}
+}
+
+type CreateServiceAccountRequest struct {
+	Name        string     `json:"name" binding:"required"`
+	Description string     `json:"description"`
+	AgencyID    *uuid.UUID `json:"agency_id"`
+}
+
+type CreateAPIKeyRequest struct {
+	Scopes    []string   `json:"scopes" binding:"required,min=1"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+func (h *ServiceAccountHandler) Create(c *gin.Context) {
+	var req CreateServiceAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	account := &domain.ServiceAccount{
+		Name:        req.Name,
+		Description: req.Description,
+	}
+	if req.AgencyID != nil {
+		account.AgencyID = *req.AgencyID
+	}
+
+	if err := h.serviceAccountService.Create(currentActor(c), account); err != nil {
+		c.JSON(accessStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, account)
+}
+
+func (h *ServiceAccountHandler) GetByID(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	account, err := h.serviceAccountService.GetByID(currentActor(c), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "service account not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, account)
+}
+
+// Delete removes the service account and revokes all of its keys.
+func (h *ServiceAccountHandler) Delete(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	if err := h.serviceAccountService.Delete(currentActor(c), id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "service account not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "service account deleted"})
+}
+
+func (h *ServiceAccountHandler) List(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	accounts, total, err := h.serviceAccountService.List(currentActor(c), limit, offset)
+	if err != nil {
+		c.JSON(accessStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":   accounts,
+		"total":  total,
+		"limit":  limit,
+		"offset": offset,
+	})
+}
+
+// CreateKey issues an API key. The key itself is only returned this once.
+func (h *ServiceAccountHandler) CreateKey(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	key, plain, err := h.serviceAccountService.CreateKey(currentActor(c), id, req.Scopes, req.ExpiresAt)
+	if err != nil {
+		c.JSON(accessStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, apiKeyResponse(key, plain))
+}
+
+func (h *ServiceAccountHandler) ListKeys(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	keys, err := h.serviceAccountService.ListKeys(currentActor(c), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "service account not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": keys})
+}
+
+// RotateKey issues a replacement key. The old key keeps working for a grace
+// period so the integration can switch over.
+func (h *ServiceAccountHandler) RotateKey(c *gin.Context) {
+	id, keyID, ok := parseKeyParams(c)
+	if !ok {
+		return
+	}
+
+	key, plain, err := h.serviceAccountService.RotateKey(currentActor(c), id, keyID)
+	if err != nil {
+		c.JSON(accessStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, apiKeyResponse(key, plain))
+}
+
+func (h *ServiceAccountHandler) RevokeKey(c *gin.Context) {
+	id, keyID, ok := parseKeyParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.serviceAccountService.RevokeKey(currentActor(c), id, keyID); err != nil {
+		c.JSON(accessStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "API key revoked"})
+}
+
+func parseKeyParams(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	keyID, err := uuid.Parse(c.Param("key_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid key id"})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return id, keyID, true
+}
+
+func apiKeyResponse(key *domain.APIKey, plain string) gin.H {
+	return gin.H{
+		"id":         key.ID,
+		"key":        plain,
+		"prefix":     key.Prefix,
+		"scopes":     key.ScopeList(),
+		"expires_at": key.ExpiresAt,
+		"created_at": key.CreatedAt,
+	}
+}
@@ -0,0 +1,221 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/touros-platform/api/internal/domain"
+	"github.com/touros-platform/api/internal/repository"
+	"github.com/touros-platform/api/internal/service"
+)
+
+type TrekkerHandler struct {
+	trekkerService service.TrekkerService
+}
+
+func NewTrekkerHandler(trekkerService service.TrekkerService) *TrekkerHandler {
+	return &TrekkerHandler{
+		trekkerService: trekkerService,
+	}
+}
+
+type EmergencyContactRequest struct {
+	Name         string `json:"name" binding:"required"`
+	Relationship string `json:"relationship"`
+	Phone        string `json:"phone" binding:"required"`
+	Email        string `json:"email" binding:"omitempty,email"`
+}
+
+type CreateTrekkerRequest struct {
+	FullName              string                    `json:"full_name" binding:"required"`
+	Email                 string                    `json:"email" binding:"omitempty,email"`
+	Phone                 string                    `json:"phone"`
+	PassportNumber        string                    `json:"passport_number" binding:"required"`
+	Nationality           string                    `json:"nationality" binding:"required,len=2"`
+	DateOfBirth           *time.Time                `json:"date_of_birth"`
+	InsuranceProvider     string                    `json:"insurance_provider"`
+	InsurancePolicyNumber string                    `json:"insurance_policy_number"`
+	InsuranceExpiry       *time.Time                `json:"insurance_expiry"`
+	EmergencyContacts     []EmergencyContactRequest `json:"emergency_contacts" binding:"dive"`
+}
+
+type UpdateTrekkerRequest struct {
+	FullName              *string                    `json:"full_name"`
+	Email                 *string                    `json:"email" binding:"omitempty,email"`
+	Phone                 *string                    `json:"phone"`
+	PassportNumber        *string                    `json:"passport_number"`
+	Nationality           *string                    `json:"nationality" binding:"omitempty,len=2"`
+	DateOfBirth           *time.Time                 `json:"date_of_birth"`
+	InsuranceProvider     *string                    `json:"insurance_provider"`
+	InsurancePolicyNumber *string                    `json:"insurance_policy_number"`
+	InsuranceExpiry       *time.Time                 `json:"insurance_expiry"`
+	EmergencyContacts     *[]EmergencyContactRequest `json:"emergency_contacts" binding:"omitempty,dive"`
+}
+
+func toEmergencyContacts(reqs []EmergencyContactRequest) []domain.EmergencyContact {
+	contacts := make([]domain.EmergencyContact, 0, len(reqs))
+	for _, req := range reqs {
+		contacts = append(contacts, domain.EmergencyContact{
+			Name:         req.Name,
+			Relationship: req.Relationship,
+			Phone:        req.Phone,
+			Email:        req.Email,
+		})
+	}
+	return contacts
+}
+
+func (h *TrekkerHandler) Create(c *gin.Context) {
+	var req CreateTrekkerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	trekker := &domain.Trekker{
+		FullName:              req.FullName,
+		Email:                 req.Email,
+		Phone:                 req.Phone,
+		PassportNumber:        req.PassportNumber,
+		Nationality:           req.Nationality,
+		DateOfBirth:           req.DateOfBirth,
+		InsuranceProvider:     req.InsuranceProvider,
+		InsurancePolicyNumber: req.InsurancePolicyNumber,
+		InsuranceExpiry:       req.InsuranceExpiry,
+		EmergencyContacts:     toEmergencyContacts(req.EmergencyContacts),
+	}
+
+	if err := h.trekkerService.Create(currentActor(c), trekker); err != nil {
+		c.JSON(accessStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, trekker)
+}
+
+func (h *TrekkerHandler) GetByID(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	trekker, err := h.trekkerService.GetByID(currentActor(c), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "trekker not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, trekker)
+}
+
+func (h *TrekkerHandler) Update(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req UpdateTrekkerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updates := &service.UpdateTrekkerRequest{
+		FullName:              req.FullName,
+		Email:                 req.Email,
+		Phone:                 req.Phone,
+		PassportNumber:        req.PassportNumber,
+		Nationality:           req.Nationality,
+		DateOfBirth:           req.DateOfBirth,
+		InsuranceProvider:     req.InsuranceProvider,
+		InsurancePolicyNumber: req.InsurancePolicyNumber,
+		InsuranceExpiry:       req.InsuranceExpiry,
+	}
+	if req.EmergencyContacts != nil {
+		contacts := toEmergencyContacts(*req.EmergencyContacts)
+		updates.EmergencyContacts = &contacts
+	}
+
+	trekker, err := h.trekkerService.Update(currentActor(c), id, updates)
+	if err != nil {
+		c.JSON(accessStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, trekker)
+}
+
+func (h *TrekkerHandler) Delete(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	if err := h.trekkerService.Delete(id); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "trekker deleted"})
+}
+
+func (h *TrekkerHandler) List(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	filter := repository.TrekkerFilter{
+		Query:          c.Query("q"),
+		PassportNumber: c.Query("passport_number"),
+		Nationality:    c.Query("nationality"),
+	}
+	if agencyIDStr := c.Query("agency_id"); agencyIDStr != "" {
+		if id, err := uuid.Parse(agencyIDStr); err == nil {
+			filter.AgencyID = &id
+		}
+	}
+
+	trekkers, total, err := h.trekkerService.List(currentActor(c), limit, offset, filter)
+	if err != nil {
+		c.JSON(accessStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":   trekkers,
+		"total":  total,
+		"limit":  limit,
+		"offset": offset,
+	})
+}
+
+// ListPermits returns every permit the trekker has held; total doubles as
+// the trekker's visit count.
+func (h *TrekkerHandler) ListPermits(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	permits, total, err := h.trekkerService.ListPermits(currentActor(c), id, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":   permits,
+		"total":  total,
+		"limit":  limit,
+		"offset": offset,
+	})
+}
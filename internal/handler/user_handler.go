@@ -0,0 +1,312 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/touros-platform/api/internal/domain"
+	"github.com/touros-platform/api/internal/service"
+)
+
+type UserHandler struct {
+	userService service.UserService
+}
+
+func NewUserHandler(userService service.UserService) *UserHandler {
+	return &UserHandler{
+		userService: userService,
+	}
+}
+
+type CreateUserRequest struct {
+	Email    string      `json:"email" binding:"required,email"`
+	Password string      `json:"password" binding:"required,min=8"`
+	FullName string      `json:"full_name" binding:"required"`
+	Role     domain.Role `json:"role" binding:"required"`
+	AgencyID *uuid.UUID  `json:"agency_id"`
+}
+
+type UpdateUserRequest struct {
+	Email       *string    `json:"email" binding:"omitempty,email"`
+	FullName    *string    `json:"full_name"`
+	AgencyID    *uuid.UUID `json:"agency_id"`
+	ClearAgency bool       `json:"clear_agency"`
+}
+
+type SetRoleRequest struct {
+	Role domain.Role `json:"role" binding:"required"`
+}
+
+type UpdateProfileRequest struct {
+	FullName *string `json:"full_name"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required,min=8"`
+}
+
+func (h *UserHandler) Create(c *gin.Context) {
+	var req CreateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user := &domain.User{
+		Email:    req.Email,
+		FullName: req.FullName,
+		Role:     req.Role,
+		AgencyID: req.AgencyID,
+	}
+
+	if err := h.userService.Create(user, req.Password); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, user)
+}
+
+func (h *UserHandler) GetByID(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	user, err := h.userService.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+func (h *UserHandler) Update(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req UpdateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updates := &service.UpdateUserRequest{
+		Email:       req.Email,
+		FullName:    req.FullName,
+		AgencyID:    req.AgencyID,
+		ClearAgency: req.ClearAgency,
+	}
+
+	user, err := h.userService.Update(id, updates)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+func (h *UserHandler) SetRole(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req SetRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	actorID := userID.(uuid.UUID)
+
+	user, err := h.userService.SetRole(id, req.Role, actorID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+func (h *UserHandler) Activate(c *gin.Context) {
+	h.setActive(c, true)
+}
+
+func (h *UserHandler) Deactivate(c *gin.Context) {
+	h.setActive(c, false)
+}
+
+func (h *UserHandler) setActive(c *gin.Context, active bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	actorID := userID.(uuid.UUID)
+
+	user, err := h.userService.SetActive(id, active, actorID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+func (h *UserHandler) Delete(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	actorID := userID.(uuid.UUID)
+
+	if err := h.userService.Delete(id, actorID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "user deleted"})
+}
+
+func (h *UserHandler) List(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	var role *domain.Role
+	if roleStr := c.Query("role"); roleStr != "" {
+		r := domain.Role(roleStr)
+		role = &r
+	}
+
+	var active *bool
+	if activeStr := c.Query("active"); activeStr != "" {
+		a := activeStr == "true"
+		active = &a
+	}
+
+	users, total, err := h.userService.List(limit, offset, role, active)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":   users,
+		"total":  total,
+		"limit":  limit,
+		"offset": offset,
+	})
+}
+
+// Unlock lifts a lockout caused by repeated failed logins.
+func (h *UserHandler) Unlock(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	user, err := h.userService.Unlock(id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// LoginAttempts lists the user's recent successful and failed logins.
+func (h *UserHandler) LoginAttempts(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	attempts, total, err := h.userService.LoginAttempts(id, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":   attempts,
+		"total":  total,
+		"limit":  limit,
+		"offset": offset,
+	})
+}
+
+// Me returns the authenticated user's own account.
+func (h *UserHandler) Me(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	user, err := h.userService.GetByID(userID.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+func (h *UserHandler) UpdateMe(c *gin.Context) {
+	var req UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	updates := &service.UpdateProfileRequest{
+		FullName: req.FullName,
+	}
+
+	user, err := h.userService.UpdateProfile(userID.(uuid.UUID), updates)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// ChangePassword sets a new password for the authenticated user. Every
+// session, including the current one, ends and the user must log in again.
+func (h *UserHandler) ChangePassword(c *gin.Context) {
+	var req ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+
+	if err := h.userService.ChangePassword(userID.(uuid.UUID), req.CurrentPassword, req.NewPassword); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "password changed, please log in again"})
+}
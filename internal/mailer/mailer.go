@@ -0,0 +1,66 @@
+package mailer
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/touros-platform/api/internal/config"
+	"go.uber.org/zap"
+)
+
+// Message is a plain text email.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers outgoing email.
+type Mailer interface {
+	Send(msg *Message) error
+}
+
+// New returns the mailer selected by cfg.Driver: "smtp", "file" or "log".
+func New(cfg config.MailConfig, logger *zap.Logger) (Mailer, error) {
+	switch cfg.Driver {
+	case "smtp":
+		return NewSMTPMailer(cfg), nil
+	case "file":
+		return NewFileMailer(cfg.From, cfg.FileDir)
+	case "log", "":
+		return NewLogMailer(logger), nil
+	default:
+		return nil, fmt.Errorf("unknown mail driver: %s", cfg.Driver)
+	}
+}
+
+// compose renders msg as an RFC 5322 message.
+func compose(from string, msg *Message, at time.Time) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", at.Format(time.RFC1123Z))
+	fmt.Fprintf(&b, "Message-ID: <%s@touros>\r\n", uuid.NewString())
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return b.Bytes()
+}
+
+// address extracts the bare address from a header value such as
+// "Touros <no-reply@touros.gov.np>".
+func address(value string) (string, error) {
+	addr, err := mail.ParseAddress(value)
+	if err != nil {
+		return "", fmt.Errorf("invalid address %q: %w", value, err)
+	}
+	return addr.Address, nil
+}
@@ -0,0 +1,48 @@
+package mailer
+
+import (
+	"net"
+	"net/smtp"
+	"time"
+
+	"github.com/touros-platform/api/internal/config"
+)
+
+// SMTPMailer sends mail through an SMTP relay. The connection is upgraded
+// with STARTTLS when the server offers it; credentials are only sent when a
+// username is configured, which suits local catchers such as MailHog.
+type SMTPMailer struct {
+	addr     string
+	host     string
+	username string
+	password string
+	from     string
+}
+
+func NewSMTPMailer(cfg config.MailConfig) *SMTPMailer {
+	return &SMTPMailer{
+		addr:     net.JoinHostPort(cfg.SMTPHost, cfg.SMTPPort),
+		host:     cfg.SMTPHost,
+		username: cfg.SMTPUsername,
+		password: cfg.SMTPPassword,
+		from:     cfg.From,
+	}
+}
+
+func (m *SMTPMailer) Send(msg *Message) error {
+	from, err := address(m.from)
+	if err != nil {
+		return err
+	}
+	to, err := address(msg.To)
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if m.username != "" {
+		auth = smtp.PlainAuth("", m.username, m.password, m.host)
+	}
+
+	return smtp.SendMail(m.addr, auth, from, []string{to}, compose(m.from, msg, time.Now()))
+}
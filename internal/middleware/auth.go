@@ -5,10 +5,13 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/touros-platform/api/internal/domain"
 	"github.com/touros-platform/api/internal/service"
 )
 
-func AuthMiddleware(authService service.AuthService) gin.HandlerFunc {
+// AuthMiddleware accepts a bearer JWT or, when serviceAccountService is not
+// nil, a service account API key.
+func AuthMiddleware(authService service.AuthService, serviceAccountService service.ServiceAccountService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -24,6 +27,28 @@ func AuthMiddleware(authService service.AuthService) gin.HandlerFunc {
 			return
 		}
 
+		if service.IsAPIKey(parts[1]) {
+			if serviceAccountService == nil {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "API keys are not accepted here"})
+				c.Abort()
+				return
+			}
+
+			actor, err := serviceAccountService.Authenticate(parts[1], c.ClientIP())
+			if err != nil {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+				c.Abort()
+				return
+			}
+
+			c.Set("actor", actor)
+			c.Set("user_id", actor.UserID)
+			c.Set("user_role", actor.Role)
+			c.Set("service_account_id", actor.UserID)
+			c.Next()
+			return
+		}
+
 		claims, err := authService.ValidateToken(parts[1])
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired token"})
@@ -31,6 +56,19 @@ func AuthMiddleware(authService service.AuthService) gin.HandlerFunc {
 			return
 		}
 
+		revoked, err := authService.IsRevoked(claims)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check token status"})
+			c.Abort()
+			return
+		}
+		if revoked {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "token has been revoked"})
+			c.Abort()
+			return
+		}
+
+		c.Set("claims", claims)
 		c.Set("user_id", claims.UserID)
 		c.Set("user_email", claims.Email)
 		c.Set("user_role", claims.Role)
@@ -47,9 +85,9 @@ func RequireRole(allowedRoles ...string) gin.HandlerFunc {
 			return
 		}
 
-		roleStr := string(role.(string))
+		userRole, _ := role.(domain.Role)
 		for _, allowed := range allowedRoles {
-			if roleStr == allowed {
+			if string(userRole) == allowed {
 				c.Next()
 				return
 			}
@@ -59,3 +97,37 @@ func RequireRole(allowedRoles ...string) gin.HandlerFunc {
 		c.Abort()
 	}
 }
+
+// RequireScope limits API key requests to keys holding the resource's read
+// scope for GET and HEAD requests, or its write scope otherwise. Requests
+// authenticated with a JWT are not affected.
+func RequireScope(resource string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope := resource + ":write"
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			scope = resource + ":read"
+		}
+
+		actor, _ := c.Get("actor")
+		if a, ok := actor.(*service.Actor); ok && !a.HasScope(scope) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "API key lacks the " + scope + " scope"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireUser refuses service accounts, for endpoints that only make sense
+// for a person.
+func RequireUser() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		actor, _ := c.Get("actor")
+		if a, ok := actor.(*service.Actor); ok && a.ServiceAccount {
+			c.JSON(http.StatusForbidden, gin.H{"error": "not available to service accounts"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
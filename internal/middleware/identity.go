@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/touros-platform/api/internal/service"
+)
+
+// IdentityMiddleware resolves the authenticated user into a service.Actor,
+// including their guide profile, and stores it as "actor" for handlers to
+// pass to the services. It must run after AuthMiddleware.
+func IdentityMiddleware(identityService service.IdentityService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Service accounts are resolved by AuthMiddleware from their key.
+		if _, ok := c.Get("actor"); ok {
+			c.Next()
+			return
+		}
+
+		claims, _ := c.Get("claims")
+
+		actor, err := identityService.Resolve(claims.(*service.Claims))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to resolve user identity"})
+			c.Abort()
+			return
+		}
+
+		c.Set("actor", actor)
+		if actor.GuideID != nil {
+			c.Set("guide_id", *actor.GuideID)
+		}
+		if actor.AgencyID != nil {
+			c.Set("agency_id", *actor.AgencyID)
+		}
+		c.Next()
+	}
+}
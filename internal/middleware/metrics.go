@@ -47,6 +47,14 @@ var (
 			Help: "Total number of SOS incidents",
 		},
 	)
+
+	permitScansTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "permit_scans_total",
+			Help: "Total number of checkpoint permit scans",
+		},
+		[]string{"result"},
+	)
 )
 
 func MetricsMiddleware() gin.HandlerFunc {
@@ -76,3 +84,6 @@ func IncrementSOSIncidents() {
 	sosIncidentsTotal.Inc()
 }
 
+func IncrementPermitScans(result string) {
+	permitScansTotal.WithLabelValues(result).Inc()
+}
@@ -0,0 +1,377 @@
+// Package oidc implements the relying party side of the OpenID Connect
+// authorization code flow with PKCE: provider discovery, the authorization
+// URL, the code exchange and ID token verification against the provider's
+// published keys.
+package oidc
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/touros-platform/api/pkg/jwk"
+)
+
+const (
+	// keyRefreshInterval limits how often the provider's keys are fetched
+	// again when a token names an unknown key.
+	keyRefreshInterval = time.Minute
+	// clockSkew is tolerated between the provider's clock and ours.
+	clockSkew = time.Minute
+	// maxResponseSize caps the discovery, key set and token responses.
+	maxResponseSize = 1 << 20
+)
+
+var signingMethods = []string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+// Config describes the client registration at one provider.
+type Config struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+// Provider is an OpenID provider. Its metadata is discovered on first use
+// and its signing keys are cached and refreshed when a token names a key
+// that is not known yet.
+type Provider struct {
+	config Config
+	client *http.Client
+
+	mu       sync.Mutex
+	metadata *metadata
+	keys     map[string]crypto.PublicKey
+	keysAt   time.Time
+}
+
+type metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// IDToken is a verified ID token.
+type IDToken struct {
+	Issuer  string
+	Subject string
+	Nonce   string
+	Claims  map[string]interface{}
+}
+
+// String returns a string claim, or "" if it is missing or not a string.
+func (t *IDToken) String(name string) string {
+	value, _ := t.Claims[name].(string)
+	return value
+}
+
+// Bool returns a boolean claim. Some providers send booleans as strings.
+func (t *IDToken) Bool(name string) bool {
+	switch value := t.Claims[name].(type) {
+	case bool:
+		return value
+	case string:
+		return value == "true"
+	}
+	return false
+}
+
+// Strings returns a claim holding a string or a list of strings.
+func (t *IDToken) Strings(name string) []string {
+	switch value := t.Claims[name].(type) {
+	case string:
+		return []string{value}
+	case []interface{}:
+		values := make([]string, 0, len(value))
+		for _, v := range value {
+			if s, ok := v.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+func NewProvider(cfg Config, client *http.Client) *Provider {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Provider{
+		config: cfg,
+		client: client,
+	}
+}
+
+// NewVerifier returns a random PKCE code verifier.
+func NewVerifier() (string, error) {
+	return RandomToken(32)
+}
+
+// RandomToken returns n random bytes, base64url encoded, for use as state,
+// nonce or code verifier.
+func RandomToken(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("oidc: failed to generate random token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func codeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// AuthCodeURL returns the URL to send the user to. The code challenge is
+// derived from verifier with S256.
+func (p *Provider) AuthCodeURL(state, nonce, verifier string) (string, error) {
+	meta, err := p.discover()
+	if err != nil {
+		return "", err
+	}
+
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.config.ClientID},
+		"redirect_uri":          {p.config.RedirectURL},
+		"scope":                 {strings.Join(p.config.Scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {codeChallenge(verifier)},
+		"code_challenge_method": {"S256"},
+	}
+
+	separator := "?"
+	if strings.Contains(meta.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return meta.AuthorizationEndpoint + separator + query.Encode(), nil
+}
+
+// Exchange redeems an authorization code and returns the verified ID token.
+// The caller checks the nonce.
+func (p *Provider) Exchange(code, verifier string) (*IDToken, error) {
+	meta, err := p.discover()
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.config.RedirectURL},
+		"code_verifier": {verifier},
+	}
+	if p.config.ClientSecret == "" {
+		form.Set("client_id", p.config.ClientID)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, meta.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.config.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.config.ClientID), url.QueryEscape(p.config.ClientSecret))
+	}
+
+	var body struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	status, err := p.do(req, &body)
+	if err != nil {
+		return nil, fmt.Errorf("oidc: token request failed: %w", err)
+	}
+	if body.Error != "" {
+		if body.ErrorDescription != "" {
+			return nil, fmt.Errorf("oidc: token request failed: %s: %s", body.Error, body.ErrorDescription)
+		}
+		return nil, fmt.Errorf("oidc: token request failed: %s", body.Error)
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("oidc: token request failed with status %d", status)
+	}
+	if body.IDToken == "" {
+		return nil, errors.New("oidc: token response has no id_token")
+	}
+
+	return p.Verify(body.IDToken)
+}
+
+// Verify checks an ID token's signature, issuer, audience and lifetime.
+func (p *Provider) Verify(raw string) (*IDToken, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(raw, claims, p.keyfunc,
+		jwt.WithValidMethods(signingMethods),
+		jwt.WithIssuer(p.config.Issuer),
+		jwt.WithAudience(p.config.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(clockSkew),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("oidc: invalid ID token: %w", err)
+	}
+
+	// With several audiences the token must have been issued to us.
+	audience, _ := claims.GetAudience()
+	if len(audience) > 1 {
+		if azp, _ := claims["azp"].(string); azp != p.config.ClientID {
+			return nil, errors.New("oidc: invalid ID token: authorized party mismatch")
+		}
+	}
+
+	token := &IDToken{Claims: claims}
+	token.Issuer = token.String("iss")
+	token.Subject = token.String("sub")
+	token.Nonce = token.String("nonce")
+	if token.Subject == "" {
+		return nil, errors.New("oidc: invalid ID token: missing subject")
+	}
+	return token, nil
+}
+
+func (p *Provider) keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	p.mu.Lock()
+	key, ok := lookupKey(p.keys, kid)
+	refresh := !ok && time.Since(p.keysAt) >= keyRefreshInterval
+	p.mu.Unlock()
+	if ok {
+		return key, nil
+	}
+	if !refresh {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	keys, err := p.fetchKeys()
+	if err != nil {
+		return nil, err
+	}
+	if key, ok := lookupKey(keys, kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// lookupKey finds the key named kid. A token without a kid is accepted
+// when the provider publishes a single key.
+func lookupKey(keys map[string]crypto.PublicKey, kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(keys) == 1 {
+		for _, key := range keys {
+			return key, true
+		}
+	}
+	key, ok := keys[kid]
+	return key, ok
+}
+
+func (p *Provider) fetchKeys() (map[string]crypto.PublicKey, error) {
+	meta, err := p.discover()
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	p.keysAt = time.Now()
+	p.mu.Unlock()
+
+	req, err := http.NewRequest(http.MethodGet, meta.JWKSURI, nil)
+	if err != nil {
+		return nil, err
+	}
+	data, status, err := p.read(req)
+	if err != nil {
+		return nil, fmt.Errorf("oidc: failed to fetch signing keys: %w", err)
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("oidc: failed to fetch signing keys: status %d", status)
+	}
+	keys, err := jwk.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	p.keys = keys
+	p.mu.Unlock()
+	return keys, nil
+}
+
+// discover fetches the provider metadata once. A failed discovery is tried
+// again on the next call.
+func (p *Provider) discover() (*metadata, error) {
+	p.mu.Lock()
+	meta := p.metadata
+	p.mu.Unlock()
+	if meta != nil {
+		return meta, nil
+	}
+
+	req, err := http.NewRequest(http.MethodGet, strings.TrimSuffix(p.config.Issuer, "/")+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, err
+	}
+	meta = &metadata{}
+	status, err := p.do(req, meta)
+	if err != nil {
+		return nil, fmt.Errorf("oidc: discovery failed: %w", err)
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("oidc: discovery failed: status %d", status)
+	}
+	if meta.Issuer != p.config.Issuer {
+		return nil, fmt.Errorf("oidc: discovery returned issuer %q, expected %q", meta.Issuer, p.config.Issuer)
+	}
+	if meta.AuthorizationEndpoint == "" || meta.TokenEndpoint == "" || meta.JWKSURI == "" {
+		return nil, errors.New("oidc: discovery document is incomplete")
+	}
+
+	p.mu.Lock()
+	p.metadata = meta
+	p.mu.Unlock()
+	return meta, nil
+}
+
+func (p *Provider) do(req *http.Request, v interface{}) (int, error) {
+	data, status, err := p.read(req)
+	if err != nil {
+		return 0, err
+	}
+	if err := json.Unmarshal(data, v); err != nil && status == http.StatusOK {
+		return status, fmt.Errorf("invalid response: %w", err)
+	}
+	return status, nil
+}
+
+func (p *Provider) read(req *http.Request) ([]byte, int, error) {
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, 0, err
+	}
+	return data, resp.StatusCode, nil
+}
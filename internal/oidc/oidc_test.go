@@ -0,0 +1,210 @@
+package oidc
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/touros-platform/api/pkg/jwk"
+)
+
+const (
+	testClientID     = "touros"
+	testClientSecret = "client-secret"
+	testRedirectURL  = "http://localhost:3000/auth/oidc/stub/callback"
+)
+
+// stubProvider is an in-process OpenID provider. It issues a code for the
+// last authorization request and checks the PKCE verifier when the code is
+// redeemed.
+type stubProvider struct {
+	t      *testing.T
+	server *httptest.Server
+	keyID  string
+	key    ed25519.PrivateKey
+
+	challenge string
+	nonce     string
+	audience  string
+}
+
+func newStubProvider(t *testing.T) *stubProvider {
+	t.Helper()
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &stubProvider{t: t, keyID: "stub-1", key: key, audience: testClientID}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 p.server.URL,
+			"authorization_endpoint": p.server.URL + "/authorize",
+			"token_endpoint":         p.server.URL + "/token",
+			"jwks_uri":               p.server.URL + "/keys",
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		key, err := jwk.NewKey(p.keyID, p.key.Public())
+		if err != nil {
+			t.Fatal(err)
+		}
+		json.NewEncoder(w).Encode(jwk.Set{Keys: []jwk.Key{key}})
+	})
+	mux.HandleFunc("/token", p.token)
+	p.server = httptest.NewServer(mux)
+	t.Cleanup(p.server.Close)
+	return p
+}
+
+func (p *stubProvider) token(w http.ResponseWriter, r *http.Request) {
+	id, secret, ok := r.BasicAuth()
+	if !ok || id != testClientID || secret != testClientSecret {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid_client"})
+		return
+	}
+	if r.FormValue("code") != "good-code" || r.FormValue("redirect_uri") != testRedirectURL {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+		return
+	}
+	if codeChallenge(r.FormValue("code_verifier")) != p.challenge {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant", "error_description": "PKCE verification failed"})
+		return
+	}
+
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, jwt.MapClaims{
+		"iss":            p.server.URL,
+		"sub":            "official-42",
+		"aud":            p.audience,
+		"exp":            now.Add(time.Minute).Unix(),
+		"iat":            now.Unix(),
+		"nonce":          p.nonce,
+		"email":          "official@tourism.gov.np",
+		"email_verified": true,
+		"roles":          []string{"staff", "checkpoint-officer"},
+	})
+	token.Header["kid"] = p.keyID
+	signed, err := token.SignedString(p.key)
+	if err != nil {
+		p.t.Fatal(err)
+	}
+	json.NewEncoder(w).Encode(map[string]string{"id_token": signed, "access_token": "unused", "token_type": "Bearer"})
+}
+
+// authorize follows the authorization URL the way a browser would, and
+// remembers the challenge and nonce it carried.
+func (p *stubProvider) authorize(authURL string) {
+	u, err := url.Parse(authURL)
+	if err != nil {
+		p.t.Fatal(err)
+	}
+	q := u.Query()
+	if q.Get("code_challenge_method") != "S256" || q.Get("client_id") != testClientID || q.Get("redirect_uri") != testRedirectURL {
+		p.t.Fatalf("unexpected authorization request: %s", authURL)
+	}
+	p.challenge = q.Get("code_challenge")
+	p.nonce = q.Get("nonce")
+}
+
+func newTestProvider(p *stubProvider) *Provider {
+	return NewProvider(Config{
+		Issuer:       p.server.URL,
+		ClientID:     testClientID,
+		ClientSecret: testClientSecret,
+		RedirectURL:  testRedirectURL,
+		Scopes:       []string{"openid", "email"},
+	}, p.server.Client())
+}
+
+func TestAuthorizationCodeFlow(t *testing.T) {
+	stub := newStubProvider(t)
+	provider := newTestProvider(stub)
+
+	verifier, err := NewVerifier()
+	if err != nil {
+		t.Fatal(err)
+	}
+	authURL, err := provider.AuthCodeURL("state-1", "nonce-1", verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(authURL, stub.server.URL+"/authorize?") {
+		t.Fatalf("authorization URL %q does not point at the provider", authURL)
+	}
+	stub.authorize(authURL)
+
+	token, err := provider.Exchange("good-code", verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.Subject != "official-42" || token.Nonce != "nonce-1" || token.Issuer != stub.server.URL {
+		t.Fatalf("unexpected token: %+v", token)
+	}
+	if !token.Bool("email_verified") || token.String("email") != "official@tourism.gov.np" {
+		t.Fatalf("unexpected email claims: %v", token.Claims)
+	}
+	if roles := token.Strings("roles"); len(roles) != 2 || roles[1] != "checkpoint-officer" {
+		t.Fatalf("unexpected roles: %v", roles)
+	}
+}
+
+func TestExchangeRejectsWrongVerifier(t *testing.T) {
+	stub := newStubProvider(t)
+	provider := newTestProvider(stub)
+
+	verifier, _ := NewVerifier()
+	authURL, err := provider.AuthCodeURL("state-1", "nonce-1", verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stub.authorize(authURL)
+
+	other, _ := NewVerifier()
+	if _, err := provider.Exchange("good-code", other); err == nil || !strings.Contains(err.Error(), "PKCE") {
+		t.Fatalf("expected a PKCE failure, got %v", err)
+	}
+}
+
+func TestExchangeRejectsTokenForAnotherClient(t *testing.T) {
+	stub := newStubProvider(t)
+	stub.audience = "someone-else"
+	provider := newTestProvider(stub)
+
+	verifier, _ := NewVerifier()
+	authURL, err := provider.AuthCodeURL("state-1", "nonce-1", verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stub.authorize(authURL)
+
+	if _, err := provider.Exchange("good-code", verifier); err == nil {
+		t.Fatal("expected a token for another audience to be rejected")
+	}
+}
+
+func TestDiscoveryRejectsIssuerMismatch(t *testing.T) {
+	stub := newStubProvider(t)
+	provider := NewProvider(Config{
+		Issuer:      stub.server.URL + "/",
+		ClientID:    testClientID,
+		RedirectURL: testRedirectURL,
+	}, stub.server.Client())
+
+	_, err := provider.AuthCodeURL("state", "nonce", "verifier")
+	if err == nil || !strings.Contains(err.Error(), "expected") {
+		t.Fatalf("expected an issuer mismatch, got %v", err)
+	}
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"github.com/google/uuid"
+	"github.com/touros-platform/api/internal/domain"
+	"gorm.io/gorm"
+)
+
+type AgencyDocumentRepository interface {
+	Create(document *domain.AgencyDocument) error
+	GetByID(agencyID, id uuid.UUID) (*domain.AgencyDocument, error)
+	Update(document *domain.AgencyDocument) error
+	ListByAgency(agencyID uuid.UUID, includeSuperseded bool) ([]domain.AgencyDocument, error)
+}
+
+type agencyDocumentRepository struct {
+	db *gorm.DB
+}
+
+func NewAgencyDocumentRepository(db *gorm.DB) AgencyDocumentRepository {
+	return &agencyDocumentRepository{db: db}
+}
+
+// Create stores the document and supersedes the agency's current document
+// of the same type.
+func (r *agencyDocumentRepository) Create(document *domain.AgencyDocument) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&domain.AgencyDocument{}).
+			Where("agency_id = ? AND type = ? AND status <> ?", document.AgencyID, document.Type, domain.AgencyDocumentSuperseded).
+			Update("status", domain.AgencyDocumentSuperseded).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(document).Error
+	})
+}
+
+func (r *agencyDocumentRepository) GetByID(agencyID, id uuid.UUID) (*domain.AgencyDocument, error) {
+	var document domain.AgencyDocument
+	err := r.db.Where("id = ? AND agency_id = ?", id, agencyID).First(&document).Error
+	if err != nil {
+		return nil, err
+	}
+	return &document, nil
+}
+
+func (r *agencyDocumentRepository) Update(document *domain.AgencyDocument) error {
+	return r.db.Save(document).Error
+}
+
+// ListByAgency returns the agency's current documents, newest first, and
+// with includeSuperseded the documents they replaced.
+func (r *agencyDocumentRepository) ListByAgency(agencyID uuid.UUID, includeSuperseded bool) ([]domain.AgencyDocument, error) {
+	var documents []domain.AgencyDocument
+	query := r.db.Where("agency_id = ?", agencyID)
+	if !includeSuperseded {
+		query = query.Where("status <> ?", domain.AgencyDocumentSuperseded)
+	}
+	err := query.Order("created_at DESC").Find(&documents).Error
+	return documents, err
+}
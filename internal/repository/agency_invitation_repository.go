@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/touros-platform/api/internal/domain"
+	"gorm.io/gorm"
+)
+
+type AgencyInvitationRepository interface {
+	Create(invitation *domain.AgencyInvitation) error
+	GetByID(agencyID, id uuid.UUID) (*domain.AgencyInvitation, error)
+	GetByTokenHash(tokenHash string) (*domain.AgencyInvitation, error)
+	ListPending(agencyID uuid.UUID) ([]domain.AgencyInvitation, error)
+	Accept(id uuid.UUID, user *domain.User) error
+	Revoke(id uuid.UUID) error
+}
+
+type agencyInvitationRepository struct {
+	db *gorm.DB
+}
+
+func NewAgencyInvitationRepository(db *gorm.DB) AgencyInvitationRepository {
+	return &agencyInvitationRepository{db: db}
+}
+
+// Create stores the invitation and revokes any earlier pending invitation
+// of the same address to the agency, so only the latest link works.
+func (r *agencyInvitationRepository) Create(invitation *domain.AgencyInvitation) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&domain.AgencyInvitation{}).
+			Where("agency_id = ? AND email = ? AND accepted_at IS NULL AND revoked_at IS NULL", invitation.AgencyID, invitation.Email).
+			Update("revoked_at", time.Now()).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(invitation).Error
+	})
+}
+
+func (r *agencyInvitationRepository) GetByID(agencyID, id uuid.UUID) (*domain.AgencyInvitation, error) {
+	var invitation domain.AgencyInvitation
+	err := r.db.Where("id = ? AND agency_id = ?", id, agencyID).First(&invitation).Error
+	if err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+func (r *agencyInvitationRepository) GetByTokenHash(tokenHash string) (*domain.AgencyInvitation, error) {
+	var invitation domain.AgencyInvitation
+	err := r.db.Where("token_hash = ?", tokenHash).First(&invitation).Error
+	if err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+// ListPending returns the invitations that have been neither accepted nor
+// revoked and have not expired, newest first.
+func (r *agencyInvitationRepository) ListPending(agencyID uuid.UUID) ([]domain.AgencyInvitation, error) {
+	var invitations []domain.AgencyInvitation
+	err := r.db.Where("agency_id = ? AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > ?", agencyID, time.Now()).
+		Order("created_at DESC").
+		Find(&invitations).Error
+	return invitations, err
+}
+
+// Accept consumes a pending invitation and creates the invited user in one
+// transaction. It returns gorm.ErrRecordNotFound when the invitation was
+// accepted or revoked meanwhile.
+func (r *agencyInvitationRepository) Accept(id uuid.UUID, user *domain.User) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if user.ID == uuid.Nil {
+			user.ID = uuid.New()
+		}
+		result := tx.Model(&domain.AgencyInvitation{}).
+			Where("id = ? AND accepted_at IS NULL AND revoked_at IS NULL", id).
+			Updates(map[string]interface{}{"accepted_at": time.Now(), "user_id": user.ID})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected != 1 {
+			return gorm.ErrRecordNotFound
+		}
+		return tx.Create(user).Error
+	})
+}
+
+func (r *agencyInvitationRepository) Revoke(id uuid.UUID) error {
+	return r.db.Model(&domain.AgencyInvitation{}).
+		Where("id = ? AND accepted_at IS NULL AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now()).Error
+}
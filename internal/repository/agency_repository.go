@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 	"github.com/touros-platform/api/internal/domain"
 	"gorm.io/gorm"
@@ -13,6 +15,7 @@ type AgencyRepository interface {
 	Update(agency *domain.Agency) error
 	Delete(id uuid.UUID) error
 	List(limit, offset int, status *domain.AgencyStatus) ([]domain.Agency, int64, error)
+	ListLicenseExpiringBefore(cutoff time.Time) ([]domain.Agency, error)
 }
 
 type agencyRepository struct {
@@ -69,3 +72,15 @@ func (r *agencyRepository) List(limit, offset int, status *domain.AgencyStatus)
 	err := query.Limit(limit).Offset(offset).Find(&agencies).Error
 	return agencies, total, err
 }
+
+// ListLicenseExpiringBefore returns pending and verified agencies whose
+// license expires before cutoff, soonest first. Expired licenses are
+// included.
+func (r *agencyRepository) ListLicenseExpiringBefore(cutoff time.Time) ([]domain.Agency, error) {
+	var agencies []domain.Agency
+	err := r.db.Where("license_expiry IS NOT NULL AND license_expiry < ?", cutoff).
+		Where("status IN ?", []domain.AgencyStatus{domain.AgencyStatusPending, domain.AgencyStatusVerified}).
+		Order("license_expiry").
+		Find(&agencies).Error
+	return agencies, err
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/touros-platform/api/internal/domain"
+	"gorm.io/gorm"
+)
+
+type CheckpointRepository interface {
+	Create(checkpoint *domain.Checkpoint) error
+	GetByID(id uuid.UUID) (*domain.Checkpoint, error)
+	GetByCode(code string) (*domain.Checkpoint, error)
+	Update(checkpoint *domain.Checkpoint) error
+	List(limit, offset int, activeOnly bool) ([]domain.Checkpoint, int64, error)
+	CreateScan(scan *domain.PermitScan) error
+	ListScansByCheckpoint(checkpointID uuid.UUID, limit, offset int, since *time.Time) ([]domain.PermitScan, int64, error)
+	ListScansByPermit(permitID uuid.UUID) ([]domain.PermitScan, error)
+}
+
+type checkpointRepository struct {
+	db *gorm.DB
+}
+
+func NewCheckpointRepository(db *gorm.DB) CheckpointRepository {
+	return &checkpointRepository{db: db}
+}
+
+func (r *checkpointRepository) Create(checkpoint *domain.Checkpoint) error {
+	return r.db.Omit("RouteCheckpoint").Create(checkpoint).Error
+}
+
+func (r *checkpointRepository) GetByID(id uuid.UUID) (*domain.Checkpoint, error) {
+	var checkpoint domain.Checkpoint
+	err := r.db.Preload("RouteCheckpoint").Where("id = ?", id).First(&checkpoint).Error
+	if err != nil {
+		return nil, err
+	}
+	return &checkpoint, nil
+}
+
+func (r *checkpointRepository) GetByCode(code string) (*domain.Checkpoint, error) {
+	var checkpoint domain.Checkpoint
+	err := r.db.Preload("RouteCheckpoint").Where("code = ?", code).First(&checkpoint).Error
+	if err != nil {
+		return nil, err
+	}
+	return &checkpoint, nil
+}
+
+func (r *checkpointRepository) Update(checkpoint *domain.Checkpoint) error {
+	return r.db.Omit("RouteCheckpoint").Save(checkpoint).Error
+}
+
+func (r *checkpointRepository) List(limit, offset int, activeOnly bool) ([]domain.Checkpoint, int64, error) {
+	var checkpoints []domain.Checkpoint
+	var total int64
+
+	query := r.db.Model(&domain.Checkpoint{}).Preload("RouteCheckpoint")
+	if activeOnly {
+		query = query.Where("is_active = ?", true)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Limit(limit).Offset(offset).Order("name").Find(&checkpoints).Error
+	return checkpoints, total, err
+}
+
+// CreateScan stores the scan and bumps the checkpoint's last scan time.
+func (r *checkpointRepository) CreateScan(scan *domain.PermitScan) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Checkpoint").Create(scan).Error; err != nil {
+			return err
+		}
+		return tx.Model(&domain.Checkpoint{}).Where("id = ?", scan.CheckpointID).
+			Update("last_scan_at", scan.ScannedAt).Error
+	})
+}
+
+func (r *checkpointRepository) ListScansByCheckpoint(checkpointID uuid.UUID, limit, offset int, since *time.Time) ([]domain.PermitScan, int64, error) {
+	var scans []domain.PermitScan
+	var total int64
+
+	query := r.db.Model(&domain.PermitScan{}).Where("checkpoint_id = ?", checkpointID)
+	if since != nil {
+		query = query.Where("scanned_at >= ?", *since)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Limit(limit).Offset(offset).Order("scanned_at DESC").Find(&scans).Error
+	return scans, total, err
+}
+
+// ListScansByPermit returns the permit's scans in the order they happened.
+func (r *checkpointRepository) ListScansByPermit(permitID uuid.UUID) ([]domain.PermitScan, error) {
+	var scans []domain.PermitScan
+	err := r.db.Preload("Checkpoint.RouteCheckpoint").
+		Where("permit_id = ?", permitID).
+		Order("scanned_at").
+		Find(&scans).Error
+	return scans, err
+}
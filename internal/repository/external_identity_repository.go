@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/touros-platform/api/internal/domain"
+	"gorm.io/gorm"
+)
+
+type ExternalIdentityRepository interface {
+	Create(identity *domain.ExternalIdentity) error
+	GetBySubject(issuer, subject string) (*domain.ExternalIdentity, error)
+	Touch(id uuid.UUID, email string, at time.Time) error
+	CreateLoginState(state *domain.OIDCLoginState) error
+	ConsumeLoginState(stateHash string) (*domain.OIDCLoginState, error)
+	DeleteExpiredLoginStates(before time.Time) (int64, error)
+}
+
+type externalIdentityRepository struct {
+	db *gorm.DB
+}
+
+func NewExternalIdentityRepository(db *gorm.DB) ExternalIdentityRepository {
+	return &externalIdentityRepository{db: db}
+}
+
+func (r *externalIdentityRepository) Create(identity *domain.ExternalIdentity) error {
+	return r.db.Create(identity).Error
+}
+
+func (r *externalIdentityRepository) GetBySubject(issuer, subject string) (*domain.ExternalIdentity, error) {
+	var identity domain.ExternalIdentity
+	err := r.db.Where("issuer = ? AND subject = ?", issuer, subject).First(&identity).Error
+	if err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+// Touch records a login and the email address the provider reported for it.
+func (r *externalIdentityRepository) Touch(id uuid.UUID, email string, at time.Time) error {
+	return r.db.Model(&domain.ExternalIdentity{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"email": email, "last_login_at": at}).Error
+}
+
+// CreateLoginState stores a new login state and clears out expired ones.
+func (r *externalIdentityRepository) CreateLoginState(state *domain.OIDCLoginState) error {
+	if err := r.db.Where("expires_at < ?", time.Now()).Delete(&domain.OIDCLoginState{}).Error; err != nil {
+		return err
+	}
+	return r.db.Create(state).Error
+}
+
+// ConsumeLoginState deletes the state and returns it, so that a state can
+// only complete one login even when two callbacks race.
+func (r *externalIdentityRepository) ConsumeLoginState(stateHash string) (*domain.OIDCLoginState, error) {
+	var state domain.OIDCLoginState
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("state_hash = ?", stateHash).First(&state).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&domain.OIDCLoginState{}, "id = ?", state.ID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected != 1 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// DeleteExpiredLoginStates deletes the states of logins that were abandoned
+// at the provider and expired before the given time.
+func (r *externalIdentityRepository) DeleteExpiredLoginStates(before time.Time) (int64, error) {
+	result := r.db.Where("expires_at < ?", before).Delete(&domain.OIDCLoginState{})
+	return result.RowsAffected, result.Error
+}
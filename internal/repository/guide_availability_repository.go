@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/touros-platform/api/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GuideUnavailableError is returned when a guide would be booked while
+// already leading another active permit or during a blocked period.
+type GuideUnavailableError struct {
+	Permit        *domain.Permit
+	BlockedPeriod *domain.GuideBlockedPeriod
+}
+
+func (e *GuideUnavailableError) Error() string {
+	if e.Permit != nil {
+		return fmt.Sprintf("guide is already assigned to permit %s from %s to %s",
+			e.Permit.PermitNumber, e.Permit.StartDate.Format(dateLayout), e.Permit.EndDate.Format(dateLayout))
+	}
+	return fmt.Sprintf("guide is unavailable (%s) from %s to %s",
+		e.BlockedPeriod.Reason, e.BlockedPeriod.StartDate.Format(dateLayout), e.BlockedPeriod.EndDate.Format(dateLayout))
+}
+
+type GuideAvailabilityRepository interface {
+	CreateBlockedPeriod(period *domain.GuideBlockedPeriod) error
+	GetBlockedPeriod(guideID, id uuid.UUID) (*domain.GuideBlockedPeriod, error)
+	DeleteBlockedPeriod(id uuid.UUID) error
+	ListBlockedPeriods(guideID uuid.UUID, from, to time.Time) ([]domain.GuideBlockedPeriod, error)
+	ListBookings(guideID uuid.UUID, from, to time.Time) ([]domain.Permit, error)
+}
+
+type guideAvailabilityRepository struct {
+	db *gorm.DB
+}
+
+func NewGuideAvailabilityRepository(db *gorm.DB) GuideAvailabilityRepository {
+	return &guideAvailabilityRepository{db: db}
+}
+
+// CreateBlockedPeriod stores the period unless the guide leads an active
+// permit during it, failing with GuideUnavailableError.
+func (r *guideAvailabilityRepository) CreateBlockedPeriod(period *domain.GuideBlockedPeriod) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockGuide(tx, period.GuideID); err != nil {
+			return err
+		}
+
+		var permit domain.Permit
+		err := permitsDuring(bookedPermits(tx, period.GuideID), period.StartDate, period.EndDate).
+			Order("start_date").
+			First(&permit).Error
+		if err == nil {
+			return &GuideUnavailableError{Permit: &permit}
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		return tx.Create(period).Error
+	})
+}
+
+func (r *guideAvailabilityRepository) GetBlockedPeriod(guideID, id uuid.UUID) (*domain.GuideBlockedPeriod, error) {
+	var period domain.GuideBlockedPeriod
+	err := r.db.Where("id = ? AND guide_id = ?", id, guideID).First(&period).Error
+	if err != nil {
+		return nil, err
+	}
+	return &period, nil
+}
+
+func (r *guideAvailabilityRepository) DeleteBlockedPeriod(id uuid.UUID) error {
+	return r.db.Delete(&domain.GuideBlockedPeriod{}, id).Error
+}
+
+// ListBlockedPeriods returns the guide's blocked periods that overlap the
+// days from through to.
+func (r *guideAvailabilityRepository) ListBlockedPeriods(guideID uuid.UUID, from, to time.Time) ([]domain.GuideBlockedPeriod, error) {
+	var periods []domain.GuideBlockedPeriod
+	err := blockedDuring(r.db.Where("guide_id = ?", guideID), from, to).
+		Order("start_date").
+		Find(&periods).Error
+	return periods, err
+}
+
+// ListBookings returns the guide's active permits that overlap the days
+// from through to.
+func (r *guideAvailabilityRepository) ListBookings(guideID uuid.UUID, from, to time.Time) ([]domain.Permit, error) {
+	var permits []domain.Permit
+	err := permitsDuring(bookedPermits(r.db, guideID), from, to).
+		Preload("Route").
+		Order("start_date").
+		Find(&permits).Error
+	return permits, err
+}
+
+// lockGuide locks the guide row for the rest of the transaction, so that
+// bookings and blocked periods for one guide are checked one at a time.
+func lockGuide(tx *gorm.DB, guideID uuid.UUID) error {
+	var guide domain.Guide
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").
+		Where("id = ?", guideID).
+		First(&guide).Error
+}
+
+// bookedPermits selects the guide's active permits.
+func bookedPermits(db *gorm.DB, guideID uuid.UUID) *gorm.DB {
+	return db.Model(&domain.Permit{}).Where("guide_id = ? AND status = ?", guideID, domain.PermitStatusActive)
+}
+
+// Guides are booked by whole days. A permit occupies every day from the
+// one it starts on through the one it ends on, and a blocked period every
+// day from its start date through its end date, so a permit ending on a day
+// overlaps another permit or a blocked period starting that day. Every
+// availability check goes through permitsDuring and blockedDuring.
+const (
+	// permitsDuringDays matches permits occupying any day of a dayRange.
+	permitsDuringDays = "permits.start_date < ? AND permits.end_date >= ?"
+	// blockedDuringDays matches blocked periods covering any of the days
+	// from through to.
+	blockedDuringDays = "guide_blocked_periods.start_date <= ? AND guide_blocked_periods.end_date >= ?"
+)
+
+// permitsDuring narrows a permit query to permits that overlap the days
+// from through to.
+func permitsDuring(query *gorm.DB, from, to time.Time) *gorm.DB {
+	start, end := dayRange(from, to)
+	return query.Where(permitsDuringDays, end, start)
+}
+
+// blockedDuring narrows a blocked period query to periods that overlap the
+// days from through to.
+func blockedDuring(query *gorm.DB, from, to time.Time) *gorm.DB {
+	return query.Where(blockedDuringDays, to.Format(dateLayout), from.Format(dateLayout))
+}
+
+// dayRange returns the start of from's day and the start of the day after
+// to, for matching permit times against whole days.
+func dayRange(from, to time.Time) (time.Time, time.Time) {
+	fy, fm, fd := from.Date()
+	ty, tm, td := to.Date()
+	return time.Date(fy, fm, fd, 0, 0, 0, 0, time.UTC), time.Date(ty, tm, td+1, 0, 0, 0, 0, time.UTC)
+}
+
+// checkGuideAvailable fails with GuideUnavailableError when the guide leads
+// another active permit or has a blocked period on any day of the permit.
+// The guide must be locked.
+func checkGuideAvailable(tx *gorm.DB, permit *domain.Permit) error {
+	var booked domain.Permit
+	err := permitsDuring(bookedPermits(tx, permit.GuideID).Where("id <> ?", permit.ID), permit.StartDate, permit.EndDate).
+		Order("start_date").
+		First(&booked).Error
+	if err == nil {
+		return &GuideUnavailableError{Permit: &booked}
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	var period domain.GuideBlockedPeriod
+	err = blockedDuring(tx.Where("guide_id = ?", permit.GuideID), permit.StartDate, permit.EndDate).
+		Order("start_date").
+		First(&period).Error
+	if err == nil {
+		return &GuideUnavailableError{BlockedPeriod: &period}
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	return nil
+}
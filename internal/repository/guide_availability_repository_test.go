@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/touros-platform/api/internal/domain"
+	"gorm.io/gorm"
+)
+
+func day(month time.Month, d, hour int) time.Time {
+	return time.Date(2026, month, d, hour, 0, 0, 0, time.UTC)
+}
+
+func mustBeUnavailable(t *testing.T, err error, what string) *GuideUnavailableError {
+	t.Helper()
+
+	var unavailable *GuideUnavailableError
+	if !errors.As(err, &unavailable) {
+		t.Fatalf("%s: got %v, want GuideUnavailableError", what, err)
+	}
+	return unavailable
+}
+
+// A guide is booked by whole days, so a permit ending on a day and one
+// starting that day overlap however far apart their times are. Booking,
+// blocking and the search for free guides apply the same rule.
+func TestGuideBookingsOverlapOnSharedDay(t *testing.T) {
+	db := testDB(t)
+	f := fixtures{t: t, db: db}
+	permits := NewPermitRepository(db)
+	availability := NewGuideAvailabilityRepository(db)
+	guides := NewGuideRepository(db)
+	route := f.route("Annapurna")
+	guide := f.guide()
+
+	issue := func(start time.Time) (*domain.Permit, error) {
+		permit := f.permit(route, start, 1)
+		permit.GuideID = guide.ID
+		return permit, permits.Create(permit)
+	}
+
+	// Booked from the morning of April 1 to the morning of April 11.
+	booked, err := issue(day(time.April, 1, 6))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = issue(day(time.April, 11, 18))
+	if unavailable := mustBeUnavailable(t, err, "permit starting on the day the booking ends"); unavailable.Permit.ID != booked.ID {
+		t.Fatalf("conflict reported with permit %s, want %s", unavailable.Permit.ID, booked.ID)
+	}
+	_, err = issue(day(time.March, 22, 18))
+	mustBeUnavailable(t, err, "permit ending on the day the booking starts")
+	if _, err := issue(day(time.April, 12, 0)); err != nil {
+		t.Fatalf("permit starting the day after the booking ends: %v", err)
+	}
+
+	bookings, err := availability.ListBookings(guide.ID, day(time.April, 11, 0), day(time.April, 11, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bookings) != 1 || bookings[0].ID != booked.ID {
+		t.Fatalf("bookings on the booking's last day: got %d, want the booking", len(bookings))
+	}
+
+	period := &domain.GuideBlockedPeriod{
+		GuideID:   guide.ID,
+		Reason:    domain.BlockedPeriodLeave,
+		StartDate: day(time.April, 11, 0),
+		EndDate:   day(time.April, 11, 0),
+		CreatedBy: uuid.New(),
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		return NewGuideAvailabilityRepository(tx).CreateBlockedPeriod(period)
+	})
+	mustBeUnavailable(t, err, "blocked period on the booking's last day")
+
+	period.StartDate, period.EndDate = day(time.March, 31, 0), day(time.March, 31, 0)
+	if err := availability.CreateBlockedPeriod(period); err != nil {
+		t.Fatalf("blocked period the day before the booking: %v", err)
+	}
+	_, err = issue(day(time.March, 21, 6))
+	if unavailable := mustBeUnavailable(t, err, "permit ending on a blocked day"); unavailable.BlockedPeriod == nil {
+		t.Fatalf("conflict reported with permit %s, want the blocked period", unavailable.Permit.PermitNumber)
+	}
+
+	for _, tt := range []struct {
+		day  time.Time
+		free bool
+	}{
+		{day(time.March, 30, 0), true},
+		{day(time.March, 31, 0), false},
+		{day(time.April, 11, 0), false},
+		{day(time.April, 23, 0), true},
+	} {
+		from, to := tt.day, tt.day
+		found, _, err := guides.List(100, 0, Scope{}, GuideFilter{AvailableFrom: &from, AvailableTo: &to})
+		if err != nil {
+			t.Fatal(err)
+		}
+		free := false
+		for _, g := range found {
+			free = free || g.ID == guide.ID
+		}
+		if free != tt.free {
+			t.Errorf("guide free on %s = %t, want %t", tt.day.Format(dateLayout), free, tt.free)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"github.com/google/uuid"
+	"github.com/touros-platform/api/internal/domain"
+	"gorm.io/gorm"
+)
+
+type GuideCertificationRepository interface {
+	Create(certification *domain.GuideCertification) error
+	GetByID(guideID, id uuid.UUID) (*domain.GuideCertification, error)
+	Update(certification *domain.GuideCertification) error
+	Delete(id uuid.UUID) error
+	ListByGuide(guideID uuid.UUID) ([]domain.GuideCertification, error)
+}
+
+type guideCertificationRepository struct {
+	db *gorm.DB
+}
+
+func NewGuideCertificationRepository(db *gorm.DB) GuideCertificationRepository {
+	return &guideCertificationRepository{db: db}
+}
+
+func (r *guideCertificationRepository) Create(certification *domain.GuideCertification) error {
+	return r.db.Create(certification).Error
+}
+
+func (r *guideCertificationRepository) GetByID(guideID, id uuid.UUID) (*domain.GuideCertification, error) {
+	var certification domain.GuideCertification
+	err := r.db.Where("id = ? AND guide_id = ?", id, guideID).First(&certification).Error
+	if err != nil {
+		return nil, err
+	}
+	return &certification, nil
+}
+
+func (r *guideCertificationRepository) Update(certification *domain.GuideCertification) error {
+	return r.db.Save(certification).Error
+}
+
+func (r *guideCertificationRepository) Delete(id uuid.UUID) error {
+	return r.db.Delete(&domain.GuideCertification{}, id).Error
+}
+
+// ListByGuide returns the guide's certifications by type, latest expiry
+// first.
+func (r *guideCertificationRepository) ListByGuide(guideID uuid.UUID) ([]domain.GuideCertification, error) {
+	var certifications []domain.GuideCertification
+	err := r.db.Where("guide_id = ?", guideID).
+		Order("type").Order("expiry_date DESC NULLS FIRST").Order("created_at DESC").
+		Find(&certifications).Error
+	return certifications, err
+}
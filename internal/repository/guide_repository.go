@@ -8,15 +8,34 @@ import (
 	"gorm.io/gorm"
 )
 
+// GuideFilter narrows a guide search. A guide must speak every language in
+// Languages and hold every certification in Certifications, approved and
+// unexpired. With AvailableFrom and AvailableTo set, only guides without an
+// active permit or blocked period on any of those days match.
+type GuideFilter struct {
+	Status         *domain.GuideStatus
+	AgencyID       *uuid.UUID
+	Languages      []string
+	Region         string
+	Certifications []domain.CertificationType
+	MinAltitude    int
+	AvailableFrom  *time.Time
+	AvailableTo    *time.Time
+}
+
 type GuideRepository interface {
 	Create(guide *domain.Guide) error
 	GetByID(id uuid.UUID) (*domain.Guide, error)
 	GetByUserID(userID uuid.UUID) (*domain.Guide, error)
 	GetByLicenseNumber(licenseNum string) (*domain.Guide, error)
 	Update(guide *domain.Guide) error
+	ReplaceLanguages(guideID uuid.UUID, languages []domain.GuideLanguage) error
+	ReplaceRegions(guideID uuid.UUID, regions []domain.GuideRegion) error
 	Delete(id uuid.UUID) error
-	List(limit, offset int, status *domain.GuideStatus, agencyID *uuid.UUID) ([]domain.Guide, int64, error)
+	List(limit, offset int, scope Scope, filter GuideFilter) ([]domain.Guide, int64, error)
 	UpdateLastCheckIn(guideID uuid.UUID) error
+	ListOnTrekWithoutCheckInSince(cutoff time.Time) ([]domain.Guide, error)
+	ListLicenseExpiringBefore(cutoff time.Time) ([]domain.Guide, error)
 }
 
 type guideRepository struct {
@@ -27,13 +46,17 @@ func NewGuideRepository(db *gorm.DB) GuideRepository {
 	return &guideRepository{db: db}
 }
 
+func preloadGuide(db *gorm.DB) *gorm.DB {
+	return db.Preload("User").Preload("Agency").Preload("Languages").Preload("Regions")
+}
+
 func (r *guideRepository) Create(guide *domain.Guide) error {
 	return r.db.Create(guide).Error
 }
 
 func (r *guideRepository) GetByID(id uuid.UUID) (*domain.Guide, error) {
 	var guide domain.Guide
-	err := r.db.Preload("User").Preload("Agency").Where("id = ?", id).First(&guide).Error
+	err := preloadGuide(r.db).Where("id = ?", id).First(&guide).Error
 	if err != nil {
 		return nil, err
 	}
@@ -42,7 +65,7 @@ func (r *guideRepository) GetByID(id uuid.UUID) (*domain.Guide, error) {
 
 func (r *guideRepository) GetByUserID(userID uuid.UUID) (*domain.Guide, error) {
 	var guide domain.Guide
-	err := r.db.Preload("User").Preload("Agency").Where("user_id = ?", userID).First(&guide).Error
+	err := preloadGuide(r.db).Where("user_id = ?", userID).First(&guide).Error
 	if err != nil {
 		return nil, err
 	}
@@ -51,7 +74,7 @@ func (r *guideRepository) GetByUserID(userID uuid.UUID) (*domain.Guide, error) {
 
 func (r *guideRepository) GetByLicenseNumber(licenseNum string) (*domain.Guide, error) {
 	var guide domain.Guide
-	err := r.db.Preload("User").Preload("Agency").Where("license_number = ?", licenseNum).First(&guide).Error
+	err := preloadGuide(r.db).Where("license_number = ?", licenseNum).First(&guide).Error
 	if err != nil {
 		return nil, err
 	}
@@ -59,23 +82,84 @@ func (r *guideRepository) GetByLicenseNumber(licenseNum string) (*domain.Guide,
 }
 
 func (r *guideRepository) Update(guide *domain.Guide) error {
-	return r.db.Save(guide).Error
+	return r.db.Omit("Languages", "Regions").Save(guide).Error
+}
+
+// ReplaceLanguages swaps the guide's languages for the given list in a
+// single transaction.
+func (r *guideRepository) ReplaceLanguages(guideID uuid.UUID, languages []domain.GuideLanguage) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("guide_id = ?", guideID).Delete(&domain.GuideLanguage{}).Error; err != nil {
+			return err
+		}
+		if len(languages) == 0 {
+			return nil
+		}
+		for i := range languages {
+			languages[i].GuideID = guideID
+		}
+		return tx.Create(&languages).Error
+	})
+}
+
+// ReplaceRegions swaps the guide's regions for the given list in a single
+// transaction.
+func (r *guideRepository) ReplaceRegions(guideID uuid.UUID, regions []domain.GuideRegion) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("guide_id = ?", guideID).Delete(&domain.GuideRegion{}).Error; err != nil {
+			return err
+		}
+		if len(regions) == 0 {
+			return nil
+		}
+		for i := range regions {
+			regions[i].GuideID = guideID
+		}
+		return tx.Create(&regions).Error
+	})
 }
 
 func (r *guideRepository) Delete(id uuid.UUID) error {
 	return r.db.Delete(&domain.Guide{}, id).Error
 }
 
-func (r *guideRepository) List(limit, offset int, status *domain.GuideStatus, agencyID *uuid.UUID) ([]domain.Guide, int64, error) {
+func (r *guideRepository) List(limit, offset int, scope Scope, filter GuideFilter) ([]domain.Guide, int64, error) {
 	var guides []domain.Guide
 	var total int64
 
-	query := r.db.Model(&domain.Guide{}).Preload("User").Preload("Agency")
-	if status != nil {
-		query = query.Where("status = ?", *status)
+	query := preloadGuide(r.db.Model(&domain.Guide{}))
+	if scope.AgencyID != nil {
+		query = query.Where("guides.agency_id = ?", *scope.AgencyID)
+	}
+	if scope.GuideID != nil {
+		query = query.Where("guides.id = ?", *scope.GuideID)
+	}
+	if filter.Status != nil {
+		query = query.Where("status = ?", *filter.Status)
+	}
+	if filter.AgencyID != nil {
+		query = query.Where("agency_id = ?", *filter.AgencyID)
 	}
-	if agencyID != nil {
-		query = query.Where("agency_id = ?", *agencyID)
+	for _, language := range filter.Languages {
+		query = query.Where("EXISTS (SELECT 1 FROM guide_languages WHERE guide_languages.guide_id = guides.id AND guide_languages.language = ?)", language)
+	}
+	if filter.Region != "" {
+		query = query.Where("EXISTS (SELECT 1 FROM guide_regions WHERE guide_regions.guide_id = guides.id AND lower(guide_regions.region) = lower(?))", filter.Region)
+	}
+	for _, certification := range filter.Certifications {
+		query = query.Where("EXISTS (SELECT 1 FROM guide_certifications WHERE guide_certifications.guide_id = guides.id AND guide_certifications.type = ? AND guide_certifications.status = ? AND (guide_certifications.expiry_date IS NULL OR guide_certifications.expiry_date > CURRENT_DATE))",
+			certification, domain.CertificationApproved)
+	}
+	if filter.MinAltitude > 0 {
+		query = query.Where("max_altitude_meters >= ?", filter.MinAltitude)
+	}
+	if filter.AvailableFrom != nil && filter.AvailableTo != nil {
+		booked := r.db.Model(&domain.Permit{}).Select("1").
+			Where("permits.guide_id = guides.id AND permits.status = ?", domain.PermitStatusActive)
+		query = query.Where("NOT EXISTS (?)", permitsDuring(booked, *filter.AvailableFrom, *filter.AvailableTo))
+		blocked := r.db.Model(&domain.GuideBlockedPeriod{}).Select("1").
+			Where("guide_blocked_periods.guide_id = guides.id")
+		query = query.Where("NOT EXISTS (?)", blockedDuring(blocked, *filter.AvailableFrom, *filter.AvailableTo))
 	}
 
 	if err := query.Count(&total).Error; err != nil {
@@ -91,3 +175,28 @@ func (r *guideRepository) UpdateLastCheckIn(guideID uuid.UUID) error {
 	return r.db.Model(&domain.Guide{}).Where("id = ?", guideID).Update("last_check_in", now).Error
 }
 
+// ListOnTrekWithoutCheckInSince returns guides that currently hold an active
+// permit and have not checked in since cutoff (or have never checked in).
+func (r *guideRepository) ListOnTrekWithoutCheckInSince(cutoff time.Time) ([]domain.Guide, error) {
+	var guides []domain.Guide
+	now := time.Now()
+	err := r.db.Preload("User").Preload("Agency").
+		Where("last_check_in IS NULL OR last_check_in <= ?", cutoff).
+		Where("EXISTS (SELECT 1 FROM permits WHERE permits.guide_id = guides.id AND permits.status = ? AND permits.start_date <= ? AND permits.end_date >= ? AND permits.deleted_at IS NULL)",
+			domain.PermitStatusActive, now, now).
+		Find(&guides).Error
+	return guides, err
+}
+
+// ListLicenseExpiringBefore returns pending and verified guides whose
+// license expires before cutoff, soonest first. Expired licenses are
+// included.
+func (r *guideRepository) ListLicenseExpiringBefore(cutoff time.Time) ([]domain.Guide, error) {
+	var guides []domain.Guide
+	err := r.db.Preload("User").Preload("Agency").
+		Where("license_expiry IS NOT NULL AND license_expiry < ?", cutoff).
+		Where("status IN ?", []domain.GuideStatus{domain.GuideStatusPending, domain.GuideStatusVerified}).
+		Order("license_expiry").
+		Find(&guides).Error
+	return guides, err
+}
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"github.com/google/uuid"
+	"github.com/touros-platform/api/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LicenseReminderRepository interface {
+	Claim(reminder *domain.LicenseReminder) (bool, error)
+	Delete(id uuid.UUID) error
+}
+
+type licenseReminderRepository struct {
+	db *gorm.DB
+}
+
+func NewLicenseReminderRepository(db *gorm.DB) LicenseReminderRepository {
+	return &licenseReminderRepository{db: db}
+}
+
+// Claim records the reminder unless it has already been recorded, and
+// reports whether this call recorded it. Only the caller that claims a
+// reminder sends it.
+func (r *licenseReminderRepository) Claim(reminder *domain.LicenseReminder) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(reminder)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// Delete releases a claimed reminder that could not be sent, so the next
+// run tries again.
+func (r *licenseReminderRepository) Delete(id uuid.UUID) error {
+	return r.db.Delete(&domain.LicenseReminder{}, "id = ?", id).Error
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/touros-platform/api/internal/domain"
+	"gorm.io/gorm"
+)
+
+type LoginAttemptRepository interface {
+	Create(attempt *domain.LoginAttempt) error
+	List(limit, offset int, userID *uuid.UUID, ip *string) ([]domain.LoginAttempt, int64, error)
+	CountIPFailures(ip string, since time.Time) (int64, error)
+	AddFailure(userID uuid.UUID) (int, error)
+	Lock(userID uuid.UUID, until time.Time) error
+	Reset(userID uuid.UUID) error
+	DeleteBefore(before time.Time) (int64, error)
+}
+
+type loginAttemptRepository struct {
+	db *gorm.DB
+}
+
+func NewLoginAttemptRepository(db *gorm.DB) LoginAttemptRepository {
+	return &loginAttemptRepository{db: db}
+}
+
+func (r *loginAttemptRepository) Create(attempt *domain.LoginAttempt) error {
+	return r.db.Create(attempt).Error
+}
+
+func (r *loginAttemptRepository) List(limit, offset int, userID *uuid.UUID, ip *string) ([]domain.LoginAttempt, int64, error) {
+	var attempts []domain.LoginAttempt
+	var total int64
+
+	query := r.db.Model(&domain.LoginAttempt{})
+	if userID != nil {
+		query = query.Where("user_id = ?", *userID)
+	}
+	if ip != nil {
+		query = query.Where("ip_address = ?", *ip)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Limit(limit).Offset(offset).Order("created_at DESC").Find(&attempts).Error
+	return attempts, total, err
+}
+
+// CountIPFailures counts wrong passwords, unknown emails and wrong MFA codes
+// from ip after the given time. Attempts turned away by a lockout are left
+// out: they test no credentials, and counting them would keep a blocked
+// address blocked for as long as it kept retrying.
+func (r *loginAttemptRepository) CountIPFailures(ip string, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&domain.LoginAttempt{}).
+		Where("ip_address = ? AND created_at > ? AND result IN ?", ip, since,
+			[]domain.LoginAttemptResult{domain.LoginBadPassword, domain.LoginUnknownEmail, domain.LoginBadMFACode}).
+		Count(&count).Error
+	return count, err
+}
+
+// AddFailure increments the user's consecutive failure count and returns the
+// new value. The increment happens in the database so that concurrent
+// attempts are all counted.
+func (r *loginAttemptRepository) AddFailure(userID uuid.UUID) (int, error) {
+	var failures int
+	err := r.db.Raw(`UPDATE users SET failed_login_attempts = failed_login_attempts + 1
+		WHERE id = ? RETURNING failed_login_attempts`, userID).Scan(&failures).Error
+	return failures, err
+}
+
+func (r *loginAttemptRepository) Lock(userID uuid.UUID, until time.Time) error {
+	return r.db.Model(&domain.User{}).Where("id = ?", userID).Update("locked_until", until).Error
+}
+
+// Reset clears the failure count and any lock.
+func (r *loginAttemptRepository) Reset(userID uuid.UUID) error {
+	return r.db.Model(&domain.User{}).Where("id = ?", userID).
+		Updates(map[string]interface{}{"failed_login_attempts": 0, "locked_until": nil}).Error
+}
+
+// DeleteBefore deletes attempts recorded before the given time.
+func (r *loginAttemptRepository) DeleteBefore(before time.Time) (int64, error) {
+	result := r.db.Where("created_at < ?", before).Delete(&domain.LoginAttempt{})
+	return result.RowsAffected, result.Error
+}
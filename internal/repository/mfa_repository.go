@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/touros-platform/api/internal/domain"
+	"gorm.io/gorm"
+)
+
+type MFARepository interface {
+	GetFactor(userID uuid.UUID) (*domain.MFAFactor, error)
+	SaveFactor(factor *domain.MFAFactor) error
+	DeleteFactor(userID uuid.UUID) error
+	UseStep(factorID uuid.UUID, step int64) (bool, error)
+	ReplaceRecoveryCodes(userID uuid.UUID, codes []domain.MFARecoveryCode) error
+	UseRecoveryCode(userID uuid.UUID, codeHash string) (bool, error)
+	CountRecoveryCodes(userID uuid.UUID) (int64, error)
+}
+
+type mfaRepository struct {
+	db *gorm.DB
+}
+
+func NewMFARepository(db *gorm.DB) MFARepository {
+	return &mfaRepository{db: db}
+}
+
+// GetFactor returns the user's authenticator, or nil without an error when
+// they have none, so that callers cannot mistake a database failure for an
+// account without MFA.
+func (r *mfaRepository) GetFactor(userID uuid.UUID) (*domain.MFAFactor, error) {
+	var factor domain.MFAFactor
+	err := r.db.Where("user_id = ?", userID).First(&factor).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &factor, nil
+}
+
+func (r *mfaRepository) SaveFactor(factor *domain.MFAFactor) error {
+	return r.db.Save(factor).Error
+}
+
+// DeleteFactor removes the user's authenticator and recovery codes.
+func (r *mfaRepository) DeleteFactor(userID uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&domain.MFARecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&domain.MFAFactor{}).Error
+	})
+}
+
+// UseStep records step as the factor's last accepted time step. It reports
+// false when a code for this or a later step was already accepted.
+func (r *mfaRepository) UseStep(factorID uuid.UUID, step int64) (bool, error) {
+	result := r.db.Model(&domain.MFAFactor{}).
+		Where("id = ? AND last_used_step < ?", factorID, step).
+		Update("last_used_step", step)
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *mfaRepository) ReplaceRecoveryCodes(userID uuid.UUID, codes []domain.MFARecoveryCode) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&domain.MFARecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+func (r *mfaRepository) UseRecoveryCode(userID uuid.UUID, codeHash string) (bool, error) {
+	result := r.db.Model(&domain.MFARecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *mfaRepository) CountRecoveryCodes(userID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.Model(&domain.MFARecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
@@ -13,9 +13,16 @@ type PermitRepository interface {
 	GetByID(id uuid.UUID) (*domain.Permit, error)
 	GetByPermitNumber(permitNum string) (*domain.Permit, error)
 	Update(permit *domain.Permit) error
+	Revoke(permit *domain.Permit) error
+	UpdateQRCode(id uuid.UUID, qrCode string) error
 	Delete(id uuid.UUID) error
-	List(limit, offset int, guideID *uuid.UUID, status *domain.PermitStatus) ([]domain.Permit, int64, error)
+	List(limit, offset int, scope Scope, guideID *uuid.UUID, status *domain.PermitStatus) ([]domain.Permit, int64, error)
 	GetActiveByGuideID(guideID uuid.UUID) ([]domain.Permit, error)
+	ListByTrekkerID(trekkerID uuid.UUID, scope Scope, limit, offset int) ([]domain.Permit, int64, error)
+	AddMember(permit *domain.Permit, member *domain.PermitMember) error
+	GetMember(permitID, memberID uuid.UUID) (*domain.PermitMember, error)
+	UpdateMember(member *domain.PermitMember) error
+	WithdrawMember(permit *domain.Permit, member *domain.PermitMember) error
 }
 
 type permitRepository struct {
@@ -26,13 +33,31 @@ func NewPermitRepository(db *gorm.DB) PermitRepository {
 	return &permitRepository{db: db}
 }
 
+// Create inserts the permit and its party members and reserves quota
+// capacity for its trekkers in the same transaction. It fails with
+// GuideUnavailableError when the guide is booked or blocked during the
+// permit's dates. The guide, trekker and route it references are never
+// written through the permit; permit.Route must be loaded so that regional
+// quotas apply.
 func (r *permitRepository) Create(permit *domain.Permit) error {
-	return r.db.Create(permit).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockGuide(tx, permit.GuideID); err != nil {
+			return err
+		}
+		if err := checkGuideAvailable(tx, permit); err != nil {
+			return err
+		}
+		if err := tx.Omit("Guide", "Trekker", "Route").Create(permit).Error; err != nil {
+			return err
+		}
+		trekkers := permit.PartySummary().Trekkers
+		return reserveQuota(tx, permit, trekkers, trekkers)
+	})
 }
 
 func (r *permitRepository) GetByID(id uuid.UUID) (*domain.Permit, error) {
 	var permit domain.Permit
-	err := r.db.Preload("Guide.User").Preload("Guide.Agency").Where("id = ?", id).First(&permit).Error
+	err := r.db.Preload("Guide.User").Preload("Guide.Agency").Preload("Trekker").Preload("Members.Trekker").Preload("Route.Checkpoints", orderedCheckpoints).Where("id = ?", id).First(&permit).Error
 	if err != nil {
 		return nil, err
 	}
@@ -41,7 +66,7 @@ func (r *permitRepository) GetByID(id uuid.UUID) (*domain.Permit, error) {
 
 func (r *permitRepository) GetByPermitNumber(permitNum string) (*domain.Permit, error) {
 	var permit domain.Permit
-	err := r.db.Preload("Guide.User").Preload("Guide.Agency").Where("permit_number = ?", permitNum).First(&permit).Error
+	err := r.db.Preload("Guide.User").Preload("Guide.Agency").Preload("Trekker").Preload("Members.Trekker").Preload("Route.Checkpoints", orderedCheckpoints).Where("permit_number = ?", permitNum).First(&permit).Error
 	if err != nil {
 		return nil, err
 	}
@@ -52,15 +77,34 @@ func (r *permitRepository) Update(permit *domain.Permit) error {
 	return r.db.Save(permit).Error
 }
 
+// Revoke saves the revoked permit and releases its quota reservations.
+func (r *permitRepository) Revoke(permit *domain.Permit) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(permit).Error; err != nil {
+			return err
+		}
+		return releaseQuota(tx, permit.ID)
+	})
+}
+
+func (r *permitRepository) UpdateQRCode(id uuid.UUID, qrCode string) error {
+	return r.db.Model(&domain.Permit{}).Where("id = ?", id).Update("qr_code", qrCode).Error
+}
+
 func (r *permitRepository) Delete(id uuid.UUID) error {
-	return r.db.Delete(&domain.Permit{}, id).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&domain.Permit{}, id).Error; err != nil {
+			return err
+		}
+		return releaseQuota(tx, id)
+	})
 }
 
-func (r *permitRepository) List(limit, offset int, guideID *uuid.UUID, status *domain.PermitStatus) ([]domain.Permit, int64, error) {
+func (r *permitRepository) List(limit, offset int, scope Scope, guideID *uuid.UUID, status *domain.PermitStatus) ([]domain.Permit, int64, error) {
 	var permits []domain.Permit
 	var total int64
 
-	query := r.db.Model(&domain.Permit{}).Preload("Guide.User").Preload("Guide.Agency")
+	query := scopePermits(r.db.Model(&domain.Permit{}), scope).Preload("Guide.User").Preload("Guide.Agency").Preload("Trekker").Preload("Members.Trekker").Preload("Route.Checkpoints", orderedCheckpoints)
 	if guideID != nil {
 		query = query.Where("guide_id = ?", *guideID)
 	}
@@ -79,10 +123,78 @@ func (r *permitRepository) List(limit, offset int, guideID *uuid.UUID, status *d
 func (r *permitRepository) GetActiveByGuideID(guideID uuid.UUID) ([]domain.Permit, error) {
 	var permits []domain.Permit
 	now := time.Now()
-	err := r.db.Preload("Guide.User").Preload("Guide.Agency").
+	err := r.db.Preload("Guide.User").Preload("Guide.Agency").Preload("Trekker").Preload("Members.Trekker").Preload("Route.Checkpoints", orderedCheckpoints).
 		Where("guide_id = ? AND status = ? AND start_date <= ? AND end_date >= ?", 
 			guideID, domain.PermitStatusActive, now, now).
 		Find(&permits).Error
 	return permits, err
 }
 
+func (r *permitRepository) ListByTrekkerID(trekkerID uuid.UUID, scope Scope, limit, offset int) ([]domain.Permit, int64, error) {
+	var permits []domain.Permit
+	var total int64
+
+	query := scopePermits(r.db.Model(&domain.Permit{}), scope).Preload("Guide.User").Preload("Guide.Agency").Preload("Trekker").Preload("Members.Trekker").Preload("Route.Checkpoints", orderedCheckpoints).
+		Where("(trekker_id = ? OR EXISTS (SELECT 1 FROM permit_members WHERE permit_members.permit_id = permits.id AND permit_members.trekker_id = ?))",
+			trekkerID, trekkerID)
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Limit(limit).Offset(offset).Order("start_date DESC").Find(&permits).Error
+	return permits, total, err
+}
+
+// AddMember inserts a party member. Trekkers take one place in each quota
+// covering the permit; permit.Members must already include the new member.
+func (r *permitRepository) AddMember(permit *domain.Permit, member *domain.PermitMember) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Trekker").Create(member).Error; err != nil {
+			return err
+		}
+		if member.Role != domain.PartyRoleTrekker {
+			return nil
+		}
+		return reserveQuota(tx, permit, 1, permit.PartySummary().Trekkers)
+	})
+}
+
+func (r *permitRepository) GetMember(permitID, memberID uuid.UUID) (*domain.PermitMember, error) {
+	var member domain.PermitMember
+	err := r.db.Preload("Trekker").Where("id = ? AND permit_id = ?", memberID, permitID).First(&member).Error
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+func (r *permitRepository) UpdateMember(member *domain.PermitMember) error {
+	return r.db.Omit("Trekker").Save(member).Error
+}
+
+// WithdrawMember saves a withdrawn member and gives a trekker's place back to
+// the quotas covering the permit.
+func (r *permitRepository) WithdrawMember(permit *domain.Permit, member *domain.PermitMember) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Trekker").Save(member).Error; err != nil {
+			return err
+		}
+		if member.Role != domain.PartyRoleTrekker {
+			return nil
+		}
+		return reserveQuota(tx, permit, -1, permit.PartySummary().Trekkers)
+	})
+}
+
+// scopePermits limits a permit query to the permits issued for the scope's
+// agency or led by its guide.
+func scopePermits(query *gorm.DB, scope Scope) *gorm.DB {
+	if scope.AgencyID != nil {
+		query = query.Where("permits.agency_id = ?", *scope.AgencyID)
+	}
+	if scope.GuideID != nil {
+		query = query.Where("permits.guide_id = ?", *scope.GuideID)
+	}
+	return query
+}
@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/touros-platform/api/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const dateLayout = "2006-01-02"
+
+// QuotaExceededError is returned when reserving capacity for a permit would
+// overbook a quota.
+type QuotaExceededError struct {
+	Quota     domain.Quota
+	Date      time.Time
+	Remaining int
+}
+
+func (e *QuotaExceededError) Error() string {
+	target := e.Quota.Region
+	if e.Quota.RouteID != nil {
+		target = "this route"
+	}
+	if e.Quota.Period == domain.QuotaPeriodDaily {
+		return fmt.Sprintf("daily quota for %s is full on %s (%d places left)", target, e.Date.Format(dateLayout), e.Remaining)
+	}
+	return fmt.Sprintf("season quota for %s from %s to %s is full (%d places left)",
+		target, e.Quota.StartDate.Format(dateLayout), e.Quota.EndDate.Format(dateLayout), e.Remaining)
+}
+
+type QuotaRepository interface {
+	Create(quota *domain.Quota) error
+	GetByID(id uuid.UUID) (*domain.Quota, error)
+	Update(quota *domain.Quota) error
+	Delete(id uuid.UUID) error
+	List(limit, offset int, routeID *uuid.UUID, region *string) ([]domain.Quota, int64, error)
+	ListApplicable(routeID uuid.UUID, region string, from, to time.Time) ([]domain.Quota, error)
+	Reserved(quota *domain.Quota, date time.Time) (int, error)
+}
+
+type quotaRepository struct {
+	db *gorm.DB
+}
+
+func NewQuotaRepository(db *gorm.DB) QuotaRepository {
+	return &quotaRepository{db: db}
+}
+
+func (r *quotaRepository) Create(quota *domain.Quota) error {
+	return r.db.Omit("Route").Create(quota).Error
+}
+
+func (r *quotaRepository) GetByID(id uuid.UUID) (*domain.Quota, error) {
+	var quota domain.Quota
+	err := r.db.Preload("Route").Where("id = ?", id).First(&quota).Error
+	if err != nil {
+		return nil, err
+	}
+	return &quota, nil
+}
+
+func (r *quotaRepository) Update(quota *domain.Quota) error {
+	return r.db.Omit("Route").Save(quota).Error
+}
+
+func (r *quotaRepository) Delete(id uuid.UUID) error {
+	return r.db.Delete(&domain.Quota{}, id).Error
+}
+
+func (r *quotaRepository) List(limit, offset int, routeID *uuid.UUID, region *string) ([]domain.Quota, int64, error) {
+	var quotas []domain.Quota
+	var total int64
+
+	query := r.db.Model(&domain.Quota{}).Preload("Route")
+	if routeID != nil {
+		query = query.Where("route_id = ?", *routeID)
+	}
+	if region != nil {
+		query = query.Where("region = ?", *region)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Limit(limit).Offset(offset).Order("start_date DESC").Find(&quotas).Error
+	return quotas, total, err
+}
+
+// ListApplicable returns the quotas on the route or its region that overlap
+// the given dates.
+func (r *quotaRepository) ListApplicable(routeID uuid.UUID, region string, from, to time.Time) ([]domain.Quota, error) {
+	var quotas []domain.Quota
+	err := applicableQuotas(r.db, routeID, region, from, to).Order("start_date").Find(&quotas).Error
+	return quotas, err
+}
+
+func (r *quotaRepository) Reserved(quota *domain.Quota, date time.Time) (int, error) {
+	return reservedInQuota(r.db, quota, date)
+}
+
+func applicableQuotas(db *gorm.DB, routeID uuid.UUID, region string, from, to time.Time) *gorm.DB {
+	return db.Model(&domain.Quota{}).
+		Where("route_id = ? OR (route_id IS NULL AND region = ?)", routeID, region).
+		Where("start_date <= ? AND end_date >= ?", to.Format(dateLayout), from.Format(dateLayout))
+}
+
+// reservedInQuota counts the trekkers holding capacity in the quota: on the
+// given entry date for daily quotas, over the whole range for season quotas.
+func reservedInQuota(db *gorm.DB, quota *domain.Quota, date time.Time) (int, error) {
+	var reserved int
+	query := db.Model(&domain.QuotaReservation{}).
+		Select("COALESCE(SUM(trekkers), 0)").
+		Where("quota_id = ? AND released_at IS NULL", quota.ID)
+	if quota.Period == domain.QuotaPeriodDaily {
+		query = query.Where("entry_date = ?", date.Format(dateLayout))
+	}
+	err := query.Scan(&reserved).Error
+	return reserved, err
+}
+
+// reserveQuota adjusts the capacity held by permit in every quota covering
+// its entry date by delta trekkers. The quota rows are locked for the rest of
+// the transaction so that concurrent reservations are serialised; positive
+// deltas fail with QuotaExceededError when a quota would be overbooked. A
+// quota without a reservation for the permit yet (one added after the permit
+// was issued) is charged for the whole party of partySize trekkers.
+func reserveQuota(tx *gorm.DB, permit *domain.Permit, delta, partySize int) error {
+	entry := permit.EntryDate()
+
+	var quotas []domain.Quota
+	err := applicableQuotas(tx, permit.RouteID, permit.Route.Region, entry, entry).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Order("id").
+		Find(&quotas).Error
+	if err != nil {
+		return err
+	}
+
+	for i := range quotas {
+		quota := &quotas[i]
+
+		var reservation domain.QuotaReservation
+		err := tx.Where("quota_id = ? AND permit_id = ?", quota.ID, permit.ID).First(&reservation).Error
+		isNew := errors.Is(err, gorm.ErrRecordNotFound)
+		if err != nil && !isNew {
+			return err
+		}
+
+		charge := delta
+		if isNew {
+			if delta < 0 {
+				continue
+			}
+			charge = partySize
+		}
+
+		if charge > 0 {
+			reserved, err := reservedInQuota(tx, quota, entry)
+			if err != nil {
+				return err
+			}
+			if reserved+charge > quota.Capacity {
+				remaining := quota.Capacity - reserved
+				if remaining < 0 {
+					remaining = 0
+				}
+				return &QuotaExceededError{Quota: *quota, Date: entry, Remaining: remaining}
+			}
+		}
+
+		if isNew {
+			reservation = domain.QuotaReservation{
+				QuotaID:   quota.ID,
+				PermitID:  permit.ID,
+				EntryDate: entry,
+				Trekkers:  charge,
+			}
+			if err := tx.Create(&reservation).Error; err != nil {
+				return err
+			}
+			continue
+		}
+
+		reservation.Trekkers += charge
+		if reservation.Trekkers < 0 {
+			reservation.Trekkers = 0
+		}
+		if err := tx.Save(&reservation).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func releaseQuota(tx *gorm.DB, permitID uuid.UUID) error {
+	return tx.Model(&domain.QuotaReservation{}).
+		Where("permit_id = ? AND released_at IS NULL", permitID).
+		Update("released_at", time.Now()).Error
+}
@@ -0,0 +1,202 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/touros-platform/api/internal/domain"
+)
+
+var testEntryDate = time.Date(2026, 4, 1, 6, 0, 0, 0, time.UTC)
+
+// quotaHarness issues permits on one route under a daily quota for
+// testEntryDate.
+type quotaHarness struct {
+	t       *testing.T
+	f       fixtures
+	permits PermitRepository
+	quotas  QuotaRepository
+	route   *domain.Route
+	quota   *domain.Quota
+}
+
+func newQuotaHarness(t *testing.T, capacity int) *quotaHarness {
+	t.Helper()
+
+	db := testDB(t)
+	h := &quotaHarness{
+		t:       t,
+		f:       fixtures{t: t, db: db},
+		permits: NewPermitRepository(db),
+		quotas:  NewQuotaRepository(db),
+	}
+	h.route = h.f.route("Annapurna")
+
+	entry := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+	h.quota = &domain.Quota{
+		RouteID:   &h.route.ID,
+		Period:    domain.QuotaPeriodDaily,
+		StartDate: entry,
+		EndDate:   entry,
+		Capacity:  capacity,
+	}
+	if err := h.quotas.Create(h.quota); err != nil {
+		t.Fatalf("failed to create quota: %v", err)
+	}
+	return h
+}
+
+func (h *quotaHarness) issue(trekkers int) (*domain.Permit, error) {
+	permit := h.f.permit(h.route, testEntryDate, trekkers)
+	return permit, h.permits.Create(permit)
+}
+
+func (h *quotaHarness) mustIssue(trekkers int) *domain.Permit {
+	h.t.Helper()
+
+	permit, err := h.issue(trekkers)
+	if err != nil {
+		h.t.Fatalf("failed to issue permit for %d trekkers: %v", trekkers, err)
+	}
+	return permit
+}
+
+func (h *quotaHarness) addTrekker(permit *domain.Permit) error {
+	trekker := h.f.trekker(nil)
+	permit.Members = append(permit.Members, domain.PermitMember{
+		PermitID:  permit.ID,
+		Role:      domain.PartyRoleTrekker,
+		TrekkerID: &trekker.ID,
+		Status:    domain.MemberStatusPending,
+	})
+	err := h.permits.AddMember(permit, &permit.Members[len(permit.Members)-1])
+	if err != nil {
+		permit.Members = permit.Members[:len(permit.Members)-1]
+	}
+	return err
+}
+
+func (h *quotaHarness) mustHaveReserved(want int) {
+	h.t.Helper()
+
+	reserved, err := h.quotas.Reserved(h.quota, testEntryDate)
+	if err != nil {
+		h.t.Fatalf("Reserved: %v", err)
+	}
+	if reserved != want {
+		h.t.Fatalf("reserved = %d, want %d", reserved, want)
+	}
+}
+
+func mustExceed(t *testing.T, err error, remaining int) {
+	t.Helper()
+
+	var exceeded *QuotaExceededError
+	if !errors.As(err, &exceeded) {
+		t.Fatalf("got %v, want QuotaExceededError", err)
+	}
+	if exceeded.Remaining != remaining {
+		t.Fatalf("Remaining = %d, want %d", exceeded.Remaining, remaining)
+	}
+}
+
+func TestReserveQuotaFillsToCapacity(t *testing.T) {
+	h := newQuotaHarness(t, 4)
+
+	h.mustIssue(3)
+	h.mustIssue(1)
+	h.mustHaveReserved(4)
+
+	permit, err := h.issue(1)
+	mustExceed(t, err, 0)
+	h.mustHaveReserved(4)
+
+	var count int64
+	if err := h.f.db.Model(&domain.Permit{}).Where("id = ?", permit.ID).Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Fatal("permit refused for quota was still saved")
+	}
+}
+
+func TestReserveQuotaRefusesOneTrekkerOver(t *testing.T) {
+	h := newQuotaHarness(t, 3)
+
+	_, err := h.issue(4)
+	mustExceed(t, err, 3)
+	h.mustHaveReserved(0)
+
+	permit := h.mustIssue(2)
+	if err := h.addTrekker(permit); err != nil {
+		t.Fatalf("adding the trekker that fills the quota: %v", err)
+	}
+	h.mustHaveReserved(3)
+
+	mustExceed(t, h.addTrekker(permit), 0)
+	h.mustHaveReserved(3)
+}
+
+func TestReleaseQuotaOnWithdrawAndRevoke(t *testing.T) {
+	h := newQuotaHarness(t, 3)
+
+	permit := h.mustIssue(3)
+	h.mustHaveReserved(3)
+
+	member := &permit.Members[len(permit.Members)-1]
+	member.Status = domain.MemberStatusWithdrawn
+	if err := h.permits.WithdrawMember(permit, member); err != nil {
+		t.Fatalf("WithdrawMember: %v", err)
+	}
+	h.mustHaveReserved(2)
+
+	// The withdrawn trekker's place can be taken by another party.
+	h.mustIssue(1)
+	h.mustHaveReserved(3)
+
+	now := time.Now()
+	revokedBy := uuid.New()
+	permit.Status = domain.PermitStatusRevoked
+	permit.RevokedAt = &now
+	permit.RevokedBy = &revokedBy
+	if err := h.permits.Revoke(permit); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+	h.mustHaveReserved(1)
+
+	h.mustIssue(2)
+	h.mustHaveReserved(3)
+}
+
+func TestReserveQuotaChargesWholePartyForNewQuota(t *testing.T) {
+	h := newQuotaHarness(t, 10)
+	permit := h.mustIssue(2)
+
+	// A regional quota added after the permit was issued is charged for the
+	// whole party the next time the party changes.
+	entry := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+	regional := &domain.Quota{
+		Region:    h.route.Region,
+		Period:    domain.QuotaPeriodDaily,
+		StartDate: entry,
+		EndDate:   entry,
+		Capacity:  10,
+	}
+	if err := h.quotas.Create(regional); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.addTrekker(permit); err != nil {
+		t.Fatalf("AddMember: %v", err)
+	}
+
+	reserved, err := h.quotas.Reserved(regional, testEntryDate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reserved != 3 {
+		t.Fatalf("regional quota reserved = %d, want 3", reserved)
+	}
+	h.mustHaveReserved(3)
+}
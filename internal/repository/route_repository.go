@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"github.com/google/uuid"
+	"github.com/touros-platform/api/internal/domain"
+	"gorm.io/gorm"
+)
+
+type RouteRepository interface {
+	Create(route *domain.Route) error
+	GetByID(id uuid.UUID) (*domain.Route, error)
+	GetByCode(code string) (*domain.Route, error)
+	Update(route *domain.Route) error
+	ReplaceCheckpoints(routeID uuid.UUID, checkpoints []domain.RouteCheckpoint) error
+	ReplaceRequiredCertifications(routeID uuid.UUID, required []domain.RouteCertification) error
+	GetCheckpoint(id uuid.UUID) (*domain.RouteCheckpoint, error)
+	Delete(id uuid.UUID) error
+	List(limit, offset int, region *string, activeOnly bool) ([]domain.Route, int64, error)
+}
+
+type routeRepository struct {
+	db *gorm.DB
+}
+
+func NewRouteRepository(db *gorm.DB) RouteRepository {
+	return &routeRepository{db: db}
+}
+
+func orderedCheckpoints(db *gorm.DB) *gorm.DB {
+	return db.Order("sequence")
+}
+
+func (r *routeRepository) Create(route *domain.Route) error {
+	return r.db.Create(route).Error
+}
+
+func (r *routeRepository) GetByID(id uuid.UUID) (*domain.Route, error) {
+	var route domain.Route
+	err := r.db.Preload("Checkpoints", orderedCheckpoints).Preload("RequiredCertifications").Where("id = ?", id).First(&route).Error
+	if err != nil {
+		return nil, err
+	}
+	return &route, nil
+}
+
+func (r *routeRepository) GetByCode(code string) (*domain.Route, error) {
+	var route domain.Route
+	err := r.db.Preload("Checkpoints", orderedCheckpoints).Preload("RequiredCertifications").Where("code = ?", code).First(&route).Error
+	if err != nil {
+		return nil, err
+	}
+	return &route, nil
+}
+
+func (r *routeRepository) Update(route *domain.Route) error {
+	return r.db.Omit("Checkpoints", "RequiredCertifications").Save(route).Error
+}
+
+// ReplaceCheckpoints swaps the route's checkpoints for the given list in a
+// single transaction.
+func (r *routeRepository) ReplaceCheckpoints(routeID uuid.UUID, checkpoints []domain.RouteCheckpoint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("route_id = ?", routeID).Delete(&domain.RouteCheckpoint{}).Error; err != nil {
+			return err
+		}
+		if len(checkpoints) == 0 {
+			return nil
+		}
+		for i := range checkpoints {
+			checkpoints[i].RouteID = routeID
+		}
+		return tx.Create(&checkpoints).Error
+	})
+}
+
+// ReplaceRequiredCertifications swaps the route's required certifications
+// for the given list in a single transaction.
+func (r *routeRepository) ReplaceRequiredCertifications(routeID uuid.UUID, required []domain.RouteCertification) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("route_id = ?", routeID).Delete(&domain.RouteCertification{}).Error; err != nil {
+			return err
+		}
+		if len(required) == 0 {
+			return nil
+		}
+		for i := range required {
+			required[i].RouteID = routeID
+		}
+		return tx.Create(&required).Error
+	})
+}
+
+func (r *routeRepository) GetCheckpoint(id uuid.UUID) (*domain.RouteCheckpoint, error) {
+	var checkpoint domain.RouteCheckpoint
+	err := r.db.Where("id = ?", id).First(&checkpoint).Error
+	if err != nil {
+		return nil, err
+	}
+	return &checkpoint, nil
+}
+
+func (r *routeRepository) Delete(id uuid.UUID) error {
+	return r.db.Delete(&domain.Route{}, id).Error
+}
+
+func (r *routeRepository) List(limit, offset int, region *string, activeOnly bool) ([]domain.Route, int64, error) {
+	var routes []domain.Route
+	var total int64
+
+	query := r.db.Model(&domain.Route{}).Preload("Checkpoints", orderedCheckpoints).Preload("RequiredCertifications")
+	if region != nil {
+		query = query.Where("region = ?", *region)
+	}
+	if activeOnly {
+		query = query.Where("is_active = ?", true)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Limit(limit).Offset(offset).Order("name").Find(&routes).Error
+	return routes, total, err
+}
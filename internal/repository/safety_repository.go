@@ -65,8 +65,9 @@ type IncidentRepository interface {
 	Create(incident *domain.Incident) error
 	GetByID(id uuid.UUID) (*domain.Incident, error)
 	Update(incident *domain.Incident) error
-	List(limit, offset int, status *domain.IncidentStatus, guideID *uuid.UUID) ([]domain.Incident, int64, error)
+	List(limit, offset int, scope Scope, status *domain.IncidentStatus, guideID *uuid.UUID) ([]domain.Incident, int64, error)
 	GetActiveSOSByGuideID(guideID uuid.UUID) ([]domain.Incident, error)
+	GetActiveByGuideIDAndType(guideID uuid.UUID, incidentType domain.IncidentType) ([]domain.Incident, error)
 }
 
 type incidentRepository struct {
@@ -94,11 +95,20 @@ func (r *incidentRepository) Update(incident *domain.Incident) error {
 	return r.db.Save(incident).Error
 }
 
-func (r *incidentRepository) List(limit, offset int, status *domain.IncidentStatus, guideID *uuid.UUID) ([]domain.Incident, int64, error) {
+// List returns incidents newest first. An agency scope admits incidents of
+// the agency's guides and incidents on the agency's permits.
+func (r *incidentRepository) List(limit, offset int, scope Scope, status *domain.IncidentStatus, guideID *uuid.UUID) ([]domain.Incident, int64, error) {
 	var incidents []domain.Incident
 	var total int64
 
 	query := r.db.Model(&domain.Incident{}).Preload("Guide.User").Preload("Guide.Agency").Preload("Permit")
+	if scope.AgencyID != nil {
+		query = query.Where("(guide_id IN (SELECT id FROM guides WHERE agency_id = ?) OR permit_id IN (SELECT id FROM permits WHERE agency_id = ?))",
+			*scope.AgencyID, *scope.AgencyID)
+	}
+	if scope.GuideID != nil {
+		query = query.Where("guide_id = ?", *scope.GuideID)
+	}
 	if status != nil {
 		query = query.Where("status = ?", *status)
 	}
@@ -123,3 +133,12 @@ func (r *incidentRepository) GetActiveSOSByGuideID(guideID uuid.UUID) ([]domain.
 	return incidents, err
 }
 
+func (r *incidentRepository) GetActiveByGuideIDAndType(guideID uuid.UUID, incidentType domain.IncidentType) ([]domain.Incident, error) {
+	var incidents []domain.Incident
+	err := r.db.Preload("Guide.User").Preload("Guide.Agency").Preload("Permit").
+		Where("guide_id = ? AND incident_type = ? AND status IN ?",
+			guideID, incidentType, []domain.IncidentStatus{domain.IncidentStatusOpen, domain.IncidentStatusInProgress}).
+		Order("reported_at DESC").
+		Find(&incidents).Error
+	return incidents, err
+}
@@ -0,0 +1,18 @@
+package repository
+
+import "github.com/google/uuid"
+
+// Scope limits a query to the records one agency or one guide may see. Each
+// repository applies it to its own records; the zero value restricts
+// nothing.
+type Scope struct {
+	// AgencyID limits results to the agency's guides, permits, incidents
+	// and trekkers.
+	AgencyID *uuid.UUID
+	// GuideID limits results to the guide's own profile, permits and
+	// incidents, and to trekkers on those permits.
+	GuideID *uuid.UUID
+	// UserID, set together with GuideID, also admits trekkers the guide
+	// registered.
+	UserID *uuid.UUID
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/touros-platform/api/internal/domain"
+	"gorm.io/gorm"
+)
+
+type ServiceAccountRepository interface {
+	Create(account *domain.ServiceAccount) error
+	GetByID(id uuid.UUID) (*domain.ServiceAccount, error)
+	Update(account *domain.ServiceAccount) error
+	Delete(id uuid.UUID) error
+	List(limit, offset int, scope Scope) ([]domain.ServiceAccount, int64, error)
+	CreateKey(key *domain.APIKey) error
+	GetKey(accountID, keyID uuid.UUID) (*domain.APIKey, error)
+	GetKeyByPrefix(prefix string) (*domain.APIKey, error)
+	ListKeys(accountID uuid.UUID) ([]domain.APIKey, error)
+	RotateKey(old *domain.APIKey, next *domain.APIKey) error
+	RevokeKey(id uuid.UUID) error
+	TouchKey(id uuid.UUID, at time.Time, ip string) error
+}
+
+type serviceAccountRepository struct {
+	db *gorm.DB
+}
+
+func NewServiceAccountRepository(db *gorm.DB) ServiceAccountRepository {
+	return &serviceAccountRepository{db: db}
+}
+
+func (r *serviceAccountRepository) Create(account *domain.ServiceAccount) error {
+	return r.db.Omit("Agency", "Keys").Create(account).Error
+}
+
+func (r *serviceAccountRepository) GetByID(id uuid.UUID) (*domain.ServiceAccount, error) {
+	var account domain.ServiceAccount
+	err := r.db.Preload("Agency").Preload("Keys", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at DESC")
+	}).Where("id = ?", id).First(&account).Error
+	if err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+func (r *serviceAccountRepository) Update(account *domain.ServiceAccount) error {
+	return r.db.Omit("Agency", "Keys").Save(account).Error
+}
+
+// Delete removes the account and revokes its keys.
+func (r *serviceAccountRepository) Delete(id uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&domain.APIKey{}).
+			Where("service_account_id = ? AND revoked_at IS NULL", id).
+			Update("revoked_at", time.Now()).Error
+		if err != nil {
+			return err
+		}
+		return tx.Delete(&domain.ServiceAccount{}, "id = ?", id).Error
+	})
+}
+
+func (r *serviceAccountRepository) List(limit, offset int, scope Scope) ([]domain.ServiceAccount, int64, error) {
+	var accounts []domain.ServiceAccount
+	var total int64
+
+	query := r.db.Model(&domain.ServiceAccount{}).Preload("Agency")
+	if scope.AgencyID != nil {
+		query = query.Where("agency_id = ?", *scope.AgencyID)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Limit(limit).Offset(offset).Order("name").Find(&accounts).Error
+	return accounts, total, err
+}
+
+func (r *serviceAccountRepository) CreateKey(key *domain.APIKey) error {
+	return r.db.Create(key).Error
+}
+
+func (r *serviceAccountRepository) GetKey(accountID, keyID uuid.UUID) (*domain.APIKey, error) {
+	var key domain.APIKey
+	err := r.db.Where("id = ? AND service_account_id = ?", keyID, accountID).First(&key).Error
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *serviceAccountRepository) GetKeyByPrefix(prefix string) (*domain.APIKey, error) {
+	var key domain.APIKey
+	err := r.db.Where("prefix = ?", prefix).First(&key).Error
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *serviceAccountRepository) ListKeys(accountID uuid.UUID) ([]domain.APIKey, error) {
+	var keys []domain.APIKey
+	err := r.db.Where("service_account_id = ?", accountID).Order("created_at DESC").Find(&keys).Error
+	return keys, err
+}
+
+// RotateKey stores next and records on old that it was replaced, together
+// with the shortened expiry old was given for the changeover.
+func (r *serviceAccountRepository) RotateKey(old *domain.APIKey, next *domain.APIKey) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(next).Error; err != nil {
+			return err
+		}
+		old.RotatedTo = &next.ID
+		return tx.Model(old).Updates(map[string]interface{}{
+			"rotated_to": next.ID,
+			"expires_at": old.ExpiresAt,
+		}).Error
+	})
+}
+
+func (r *serviceAccountRepository) RevokeKey(id uuid.UUID) error {
+	return r.db.Model(&domain.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now()).Error
+}
+
+// TouchKey records when and from where the key was last used.
+func (r *serviceAccountRepository) TouchKey(id uuid.UUID, at time.Time, ip string) error {
+	return r.db.Model(&domain.APIKey{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"last_used_at": at, "last_used_ip": ip}).Error
+}
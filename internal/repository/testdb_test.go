@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/touros-platform/api/internal/database"
+	"github.com/touros-platform/api/internal/domain"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
+)
+
+var (
+	testDBOnce sync.Once
+	testDBConn *gorm.DB
+	testDBErr  error
+)
+
+// testDB returns a transaction on the PostgreSQL database named by
+// TEST_DATABASE_URL, migrated to the latest schema. The transaction is
+// rolled back when the test ends. Tests are skipped when the variable is
+// not set.
+func testDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+
+	testDBOnce.Do(func() {
+		testDBConn, testDBErr = gorm.Open(postgres.Open(dsn), &gorm.Config{
+			Logger: logger.Default.LogMode(logger.Silent),
+		})
+		if testDBErr != nil {
+			return
+		}
+		var migrator *database.Migrator
+		if migrator, testDBErr = database.NewMigrator(testDBConn); testDBErr != nil {
+			return
+		}
+		_, testDBErr = migrator.Up(context.Background())
+	})
+	if testDBErr != nil {
+		t.Fatalf("failed to prepare test database: %v", testDBErr)
+	}
+
+	tx := testDBConn.Begin()
+	if tx.Error != nil {
+		t.Fatalf("failed to begin transaction: %v", tx.Error)
+	}
+	t.Cleanup(func() { tx.Rollback() })
+	return tx
+}
+
+// fixtures inserts the rows tests build on.
+type fixtures struct {
+	t  *testing.T
+	db *gorm.DB
+}
+
+func (f fixtures) create(value interface{}) {
+	f.t.Helper()
+
+	if err := f.db.Omit(clause.Associations).Create(value).Error; err != nil {
+		f.t.Fatalf("failed to create %T: %v", value, err)
+	}
+}
+
+func (f fixtures) agency() *domain.Agency {
+	agency := &domain.Agency{
+		Name:               "Agency " + uuid.NewString()[:8],
+		RegistrationNumber: uuid.NewString(),
+		LicenseNumber:      uuid.NewString(),
+		ContactEmail:       uuid.NewString() + "@agency.example.com",
+		ContactPhone:       "+977-1-4000000",
+	}
+	f.create(agency)
+	return agency
+}
+
+func (f fixtures) user(role domain.Role, agencyID *uuid.UUID) *domain.User {
+	user := &domain.User{
+		Email:        uuid.NewString() + "@example.com",
+		PasswordHash: "not-a-hash",
+		Role:         role,
+		FullName:     "Test User",
+		IsActive:     true,
+		AgencyID:     agencyID,
+	}
+	f.create(user)
+	return user
+}
+
+func (f fixtures) guide() *domain.Guide {
+	user := f.user(domain.RoleGuide, nil)
+	guide := &domain.Guide{
+		UserID:           user.ID,
+		LicenseNumber:    "GL-" + uuid.NewString(),
+		PhoneNumber:      "+977-9800000000",
+		EmergencyContact: "+977-9800000001",
+		Status:           domain.GuideStatusVerified,
+	}
+	f.create(guide)
+	return guide
+}
+
+func (f fixtures) trekker(agencyID *uuid.UUID) *domain.Trekker {
+	trekker := &domain.Trekker{
+		FullName:       "Test Trekker",
+		PassportNumber: uuid.NewString()[:12],
+		Nationality:    "GB",
+		AgencyID:       agencyID,
+	}
+	f.create(trekker)
+	return trekker
+}
+
+func (f fixtures) route(region string) *domain.Route {
+	route := &domain.Route{
+		Code:            "R-" + uuid.NewString()[:8],
+		Name:            "Test Route",
+		Region:          region,
+		DurationDays:    10,
+		MinTrekkers:     1,
+		MaxTrekkers:     15,
+		MaxSupportStaff: 15,
+		IsActive:        true,
+	}
+	f.create(route)
+	return route
+}
+
+// permit builds an unsaved permit on the route for a party of trekkers,
+// led by a new guide.
+func (f fixtures) permit(route *domain.Route, start time.Time, trekkers int) *domain.Permit {
+	guide := f.guide()
+	lead := f.trekker(nil)
+	permit := &domain.Permit{
+		PermitNumber: "TP-" + uuid.NewString(),
+		GuideID:      guide.ID,
+		TrekkerID:    lead.ID,
+		StartDate:    start,
+		EndDate:      start.AddDate(0, 0, route.DurationDays),
+		RouteID:      route.ID,
+		Route:        *route,
+		Status:       domain.PermitStatusActive,
+		IssuedBy:     guide.UserID,
+	}
+	permit.Members = append(permit.Members, domain.PermitMember{
+		Role:      domain.PartyRoleTrekker,
+		TrekkerID: &lead.ID,
+		Status:    domain.MemberStatusPending,
+	})
+	for i := 1; i < trekkers; i++ {
+		trekker := f.trekker(nil)
+		permit.Members = append(permit.Members, domain.PermitMember{
+			Role:      domain.PartyRoleTrekker,
+			TrekkerID: &trekker.ID,
+			Status:    domain.MemberStatusPending,
+		})
+	}
+	return permit
+}
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/touros-platform/api/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TokenRepository interface {
+	CreateRefreshToken(token *domain.RefreshToken) error
+	GetRefreshToken(id uuid.UUID) (*domain.RefreshToken, error)
+	RotateRefreshToken(usedID uuid.UUID, next *domain.RefreshToken) (bool, error)
+	RevokeFamily(familyID uuid.UUID, reason string) error
+	RevokeAllForUser(userID uuid.UUID, reason string) error
+	RevokeAccessToken(token *domain.RevokedAccessToken) error
+	IsAccessTokenRevoked(jti string, userID uuid.UUID, issuedAt time.Time) (bool, error)
+	DeleteExpired(before time.Time) (int64, error)
+}
+
+type tokenRepository struct {
+	db *gorm.DB
+}
+
+func NewTokenRepository(db *gorm.DB) TokenRepository {
+	return &tokenRepository{db: db}
+}
+
+func (r *tokenRepository) CreateRefreshToken(token *domain.RefreshToken) error {
+	return r.db.Create(token).Error
+}
+
+func (r *tokenRepository) GetRefreshToken(id uuid.UUID) (*domain.RefreshToken, error) {
+	var token domain.RefreshToken
+	err := r.db.Where("id = ?", id).First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// RotateRefreshToken marks usedID as used and stores its successor in one
+// transaction. It reports false, storing nothing, when usedID had already
+// been used or revoked, which happens when two requests race with the same
+// token.
+func (r *tokenRepository) RotateRefreshToken(usedID uuid.UUID, next *domain.RefreshToken) (bool, error) {
+	rotated := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&domain.RefreshToken{}).
+			Where("id = ? AND used_at IS NULL AND revoked_at IS NULL", usedID).
+			Update("used_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		if err := tx.Create(next).Error; err != nil {
+			return err
+		}
+		rotated = true
+		return nil
+	})
+	return rotated, err
+}
+
+func (r *tokenRepository) RevokeFamily(familyID uuid.UUID, reason string) error {
+	return r.db.Model(&domain.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Updates(map[string]interface{}{"revoked_at": time.Now(), "revoked_reason": reason}).Error
+}
+
+// RevokeAllForUser revokes every refresh token of the user and rejects the
+// access tokens already issued to them.
+func (r *tokenRepository) RevokeAllForUser(userID uuid.UUID, reason string) error {
+	now := time.Now()
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&domain.RefreshToken{}).
+			Where("user_id = ? AND revoked_at IS NULL", userID).
+			Updates(map[string]interface{}{"revoked_at": now, "revoked_reason": reason}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(&domain.User{}).Where("id = ?", userID).Update("tokens_revoked_at", now).Error
+	})
+}
+
+func (r *tokenRepository) RevokeAccessToken(token *domain.RevokedAccessToken) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(token).Error
+}
+
+// IsAccessTokenRevoked reports whether the token is denylisted, was not
+// issued after the user last logged out everywhere, or belongs to a user who
+// has since been deactivated or deleted. Token issue times only have one
+// second resolution, so a token issued in the same second as a logout
+// everywhere counts as revoked, whether it came just before or just after.
+func (r *tokenRepository) IsAccessTokenRevoked(jti string, userID uuid.UUID, issuedAt time.Time) (bool, error) {
+	var revoked bool
+	err := r.db.Raw(`SELECT EXISTS (SELECT 1 FROM revoked_access_tokens WHERE jti = ?)
+		OR NOT EXISTS (
+			SELECT 1 FROM users
+			WHERE id = ? AND is_active AND deleted_at IS NULL
+			AND (tokens_revoked_at IS NULL OR tokens_revoked_at < ?)
+		)`, jti, userID, issuedAt).Scan(&revoked).Error
+	return revoked, err
+}
+
+// DeleteExpired deletes refresh tokens and access token denylist entries
+// that expired before the given time; an expired token is refused whether
+// or not its row is kept. It returns the number of rows deleted.
+func (r *tokenRepository) DeleteExpired(before time.Time) (int64, error) {
+	var deleted int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("expires_at < ?", before).Delete(&domain.RefreshToken{})
+		if result.Error != nil {
+			return result.Error
+		}
+		deleted += result.RowsAffected
+
+		result = tx.Where("expires_at < ?", before).Delete(&domain.RevokedAccessToken{})
+		if result.Error != nil {
+			return result.Error
+		}
+		deleted += result.RowsAffected
+		return nil
+	})
+	return deleted, err
+}
@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/touros-platform/api/internal/domain"
+)
+
+func newTestRefreshToken(userID, familyID uuid.UUID, parentID *uuid.UUID) *domain.RefreshToken {
+	return &domain.RefreshToken{
+		ID:        uuid.New(),
+		UserID:    userID,
+		FamilyID:  familyID,
+		ParentID:  parentID,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+}
+
+func mustGetRefreshToken(t *testing.T, tokens TokenRepository, id uuid.UUID) *domain.RefreshToken {
+	t.Helper()
+
+	token, err := tokens.GetRefreshToken(id)
+	if err != nil {
+		t.Fatalf("GetRefreshToken: %v", err)
+	}
+	return token
+}
+
+func mustBeRevoked(t *testing.T, tokens TokenRepository, jti string, userID uuid.UUID, issuedAt time.Time, want bool) {
+	t.Helper()
+
+	revoked, err := tokens.IsAccessTokenRevoked(jti, userID, issuedAt)
+	if err != nil {
+		t.Fatalf("IsAccessTokenRevoked: %v", err)
+	}
+	if revoked != want {
+		t.Fatalf("token issued at %s: revoked = %t, want %t", issuedAt.Format(time.RFC3339Nano), revoked, want)
+	}
+}
+
+func TestRotateRefreshTokenUsesTokenOnce(t *testing.T) {
+	db := testDB(t)
+	f := fixtures{t: t, db: db}
+	tokens := NewTokenRepository(db)
+	user := f.user(domain.RoleGuide, nil)
+
+	first := newTestRefreshToken(user.ID, uuid.New(), nil)
+	if err := tokens.CreateRefreshToken(first); err != nil {
+		t.Fatal(err)
+	}
+
+	second := newTestRefreshToken(user.ID, first.FamilyID, &first.ID)
+	rotated, err := tokens.RotateRefreshToken(first.ID, second)
+	if err != nil || !rotated {
+		t.Fatalf("first rotation: rotated = %t, err = %v", rotated, err)
+	}
+	if mustGetRefreshToken(t, tokens, first.ID).UsedAt == nil {
+		t.Fatal("rotated token is not marked used")
+	}
+
+	// A second request racing with the same token stores nothing.
+	racing := newTestRefreshToken(user.ID, first.FamilyID, &first.ID)
+	rotated, err = tokens.RotateRefreshToken(first.ID, racing)
+	if err != nil || rotated {
+		t.Fatalf("second rotation: rotated = %t, err = %v", rotated, err)
+	}
+	if _, err := tokens.GetRefreshToken(racing.ID); err == nil {
+		t.Fatal("successor of a used token was stored")
+	}
+}
+
+func TestRevokeFamilyRevokesEveryTokenInIt(t *testing.T) {
+	db := testDB(t)
+	f := fixtures{t: t, db: db}
+	tokens := NewTokenRepository(db)
+	user := f.user(domain.RoleGuide, nil)
+
+	first := newTestRefreshToken(user.ID, uuid.New(), nil)
+	second := newTestRefreshToken(user.ID, first.FamilyID, &first.ID)
+	other := newTestRefreshToken(user.ID, uuid.New(), nil)
+	for _, token := range []*domain.RefreshToken{first, second, other} {
+		if err := tokens.CreateRefreshToken(token); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := tokens.RevokeFamily(first.FamilyID, domain.TokenRevokedReuse); err != nil {
+		t.Fatal(err)
+	}
+	for _, token := range []*domain.RefreshToken{first, second} {
+		got := mustGetRefreshToken(t, tokens, token.ID)
+		if got.RevokedAt == nil || got.RevokedReason != domain.TokenRevokedReuse {
+			t.Fatalf("token in the family: revoked at %v for %q", got.RevokedAt, got.RevokedReason)
+		}
+	}
+	if mustGetRefreshToken(t, tokens, other.ID).RevokedAt != nil {
+		t.Fatal("token of another family was revoked")
+	}
+}
+
+func TestRevokeAccessTokenDenylistsOnlyThatToken(t *testing.T) {
+	db := testDB(t)
+	f := fixtures{t: t, db: db}
+	tokens := NewTokenRepository(db)
+	user := f.user(domain.RoleGuide, nil)
+	issuedAt := time.Now().Truncate(time.Second)
+
+	jti := uuid.NewString()
+	mustBeRevoked(t, tokens, jti, user.ID, issuedAt, false)
+
+	revoked := &domain.RevokedAccessToken{
+		JTI:       jti,
+		UserID:    user.ID,
+		ExpiresAt: time.Now().Add(time.Hour),
+		RevokedAt: time.Now(),
+	}
+	if err := tokens.RevokeAccessToken(revoked); err != nil {
+		t.Fatal(err)
+	}
+	// Logging out twice with the same token is not an error.
+	if err := tokens.RevokeAccessToken(revoked); err != nil {
+		t.Fatal(err)
+	}
+
+	mustBeRevoked(t, tokens, jti, user.ID, issuedAt, true)
+	mustBeRevoked(t, tokens, uuid.NewString(), user.ID, issuedAt, false)
+}
+
+func TestRevokeAllForUserRejectsTokensNotIssuedAfterIt(t *testing.T) {
+	db := testDB(t)
+	f := fixtures{t: t, db: db}
+	tokens := NewTokenRepository(db)
+	user := f.user(domain.RoleGuide, nil)
+	other := f.user(domain.RoleGuide, nil)
+
+	session := newTestRefreshToken(user.ID, uuid.New(), nil)
+	if err := tokens.CreateRefreshToken(session); err != nil {
+		t.Fatal(err)
+	}
+
+	// PostgreSQL keeps microseconds.
+	before := time.Now().Truncate(time.Microsecond)
+	if err := tokens.RevokeAllForUser(user.ID, domain.TokenRevokedLogoutAll); err != nil {
+		t.Fatal(err)
+	}
+	var cutoff time.Time
+	if err := db.Raw("SELECT tokens_revoked_at FROM users WHERE id = ?", user.ID).Scan(&cutoff).Error; err != nil {
+		t.Fatal(err)
+	}
+	if cutoff.Before(before) {
+		t.Fatalf("tokens_revoked_at %s is earlier than the logout at %s", cutoff, before)
+	}
+
+	// Access tokens carry their issue time in whole seconds, so one issued
+	// in the same second as the logout, before or after it, is refused.
+	second := cutoff.Truncate(time.Second)
+	mustBeRevoked(t, tokens, uuid.NewString(), user.ID, second.Add(-time.Second), true)
+	mustBeRevoked(t, tokens, uuid.NewString(), user.ID, second, true)
+	mustBeRevoked(t, tokens, uuid.NewString(), user.ID, second.Add(time.Second), false)
+	mustBeRevoked(t, tokens, uuid.NewString(), other.ID, second, false)
+
+	got := mustGetRefreshToken(t, tokens, session.ID)
+	if got.RevokedAt == nil || got.RevokedReason != domain.TokenRevokedLogoutAll {
+		t.Fatalf("refresh token: revoked at %v for %q", got.RevokedAt, got.RevokedReason)
+	}
+}
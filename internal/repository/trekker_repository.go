@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/touros-platform/api/internal/domain"
+	"gorm.io/gorm"
+)
+
+type TrekkerFilter struct {
+	Query          string
+	PassportNumber string
+	Nationality    string
+	AgencyID       *uuid.UUID
+}
+
+type TrekkerRepository interface {
+	Create(trekker *domain.Trekker) error
+	GetByID(id uuid.UUID) (*domain.Trekker, error)
+	GetByPassport(agencyID *uuid.UUID, nationality, passportNumber string) (*domain.Trekker, error)
+	Update(trekker *domain.Trekker) error
+	ReplaceEmergencyContacts(trekkerID uuid.UUID, contacts []domain.EmergencyContact) error
+	Delete(id uuid.UUID) error
+	List(limit, offset int, scope Scope, filter TrekkerFilter) ([]domain.Trekker, int64, error)
+}
+
+type trekkerRepository struct {
+	db *gorm.DB
+}
+
+func NewTrekkerRepository(db *gorm.DB) TrekkerRepository {
+	return &trekkerRepository{db: db}
+}
+
+func (r *trekkerRepository) Create(trekker *domain.Trekker) error {
+	return r.db.Create(trekker).Error
+}
+
+func (r *trekkerRepository) GetByID(id uuid.UUID) (*domain.Trekker, error) {
+	var trekker domain.Trekker
+	err := r.db.Preload("Agency").Preload("EmergencyContacts").Where("id = ?", id).First(&trekker).Error
+	if err != nil {
+		return nil, err
+	}
+	return &trekker, nil
+}
+
+// GetByPassport finds a trekker registered by the agency, or without an
+// agency when agencyID is nil. Each agency keeps its own trekker records.
+func (r *trekkerRepository) GetByPassport(agencyID *uuid.UUID, nationality, passportNumber string) (*domain.Trekker, error) {
+	var trekker domain.Trekker
+	err := r.db.Preload("Agency").Preload("EmergencyContacts").
+		Where("agency_id IS NOT DISTINCT FROM ? AND nationality = ? AND passport_number = ?", agencyID, nationality, passportNumber).
+		First(&trekker).Error
+	if err != nil {
+		return nil, err
+	}
+	return &trekker, nil
+}
+
+func (r *trekkerRepository) Update(trekker *domain.Trekker) error {
+	return r.db.Omit("EmergencyContacts").Save(trekker).Error
+}
+
+// ReplaceEmergencyContacts swaps the trekker's emergency contacts for the
+// given list in a single transaction.
+func (r *trekkerRepository) ReplaceEmergencyContacts(trekkerID uuid.UUID, contacts []domain.EmergencyContact) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("trekker_id = ?", trekkerID).Delete(&domain.EmergencyContact{}).Error; err != nil {
+			return err
+		}
+		if len(contacts) == 0 {
+			return nil
+		}
+		for i := range contacts {
+			contacts[i].TrekkerID = trekkerID
+		}
+		return tx.Create(&contacts).Error
+	})
+}
+
+func (r *trekkerRepository) Delete(id uuid.UUID) error {
+	return r.db.Delete(&domain.Trekker{}, id).Error
+}
+
+// List returns trekkers by name. A guide scope admits the trekkers the guide
+// registered and those on the guide's permits.
+func (r *trekkerRepository) List(limit, offset int, scope Scope, filter TrekkerFilter) ([]domain.Trekker, int64, error) {
+	var trekkers []domain.Trekker
+	var total int64
+
+	query := r.db.Model(&domain.Trekker{}).Preload("Agency").Preload("EmergencyContacts")
+	if scope.AgencyID != nil {
+		query = query.Where("trekkers.agency_id = ?", *scope.AgencyID)
+	}
+	if scope.GuideID != nil {
+		query = query.Where("(trekkers.created_by = ? OR trekkers.id IN (SELECT permits.trekker_id FROM permits WHERE permits.guide_id = ? AND permits.deleted_at IS NULL) OR trekkers.id IN (SELECT permit_members.trekker_id FROM permit_members JOIN permits ON permits.id = permit_members.permit_id WHERE permits.guide_id = ? AND permits.deleted_at IS NULL))",
+			scope.UserID, *scope.GuideID, *scope.GuideID)
+	}
+	if q := strings.TrimSpace(filter.Query); q != "" {
+		like := "%" + q + "%"
+		query = query.Where("full_name ILIKE ? OR email ILIKE ? OR passport_number ILIKE ?", like, like, like)
+	}
+	if filter.PassportNumber != "" {
+		query = query.Where("passport_number = ?", filter.PassportNumber)
+	}
+	if filter.Nationality != "" {
+		query = query.Where("nationality = ?", filter.Nationality)
+	}
+	if filter.AgencyID != nil {
+		query = query.Where("agency_id = ?", *filter.AgencyID)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Limit(limit).Offset(offset).Order("full_name").Find(&trekkers).Error
+	return trekkers, total, err
+}
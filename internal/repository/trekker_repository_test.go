@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/touros-platform/api/internal/domain"
+	"gorm.io/gorm"
+)
+
+func TestTrekkerPassportIsUniquePerAgency(t *testing.T) {
+	db := testDB(t)
+	f := fixtures{t: t, db: db}
+	trekkers := NewTrekkerRepository(db)
+	first := f.agency()
+	second := f.agency()
+
+	register := func(agencyID *uuid.UUID) (*domain.Trekker, error) {
+		trekker := &domain.Trekker{
+			FullName:       "Test Trekker",
+			PassportNumber: "P1234567",
+			Nationality:    "GB",
+			AgencyID:       agencyID,
+		}
+		// Each attempt runs in a savepoint, so a refused insert does not
+		// abort the test transaction.
+		return trekker, db.Transaction(func(tx *gorm.DB) error {
+			return NewTrekkerRepository(tx).Create(trekker)
+		})
+	}
+
+	mine, err := register(&first.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := register(&first.ID); err == nil {
+		t.Fatal("agency registered the same passport twice")
+	}
+	theirs, err := register(&second.ID)
+	if err != nil {
+		t.Fatalf("second agency registering the same passport: %v", err)
+	}
+	if _, err := register(nil); err != nil {
+		t.Fatalf("trekker without an agency: %v", err)
+	}
+	if _, err := register(nil); err == nil {
+		t.Fatal("the same passport was registered twice without an agency")
+	}
+
+	for _, tt := range []struct {
+		agencyID *uuid.UUID
+		want     uuid.UUID
+	}{
+		{&first.ID, mine.ID},
+		{&second.ID, theirs.ID},
+	} {
+		found, err := trekkers.GetByPassport(tt.agencyID, "GB", "P1234567")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if found.ID != tt.want {
+			t.Fatalf("GetByPassport for agency %s found trekker %s, want %s", tt.agencyID, found.ID, tt.want)
+		}
+	}
+	third := f.agency()
+	if _, err := trekkers.GetByPassport(&third.ID, "GB", "P1234567"); err == nil {
+		t.Fatal("GetByPassport found another agency's trekker")
+	}
+}
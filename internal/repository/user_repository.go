@@ -12,7 +12,8 @@ type UserRepository interface {
 	GetByEmail(email string) (*domain.User, error)
 	Update(user *domain.User) error
 	Delete(id uuid.UUID) error
-	List(limit, offset int) ([]domain.User, int64, error)
+	List(limit, offset int, role *domain.Role, active *bool) ([]domain.User, int64, error)
+	ListByAgency(agencyID uuid.UUID) ([]domain.User, error)
 }
 
 type userRepository struct {
@@ -46,22 +47,38 @@ func (r *userRepository) GetByEmail(email string) (*domain.User, error) {
 }
 
 func (r *userRepository) Update(user *domain.User) error {
-	return r.db.Save(user).Error
+	return r.db.Omit("Agency").Save(user).Error
 }
 
 func (r *userRepository) Delete(id uuid.UUID) error {
 	return r.db.Delete(&domain.User{}, id).Error
 }
 
-func (r *userRepository) List(limit, offset int) ([]domain.User, int64, error) {
+func (r *userRepository) List(limit, offset int, role *domain.Role, active *bool) ([]domain.User, int64, error) {
 	var users []domain.User
 	var total int64
 
-	if err := r.db.Model(&domain.User{}).Count(&total).Error; err != nil {
+	query := r.db.Model(&domain.User{}).Preload("Agency")
+	if role != nil {
+		query = query.Where("role = ?", *role)
+	}
+	if active != nil {
+		query = query.Where("is_active = ?", *active)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
-	err := r.db.Preload("Agency").Limit(limit).Offset(offset).Find(&users).Error
+	err := query.Limit(limit).Offset(offset).Order("created_at DESC").Find(&users).Error
 	return users, total, err
 }
 
+// ListByAgency returns the agency's users, oldest first.
+func (r *userRepository) ListByAgency(agencyID uuid.UUID) ([]domain.User, error) {
+	var users []domain.User
+	err := r.db.Where("agency_id = ? AND role = ?", agencyID, domain.RoleAgency).
+		Order("created_at").
+		Find(&users).Error
+	return users, err
+}
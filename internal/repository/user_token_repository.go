@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/touros-platform/api/internal/domain"
+	"gorm.io/gorm"
+)
+
+type UserTokenRepository interface {
+	Create(token *domain.UserToken) error
+	GetByHash(tokenHash string, purpose domain.TokenPurpose) (*domain.UserToken, error)
+	MarkUsed(id uuid.UUID) (bool, error)
+	InvalidateForUser(userID uuid.UUID, purpose domain.TokenPurpose) error
+	DeleteExpired(before time.Time) (int64, error)
+}
+
+type userTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewUserTokenRepository(db *gorm.DB) UserTokenRepository {
+	return &userTokenRepository{db: db}
+}
+
+func (r *userTokenRepository) Create(token *domain.UserToken) error {
+	return r.db.Create(token).Error
+}
+
+func (r *userTokenRepository) GetByHash(tokenHash string, purpose domain.TokenPurpose) (*domain.UserToken, error) {
+	var token domain.UserToken
+	err := r.db.Where("token_hash = ? AND purpose = ?", tokenHash, purpose).First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// MarkUsed consumes the token. It reports false when the token had already
+// been used, so that two concurrent requests cannot both redeem it.
+func (r *userTokenRepository) MarkUsed(id uuid.UUID) (bool, error) {
+	result := r.db.Model(&domain.UserToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	return result.RowsAffected == 1, result.Error
+}
+
+// InvalidateForUser consumes every outstanding token of the purpose, so that
+// only the most recently sent link works.
+func (r *userTokenRepository) InvalidateForUser(userID uuid.UUID, purpose domain.TokenPurpose) error {
+	return r.db.Model(&domain.UserToken{}).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+		Update("used_at", time.Now()).Error
+}
+
+// DeleteExpired deletes tokens that expired before the given time, used or
+// not.
+func (r *userTokenRepository) DeleteExpired(before time.Time) (int64, error) {
+	result := r.db.Where("expires_at < ?", before).Delete(&domain.UserToken{})
+	return result.RowsAffected, result.Error
+}
@@ -13,9 +13,25 @@ func SetupRouter(
 	cfg *config.Config,
 	logger *zap.Logger,
 	authService service.AuthService,
+	accountService service.AccountService,
+	identityService service.IdentityService,
+	serviceAccountService service.ServiceAccountService,
 	guideHandler *handler.GuideHandler,
+	guideCertificationHandler *handler.GuideCertificationHandler,
+	guideAvailabilityHandler *handler.GuideAvailabilityHandler,
 	agencyHandler *handler.AgencyHandler,
+	agencyDocumentHandler *handler.AgencyDocumentHandler,
+	licenseHandler *handler.LicenseHandler,
+	agencyMemberHandler *handler.AgencyMemberHandler,
 	permitHandler *handler.PermitHandler,
+	trekkerHandler *handler.TrekkerHandler,
+	routeHandler *handler.RouteHandler,
+	checkpointHandler *handler.CheckpointHandler,
+	quotaHandler *handler.QuotaHandler,
+	userHandler *handler.UserHandler,
+	mfaHandler *handler.MFAHandler,
+	oidcHandler *handler.OIDCHandler,
+	serviceAccountHandler *handler.ServiceAccountHandler,
 	safetyHandler *handler.SafetyHandler,
 	healthHandler *handler.HealthHandler,
 ) *gin.Engine {
@@ -33,27 +49,98 @@ func SetupRouter(
 	r.GET("/ready", healthHandler.Ready)
 	SetupMetrics(r)
 
+	authHandler := handler.NewAuthHandler(authService, accountService)
+	r.GET("/.well-known/jwks.json", authHandler.JWKS)
+
 	auth := r.Group("/api/v1/auth")
 	{
-		authHandler := handler.NewAuthHandler(authService)
 		auth.POST("/login", authHandler.Login)
 		auth.POST("/refresh", authHandler.RefreshToken)
+		auth.POST("/mfa/verify", authHandler.VerifyMFA)
+		auth.POST("/mfa/enroll", authHandler.EnrollMFA)
+		auth.POST("/mfa/enroll/confirm", authHandler.ConfirmMFAEnrollment)
+		auth.POST("/forgot-password", authHandler.ForgotPassword)
+		auth.POST("/reset-password", authHandler.ResetPassword)
+		auth.POST("/verify-email", authHandler.VerifyEmail)
+		auth.POST("/resend-verification", authHandler.ResendVerification)
+		auth.POST("/accept-invitation", agencyMemberHandler.AcceptInvitation)
+		auth.GET("/oidc/providers", oidcHandler.Providers)
+		auth.POST("/oidc/:provider/authorize", oidcHandler.Authorize)
+		auth.POST("/oidc/:provider/callback", oidcHandler.Callback)
+		auth.POST("/logout", middleware.AuthMiddleware(authService, nil), authHandler.Logout)
+		auth.POST("/logout-all", middleware.AuthMiddleware(authService, nil), authHandler.LogoutAll)
 	}
 
 	api := r.Group("/api/v1")
-	api.Use(middleware.AuthMiddleware(authService))
+	api.Use(middleware.AuthMiddleware(authService, serviceAccountService))
+	api.Use(middleware.IdentityMiddleware(identityService))
 	{
+		me := api.Group("/me")
+		me.Use(middleware.RequireUser())
+		{
+			me.GET("", userHandler.Me)
+			me.PUT("", userHandler.UpdateMe)
+			me.PUT("/password", userHandler.ChangePassword)
+			me.GET("/mfa", mfaHandler.Status)
+			me.POST("/mfa/enroll", mfaHandler.Enroll)
+			me.POST("/mfa/confirm", mfaHandler.Confirm)
+			me.POST("/mfa/disable", mfaHandler.Disable)
+			me.POST("/mfa/recovery-codes", mfaHandler.RegenerateRecoveryCodes)
+		}
+
+		users := api.Group("/users")
+		users.Use(middleware.RequireRole("admin"))
+		{
+			users.POST("", userHandler.Create)
+			users.GET("", userHandler.List)
+			users.GET("/:id", userHandler.GetByID)
+			users.PUT("/:id", userHandler.Update)
+			users.DELETE("/:id", userHandler.Delete)
+			users.PUT("/:id/role", userHandler.SetRole)
+			users.POST("/:id/activate", userHandler.Activate)
+			users.POST("/:id/deactivate", userHandler.Deactivate)
+			users.POST("/:id/unlock", userHandler.Unlock)
+			users.GET("/:id/login-attempts", userHandler.LoginAttempts)
+		}
+
 		guides := api.Group("/guides")
+		guides.Use(middleware.RequireScope("guides"))
 		{
 			guides.POST("", guideHandler.Create)
 			guides.GET("", guideHandler.List)
+			guides.GET("/available", guideAvailabilityHandler.Available)
 			guides.GET("/:id", guideHandler.GetByID)
 			guides.PUT("/:id", guideHandler.Update)
 			guides.POST("/:id/verify", middleware.RequireRole("admin"), guideHandler.Verify)
 			guides.POST("/:id/suspend", middleware.RequireRole("admin"), guideHandler.Suspend)
+
+			guides.POST("/:id/certifications", guideCertificationHandler.Upload)
+			guides.GET("/:id/certifications", guideCertificationHandler.List)
+			guides.GET("/:id/certifications/:cert_id/download", guideCertificationHandler.Download)
+			guides.DELETE("/:id/certifications/:cert_id", guideCertificationHandler.Delete)
+			guides.POST("/:id/certifications/:cert_id/approve", middleware.RequireRole("admin"), guideCertificationHandler.Approve)
+			guides.POST("/:id/certifications/:cert_id/reject", middleware.RequireRole("admin"), guideCertificationHandler.Reject)
+
+			guides.GET("/:id/calendar", guideAvailabilityHandler.Calendar)
+			guides.POST("/:id/blocked-periods", guideAvailabilityHandler.Block)
+			guides.DELETE("/:id/blocked-periods/:period_id", guideAvailabilityHandler.Unblock)
+		}
+
+		serviceAccounts := api.Group("/service-accounts")
+		serviceAccounts.Use(middleware.RequireUser(), middleware.RequireRole("admin", "agency"))
+		{
+			serviceAccounts.POST("", serviceAccountHandler.Create)
+			serviceAccounts.GET("", serviceAccountHandler.List)
+			serviceAccounts.GET("/:id", serviceAccountHandler.GetByID)
+			serviceAccounts.DELETE("/:id", serviceAccountHandler.Delete)
+			serviceAccounts.POST("/:id/keys", serviceAccountHandler.CreateKey)
+			serviceAccounts.GET("/:id/keys", serviceAccountHandler.ListKeys)
+			serviceAccounts.POST("/:id/keys/:key_id/rotate", serviceAccountHandler.RotateKey)
+			serviceAccounts.DELETE("/:id/keys/:key_id", serviceAccountHandler.RevokeKey)
 		}
 
 		agencies := api.Group("/agencies")
+		agencies.Use(middleware.RequireUser())
 		{
 			agencies.POST("", agencyHandler.Create)
 			agencies.GET("", agencyHandler.List)
@@ -61,22 +148,100 @@ func SetupRouter(
 			agencies.PUT("/:id", agencyHandler.Update)
 			agencies.POST("/:id/verify", middleware.RequireRole("admin"), agencyHandler.Verify)
 			agencies.POST("/:id/suspend", middleware.RequireRole("admin"), agencyHandler.Suspend)
+			agencies.POST("/:id/reject", middleware.RequireRole("admin"), agencyHandler.Reject)
+
+			agencies.POST("/:id/documents", agencyDocumentHandler.Upload)
+			agencies.GET("/:id/documents", agencyDocumentHandler.List)
+			agencies.GET("/:id/documents/checklist", agencyDocumentHandler.Checklist)
+			agencies.GET("/:id/documents/:doc_id/download", agencyDocumentHandler.Download)
+			agencies.POST("/:id/documents/:doc_id/approve", middleware.RequireRole("admin"), agencyDocumentHandler.Approve)
+			agencies.POST("/:id/documents/:doc_id/reject", middleware.RequireRole("admin"), agencyDocumentHandler.Reject)
+
+			agencies.GET("/:id/members", agencyMemberHandler.List)
+			agencies.POST("/:id/members/invitations", agencyMemberHandler.Invite)
+			agencies.GET("/:id/members/invitations", agencyMemberHandler.ListInvitations)
+			agencies.DELETE("/:id/members/invitations/:invitation_id", agencyMemberHandler.RevokeInvitation)
+			agencies.PUT("/:id/members/:user_id/role", agencyMemberHandler.SetRole)
+			agencies.POST("/:id/members/:user_id/activate", agencyMemberHandler.Activate)
+			agencies.POST("/:id/members/:user_id/deactivate", agencyMemberHandler.Deactivate)
+			agencies.DELETE("/:id/members/:user_id", agencyMemberHandler.Remove)
+		}
+
+		licenses := api.Group("/licenses")
+		licenses.Use(middleware.RequireRole("admin"))
+		{
+			licenses.GET("/expiring", licenseHandler.Expiring)
 		}
 
 		permits := api.Group("/permits")
+		permits.Use(middleware.RequireScope("permits"))
 		{
 			permits.POST("", permitHandler.Create)
 			permits.GET("", permitHandler.List)
 			permits.GET("/:id", permitHandler.GetByID)
+			permits.GET("/:id/qr.png", permitHandler.QRCodePNG)
+			permits.GET("/:id/qr.svg", permitHandler.QRCodeSVG)
+			permits.GET("/:id/document.pdf", permitHandler.DocumentPDF)
 			permits.POST("/:id/revoke", middleware.RequireRole("admin"), permitHandler.Revoke)
+			permits.GET("/:id/members", permitHandler.ListMembers)
+			permits.POST("/:id/members", permitHandler.AddMember)
+			permits.DELETE("/:id/members/:member_id", permitHandler.WithdrawMember)
+			permits.PUT("/:id/members/:member_id/status", permitHandler.SetMemberStatus)
+			permits.GET("/:id/scans", checkpointHandler.PermitScans)
+		}
+
+		routes := api.Group("/routes")
+		routes.Use(middleware.RequireScope("catalog"))
+		{
+			routes.GET("", routeHandler.List)
+			routes.GET("/:id", routeHandler.GetByID)
+			routes.POST("", middleware.RequireRole("admin"), routeHandler.Create)
+			routes.PUT("/:id", middleware.RequireRole("admin"), routeHandler.Update)
+			routes.DELETE("/:id", middleware.RequireRole("admin"), routeHandler.Delete)
+		}
+
+		quotas := api.Group("/quotas")
+		quotas.Use(middleware.RequireScope("catalog"))
+		{
+			quotas.GET("", quotaHandler.List)
+			quotas.GET("/availability", quotaHandler.Availability)
+			quotas.GET("/:id", quotaHandler.GetByID)
+			quotas.POST("", middleware.RequireRole("admin"), quotaHandler.Create)
+			quotas.PUT("/:id", middleware.RequireRole("admin"), quotaHandler.Update)
+			quotas.DELETE("/:id", middleware.RequireRole("admin"), quotaHandler.Delete)
+		}
+
+		trekkers := api.Group("/trekkers")
+		trekkers.Use(middleware.RequireScope("trekkers"))
+		{
+			trekkers.POST("", trekkerHandler.Create)
+			trekkers.GET("", trekkerHandler.List)
+			trekkers.GET("/:id", trekkerHandler.GetByID)
+			trekkers.PUT("/:id", trekkerHandler.Update)
+			trekkers.DELETE("/:id", middleware.RequireRole("admin"), trekkerHandler.Delete)
+			trekkers.GET("/:id/permits", trekkerHandler.ListPermits)
+		}
+
+		checkpoints := api.Group("/checkpoints")
+		checkpoints.Use(middleware.RequireScope("catalog"))
+		{
+			checkpoints.GET("", checkpointHandler.List)
+			checkpoints.GET("/:id", checkpointHandler.GetByID)
+			checkpoints.POST("", middleware.RequireRole("admin", "officer"), checkpointHandler.Register)
+			checkpoints.PUT("/:id", middleware.RequireRole("admin", "officer"), checkpointHandler.Update)
+			checkpoints.POST("/:id/scans", middleware.RequireRole("admin", "officer"), checkpointHandler.RecordScan)
+			checkpoints.GET("/:id/scans", middleware.RequireRole("admin", "officer"), checkpointHandler.ListScans)
 		}
 
 		permitsPublic := r.Group("/api/v1/permits")
 		{
 			permitsPublic.GET("/validate/:number", permitHandler.Validate)
+			permitsPublic.POST("/verify", permitHandler.Verify)
+			permitsPublic.GET("/keys", permitHandler.PublicKeys)
 		}
 
 		safety := api.Group("/safety")
+		safety.Use(middleware.RequireScope("incidents"))
 		{
 			safety.POST("/check-ins", safetyHandler.CreateCheckIn)
 			safety.GET("/check-ins/:id", safetyHandler.GetCheckInByID)
@@ -87,6 +252,9 @@ func SetupRouter(
 			safety.GET("/incidents/:id", safetyHandler.GetIncidentByID)
 			safety.PUT("/incidents/:id", safetyHandler.UpdateIncident)
 			safety.GET("/guides/:guide_id/sos", safetyHandler.GetActiveSOS)
+
+			safety.GET("/stream", safetyHandler.Stream)
+			safety.GET("/ws", safetyHandler.WebSocket)
 		}
 	}
 
@@ -0,0 +1,83 @@
+// Package scheduler runs background jobs at fixed intervals for the life of
+// the process.
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type job struct {
+	name     string
+	interval time.Duration
+	run      func() error
+}
+
+// Scheduler runs each registered job once at start and then every interval.
+// A job never overlaps with itself: the next run waits for the previous one
+// to finish. Jobs must tolerate running on several instances at once.
+type Scheduler struct {
+	logger *zap.Logger
+	jobs   []job
+	wg     sync.WaitGroup
+}
+
+func New(logger *zap.Logger) *Scheduler {
+	return &Scheduler{logger: logger}
+}
+
+// Every registers run to be called every interval. Register jobs before
+// calling Start.
+func (s *Scheduler) Every(name string, interval time.Duration, run func() error) {
+	s.jobs = append(s.jobs, job{name: name, interval: interval, run: run})
+}
+
+// Start runs the jobs in the background until ctx is done.
+func (s *Scheduler) Start(ctx context.Context) {
+	for _, j := range s.jobs {
+		s.logger.Info("Scheduled job", zap.String("job", j.name), zap.Duration("interval", j.interval))
+		s.wg.Add(1)
+		go s.loop(ctx, j)
+	}
+}
+
+// Wait blocks until every job has stopped after ctx is done, letting a run
+// in progress finish.
+func (s *Scheduler) Wait() {
+	s.wg.Wait()
+}
+
+func (s *Scheduler) loop(ctx context.Context, j job) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		s.runOnce(j)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) runOnce(j job) {
+	started := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			s.logger.Error("Scheduled job panicked", zap.String("job", j.name), zap.Any("panic", r))
+		}
+	}()
+
+	if err := j.run(); err != nil {
+		s.logger.Error("Scheduled job failed", zap.String("job", j.name), zap.Error(err))
+		return
+	}
+	s.logger.Debug("Scheduled job finished", zap.String("job", j.name), zap.Duration("duration", time.Since(started)))
+}
@@ -0,0 +1,243 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/touros-platform/api/internal/config"
+	"github.com/touros-platform/api/internal/domain"
+	"github.com/touros-platform/api/internal/mailer"
+	"github.com/touros-platform/api/internal/repository"
+	"go.uber.org/zap"
+)
+
+var errInvalidUserToken = errors.New("invalid or expired token")
+
+// AccountService runs the emailed account flows: password reset and email
+// verification. Links carry a random token whose SHA-256 hash is stored, so
+// a database leak does not expose usable tokens.
+type AccountService interface {
+	ForgotPassword(email string) error
+	ResetPassword(token, newPassword string) error
+	SendVerification(user *domain.User) error
+	ResendVerification(email string) error
+	VerifyEmail(token string) error
+}
+
+type accountService struct {
+	userRepo      repository.UserRepository
+	userTokenRepo repository.UserTokenRepository
+	tokenRepo     repository.TokenRepository
+	mailer        mailer.Mailer
+	config        config.AccountConfig
+	logger        *zap.Logger
+}
+
+func NewAccountService(
+	userRepo repository.UserRepository,
+	userTokenRepo repository.UserTokenRepository,
+	tokenRepo repository.TokenRepository,
+	mail mailer.Mailer,
+	cfg config.AccountConfig,
+	logger *zap.Logger,
+) AccountService {
+	return &accountService{
+		userRepo:      userRepo,
+		userTokenRepo: userTokenRepo,
+		tokenRepo:     tokenRepo,
+		mailer:        mail,
+		config:        cfg,
+		logger:        logger,
+	}
+}
+
+// ForgotPassword emails a reset link to the account with this address. It
+// succeeds whether or not such an account exists, so callers cannot probe
+// for registered addresses.
+func (s *accountService) ForgotPassword(email string) error {
+	user, err := s.userRepo.GetByEmail(strings.TrimSpace(email))
+	if err != nil || !user.IsActive {
+		return nil
+	}
+
+	token, err := s.issueToken(user, domain.TokenPurposePasswordReset, s.config.PasswordResetTTL)
+	if err != nil {
+		return err
+	}
+
+	s.send(&mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your Touros password",
+		Body: fmt.Sprintf("Hello %s,\n\n"+
+			"Someone asked to reset the password for your Touros account. To choose a new password, open:\n\n"+
+			"%s\n\n"+
+			"The link expires in %s and can be used once. If you did not ask for this, ignore this email; "+
+			"your password has not changed.\n",
+			user.FullName, s.link("/reset-password", token), s.config.PasswordResetTTL),
+	})
+	return nil
+}
+
+// ResetPassword sets a new password using an emailed reset token. Following
+// the link also proves the user controls the address, so it is marked
+// verified. Every existing session is ended.
+func (s *accountService) ResetPassword(token, newPassword string) error {
+	if len(newPassword) < minPasswordLength {
+		return fmt.Errorf("password must be at least %d characters", minPasswordLength)
+	}
+
+	record, err := s.redeemToken(token, domain.TokenPurposePasswordReset)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.GetByID(record.UserID)
+	if err != nil || !user.IsActive {
+		return errInvalidUserToken
+	}
+
+	hash, err := HashPassword(newPassword)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+	user.PasswordHash = hash
+	if user.EmailVerifiedAt == nil {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+	}
+
+	if err := s.userRepo.Update(user); err != nil {
+		return err
+	}
+
+	if err := s.tokenRepo.RevokeAllForUser(user.ID, domain.TokenRevokedLogoutAll); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	return nil
+}
+
+// SendVerification emails a verification link to a newly created user.
+func (s *accountService) SendVerification(user *domain.User) error {
+	token, err := s.issueToken(user, domain.TokenPurposeEmailVerification, s.config.EmailVerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	s.send(&mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your Touros email address",
+		Body: fmt.Sprintf("Hello %s,\n\n"+
+			"A Touros account has been created for this address. To confirm it and activate your login, open:\n\n"+
+			"%s\n\n"+
+			"The link expires in %s.\n",
+			user.FullName, s.link("/verify-email", token), s.config.EmailVerificationTTL),
+	})
+	return nil
+}
+
+// ResendVerification sends a fresh verification link. Like ForgotPassword it
+// does not reveal whether the address is registered.
+func (s *accountService) ResendVerification(email string) error {
+	user, err := s.userRepo.GetByEmail(strings.TrimSpace(email))
+	if err != nil || !user.IsActive || user.EmailVerifiedAt != nil {
+		return nil
+	}
+	return s.SendVerification(user)
+}
+
+func (s *accountService) VerifyEmail(token string) error {
+	record, err := s.redeemToken(token, domain.TokenPurposeEmailVerification)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.GetByID(record.UserID)
+	if err != nil {
+		return errInvalidUserToken
+	}
+
+	if user.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	now := time.Now()
+	user.EmailVerifiedAt = &now
+	return s.userRepo.Update(user)
+}
+
+// issueToken stores a new token for the user, replacing any outstanding one
+// of the same purpose, and returns it in plain form for the email link.
+func (s *accountService) issueToken(user *domain.User, purpose domain.TokenPurpose, ttl time.Duration) (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(buf)
+
+	if err := s.userTokenRepo.InvalidateForUser(user.ID, purpose); err != nil {
+		return "", fmt.Errorf("failed to invalidate previous tokens: %w", err)
+	}
+
+	record := &domain.UserToken{
+		UserID:    user.ID,
+		Purpose:   purpose,
+		TokenHash: hashUserToken(token),
+		ExpiresAt: time.Now().Add(ttl),
+	}
+	if err := s.userTokenRepo.Create(record); err != nil {
+		return "", fmt.Errorf("failed to store token: %w", err)
+	}
+
+	return token, nil
+}
+
+// redeemToken looks up and consumes an emailed token.
+func (s *accountService) redeemToken(token string, purpose domain.TokenPurpose) (*domain.UserToken, error) {
+	record, err := s.userTokenRepo.GetByHash(hashUserToken(token), purpose)
+	if err != nil {
+		return nil, errInvalidUserToken
+	}
+
+	if record.UsedAt != nil || time.Now().After(record.ExpiresAt) {
+		return nil, errInvalidUserToken
+	}
+
+	used, err := s.userTokenRepo.MarkUsed(record.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !used {
+		return nil, errInvalidUserToken
+	}
+
+	return record, nil
+}
+
+// send delivers mail without failing the request; delivery problems are
+// logged and the user can ask for another link.
+func (s *accountService) send(msg *mailer.Message) {
+	if err := s.mailer.Send(msg); err != nil {
+		s.logger.Error("Failed to send email",
+			zap.String("to", msg.To),
+			zap.String("subject", msg.Subject),
+			zap.Error(err),
+		)
+	}
+}
+
+func (s *accountService) link(path, token string) string {
+	return strings.TrimRight(s.config.PublicURL, "/") + path + "?token=" + url.QueryEscape(token)
+}
+
+func hashUserToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
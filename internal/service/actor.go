@@ -0,0 +1,192 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/touros-platform/api/internal/domain"
+	"github.com/touros-platform/api/internal/repository"
+)
+
+// ErrForbidden is returned when the actor may not act on a record. Reads of
+// records outside the actor's scope report them as not found instead, so
+// that one agency cannot probe for another's records.
+var ErrForbidden = errors.New("access denied")
+
+// Actor is the authenticated user a service call is made for. GuideID is
+// the guide profile of a guide, filled in by IdentityService. A service
+// account acts as an agency user whose UserID is the account's ID, limited
+// to the Scopes of the API key it authenticated with.
+type Actor struct {
+	UserID         uuid.UUID
+	Role           domain.Role
+	AgencyID       *uuid.UUID
+	GuideID        *uuid.UUID
+	ServiceAccount bool
+	Scopes         []string
+}
+
+// Actor returns the user the token was issued to, without the guide
+// profile; use IdentityService.Resolve for the complete identity.
+func (c *Claims) Actor() *Actor {
+	return &Actor{
+		UserID:   c.UserID,
+		Role:     c.Role,
+		AgencyID: c.AgencyID,
+	}
+}
+
+// HasScope reports whether the actor may use scope. Users authenticated
+// with a password are not limited by scopes.
+func (a *Actor) HasScope(scope string) bool {
+	if !a.ServiceAccount {
+		return true
+	}
+	for _, s := range a.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Unrestricted reports whether the actor sees every agency's records.
+// Officers staff the checkpoints and validate permits from any agency.
+func (a *Actor) Unrestricted() bool {
+	return a.Role == domain.RoleAdmin || a.Role == domain.RoleOfficer
+}
+
+// authorizer decides which guides, permits, incidents and trekkers an actor
+// may see. Agency users are limited to their agency's records and guides to
+// their own.
+type authorizer struct {
+	guideRepo  repository.GuideRepository
+	permitRepo repository.PermitRepository
+}
+
+func newAuthorizer(guideRepo repository.GuideRepository, permitRepo repository.PermitRepository) *authorizer {
+	return &authorizer{
+		guideRepo:  guideRepo,
+		permitRepo: permitRepo,
+	}
+}
+
+// scope returns the repository scope for the actor's list queries.
+func (a *authorizer) scope(actor *Actor) (repository.Scope, error) {
+	if actor.Unrestricted() {
+		return repository.Scope{}, nil
+	}
+
+	switch actor.Role {
+	case domain.RoleAgency:
+		if actor.AgencyID == nil {
+			return repository.Scope{}, errors.New("user is not assigned to an agency")
+		}
+		return repository.Scope{AgencyID: actor.AgencyID}, nil
+	case domain.RoleGuide:
+		// A guide without a profile yet sees no guides, permits or
+		// incidents, only the trekkers they registered.
+		guideID := uuid.Nil
+		if actor.GuideID != nil {
+			guideID = *actor.GuideID
+		}
+		return repository.Scope{GuideID: &guideID, UserID: &actor.UserID}, nil
+	}
+	return repository.Scope{}, ErrForbidden
+}
+
+// isOwnGuide reports whether guideID is the actor's own guide profile.
+func isOwnGuide(actor *Actor, guideID uuid.UUID) bool {
+	return actor.Role == domain.RoleGuide && actor.GuideID != nil && *actor.GuideID == guideID
+}
+
+func (a *authorizer) checkGuide(actor *Actor, guide *domain.Guide) error {
+	if actor.Unrestricted() {
+		return nil
+	}
+	switch actor.Role {
+	case domain.RoleAgency:
+		if sameAgency(actor.AgencyID, guide.AgencyID) {
+			return nil
+		}
+	case domain.RoleGuide:
+		if isOwnGuide(actor, guide.ID) {
+			return nil
+		}
+	}
+	return ErrForbidden
+}
+
+func (a *authorizer) checkPermit(actor *Actor, permit *domain.Permit) error {
+	if actor.Unrestricted() {
+		return nil
+	}
+	switch actor.Role {
+	case domain.RoleAgency:
+		if sameAgency(actor.AgencyID, permit.AgencyID) {
+			return nil
+		}
+	case domain.RoleGuide:
+		if isOwnGuide(actor, permit.GuideID) {
+			return nil
+		}
+	}
+	return ErrForbidden
+}
+
+// checkIncident admits an agency to incidents of its guides and incidents
+// on its permits.
+func (a *authorizer) checkIncident(actor *Actor, incident *domain.Incident) error {
+	if actor.Unrestricted() {
+		return nil
+	}
+	switch actor.Role {
+	case domain.RoleAgency:
+		if guide, err := a.guideRepo.GetByID(incident.GuideID); err == nil && sameAgency(actor.AgencyID, guide.AgencyID) {
+			return nil
+		}
+		if incident.PermitID != nil {
+			if permit, err := a.permitRepo.GetByID(*incident.PermitID); err == nil && sameAgency(actor.AgencyID, permit.AgencyID) {
+				return nil
+			}
+		}
+	case domain.RoleGuide:
+		if isOwnGuide(actor, incident.GuideID) {
+			return nil
+		}
+	}
+	return ErrForbidden
+}
+
+// checkTrekker admits an agency to its own trekkers, and a guide to the
+// trekkers they registered or lead on a permit. Trekker records are not
+// shared between agencies: a traveller booked through two agencies has a
+// record in each, so an agency never needs another agency's record.
+func (a *authorizer) checkTrekker(actor *Actor, trekker *domain.Trekker) error {
+	if actor.Unrestricted() {
+		return nil
+	}
+	switch actor.Role {
+	case domain.RoleAgency:
+		if sameAgency(actor.AgencyID, trekker.AgencyID) {
+			return nil
+		}
+	case domain.RoleGuide:
+		if trekker.CreatedBy != nil && *trekker.CreatedBy == actor.UserID {
+			return nil
+		}
+		scope, err := a.scope(actor)
+		if err != nil {
+			return err
+		}
+		_, total, err := a.permitRepo.ListByTrekkerID(trekker.ID, scope, 1, 0)
+		if err == nil && total > 0 {
+			return nil
+		}
+	}
+	return ErrForbidden
+}
+
+func sameAgency(agencyID, other *uuid.UUID) bool {
+	return agencyID != nil && other != nil && *agencyID == *other
+}
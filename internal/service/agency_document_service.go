@@ -0,0 +1,230 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/touros-platform/api/internal/config"
+	"github.com/touros-platform/api/internal/domain"
+	"github.com/touros-platform/api/internal/repository"
+	"github.com/touros-platform/api/internal/storage"
+)
+
+type AgencyDocumentService interface {
+	Upload(actor *Actor, agencyID uuid.UUID, docType domain.AgencyDocumentType, fileName string, body io.Reader, size int64) (*domain.AgencyDocument, error)
+	List(actor *Actor, agencyID uuid.UUID, includeSuperseded bool) ([]domain.AgencyDocument, error)
+	Checklist(actor *Actor, agencyID uuid.UUID) (*AgencyChecklist, error)
+	Open(actor *Actor, agencyID, id uuid.UUID) (*domain.AgencyDocument, io.ReadCloser, error)
+	Approve(agencyID, id, reviewedBy uuid.UUID) (*domain.AgencyDocument, error)
+	Reject(agencyID, id, reviewedBy uuid.UUID, reason string) (*domain.AgencyDocument, error)
+}
+
+// AgencyChecklist shows reviewers where each required document stands.
+// ReadyForVerification is set once every required document is approved.
+type AgencyChecklist struct {
+	AgencyID             uuid.UUID
+	AgencyStatus         domain.AgencyStatus
+	Items                []AgencyChecklistItem
+	ReadyForVerification bool
+}
+
+// AgencyChecklistItem is one required document. Status is the current
+// document's status, or "missing" when none has been uploaded.
+type AgencyChecklistItem struct {
+	Type            domain.AgencyDocumentType
+	Status          string
+	Document        *domain.AgencyDocument
+	RejectionReason string
+}
+
+const checklistMissing = "missing"
+
+type agencyDocumentService struct {
+	documentRepo repository.AgencyDocumentRepository
+	agencyRepo   repository.AgencyRepository
+	userRepo     repository.UserRepository
+	store        storage.BlobStore
+	maxSize      int64
+}
+
+func NewAgencyDocumentService(documentRepo repository.AgencyDocumentRepository, agencyRepo repository.AgencyRepository, userRepo repository.UserRepository, store storage.BlobStore, cfg config.StorageConfig) AgencyDocumentService {
+	return &agencyDocumentService{
+		documentRepo: documentRepo,
+		agencyRepo:   agencyRepo,
+		userRepo:     userRepo,
+		store:        store,
+		maxSize:      cfg.MaxUploadSize,
+	}
+}
+
+// Upload stores a document for review. It replaces the agency's current
+// document of the same type, and resubmits a rejected agency for review.
+// Only the agency's owners and managers may upload.
+func (s *agencyDocumentService) Upload(actor *Actor, agencyID uuid.UUID, docType domain.AgencyDocumentType, fileName string, body io.Reader, size int64) (*domain.AgencyDocument, error) {
+	if err := checkAgencyRole(s.userRepo, actor, agencyID, domain.AgencyRoleOwner, domain.AgencyRoleManager); err != nil {
+		return nil, err
+	}
+	if !docType.Valid() {
+		return nil, fmt.Errorf("invalid document type: %s", docType)
+	}
+
+	agency, err := s.agencyRepo.GetByID(agencyID)
+	if err != nil {
+		return nil, errors.New("agency not found")
+	}
+	if agency.Status == domain.AgencyStatusSuspended {
+		return nil, errors.New("agency is suspended")
+	}
+
+	document := &domain.AgencyDocument{
+		ID:         uuid.New(),
+		AgencyID:   agencyID,
+		Type:       docType,
+		Status:     domain.AgencyDocumentPending,
+		Size:       size,
+		UploadedBy: actor.UserID,
+	}
+	document.StorageKey = fmt.Sprintf("agencies/%s/documents/%s", agencyID, document.ID)
+
+	stored, err := storeDocument(s.store, document.StorageKey, fileName, body, size, s.maxSize)
+	if err != nil {
+		return nil, err
+	}
+	document.FileName = stored.FileName
+	document.ContentType = stored.ContentType
+	document.SHA256 = stored.SHA256
+
+	if err := s.documentRepo.Create(document); err != nil {
+		s.store.Delete(document.StorageKey)
+		return nil, err
+	}
+
+	if agency.Status == domain.AgencyStatusRejected {
+		agency.Status = domain.AgencyStatusPending
+		if err := s.agencyRepo.Update(agency); err != nil {
+			return nil, err
+		}
+	}
+
+	return document, nil
+}
+
+func (s *agencyDocumentService) List(actor *Actor, agencyID uuid.UUID, includeSuperseded bool) ([]domain.AgencyDocument, error) {
+	if err := checkAgencyMember(actor, agencyID); err != nil {
+		return nil, err
+	}
+	return s.documentRepo.ListByAgency(agencyID, includeSuperseded)
+}
+
+func (s *agencyDocumentService) Checklist(actor *Actor, agencyID uuid.UUID) (*AgencyChecklist, error) {
+	if err := checkAgencyMember(actor, agencyID); err != nil {
+		return nil, err
+	}
+
+	agency, err := s.agencyRepo.GetByID(agencyID)
+	if err != nil {
+		return nil, errors.New("agency not found")
+	}
+	documents, err := s.documentRepo.ListByAgency(agencyID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	checklist := buildAgencyChecklist(documents)
+	checklist.AgencyID = agency.ID
+	checklist.AgencyStatus = agency.Status
+	return checklist, nil
+}
+
+// Open returns the document and its file. The caller closes the file.
+func (s *agencyDocumentService) Open(actor *Actor, agencyID, id uuid.UUID) (*domain.AgencyDocument, io.ReadCloser, error) {
+	if err := checkAgencyMember(actor, agencyID); err != nil {
+		return nil, nil, err
+	}
+
+	document, err := s.documentRepo.GetByID(agencyID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	file, err := s.store.Get(document.StorageKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return document, file, nil
+}
+
+func (s *agencyDocumentService) Approve(agencyID, id, reviewedBy uuid.UUID) (*domain.AgencyDocument, error) {
+	return s.review(agencyID, id, reviewedBy, domain.AgencyDocumentApproved, "")
+}
+
+// Reject sends the document back to the agency with a reason. The agency
+// resubmits by uploading a new document of the same type.
+func (s *agencyDocumentService) Reject(agencyID, id, reviewedBy uuid.UUID, reason string) (*domain.AgencyDocument, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, errors.New("a reason is required to reject a document")
+	}
+	return s.review(agencyID, id, reviewedBy, domain.AgencyDocumentRejected, reason)
+}
+
+func (s *agencyDocumentService) review(agencyID, id, reviewedBy uuid.UUID, status domain.AgencyDocumentStatus, reason string) (*domain.AgencyDocument, error) {
+	document, err := s.documentRepo.GetByID(agencyID, id)
+	if err != nil {
+		return nil, errors.New("document not found")
+	}
+	if document.Status == domain.AgencyDocumentSuperseded {
+		return nil, errors.New("document has been replaced by a newer upload")
+	}
+
+	now := time.Now()
+	document.Status = status
+	document.RejectionReason = reason
+	document.ReviewedBy = &reviewedBy
+	document.ReviewedAt = &now
+
+	if err := s.documentRepo.Update(document); err != nil {
+		return nil, err
+	}
+	return document, nil
+}
+
+// buildAgencyChecklist matches the agency's current documents against the
+// required ones.
+func buildAgencyChecklist(documents []domain.AgencyDocument) *AgencyChecklist {
+	current := make(map[domain.AgencyDocumentType]*domain.AgencyDocument, len(documents))
+	for i := range documents {
+		if _, ok := current[documents[i].Type]; !ok {
+			current[documents[i].Type] = &documents[i]
+		}
+	}
+
+	checklist := &AgencyChecklist{ReadyForVerification: true}
+	for _, docType := range domain.RequiredAgencyDocuments {
+		item := AgencyChecklistItem{Type: docType, Status: checklistMissing}
+		if document, ok := current[docType]; ok {
+			item.Status = string(document.Status)
+			item.Document = document
+			item.RejectionReason = document.RejectionReason
+		}
+		if item.Status != string(domain.AgencyDocumentApproved) {
+			checklist.ReadyForVerification = false
+		}
+		checklist.Items = append(checklist.Items, item)
+	}
+	return checklist
+}
+
+// checkAgencyMember admits admins and the agency's own users.
+func checkAgencyMember(actor *Actor, agencyID uuid.UUID) error {
+	if actor.Role == domain.RoleAdmin {
+		return nil
+	}
+	if actor.Role == domain.RoleAgency && sameAgency(actor.AgencyID, &agencyID) {
+		return nil
+	}
+	return ErrForbidden
+}
@@ -0,0 +1,427 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/touros-platform/api/internal/config"
+	"github.com/touros-platform/api/internal/domain"
+	"github.com/touros-platform/api/internal/mailer"
+	"github.com/touros-platform/api/internal/repository"
+	"go.uber.org/zap"
+)
+
+var errInvalidInvitation = errors.New("invalid or expired invitation")
+
+// AgencyMemberService manages an agency's staff. Owners manage every member
+// and change agency roles; managers invite and manage clerks. Admins act as
+// owners of every agency.
+type AgencyMemberService interface {
+	List(actor *Actor, agencyID uuid.UUID) ([]domain.User, error)
+	Invite(actor *Actor, agencyID uuid.UUID, email string, role domain.AgencyRole) (*domain.AgencyInvitation, error)
+	ListInvitations(actor *Actor, agencyID uuid.UUID) ([]domain.AgencyInvitation, error)
+	RevokeInvitation(actor *Actor, agencyID, invitationID uuid.UUID) error
+	AcceptInvitation(token, fullName, password string) (*domain.User, error)
+	SetRole(actor *Actor, agencyID, userID uuid.UUID, role domain.AgencyRole) (*domain.User, error)
+	SetActive(actor *Actor, agencyID, userID uuid.UUID, active bool) (*domain.User, error)
+	Remove(actor *Actor, agencyID, userID uuid.UUID) error
+}
+
+type agencyMemberService struct {
+	userRepo       repository.UserRepository
+	agencyRepo     repository.AgencyRepository
+	invitationRepo repository.AgencyInvitationRepository
+	tokenRepo      repository.TokenRepository
+	mailer         mailer.Mailer
+	config         config.AccountConfig
+	logger         *zap.Logger
+}
+
+func NewAgencyMemberService(
+	userRepo repository.UserRepository,
+	agencyRepo repository.AgencyRepository,
+	invitationRepo repository.AgencyInvitationRepository,
+	tokenRepo repository.TokenRepository,
+	mail mailer.Mailer,
+	cfg config.AccountConfig,
+	logger *zap.Logger,
+) AgencyMemberService {
+	return &agencyMemberService{
+		userRepo:       userRepo,
+		agencyRepo:     agencyRepo,
+		invitationRepo: invitationRepo,
+		tokenRepo:      tokenRepo,
+		mailer:         mail,
+		config:         cfg,
+		logger:         logger,
+	}
+}
+
+// List returns the agency's users. Every member can see their colleagues.
+func (s *agencyMemberService) List(actor *Actor, agencyID uuid.UUID) ([]domain.User, error) {
+	if _, err := s.actorRole(actor, agencyID); err != nil {
+		return nil, err
+	}
+	return s.userRepo.ListByAgency(agencyID)
+}
+
+// Invite emails an invitation to join the agency with the given role.
+// Inviting the same address again replaces the earlier invitation.
+func (s *agencyMemberService) Invite(actor *Actor, agencyID uuid.UUID, email string, role domain.AgencyRole) (*domain.AgencyInvitation, error) {
+	if !role.Valid() {
+		return nil, fmt.Errorf("invalid agency role: %s", role)
+	}
+	actorRole, err := s.actorRole(actor, agencyID)
+	if err != nil {
+		return nil, err
+	}
+	if !canManage(actorRole, role) {
+		return nil, ErrForbidden
+	}
+
+	agency, err := s.agencyRepo.GetByID(agencyID)
+	if err != nil {
+		return nil, errors.New("agency not found")
+	}
+
+	email = strings.TrimSpace(email)
+	if existing, _ := s.userRepo.GetByEmail(email); existing != nil {
+		return nil, errors.New("user with this email already exists")
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(buf)
+
+	invitation := &domain.AgencyInvitation{
+		AgencyID:   agencyID,
+		Email:      email,
+		AgencyRole: role,
+		TokenHash:  hashUserToken(token),
+		InvitedBy:  actor.UserID,
+		ExpiresAt:  time.Now().Add(s.config.InvitationTTL),
+	}
+	if err := s.invitationRepo.Create(invitation); err != nil {
+		return nil, fmt.Errorf("failed to store invitation: %w", err)
+	}
+
+	s.send(&mailer.Message{
+		To:      email,
+		Subject: fmt.Sprintf("You have been invited to join %s on Touros", agency.Name),
+		Body: fmt.Sprintf("Hello,\n\n"+
+			"You have been invited to join %s on Touros as %s. To accept and set up your account, open:\n\n"+
+			"%s\n\n"+
+			"The invitation expires in %s.\n",
+			agency.Name, role, strings.TrimRight(s.config.PublicURL, "/")+"/accept-invitation?token="+url.QueryEscape(token),
+			s.config.InvitationTTL),
+	})
+
+	return invitation, nil
+}
+
+func (s *agencyMemberService) ListInvitations(actor *Actor, agencyID uuid.UUID) ([]domain.AgencyInvitation, error) {
+	actorRole, err := s.actorRole(actor, agencyID)
+	if err != nil {
+		return nil, err
+	}
+	if actorRole == domain.AgencyRoleClerk {
+		return nil, ErrForbidden
+	}
+	return s.invitationRepo.ListPending(agencyID)
+}
+
+func (s *agencyMemberService) RevokeInvitation(actor *Actor, agencyID, invitationID uuid.UUID) error {
+	actorRole, err := s.actorRole(actor, agencyID)
+	if err != nil {
+		return err
+	}
+
+	invitation, err := s.invitationRepo.GetByID(agencyID, invitationID)
+	if err != nil {
+		return errors.New("invitation not found")
+	}
+	if !canManage(actorRole, invitation.AgencyRole) {
+		return ErrForbidden
+	}
+	if !invitation.Pending(time.Now()) {
+		return errors.New("invitation is no longer pending")
+	}
+
+	return s.invitationRepo.Revoke(invitation.ID)
+}
+
+// AcceptInvitation creates the invited user's account. Following the
+// emailed link proves the address, so the account is verified at once.
+func (s *agencyMemberService) AcceptInvitation(token, fullName, password string) (*domain.User, error) {
+	invitation, err := s.invitationRepo.GetByTokenHash(hashUserToken(token))
+	if err != nil || !invitation.Pending(time.Now()) {
+		return nil, errInvalidInvitation
+	}
+
+	fullName = strings.TrimSpace(fullName)
+	if fullName == "" {
+		return nil, errors.New("full name is required")
+	}
+	if len(password) < minPasswordLength {
+		return nil, fmt.Errorf("password must be at least %d characters", minPasswordLength)
+	}
+	if existing, _ := s.userRepo.GetByEmail(invitation.Email); existing != nil {
+		return nil, errors.New("user with this email already exists")
+	}
+
+	hash, err := HashPassword(password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+	now := time.Now()
+	agencyID := invitation.AgencyID
+	user := &domain.User{
+		Email:           invitation.Email,
+		PasswordHash:    hash,
+		Role:            domain.RoleAgency,
+		FullName:        fullName,
+		IsActive:        true,
+		AgencyID:        &agencyID,
+		AgencyRole:      invitation.AgencyRole,
+		EmailVerifiedAt: &now,
+	}
+	if err := s.invitationRepo.Accept(invitation.ID, user); err != nil {
+		return nil, errInvalidInvitation
+	}
+
+	return user, nil
+}
+
+// SetRole changes a member's agency role. Only owners change roles, and an
+// agency always keeps an active owner.
+func (s *agencyMemberService) SetRole(actor *Actor, agencyID, userID uuid.UUID, role domain.AgencyRole) (*domain.User, error) {
+	if !role.Valid() {
+		return nil, fmt.Errorf("invalid agency role: %s", role)
+	}
+	actorRole, err := s.actorRole(actor, agencyID)
+	if err != nil {
+		return nil, err
+	}
+	if actorRole != domain.AgencyRoleOwner {
+		return nil, ErrForbidden
+	}
+	if userID == actor.UserID {
+		return nil, errors.New("cannot change your own agency role")
+	}
+
+	member, err := s.member(agencyID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if member.AgencyRole == role {
+		return member, nil
+	}
+	if err := keepOwner(s.userRepo, agencyID, member); err != nil {
+		return nil, err
+	}
+
+	member.AgencyRole = role
+	if err := s.userRepo.Update(member); err != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
+// SetActive activates or deactivates a member. Deactivating ends all of the
+// member's sessions.
+func (s *agencyMemberService) SetActive(actor *Actor, agencyID, userID uuid.UUID, active bool) (*domain.User, error) {
+	member, err := s.managedMember(actor, agencyID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if member.IsActive == active {
+		return member, nil
+	}
+	if !active {
+		if err := keepOwner(s.userRepo, agencyID, member); err != nil {
+			return nil, err
+		}
+	}
+
+	member.IsActive = active
+	if err := s.userRepo.Update(member); err != nil {
+		return nil, err
+	}
+
+	if !active {
+		if err := s.tokenRepo.RevokeAllForUser(member.ID, domain.TokenRevokedLogoutAll); err != nil {
+			return nil, fmt.Errorf("failed to revoke sessions: %w", err)
+		}
+	}
+	return member, nil
+}
+
+// Remove takes the member out of the agency. The account is deactivated,
+// since an agency user without an agency has nothing to do, and its
+// sessions end.
+func (s *agencyMemberService) Remove(actor *Actor, agencyID, userID uuid.UUID) error {
+	member, err := s.managedMember(actor, agencyID, userID)
+	if err != nil {
+		return err
+	}
+	if err := keepOwner(s.userRepo, agencyID, member); err != nil {
+		return err
+	}
+
+	member.AgencyID = nil
+	member.Agency = nil
+	member.AgencyRole = ""
+	member.IsActive = false
+	if err := s.userRepo.Update(member); err != nil {
+		return err
+	}
+
+	if err := s.tokenRepo.RevokeAllForUser(member.ID, domain.TokenRevokedLogoutAll); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	return nil
+}
+
+// actorRole returns the actor's role in the agency.
+func (s *agencyMemberService) actorRole(actor *Actor, agencyID uuid.UUID) (domain.AgencyRole, error) {
+	return agencyRole(s.userRepo, actor, agencyID)
+}
+
+// agencyRole returns the actor's role in the agency, counting admins as
+// owners. The role is read from the user record rather than the token, so
+// a demotion applies at once.
+func agencyRole(userRepo repository.UserRepository, actor *Actor, agencyID uuid.UUID) (domain.AgencyRole, error) {
+	if actor.Role == domain.RoleAdmin {
+		return domain.AgencyRoleOwner, nil
+	}
+	if actor.Role != domain.RoleAgency || actor.ServiceAccount || !sameAgency(actor.AgencyID, &agencyID) {
+		return "", ErrForbidden
+	}
+
+	user, err := userRepo.GetByID(actor.UserID)
+	if err != nil || !sameAgency(user.AgencyID, &agencyID) {
+		return "", ErrForbidden
+	}
+	return effectiveAgencyRole(user.AgencyRole), nil
+}
+
+// checkAgencyRole admits admins and the agency's users holding one of the
+// given roles.
+func checkAgencyRole(userRepo repository.UserRepository, actor *Actor, agencyID uuid.UUID, roles ...domain.AgencyRole) error {
+	role, err := agencyRole(userRepo, actor, agencyID)
+	if err != nil {
+		return err
+	}
+	for _, allowed := range roles {
+		if role == allowed {
+			return nil
+		}
+	}
+	return ErrForbidden
+}
+
+// managedMember returns a member the actor may deactivate or remove: anyone
+// but themselves for owners, and clerks for managers.
+func (s *agencyMemberService) managedMember(actor *Actor, agencyID, userID uuid.UUID) (*domain.User, error) {
+	actorRole, err := s.actorRole(actor, agencyID)
+	if err != nil {
+		return nil, err
+	}
+	if userID == actor.UserID {
+		return nil, errors.New("cannot change your own membership")
+	}
+
+	member, err := s.member(agencyID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !canManage(actorRole, effectiveAgencyRole(member.AgencyRole)) {
+		return nil, ErrForbidden
+	}
+	return member, nil
+}
+
+func (s *agencyMemberService) member(agencyID, userID uuid.UUID) (*domain.User, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil || user.Role != domain.RoleAgency || !sameAgency(user.AgencyID, &agencyID) {
+		return nil, errors.New("member not found")
+	}
+	return user, nil
+}
+
+// keepOwner refuses to demote, deactivate, remove or move away the agency's
+// last active owner.
+func keepOwner(userRepo repository.UserRepository, agencyID uuid.UUID, member *domain.User) error {
+	if member.AgencyRole != domain.AgencyRoleOwner || !member.IsActive {
+		return nil
+	}
+
+	members, err := userRepo.ListByAgency(agencyID)
+	if err != nil {
+		return err
+	}
+	for _, other := range members {
+		if other.ID != member.ID && other.AgencyRole == domain.AgencyRoleOwner && other.IsActive {
+			return nil
+		}
+	}
+	return errors.New("an agency must keep at least one active owner")
+}
+
+func (s *agencyMemberService) send(msg *mailer.Message) {
+	if err := s.mailer.Send(msg); err != nil {
+		s.logger.Error("Failed to send email",
+			zap.String("to", msg.To),
+			zap.String("subject", msg.Subject),
+			zap.Error(err),
+		)
+	}
+}
+
+// canManage reports whether an actor with the given agency role may invite
+// or manage members with the target role.
+func canManage(actor, target domain.AgencyRole) bool {
+	switch actor {
+	case domain.AgencyRoleOwner:
+		return true
+	case domain.AgencyRoleManager:
+		return target == domain.AgencyRoleClerk
+	}
+	return false
+}
+
+// leaveAgency checks that the user may leave their current agency, then
+// moves them to the given one. The agency role does not carry over: agency
+// users start in the new agency as clerks.
+func leaveAgency(userRepo repository.UserRepository, user *domain.User, agencyID *uuid.UUID) error {
+	if user.AgencyID != nil {
+		if err := keepOwner(userRepo, *user.AgencyID, user); err != nil {
+			return err
+		}
+	}
+
+	user.AgencyID = agencyID
+	user.Agency = nil
+	user.AgencyRole = ""
+	if user.Role == domain.RoleAgency && agencyID != nil {
+		user.AgencyRole = domain.AgencyRoleClerk
+	}
+	return nil
+}
+
+// effectiveAgencyRole treats agency users without an agency role, such as
+// those provisioned by single sign-on, as clerks.
+func effectiveAgencyRole(role domain.AgencyRole) domain.AgencyRole {
+	if role.Valid() {
+		return role
+	}
+	return domain.AgencyRoleClerk
+}
@@ -2,6 +2,8 @@ package service
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -12,11 +14,12 @@ import (
 type AgencyService interface {
 	Create(agency *domain.Agency) error
 	GetByID(id uuid.UUID) (*domain.Agency, error)
-	Update(id uuid.UUID, updates *UpdateAgencyRequest) (*domain.Agency, error)
+	Update(actor *Actor, id uuid.UUID, updates *UpdateAgencyRequest) (*domain.Agency, error)
 	Delete(id uuid.UUID) error
 	List(limit, offset int, status *domain.AgencyStatus) ([]domain.Agency, int64, error)
 	Verify(id uuid.UUID, verifiedBy uuid.UUID) error
 	Suspend(id uuid.UUID, verifiedBy uuid.UUID) error
+	Reject(id uuid.UUID, rejectedBy uuid.UUID, reason string) error
 }
 
 type UpdateAgencyRequest struct {
@@ -28,12 +31,16 @@ type UpdateAgencyRequest struct {
 }
 
 type agencyService struct {
-	agencyRepo repository.AgencyRepository
+	agencyRepo   repository.AgencyRepository
+	documentRepo repository.AgencyDocumentRepository
+	userRepo     repository.UserRepository
 }
 
-func NewAgencyService(agencyRepo repository.AgencyRepository) AgencyService {
+func NewAgencyService(agencyRepo repository.AgencyRepository, documentRepo repository.AgencyDocumentRepository, userRepo repository.UserRepository) AgencyService {
 	return &agencyService{
-		agencyRepo: agencyRepo,
+		agencyRepo:   agencyRepo,
+		documentRepo: documentRepo,
+		userRepo:     userRepo,
 	}
 }
 
@@ -50,7 +57,17 @@ func (s *agencyService) GetByID(id uuid.UUID) (*domain.Agency, error) {
 	return s.agencyRepo.GetByID(id)
 }
 
-func (s *agencyService) Update(id uuid.UUID, updates *UpdateAgencyRequest) (*domain.Agency, error) {
+// Update changes an agency's details. Besides admins, only the agency's own
+// owners and managers can update it.
+func (s *agencyService) Update(actor *Actor, id uuid.UUID, updates *UpdateAgencyRequest) (*domain.Agency, error) {
+	if err := checkAgencyRole(s.userRepo, actor, id, domain.AgencyRoleOwner, domain.AgencyRoleManager); err != nil {
+		return nil, err
+	}
+	// The license expiry is recorded by the licensing authority.
+	if updates.LicenseExpiry != nil && actor.Role != domain.RoleAdmin {
+		return nil, ErrForbidden
+	}
+
 	agency, err := s.agencyRepo.GetByID(id)
 	if err != nil {
 		return nil, err
@@ -87,16 +104,38 @@ func (s *agencyService) List(limit, offset int, status *domain.AgencyStatus) ([]
 	return s.agencyRepo.List(limit, offset, status)
 }
 
+// Verify marks the agency verified. Every required document must have
+// been approved first, and an expired license renewed.
 func (s *agencyService) Verify(id uuid.UUID, verifiedBy uuid.UUID) error {
 	agency, err := s.agencyRepo.GetByID(id)
 	if err != nil {
 		return err
 	}
+	if licenseExpired(agency.LicenseExpiry, time.Now()) {
+		return errors.New("agency license has expired; record the renewed license expiry first")
+	}
+
+	documents, err := s.documentRepo.ListByAgency(id, false)
+	if err != nil {
+		return err
+	}
+	checklist := buildAgencyChecklist(documents)
+	if !checklist.ReadyForVerification {
+		var outstanding []string
+		for _, item := range checklist.Items {
+			if item.Status != string(domain.AgencyDocumentApproved) {
+				outstanding = append(outstanding, fmt.Sprintf("%s (%s)", item.Type, item.Status))
+			}
+		}
+		return fmt.Errorf("documents not approved: %s", strings.Join(outstanding, ", "))
+	}
 
 	now := time.Now()
 	agency.Status = domain.AgencyStatusVerified
 	agency.VerifiedAt = &now
 	agency.VerifiedBy = &verifiedBy
+	agency.RejectionReason = ""
+	agency.SuspensionReason = ""
 
 	return s.agencyRepo.Update(agency)
 }
@@ -113,3 +152,26 @@ func (s *agencyService) Suspend(id uuid.UUID, verifiedBy uuid.UUID) error {
 	return s.agencyRepo.Update(agency)
 }
 
+
+// Reject turns the agency's application down with a reason. The agency
+// returns to pending when it uploads a corrected document.
+func (s *agencyService) Reject(id uuid.UUID, rejectedBy uuid.UUID, reason string) error {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return errors.New("a reason is required to reject an agency")
+	}
+
+	agency, err := s.agencyRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if agency.Status == domain.AgencyStatusVerified {
+		return errors.New("agency is already verified; suspend it instead")
+	}
+
+	agency.Status = domain.AgencyStatusRejected
+	agency.VerifiedBy = &rejectedBy
+	agency.RejectionReason = reason
+
+	return s.agencyRepo.Update(agency)
+}
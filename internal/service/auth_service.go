@@ -1,6 +1,8 @@
 package service
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
 	"errors"
 	"fmt"
 	"time"
@@ -10,13 +12,22 @@ import (
 	"github.com/touros-platform/api/internal/config"
 	"github.com/touros-platform/api/internal/domain"
 	"github.com/touros-platform/api/internal/repository"
+	"github.com/touros-platform/api/pkg/jwk"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type AuthService interface {
-	Login(email, password string) (*TokenPair, error)
+	Login(email, password, ip string) (*LoginResult, error)
+	VerifyMFA(challengeToken, code, recoveryCode, ip string) (*TokenPair, error)
+	BeginMFAEnrollment(challengeToken string) (*MFAEnrollment, error)
+	CompleteMFAEnrollment(challengeToken, code, ip string) (*TokenPair, []string, error)
 	RefreshToken(refreshToken string) (*TokenPair, error)
 	ValidateToken(tokenString string) (*Claims, error)
+	IsRevoked(claims *Claims) (bool, error)
+	Logout(claims *Claims) error
+	LogoutAll(userID uuid.UUID) error
+	StartExternalSession(user *domain.User, ip string) (*TokenPair, error)
+	KeySet() jwk.Set
 }
 
 type TokenPair struct {
@@ -25,48 +36,303 @@ type TokenPair struct {
 	ExpiresIn    int64
 }
 
-type Claims struct {
+// LoginResult holds either the session tokens or, when the account needs a
+// second factor, an MFA challenge to complete first.
+type LoginResult struct {
+	Tokens    *TokenPair
+	Challenge *MFAChallenge
+}
+
+// MFAChallenge proves the password step of a login succeeded. It is
+// exchanged for tokens together with a TOTP or recovery code, or, when
+// EnrollmentRequired is set because policy requires MFA for the user's role,
+// used to enroll an authenticator first.
+type MFAChallenge struct {
+	Token              string
+	ExpiresIn          int64
+	EnrollmentRequired bool
+}
+
+type mfaChallengeClaims struct {
 	UserID uuid.UUID
-	Email  string
-	Role   domain.Role
+	Enroll bool
+	jwt.RegisteredClaims
+}
+
+// mfaChallengeAudience keeps challenge tokens from being accepted anywhere
+// else; they are also signed with a key derived from the access secret.
+const mfaChallengeAudience = "mfa-challenge"
+
+// Claims are carried by both access and refresh tokens. The JWT ID names
+// the token itself and SessionID the refresh token family it belongs to.
+type Claims struct {
+	UserID    uuid.UUID
+	Email     string
+	Role      domain.Role
+	AgencyID  *uuid.UUID
+	SessionID uuid.UUID
 	jwt.RegisteredClaims
 }
 
 type authService struct {
-	userRepo repository.UserRepository
-	config   *config.Config
+	userRepo   repository.UserRepository
+	tokenRepo  repository.TokenRepository
+	mfaService MFAService
+	guard      *loginGuard
+	keyring    *TokenKeyring
+	config     *config.Config
 }
 
-func NewAuthService(userRepo repository.UserRepository, cfg *config.Config) AuthService {
+func NewAuthService(
+	userRepo repository.UserRepository,
+	tokenRepo repository.TokenRepository,
+	loginAttemptRepo repository.LoginAttemptRepository,
+	mfaService MFAService,
+	keyring *TokenKeyring,
+	cfg *config.Config,
+) AuthService {
 	return &authService{
-		userRepo: userRepo,
-		config:   cfg,
+		userRepo:   userRepo,
+		tokenRepo:  tokenRepo,
+		mfaService: mfaService,
+		guard:      newLoginGuard(loginAttemptRepo, cfg.Login),
+		keyring:    keyring,
+		config:     cfg,
 	}
 }
 
-func (s *authService) Login(email, password string) (*TokenPair, error) {
+// Login checks the password. Accounts with MFA enabled, or required by
+// policy, get a challenge instead of tokens. Every attempt is recorded, and
+// repeated failures lock the account or the client address for a while.
+func (s *authService) Login(email, password, ip string) (*LoginResult, error) {
+	if err := s.guard.checkIP(email, ip); err != nil {
+		return nil, err
+	}
+
 	user, err := s.userRepo.GetByEmail(email)
 	if err != nil {
+		if err := s.guard.fail(email, nil, ip, domain.LoginUnknownEmail); err != nil {
+			return nil, err
+		}
 		return nil, errors.New("invalid credentials")
 	}
 
+	if err := s.guard.checkAccount(user, ip); err != nil {
+		return nil, err
+	}
+
 	if !user.IsActive {
 		return nil, errors.New("user account is inactive")
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		if err := s.guard.fail(email, user, ip, domain.LoginBadPassword); err != nil {
+			return nil, err
+		}
 		return nil, errors.New("invalid credentials")
 	}
 
-	return s.generateTokenPair(user)
+	if user.EmailVerifiedAt == nil {
+		return nil, errors.New("email address has not been verified")
+	}
+
+	enabled, err := s.mfaService.Enabled(user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check MFA: %w", err)
+	}
+	if enabled || s.mfaService.Required(user.Role) {
+		challenge, err := s.newMFAChallenge(user.ID, !enabled)
+		if err != nil {
+			return nil, err
+		}
+		return &LoginResult{Challenge: challenge}, nil
+	}
+
+	tokens, err := s.startSession(user, ip)
+	if err != nil {
+		return nil, err
+	}
+	return &LoginResult{Tokens: tokens}, nil
+}
+
+// VerifyMFA completes a login with a TOTP code or a recovery code. Wrong
+// codes count towards the account lockout like wrong passwords.
+func (s *authService) VerifyMFA(challengeToken, code, recoveryCode, ip string) (*TokenPair, error) {
+	user, err := s.challengeUser(challengeToken, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.guard.checkIP(user.Email, ip); err != nil {
+		return nil, err
+	}
+	if err := s.guard.checkAccount(user, ip); err != nil {
+		return nil, err
+	}
+
+	if err := s.mfaService.Verify(user.ID, code, recoveryCode); err != nil {
+		if errors.Is(err, errInvalidMFACode) || errors.Is(err, errMFACodeReused) {
+			if err := s.guard.fail(user.Email, user, ip, domain.LoginBadMFACode); err != nil {
+				return nil, err
+			}
+		}
+		return nil, err
+	}
+
+	return s.startSession(user, ip)
 }
 
+// BeginMFAEnrollment starts enrolling an authenticator for a user who must
+// use MFA but has not set it up yet.
+func (s *authService) BeginMFAEnrollment(challengeToken string) (*MFAEnrollment, error) {
+	user, err := s.challengeUser(challengeToken, true)
+	if err != nil {
+		return nil, err
+	}
+	return s.mfaService.BeginEnrollment(user.ID)
+}
+
+// CompleteMFAEnrollment confirms the new authenticator and finishes the
+// login, returning the session tokens and the user's recovery codes.
+func (s *authService) CompleteMFAEnrollment(challengeToken, code, ip string) (*TokenPair, []string, error) {
+	user, err := s.challengeUser(challengeToken, true)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := s.guard.checkAccount(user, ip); err != nil {
+		return nil, nil, err
+	}
+
+	recoveryCodes, err := s.mfaService.ConfirmEnrollment(user.ID, code)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tokens, err := s.startSession(user, ip)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tokens, recoveryCodes, nil
+}
+
+// StartExternalSession logs in a user authenticated by an external identity
+// provider. The provider is trusted with the password and second factor, so
+// neither local MFA nor an earlier password lockout applies.
+func (s *authService) StartExternalSession(user *domain.User, ip string) (*TokenPair, error) {
+	if !user.IsActive {
+		return nil, errors.New("user account is inactive")
+	}
+	return s.startSession(user, ip)
+}
+
+// startSession completes a login: it records the success and issues tokens
+// for a new refresh token family.
+func (s *authService) startSession(user *domain.User, ip string) (*TokenPair, error) {
+	record := s.newRefreshRecord(user.ID, uuid.New(), nil)
+	tokens, err := s.generateTokenPair(user, record)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.tokenRepo.CreateRefreshToken(record); err != nil {
+		return nil, fmt.Errorf("failed to store refresh token: %w", err)
+	}
+
+	if err := s.guard.succeed(user, ip); err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+func (s *authService) newMFAChallenge(userID uuid.UUID, enroll bool) (*MFAChallenge, error) {
+	now := time.Now()
+	claims := &mfaChallengeClaims{
+		UserID: userID,
+		Enroll: enroll,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Audience:  jwt.ClaimStrings{mfaChallengeAudience},
+			ExpiresAt: jwt.NewNumericDate(now.Add(s.config.MFA.ChallengeTTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+		},
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.mfaChallengeKey())
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate MFA challenge: %w", err)
+	}
+
+	return &MFAChallenge{
+		Token:              token,
+		ExpiresIn:          int64(s.config.MFA.ChallengeTTL.Seconds()),
+		EnrollmentRequired: enroll,
+	}, nil
+}
+
+// challengeUser validates an MFA challenge of the expected kind and returns
+// its user, who must still be active.
+func (s *authService) challengeUser(challengeToken string, enroll bool) (*domain.User, error) {
+	token, err := jwt.ParseWithClaims(challengeToken, &mfaChallengeClaims{}, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return s.mfaChallengeKey(), nil
+	}, jwt.WithAudience(mfaChallengeAudience))
+	if err != nil {
+		return nil, errors.New("invalid or expired MFA challenge")
+	}
+
+	claims, ok := token.Claims.(*mfaChallengeClaims)
+	if !ok || !token.Valid || claims.Enroll != enroll {
+		return nil, errors.New("invalid or expired MFA challenge")
+	}
+
+	user, err := s.userRepo.GetByID(claims.UserID)
+	if err != nil || !user.IsActive {
+		return nil, errors.New("invalid or expired MFA challenge")
+	}
+
+	return user, nil
+}
+
+func (s *authService) mfaChallengeKey() []byte {
+	mac := hmac.New(sha256.New, []byte(s.config.JWT.AccessSecret))
+	mac.Write([]byte(mfaChallengeAudience))
+	return mac.Sum(nil)
+}
+
+// RefreshToken exchanges a refresh token for a new token pair. The presented
+// token is used up; presenting it again revokes every token in its family.
 func (s *authService) RefreshToken(refreshToken string) (*TokenPair, error) {
 	claims, err := s.validateRefreshToken(refreshToken)
 	if err != nil {
 		return nil, fmt.Errorf("invalid refresh token: %w", err)
 	}
 
+	jti, err := uuid.Parse(claims.ID)
+	if err != nil {
+		return nil, errors.New("invalid refresh token")
+	}
+
+	record, err := s.tokenRepo.GetRefreshToken(jti)
+	if err != nil || record.UserID != claims.UserID {
+		return nil, errors.New("invalid refresh token")
+	}
+
+	if record.RevokedAt != nil {
+		return nil, errors.New("refresh token has been revoked")
+	}
+
+	if record.UsedAt != nil {
+		if err := s.tokenRepo.RevokeFamily(record.FamilyID, domain.TokenRevokedReuse); err != nil {
+			return nil, fmt.Errorf("failed to revoke session: %w", err)
+		}
+		return nil, errors.New("refresh token has already been used")
+	}
+
 	user, err := s.userRepo.GetByID(claims.UserID)
 	if err != nil {
 		return nil, errors.New("user not found")
@@ -76,16 +342,28 @@ func (s *authService) RefreshToken(refreshToken string) (*TokenPair, error) {
 		return nil, errors.New("user account is inactive")
 	}
 
-	return s.generateTokenPair(user)
+	next := s.newRefreshRecord(user.ID, record.FamilyID, &record.ID)
+	tokens, err := s.generateTokenPair(user, next)
+	if err != nil {
+		return nil, err
+	}
+
+	rotated, err := s.tokenRepo.RotateRefreshToken(record.ID, next)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
+	if !rotated {
+		if err := s.tokenRepo.RevokeFamily(record.FamilyID, domain.TokenRevokedReuse); err != nil {
+			return nil, fmt.Errorf("failed to revoke session: %w", err)
+		}
+		return nil, errors.New("refresh token has already been used")
+	}
+
+	return tokens, nil
 }
 
 func (s *authService) ValidateToken(tokenString string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(s.config.JWT.AccessSecret), nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, s.keyring.Keyfunc)
 
 	if err != nil {
 		return nil, err
@@ -98,16 +376,73 @@ func (s *authService) ValidateToken(tokenString string) (*Claims, error) {
 	return nil, errors.New("invalid token")
 }
 
-func (s *authService) generateTokenPair(user *domain.User) (*TokenPair, error) {
+// IsRevoked reports whether a validated access token has been logged out,
+// either on its own or by a logout everywhere, or whether its user has been
+// deactivated since it was issued.
+func (s *authService) IsRevoked(claims *Claims) (bool, error) {
+	var issuedAt time.Time
+	if claims.IssuedAt != nil {
+		issuedAt = claims.IssuedAt.Time
+	}
+	return s.tokenRepo.IsAccessTokenRevoked(claims.ID, claims.UserID, issuedAt)
+}
+
+// Logout ends the session of the given access token: the token is denylisted
+// until it expires and its refresh token family is revoked.
+func (s *authService) Logout(claims *Claims) error {
+	if claims.ID != "" && claims.ExpiresAt != nil {
+		err := s.tokenRepo.RevokeAccessToken(&domain.RevokedAccessToken{
+			JTI:       claims.ID,
+			UserID:    claims.UserID,
+			ExpiresAt: claims.ExpiresAt.Time,
+			RevokedAt: time.Now(),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to revoke access token: %w", err)
+		}
+	}
+
+	if claims.SessionID != uuid.Nil {
+		if err := s.tokenRepo.RevokeFamily(claims.SessionID, domain.TokenRevokedLogout); err != nil {
+			return fmt.Errorf("failed to revoke session: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// LogoutAll ends every session of the user.
+func (s *authService) LogoutAll(userID uuid.UUID) error {
+	if err := s.tokenRepo.RevokeAllForUser(userID, domain.TokenRevokedLogoutAll); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	return nil
+}
+
+func (s *authService) newRefreshRecord(userID, familyID uuid.UUID, parentID *uuid.UUID) *domain.RefreshToken {
+	return &domain.RefreshToken{
+		ID:        uuid.New(),
+		UserID:    userID,
+		FamilyID:  familyID,
+		ParentID:  parentID,
+		ExpiresAt: time.Now().Add(s.config.JWT.RefreshTTL),
+	}
+}
+
+// generateTokenPair signs an access token and the refresh token described by
+// record. The caller stores the record.
+func (s *authService) generateTokenPair(user *domain.User, record *domain.RefreshToken) (*TokenPair, error) {
 	now := time.Now()
 	accessExpiresAt := now.Add(s.config.JWT.AccessTTL)
-	refreshExpiresAt := now.Add(s.config.JWT.RefreshTTL)
 
 	accessClaims := &Claims{
-		UserID: user.ID,
-		Email:  user.Email,
-		Role:   user.Role,
+		UserID:    user.ID,
+		Email:     user.Email,
+		Role:      user.Role,
+		AgencyID:  user.AgencyID,
+		SessionID: record.FamilyID,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
 			ExpiresAt: jwt.NewNumericDate(accessExpiresAt),
 			IssuedAt:  jwt.NewNumericDate(now),
 			NotBefore: jwt.NewNumericDate(now),
@@ -115,18 +450,20 @@ func (s *authService) generateTokenPair(user *domain.User) (*TokenPair, error) {
 	}
 
 	refreshClaims := &Claims{
-		UserID: user.ID,
-		Email:  user.Email,
-		Role:   user.Role,
+		UserID:    user.ID,
+		Email:     user.Email,
+		Role:      user.Role,
+		AgencyID:  user.AgencyID,
+		SessionID: record.FamilyID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(refreshExpiresAt),
+			ID:        record.ID.String(),
+			ExpiresAt: jwt.NewNumericDate(record.ExpiresAt),
 			IssuedAt:  jwt.NewNumericDate(now),
 			NotBefore: jwt.NewNumericDate(now),
 		},
 	}
 
-	accessToken := jwt.NewWithClaims(jwt.SigningMethodHS256, accessClaims)
-	accessTokenString, err := accessToken.SignedString([]byte(s.config.JWT.AccessSecret))
+	accessTokenString, err := s.keyring.Sign(accessClaims)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate access token: %w", err)
 	}
@@ -144,6 +481,11 @@ func (s *authService) generateTokenPair(user *domain.User) (*TokenPair, error) {
 	}, nil
 }
 
+// KeySet returns the public keys that verify access tokens.
+func (s *authService) KeySet() jwk.Set {
+	return s.keyring.KeySet()
+}
+
 func (s *authService) validateRefreshToken(tokenString string) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
@@ -0,0 +1,240 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/touros-platform/api/internal/domain"
+	"github.com/touros-platform/api/internal/repository"
+	"gorm.io/gorm"
+)
+
+// sessionTokenRepo keeps refresh tokens and revocations in memory, following
+// the rules of the database implementation.
+type sessionTokenRepo struct {
+	refresh      map[uuid.UUID]*domain.RefreshToken
+	denylist     map[string]bool
+	revokedAfter map[uuid.UUID]time.Time
+}
+
+var _ repository.TokenRepository = (*sessionTokenRepo)(nil)
+
+func newSessionTokenRepo() *sessionTokenRepo {
+	return &sessionTokenRepo{
+		refresh:      make(map[uuid.UUID]*domain.RefreshToken),
+		denylist:     make(map[string]bool),
+		revokedAfter: make(map[uuid.UUID]time.Time),
+	}
+}
+
+func (r *sessionTokenRepo) CreateRefreshToken(token *domain.RefreshToken) error {
+	copied := *token
+	r.refresh[token.ID] = &copied
+	return nil
+}
+
+func (r *sessionTokenRepo) GetRefreshToken(id uuid.UUID) (*domain.RefreshToken, error) {
+	token, ok := r.refresh[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *token
+	return &copied, nil
+}
+
+func (r *sessionTokenRepo) RotateRefreshToken(usedID uuid.UUID, next *domain.RefreshToken) (bool, error) {
+	used, ok := r.refresh[usedID]
+	if !ok || used.UsedAt != nil || used.RevokedAt != nil {
+		return false, nil
+	}
+	now := time.Now()
+	used.UsedAt = &now
+	return true, r.CreateRefreshToken(next)
+}
+
+func (r *sessionTokenRepo) RevokeFamily(familyID uuid.UUID, reason string) error {
+	now := time.Now()
+	for _, token := range r.refresh {
+		if token.FamilyID == familyID && token.RevokedAt == nil {
+			token.RevokedAt = &now
+			token.RevokedReason = reason
+		}
+	}
+	return nil
+}
+
+func (r *sessionTokenRepo) RevokeAllForUser(userID uuid.UUID, reason string) error {
+	now := time.Now()
+	for _, token := range r.refresh {
+		if token.UserID == userID && token.RevokedAt == nil {
+			token.RevokedAt = &now
+			token.RevokedReason = reason
+		}
+	}
+	r.revokedAfter[userID] = now
+	return nil
+}
+
+func (r *sessionTokenRepo) RevokeAccessToken(token *domain.RevokedAccessToken) error {
+	r.denylist[token.JTI] = true
+	return nil
+}
+
+func (r *sessionTokenRepo) IsAccessTokenRevoked(jti string, userID uuid.UUID, issuedAt time.Time) (bool, error) {
+	if r.denylist[jti] {
+		return true, nil
+	}
+	cutoff, ok := r.revokedAfter[userID]
+	return ok && !issuedAt.After(cutoff), nil
+}
+
+func (r *sessionTokenRepo) DeleteExpired(before time.Time) (int64, error) {
+	return 0, nil
+}
+
+// sessionHarness logs a user in against sessionTokenRepo.
+type sessionHarness struct {
+	*loginHarness
+	tokens *sessionTokenRepo
+	user   *domain.User
+}
+
+func newSessionHarness(t *testing.T) *sessionHarness {
+	t.Helper()
+
+	h := &sessionHarness{loginHarness: newLoginHarness(t), tokens: newSessionTokenRepo()}
+	h.auth.tokenRepo = h.tokens
+	h.user = h.addUser("guide@example.com")
+	return h
+}
+
+func (h *sessionHarness) login() *TokenPair {
+	h.t.Helper()
+
+	result, err := h.auth.Login(h.user.Email, testPassword, "10.0.0.1")
+	if err != nil {
+		h.t.Fatalf("login: %v", err)
+	}
+	return result.Tokens
+}
+
+func (h *sessionHarness) mustRefresh(refreshToken string) *TokenPair {
+	h.t.Helper()
+
+	tokens, err := h.auth.RefreshToken(refreshToken)
+	if err != nil {
+		h.t.Fatalf("refresh: %v", err)
+	}
+	return tokens
+}
+
+func (h *sessionHarness) mustNotRefresh(refreshToken string) {
+	h.t.Helper()
+
+	if _, err := h.auth.RefreshToken(refreshToken); err == nil {
+		h.t.Fatal("refresh token was accepted, want it refused")
+	}
+}
+
+func (h *sessionHarness) claims(accessToken string) *Claims {
+	h.t.Helper()
+
+	claims, err := h.auth.ValidateToken(accessToken)
+	if err != nil {
+		h.t.Fatalf("ValidateToken: %v", err)
+	}
+	return claims
+}
+
+func (h *sessionHarness) mustBeRevoked(accessToken string, want bool) {
+	h.t.Helper()
+
+	revoked, err := h.auth.IsRevoked(h.claims(accessToken))
+	if err != nil {
+		h.t.Fatalf("IsRevoked: %v", err)
+	}
+	if revoked != want {
+		h.t.Fatalf("access token revoked = %t, want %t", revoked, want)
+	}
+}
+
+func TestRefreshTokenRotates(t *testing.T) {
+	h := newSessionHarness(t)
+	first := h.login()
+
+	second := h.mustRefresh(first.RefreshToken)
+	if second.RefreshToken == first.RefreshToken {
+		t.Fatal("refresh returned the presented refresh token")
+	}
+	third := h.mustRefresh(second.RefreshToken)
+
+	firstClaims := h.claims(first.AccessToken)
+	thirdClaims := h.claims(third.AccessToken)
+	if thirdClaims.SessionID != firstClaims.SessionID {
+		t.Fatal("rotated tokens left the session's refresh token family")
+	}
+	if len(h.tokens.refresh) != 3 {
+		t.Fatalf("stored %d refresh tokens, want 3", len(h.tokens.refresh))
+	}
+}
+
+func TestRefreshTokenReuseRevokesFamily(t *testing.T) {
+	h := newSessionHarness(t)
+	first := h.login()
+	other := h.login()
+
+	second := h.mustRefresh(first.RefreshToken)
+	h.mustNotRefresh(first.RefreshToken)
+
+	// The legitimate successor is refused too, since it cannot be told
+	// apart from a stolen one.
+	h.mustNotRefresh(second.RefreshToken)
+
+	session := h.claims(first.AccessToken).SessionID
+	for _, token := range h.tokens.refresh {
+		if token.FamilyID == session && token.RevokedReason != domain.TokenRevokedReuse {
+			t.Fatalf("token in the reused family: revoked reason %q, want %q", token.RevokedReason, domain.TokenRevokedReuse)
+		}
+	}
+
+	// Other sessions of the user are untouched.
+	h.mustRefresh(other.RefreshToken)
+}
+
+func TestLogoutDenylistsAccessToken(t *testing.T) {
+	h := newSessionHarness(t)
+	session := h.login()
+	other := h.login()
+
+	h.mustBeRevoked(session.AccessToken, false)
+	if err := h.auth.Logout(h.claims(session.AccessToken)); err != nil {
+		t.Fatal(err)
+	}
+
+	h.mustBeRevoked(session.AccessToken, true)
+	h.mustNotRefresh(session.RefreshToken)
+
+	h.mustBeRevoked(other.AccessToken, false)
+	h.mustRefresh(other.RefreshToken)
+}
+
+func TestLogoutAllEndsEverySession(t *testing.T) {
+	h := newSessionHarness(t)
+	first := h.login()
+	second := h.login()
+
+	if err := h.auth.LogoutAll(h.user.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, session := range []*TokenPair{first, second} {
+		h.mustBeRevoked(session.AccessToken, true)
+		h.mustNotRefresh(session.RefreshToken)
+	}
+
+	// Access tokens have whole second issue times; one issued in a later
+	// second is accepted.
+	time.Sleep(time.Until(h.tokens.revokedAfter[h.user.ID].Truncate(time.Second).Add(time.Second)))
+	h.mustBeRevoked(h.login().AccessToken, false)
+}
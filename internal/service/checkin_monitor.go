@@ -0,0 +1,190 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/touros-platform/api/internal/config"
+	"github.com/touros-platform/api/internal/domain"
+	"github.com/touros-platform/api/internal/events"
+	"github.com/touros-platform/api/internal/repository"
+	"go.uber.org/zap"
+)
+
+// CheckInMonitor looks for guides on an active permit who have stopped
+// checking in and raises an overdue incident for them. The incident is
+// escalated from warning to overdue to missing as more time passes and is
+// resolved by the next check-in from the guide. The scheduler runs Scan on
+// every monitor interval.
+type CheckInMonitor struct {
+	guideRepo    repository.GuideRepository
+	permitRepo   repository.PermitRepository
+	checkInRepo  repository.SafetyCheckInRepository
+	incidentRepo repository.IncidentRepository
+	publisher    events.Publisher
+	config       config.SafetyConfig
+	logger       *zap.Logger
+	now          func() time.Time
+}
+
+func NewCheckInMonitor(
+	guideRepo repository.GuideRepository,
+	permitRepo repository.PermitRepository,
+	checkInRepo repository.SafetyCheckInRepository,
+	incidentRepo repository.IncidentRepository,
+	publisher events.Publisher,
+	cfg config.SafetyConfig,
+	logger *zap.Logger,
+) *CheckInMonitor {
+	return &CheckInMonitor{
+		guideRepo:    guideRepo,
+		permitRepo:   permitRepo,
+		checkInRepo:  checkInRepo,
+		incidentRepo: incidentRepo,
+		publisher:    publisher,
+		config:       cfg,
+		logger:       logger,
+		now:          time.Now,
+	}
+}
+
+// Scan evaluates every guide that is on trek and has not checked in within
+// the warning threshold.
+func (m *CheckInMonitor) Scan() error {
+	now := m.now()
+	guides, err := m.guideRepo.ListOnTrekWithoutCheckInSince(now.Add(-m.config.WarningAfter))
+	if err != nil {
+		return fmt.Errorf("failed to list guides without recent check-in: %w", err)
+	}
+
+	for i := range guides {
+		if err := m.evaluate(&guides[i], now); err != nil {
+			m.logger.Error("Failed to evaluate overdue check-in",
+				zap.String("guide_id", guides[i].ID.String()),
+				zap.Error(err),
+			)
+		}
+	}
+
+	return nil
+}
+
+func (m *CheckInMonitor) evaluate(guide *domain.Guide, now time.Time) error {
+	permits, err := m.permitRepo.GetActiveByGuideID(guide.ID)
+	if err != nil {
+		return err
+	}
+	if len(permits) == 0 {
+		return nil
+	}
+
+	// The clock starts at the later of the last check-in and the start of
+	// the trek, so a guide is not flagged for silence before setting off.
+	permit := permits[0]
+	for _, p := range permits[1:] {
+		if p.StartDate.Before(permit.StartDate) {
+			permit = p
+		}
+	}
+	since := permit.StartDate
+	if guide.LastCheckIn != nil && guide.LastCheckIn.After(since) {
+		since = *guide.LastCheckIn
+	}
+
+	elapsed := now.Sub(since)
+	level := m.levelFor(elapsed)
+	if level == domain.EscalationNone {
+		return nil
+	}
+
+	open, err := m.incidentRepo.GetActiveByGuideIDAndType(guide.ID, domain.IncidentTypeOverdue)
+	if err != nil {
+		return err
+	}
+
+	if len(open) > 0 {
+		incident := &open[0]
+		if escalationRank(level) <= escalationRank(incident.EscalationLevel) {
+			return nil
+		}
+
+		incident.EscalationLevel = level
+		incident.EscalatedAt = &now
+		incident.Description = m.describe(guide, &permit, elapsed, level)
+		if err := m.incidentRepo.Update(incident); err != nil {
+			return fmt.Errorf("failed to escalate incident: %w", err)
+		}
+		m.publisher.Publish(events.NewIncidentEvent(events.IncidentUpdated, incident, guide.AgencyID, permit.AgencyID))
+
+		m.logger.Warn("Overdue check-in escalated",
+			zap.String("incident_id", incident.ID.String()),
+			zap.String("guide_id", guide.ID.String()),
+			zap.String("level", string(level)),
+		)
+		return nil
+	}
+
+	incident := &domain.Incident{
+		IncidentType:    domain.IncidentTypeOverdue,
+		GuideID:         guide.ID,
+		PermitID:        &permit.ID,
+		Status:          domain.IncidentStatusOpen,
+		Description:     m.describe(guide, &permit, elapsed, level),
+		ReportedAt:      now,
+		EscalationLevel: level,
+		EscalatedAt:     &now,
+	}
+
+	lastCheckIns, _, err := m.checkInRepo.ListByGuideID(guide.ID, 1, 0)
+	if err != nil {
+		return err
+	}
+	if len(lastCheckIns) > 0 {
+		incident.Latitude = lastCheckIns[0].Latitude
+		incident.Longitude = lastCheckIns[0].Longitude
+		incident.Location = lastCheckIns[0].Location
+	}
+
+	if err := m.incidentRepo.Create(incident); err != nil {
+		return fmt.Errorf("failed to create overdue incident: %w", err)
+	}
+	m.publisher.Publish(events.NewIncidentEvent(events.IncidentCreated, incident, guide.AgencyID, permit.AgencyID))
+
+	m.logger.Warn("Overdue check-in incident raised",
+		zap.String("incident_id", incident.ID.String()),
+		zap.String("guide_id", guide.ID.String()),
+		zap.String("level", string(level)),
+	)
+	return nil
+}
+
+func (m *CheckInMonitor) levelFor(elapsed time.Duration) domain.EscalationLevel {
+	switch {
+	case elapsed >= m.config.MissingAfter:
+		return domain.EscalationMissing
+	case elapsed >= m.config.OverdueAfter:
+		return domain.EscalationOverdue
+	case elapsed >= m.config.WarningAfter:
+		return domain.EscalationWarning
+	default:
+		return domain.EscalationNone
+	}
+}
+
+func (m *CheckInMonitor) describe(guide *domain.Guide, permit *domain.Permit, elapsed time.Duration, level domain.EscalationLevel) string {
+	return fmt.Sprintf("[%s] No check-in from guide %s (license %s) on permit %s for %s",
+		level, guide.User.FullName, guide.LicenseNumber, permit.PermitNumber, elapsed.Round(time.Minute))
+}
+
+func escalationRank(level domain.EscalationLevel) int {
+	switch level {
+	case domain.EscalationWarning:
+		return 1
+	case domain.EscalationOverdue:
+		return 2
+	case domain.EscalationMissing:
+		return 3
+	default:
+		return 0
+	}
+}
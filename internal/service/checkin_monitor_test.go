@@ -0,0 +1,387 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/touros-platform/api/internal/config"
+	"github.com/touros-platform/api/internal/domain"
+	"github.com/touros-platform/api/internal/events"
+	"github.com/touros-platform/api/internal/repository"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// safetyHarness runs the check-in monitor and the safety service against
+// in-memory repositories and a controllable clock.
+type safetyHarness struct {
+	t         *testing.T
+	now       time.Time
+	guides    map[uuid.UUID]*domain.Guide
+	permits   map[uuid.UUID]*domain.Permit
+	checkIns  []domain.SafetyCheckIn
+	incidents []*domain.Incident
+	published []events.Event
+	failSaves bool
+	monitor   *CheckInMonitor
+	safety    SafetyService
+}
+
+func newSafetyHarness(t *testing.T) *safetyHarness {
+	t.Helper()
+
+	h := &safetyHarness{
+		t:       t,
+		now:     time.Date(2024, 5, 10, 6, 0, 0, 0, time.UTC),
+		guides:  make(map[uuid.UUID]*domain.Guide),
+		permits: make(map[uuid.UUID]*domain.Permit),
+	}
+
+	cfg := config.SafetyConfig{
+		WarningAfter: 12 * time.Hour,
+		OverdueAfter: 24 * time.Hour,
+		MissingAfter: 48 * time.Hour,
+	}
+
+	guides := &fakeGuideRepo{h: h}
+	permits := &fakePermitRepo{h: h}
+	checkIns := &fakeCheckInRepo{h: h}
+	incidents := &fakeIncidentRepo{h: h}
+	publisher := &fakePublisher{h: h}
+
+	h.monitor = NewCheckInMonitor(guides, permits, checkIns, incidents, publisher, cfg, zap.NewNop())
+	h.monitor.now = func() time.Time { return h.now }
+	h.safety = NewSafetyService(checkIns, incidents, guides, permits, publisher, zap.NewNop())
+	return h
+}
+
+// addGuideOnTrek adds a guide whose permit started a day ago and who last
+// checked in now.
+func (h *safetyHarness) addGuideOnTrek() *domain.Guide {
+	lastCheckIn := h.now
+	guide := &domain.Guide{
+		ID:            uuid.New(),
+		LicenseNumber: "GL-" + uuid.NewString()[:8],
+		LastCheckIn:   &lastCheckIn,
+	}
+	h.guides[guide.ID] = guide
+
+	permit := &domain.Permit{
+		ID:           uuid.New(),
+		PermitNumber: "TP-" + uuid.NewString()[:8],
+		GuideID:      guide.ID,
+		StartDate:    h.now.AddDate(0, 0, -1),
+		EndDate:      h.now.AddDate(0, 0, 10),
+		Status:       domain.PermitStatusActive,
+	}
+	h.permits[permit.ID] = permit
+	return guide
+}
+
+func (h *safetyHarness) advance(d time.Duration) {
+	h.now = h.now.Add(d)
+}
+
+func (h *safetyHarness) scan() {
+	h.t.Helper()
+
+	if err := h.monitor.Scan(); err != nil {
+		h.t.Fatalf("Scan: %v", err)
+	}
+}
+
+func (h *safetyHarness) checkIn(guide *domain.Guide) *domain.SafetyCheckIn {
+	h.t.Helper()
+
+	actor := &Actor{UserID: uuid.New(), Role: domain.RoleGuide, GuideID: &guide.ID}
+	checkIn, err := h.safety.CreateCheckIn(actor, &CreateCheckInRequest{Latitude: 28.2, Longitude: 83.9})
+	if err != nil {
+		h.t.Fatalf("CreateCheckIn: %v", err)
+	}
+	return checkIn
+}
+
+// overdue returns the overdue incidents raised for the guide.
+func (h *safetyHarness) overdue(guideID uuid.UUID) []*domain.Incident {
+	var incidents []*domain.Incident
+	for _, incident := range h.incidents {
+		if incident.GuideID == guideID && incident.IncidentType == domain.IncidentTypeOverdue {
+			incidents = append(incidents, incident)
+		}
+	}
+	return incidents
+}
+
+func (h *safetyHarness) mustHaveLevel(guideID uuid.UUID, want domain.EscalationLevel) *domain.Incident {
+	h.t.Helper()
+
+	incidents := h.overdue(guideID)
+	if len(incidents) != 1 {
+		h.t.Fatalf("got %d overdue incidents, want 1", len(incidents))
+	}
+	if incidents[0].EscalationLevel != want {
+		h.t.Fatalf("escalation level = %q, want %q", incidents[0].EscalationLevel, want)
+	}
+	return incidents[0]
+}
+
+func TestCheckInMonitorEscalatesThroughThresholds(t *testing.T) {
+	h := newSafetyHarness(t)
+	guide := h.addGuideOnTrek()
+
+	h.advance(12*time.Hour - time.Minute)
+	h.scan()
+	if incidents := h.overdue(guide.ID); len(incidents) != 0 {
+		t.Fatalf("raised %d incidents before the warning threshold", len(incidents))
+	}
+
+	h.advance(time.Minute)
+	h.scan()
+	incident := h.mustHaveLevel(guide.ID, domain.EscalationWarning)
+	if incident.Status != domain.IncidentStatusOpen {
+		t.Fatalf("status = %q, want open", incident.Status)
+	}
+
+	h.advance(12 * time.Hour)
+	h.scan()
+	h.mustHaveLevel(guide.ID, domain.EscalationOverdue)
+
+	h.advance(24 * time.Hour)
+	h.scan()
+	h.mustHaveLevel(guide.ID, domain.EscalationMissing)
+
+	var created, updated int
+	for _, evt := range h.published {
+		switch evt.Type {
+		case events.IncidentCreated:
+			created++
+		case events.IncidentUpdated:
+			updated++
+		}
+	}
+	if created != 1 || updated != 2 {
+		t.Fatalf("published %d created and %d updated events, want 1 and 2", created, updated)
+	}
+}
+
+func TestCheckInMonitorDoesNotReraiseEscalation(t *testing.T) {
+	h := newSafetyHarness(t)
+	guide := h.addGuideOnTrek()
+
+	h.advance(24 * time.Hour)
+	h.scan()
+	incident := h.mustHaveLevel(guide.ID, domain.EscalationOverdue)
+	escalatedAt := *incident.EscalatedAt
+	published := len(h.published)
+
+	for i := 0; i < 3; i++ {
+		h.advance(time.Hour)
+		h.scan()
+	}
+
+	incident = h.mustHaveLevel(guide.ID, domain.EscalationOverdue)
+	if !incident.EscalatedAt.Equal(escalatedAt) {
+		t.Fatalf("EscalatedAt moved from %s to %s", escalatedAt, incident.EscalatedAt)
+	}
+	if len(h.published) != published {
+		t.Fatalf("published %d more events for an escalation already raised", len(h.published)-published)
+	}
+}
+
+func TestCheckInMonitorWaitsForTrekStart(t *testing.T) {
+	h := newSafetyHarness(t)
+	guide := h.addGuideOnTrek()
+	guide.LastCheckIn = nil
+	for _, permit := range h.permits {
+		permit.StartDate = h.now.Add(-6 * time.Hour)
+	}
+
+	h.scan()
+	if incidents := h.overdue(guide.ID); len(incidents) != 0 {
+		t.Fatalf("raised %d incidents before the warning threshold after the trek start", len(incidents))
+	}
+
+	h.advance(6 * time.Hour)
+	h.scan()
+	h.mustHaveLevel(guide.ID, domain.EscalationWarning)
+}
+
+func TestCheckInResolvesOverdueIncidents(t *testing.T) {
+	for _, level := range []struct {
+		name    string
+		silence time.Duration
+		want    domain.EscalationLevel
+	}{
+		{"overdue", 24 * time.Hour, domain.EscalationOverdue},
+		{"missing", 48 * time.Hour, domain.EscalationMissing},
+	} {
+		t.Run(level.name, func(t *testing.T) {
+			h := newSafetyHarness(t)
+			guide := h.addGuideOnTrek()
+
+			h.advance(level.silence)
+			h.scan()
+			incident := h.mustHaveLevel(guide.ID, level.want)
+
+			h.checkIn(guide)
+
+			if incident.Status != domain.IncidentStatusResolved {
+				t.Fatalf("status = %q after check-in, want resolved", incident.Status)
+			}
+			if incident.ResolvedAt == nil {
+				t.Fatal("ResolvedAt not set")
+			}
+
+			// The monitor starts counting again from the new check-in.
+			h.advance(time.Hour)
+			h.scan()
+			if incidents := h.overdue(guide.ID); len(incidents) != 1 {
+				t.Fatalf("got %d overdue incidents after check-in, want the resolved one only", len(incidents))
+			}
+		})
+	}
+}
+
+func TestCheckInSavedWhenResolveFails(t *testing.T) {
+	h := newSafetyHarness(t)
+	guide := h.addGuideOnTrek()
+
+	h.advance(24 * time.Hour)
+	h.scan()
+	h.failSaves = true
+
+	checkIn := h.checkIn(guide)
+	if checkIn == nil || len(h.checkIns) != 1 {
+		t.Fatalf("got %d saved check-ins, want 1", len(h.checkIns))
+	}
+}
+
+type fakeGuideRepo struct {
+	repository.GuideRepository
+	h *safetyHarness
+}
+
+func (r *fakeGuideRepo) GetByID(id uuid.UUID) (*domain.Guide, error) {
+	guide, ok := r.h.guides[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *guide
+	return &copied, nil
+}
+
+func (r *fakeGuideRepo) UpdateLastCheckIn(guideID uuid.UUID) error {
+	now := r.h.now
+	r.h.guides[guideID].LastCheckIn = &now
+	return nil
+}
+
+func (r *fakeGuideRepo) ListOnTrekWithoutCheckInSince(cutoff time.Time) ([]domain.Guide, error) {
+	var guides []domain.Guide
+	for _, guide := range r.h.guides {
+		if guide.LastCheckIn == nil || !guide.LastCheckIn.After(cutoff) {
+			guides = append(guides, *guide)
+		}
+	}
+	return guides, nil
+}
+
+type fakePermitRepo struct {
+	repository.PermitRepository
+	h *safetyHarness
+}
+
+func (r *fakePermitRepo) GetByID(id uuid.UUID) (*domain.Permit, error) {
+	permit, ok := r.h.permits[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *permit
+	return &copied, nil
+}
+
+func (r *fakePermitRepo) GetActiveByGuideID(guideID uuid.UUID) ([]domain.Permit, error) {
+	var permits []domain.Permit
+	for _, permit := range r.h.permits {
+		if permit.GuideID == guideID && permit.Status == domain.PermitStatusActive {
+			permits = append(permits, *permit)
+		}
+	}
+	return permits, nil
+}
+
+type fakeCheckInRepo struct {
+	repository.SafetyCheckInRepository
+	h *safetyHarness
+}
+
+func (r *fakeCheckInRepo) Create(checkIn *domain.SafetyCheckIn) error {
+	checkIn.ID = uuid.New()
+	r.h.checkIns = append(r.h.checkIns, *checkIn)
+	return nil
+}
+
+func (r *fakeCheckInRepo) ListByGuideID(guideID uuid.UUID, limit, offset int) ([]domain.SafetyCheckIn, int64, error) {
+	var checkIns []domain.SafetyCheckIn
+	for i := len(r.h.checkIns) - 1; i >= 0; i-- {
+		if r.h.checkIns[i].GuideID == guideID {
+			checkIns = append(checkIns, r.h.checkIns[i])
+		}
+	}
+	total := int64(len(checkIns))
+	if len(checkIns) > limit {
+		checkIns = checkIns[:limit]
+	}
+	return checkIns, total, nil
+}
+
+// fakeIncidentRepo keeps the harness incidents. Updates are written back to
+// the stored incident, so tests can hold on to it; failSaves makes updates
+// fail.
+type fakeIncidentRepo struct {
+	repository.IncidentRepository
+	h *safetyHarness
+}
+
+func (r *fakeIncidentRepo) Create(incident *domain.Incident) error {
+	incident.ID = uuid.New()
+	copied := *incident
+	r.h.incidents = append(r.h.incidents, &copied)
+	return nil
+}
+
+func (r *fakeIncidentRepo) Update(incident *domain.Incident) error {
+	if r.h.failSaves {
+		return errors.New("database unavailable")
+	}
+	for _, stored := range r.h.incidents {
+		if stored.ID == incident.ID {
+			*stored = *incident
+			return nil
+		}
+	}
+	return gorm.ErrRecordNotFound
+}
+
+func (r *fakeIncidentRepo) GetActiveByGuideIDAndType(guideID uuid.UUID, incidentType domain.IncidentType) ([]domain.Incident, error) {
+	var incidents []domain.Incident
+	for _, incident := range r.h.incidents {
+		if incident.GuideID != guideID || incident.IncidentType != incidentType {
+			continue
+		}
+		if incident.Status == domain.IncidentStatusOpen || incident.Status == domain.IncidentStatusInProgress {
+			incidents = append(incidents, *incident)
+		}
+	}
+	return incidents, nil
+}
+
+type fakePublisher struct {
+	h *safetyHarness
+}
+
+func (p *fakePublisher) Publish(evt events.Event) {
+	p.h.published = append(p.h.published, evt)
+}
@@ -0,0 +1,235 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/touros-platform/api/internal/domain"
+	"github.com/touros-platform/api/internal/repository"
+)
+
+// maxScanClockSkew bounds how far in the future a device-supplied scan time
+// may be.
+const maxScanClockSkew = 5 * time.Minute
+
+type CheckpointService interface {
+	Register(checkpoint *domain.Checkpoint, registeredBy uuid.UUID) error
+	GetByID(id uuid.UUID) (*domain.Checkpoint, error)
+	Update(id uuid.UUID, updates *UpdateCheckpointRequest) (*domain.Checkpoint, error)
+	List(limit, offset int, activeOnly bool) ([]domain.Checkpoint, int64, error)
+	RecordScan(req *RecordScanRequest) (*domain.PermitScan, *domain.Permit, error)
+	ListScans(checkpointID uuid.UUID, limit, offset int, since *time.Time) ([]domain.PermitScan, int64, error)
+	PermitTimeline(actor *Actor, permitID uuid.UUID) ([]domain.PermitScan, error)
+}
+
+type UpdateCheckpointRequest struct {
+	Name              *string
+	RouteCheckpointID *uuid.UUID
+	Latitude          *float64
+	Longitude         *float64
+	DeviceID          *string
+	IsActive          *bool
+}
+
+// RecordScanRequest identifies the permit either by its signed QR token or
+// by its permit number. Headcount is the party size counted by the officer;
+// when nil the permit's own party size is recorded. ScannedAt lets devices
+// upload scans made while offline.
+type RecordScanRequest struct {
+	CheckpointID uuid.UUID
+	Token        string
+	PermitNumber string
+	Headcount    *int
+	Notes        string
+	ScannedBy    uuid.UUID
+	ScannedAt    *time.Time
+}
+
+type checkpointService struct {
+	checkpointRepo repository.CheckpointRepository
+	permitRepo     repository.PermitRepository
+	routeRepo      repository.RouteRepository
+	signer         *PermitSigner
+	authz          *authorizer
+}
+
+func NewCheckpointService(checkpointRepo repository.CheckpointRepository, permitRepo repository.PermitRepository, guideRepo repository.GuideRepository, routeRepo repository.RouteRepository, signer *PermitSigner) CheckpointService {
+	return &checkpointService{
+		checkpointRepo: checkpointRepo,
+		permitRepo:     permitRepo,
+		routeRepo:      routeRepo,
+		signer:         signer,
+		authz:          newAuthorizer(guideRepo, permitRepo),
+	}
+}
+
+func (s *checkpointService) Register(checkpoint *domain.Checkpoint, registeredBy uuid.UUID) error {
+	checkpoint.Code = strings.ToUpper(strings.TrimSpace(checkpoint.Code))
+	if checkpoint.Code == "" {
+		return errors.New("checkpoint code is required")
+	}
+
+	existing, _ := s.checkpointRepo.GetByCode(checkpoint.Code)
+	if existing != nil {
+		return errors.New("checkpoint with this code already exists")
+	}
+
+	if checkpoint.RouteCheckpointID != nil {
+		if _, err := s.routeRepo.GetCheckpoint(*checkpoint.RouteCheckpointID); err != nil {
+			return errors.New("route checkpoint not found")
+		}
+	}
+
+	checkpoint.RegisteredBy = registeredBy
+	checkpoint.IsActive = true
+
+	return s.checkpointRepo.Create(checkpoint)
+}
+
+func (s *checkpointService) GetByID(id uuid.UUID) (*domain.Checkpoint, error) {
+	return s.checkpointRepo.GetByID(id)
+}
+
+func (s *checkpointService) Update(id uuid.UUID, updates *UpdateCheckpointRequest) (*domain.Checkpoint, error) {
+	checkpoint, err := s.checkpointRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if updates.Name != nil {
+		checkpoint.Name = *updates.Name
+	}
+	if updates.RouteCheckpointID != nil {
+		if _, err := s.routeRepo.GetCheckpoint(*updates.RouteCheckpointID); err != nil {
+			return nil, errors.New("route checkpoint not found")
+		}
+		checkpoint.RouteCheckpointID = updates.RouteCheckpointID
+		checkpoint.RouteCheckpoint = nil
+	}
+	if updates.Latitude != nil {
+		checkpoint.Latitude = *updates.Latitude
+	}
+	if updates.Longitude != nil {
+		checkpoint.Longitude = *updates.Longitude
+	}
+	if updates.DeviceID != nil {
+		checkpoint.DeviceID = *updates.DeviceID
+	}
+	if updates.IsActive != nil {
+		checkpoint.IsActive = *updates.IsActive
+	}
+
+	if err := s.checkpointRepo.Update(checkpoint); err != nil {
+		return nil, err
+	}
+
+	return checkpoint, nil
+}
+
+func (s *checkpointService) List(limit, offset int, activeOnly bool) ([]domain.Checkpoint, int64, error) {
+	return s.checkpointRepo.List(limit, offset, activeOnly)
+}
+
+// RecordScan validates a permit at a checkpoint and logs the outcome. Every
+// scan is stored, including ones that fail validation; an error is returned
+// only when the scan itself could not be recorded.
+func (s *checkpointService) RecordScan(req *RecordScanRequest) (*domain.PermitScan, *domain.Permit, error) {
+	checkpoint, err := s.checkpointRepo.GetByID(req.CheckpointID)
+	if err != nil {
+		return nil, nil, errors.New("checkpoint not found")
+	}
+
+	if !checkpoint.IsActive {
+		return nil, nil, errors.New("checkpoint is not active")
+	}
+
+	if (req.Token == "") == (req.PermitNumber == "") {
+		return nil, nil, errors.New("provide either a QR token or a permit number")
+	}
+
+	now := time.Now()
+	scannedAt := now
+	if req.ScannedAt != nil {
+		if req.ScannedAt.After(now.Add(maxScanClockSkew)) {
+			return nil, nil, errors.New("scan time is in the future")
+		}
+		scannedAt = *req.ScannedAt
+	}
+
+	scan := &domain.PermitScan{
+		CheckpointID: checkpoint.ID,
+		PermitNumber: req.PermitNumber,
+		Notes:        req.Notes,
+		ScannedBy:    req.ScannedBy,
+		ScannedAt:    scannedAt,
+	}
+	if req.Headcount != nil {
+		scan.Headcount = *req.Headcount
+	}
+
+	var permit *domain.Permit
+	if req.Token != "" {
+		payload, _, err := s.signer.Verifier().Parse(req.Token)
+		if err != nil {
+			scan.Result = domain.ScanResultInvalid
+			scan.Reason = err.Error()
+		} else {
+			scan.PermitNumber = payload.PermitNumber
+		}
+	}
+
+	if scan.Result == "" {
+		permit, err = s.permitRepo.GetByPermitNumber(scan.PermitNumber)
+		if err != nil {
+			permit = nil
+			scan.Result = domain.ScanResultNotFound
+			scan.Reason = "permit not found"
+		} else {
+			party := permit.PartySummary()
+			scan.PermitID = &permit.ID
+			scan.ExpectedHeadcount = party.Trekkers + party.SupportStaff
+			if req.Headcount == nil {
+				scan.Headcount = scan.ExpectedHeadcount
+			}
+			scan.Result, scan.Reason = evaluatePermit(permit, scannedAt)
+		}
+	}
+
+	if err := s.checkpointRepo.CreateScan(scan); err != nil {
+		return nil, nil, fmt.Errorf("failed to record scan: %w", err)
+	}
+
+	return scan, permit, nil
+}
+
+func (s *checkpointService) ListScans(checkpointID uuid.UUID, limit, offset int, since *time.Time) ([]domain.PermitScan, int64, error) {
+	if _, err := s.checkpointRepo.GetByID(checkpointID); err != nil {
+		return nil, 0, errors.New("checkpoint not found")
+	}
+	return s.checkpointRepo.ListScansByCheckpoint(checkpointID, limit, offset, since)
+}
+
+// PermitTimeline returns every scan of the permit in chronological order.
+func (s *checkpointService) PermitTimeline(actor *Actor, permitID uuid.UUID) ([]domain.PermitScan, error) {
+	permit, err := s.permitRepo.GetByID(permitID)
+	if err != nil || s.authz.checkPermit(actor, permit) != nil {
+		return nil, errors.New("permit not found")
+	}
+	return s.checkpointRepo.ListScansByPermit(permitID)
+}
+
+func evaluatePermit(permit *domain.Permit, at time.Time) (domain.ScanResult, string) {
+	if permit.Status != domain.PermitStatusActive {
+		return domain.ScanResultNotActive, fmt.Sprintf("permit status is %s", permit.Status)
+	}
+	if at.Before(permit.StartDate) {
+		return domain.ScanResultNotYetValid, "permit has not yet started"
+	}
+	if at.After(permit.EndDate) {
+		return domain.ScanResultExpired, "permit has expired"
+	}
+	return domain.ScanResultValid, ""
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/touros-platform/api/internal/config"
+	"github.com/touros-platform/api/internal/repository"
+	"go.uber.org/zap"
+)
+
+// CleanupJob deletes rows that no longer serve a purpose: expired refresh
+// tokens and access token denylist entries, expired account links, the
+// states of abandoned single sign-on logins, and login attempts past their
+// retention. The scheduler runs Run on every cleanup interval.
+type CleanupJob struct {
+	tokenRepo        repository.TokenRepository
+	userTokenRepo    repository.UserTokenRepository
+	loginAttemptRepo repository.LoginAttemptRepository
+	identityRepo     repository.ExternalIdentityRepository
+	config           config.CleanupConfig
+	logger           *zap.Logger
+	now              func() time.Time
+}
+
+func NewCleanupJob(
+	tokenRepo repository.TokenRepository,
+	userTokenRepo repository.UserTokenRepository,
+	loginAttemptRepo repository.LoginAttemptRepository,
+	identityRepo repository.ExternalIdentityRepository,
+	cfg config.CleanupConfig,
+	logger *zap.Logger,
+) *CleanupJob {
+	return &CleanupJob{
+		tokenRepo:        tokenRepo,
+		userTokenRepo:    userTokenRepo,
+		loginAttemptRepo: loginAttemptRepo,
+		identityRepo:     identityRepo,
+		config:           cfg,
+		logger:           logger,
+		now:              time.Now,
+	}
+}
+
+// Run deletes everything that has expired by now.
+func (j *CleanupJob) Run() error {
+	now := j.now()
+
+	tokens, err := j.tokenRepo.DeleteExpired(now)
+	if err != nil {
+		return fmt.Errorf("failed to delete expired tokens: %w", err)
+	}
+	links, err := j.userTokenRepo.DeleteExpired(now)
+	if err != nil {
+		return fmt.Errorf("failed to delete expired account links: %w", err)
+	}
+	states, err := j.identityRepo.DeleteExpiredLoginStates(now)
+	if err != nil {
+		return fmt.Errorf("failed to delete expired login states: %w", err)
+	}
+	attempts, err := j.loginAttemptRepo.DeleteBefore(now.Add(-j.config.LoginAttemptRetention))
+	if err != nil {
+		return fmt.Errorf("failed to delete old login attempts: %w", err)
+	}
+
+	j.logger.Info("Deleted expired records",
+		zap.Int64("tokens", tokens),
+		zap.Int64("account_links", links),
+		zap.Int64("login_states", states),
+		zap.Int64("login_attempts", attempts),
+	)
+	return nil
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/touros-platform/api/internal/storage"
+)
+
+// allowedDocumentTypes are the content types accepted for uploaded
+// documents, detected from the file's contents rather than trusted from the
+// client.
+var allowedDocumentTypes = map[string]bool{
+	"application/pdf": true,
+	"image/jpeg":      true,
+	"image/png":       true,
+}
+
+// storedDocument is an uploaded file written to the blob store.
+type storedDocument struct {
+	FileName    string
+	ContentType string
+	SHA256      string
+}
+
+// storeDocument checks an uploaded document's size and type and streams it
+// to the store under key, hashing it on the way.
+func storeDocument(store storage.BlobStore, key, fileName string, body io.Reader, size, maxSize int64) (*storedDocument, error) {
+	if size <= 0 {
+		return nil, errors.New("file is empty")
+	}
+	if size > maxSize {
+		return nil, fmt.Errorf("file is larger than %d bytes", maxSize)
+	}
+
+	// Read the first 512 bytes to detect the content type, then stream the
+	// rest to the store while hashing.
+	head := make([]byte, 512)
+	n, err := io.ReadFull(body, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	head = head[:n]
+	contentType := http.DetectContentType(head)
+	if i := strings.Index(contentType, ";"); i >= 0 {
+		contentType = contentType[:i]
+	}
+	if !allowedDocumentTypes[contentType] {
+		return nil, fmt.Errorf("unsupported file type %s; upload a PDF, JPEG or PNG", contentType)
+	}
+
+	hash := sha256.New()
+	reader := io.TeeReader(io.MultiReader(bytes.NewReader(head), io.LimitReader(body, size-int64(n))), hash)
+	if err := store.Put(key, reader, size, contentType); err != nil {
+		return nil, fmt.Errorf("failed to store file: %w", err)
+	}
+
+	return &storedDocument{
+		FileName:    path.Base(strings.ReplaceAll(fileName, "\\", "/")),
+		ContentType: contentType,
+		SHA256:      hex.EncodeToString(hash.Sum(nil)),
+	}, nil
+}
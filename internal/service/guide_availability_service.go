@@ -0,0 +1,189 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/touros-platform/api/internal/domain"
+	"github.com/touros-platform/api/internal/repository"
+)
+
+// maxCalendarDays bounds the date range of a guide calendar.
+const maxCalendarDays = 366
+
+// GuideUnavailableError is returned when a permit or blocked period would
+// double-book a guide.
+type GuideUnavailableError = repository.GuideUnavailableError
+
+type GuideAvailabilityService interface {
+	Calendar(actor *Actor, guideID uuid.UUID, from, to time.Time) (*GuideCalendar, error)
+	Block(actor *Actor, guideID uuid.UUID, input *BlockedPeriodInput) (*domain.GuideBlockedPeriod, error)
+	Unblock(actor *Actor, guideID, id uuid.UUID) error
+	Available(actor *Actor, limit, offset int, from, to time.Time, filter repository.GuideFilter) ([]domain.Guide, int64, error)
+}
+
+// GuideCalendar shows when a guide is booked on permits and when they are
+// blocked, between From and To inclusive.
+type GuideCalendar struct {
+	GuideID        uuid.UUID
+	From           time.Time
+	To             time.Time
+	Bookings       []GuideBooking
+	BlockedPeriods []domain.GuideBlockedPeriod
+}
+
+// GuideBooking is an active permit the guide leads.
+type GuideBooking struct {
+	PermitID     uuid.UUID
+	PermitNumber string
+	RouteID      uuid.UUID
+	RouteName    string
+	StartDate    time.Time
+	EndDate      time.Time
+}
+
+// BlockedPeriodInput describes days the guide cannot work, StartDate
+// through EndDate inclusive.
+type BlockedPeriodInput struct {
+	Reason    domain.BlockedPeriodReason
+	StartDate time.Time
+	EndDate   time.Time
+	Note      string
+}
+
+type guideAvailabilityService struct {
+	availabilityRepo repository.GuideAvailabilityRepository
+	guideRepo        repository.GuideRepository
+	authz            *authorizer
+}
+
+func NewGuideAvailabilityService(availabilityRepo repository.GuideAvailabilityRepository, guideRepo repository.GuideRepository, permitRepo repository.PermitRepository) GuideAvailabilityService {
+	return &guideAvailabilityService{
+		availabilityRepo: availabilityRepo,
+		guideRepo:        guideRepo,
+		authz:            newAuthorizer(guideRepo, permitRepo),
+	}
+}
+
+func (s *guideAvailabilityService) Calendar(actor *Actor, guideID uuid.UUID, from, to time.Time) (*GuideCalendar, error) {
+	if err := s.checkGuide(actor, guideID); err != nil {
+		return nil, err
+	}
+	from, to = toDate(from), toDate(to)
+	if err := checkDateRange(from, to, maxCalendarDays); err != nil {
+		return nil, err
+	}
+
+	permits, err := s.availabilityRepo.ListBookings(guideID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	periods, err := s.availabilityRepo.ListBlockedPeriods(guideID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	calendar := &GuideCalendar{
+		GuideID:        guideID,
+		From:           from,
+		To:             to,
+		Bookings:       make([]GuideBooking, 0, len(permits)),
+		BlockedPeriods: periods,
+	}
+	for _, permit := range permits {
+		calendar.Bookings = append(calendar.Bookings, GuideBooking{
+			PermitID:     permit.ID,
+			PermitNumber: permit.PermitNumber,
+			RouteID:      permit.RouteID,
+			RouteName:    permit.Route.Name,
+			StartDate:    permit.StartDate,
+			EndDate:      permit.EndDate,
+		})
+	}
+	return calendar, nil
+}
+
+// Block marks days the guide cannot be assigned to permits. It fails with
+// GuideUnavailableError if the guide already leads a permit on those days;
+// revoke or reassign the permit first.
+func (s *guideAvailabilityService) Block(actor *Actor, guideID uuid.UUID, input *BlockedPeriodInput) (*domain.GuideBlockedPeriod, error) {
+	if err := s.checkGuide(actor, guideID); err != nil {
+		return nil, err
+	}
+	if !input.Reason.Valid() {
+		return nil, errors.New("reason must be one of leave, training, medical or other")
+	}
+	startDate, endDate := toDate(input.StartDate), toDate(input.EndDate)
+	if err := checkDateRange(startDate, endDate, maxCalendarDays); err != nil {
+		return nil, err
+	}
+
+	period := &domain.GuideBlockedPeriod{
+		GuideID:   guideID,
+		Reason:    input.Reason,
+		StartDate: startDate,
+		EndDate:   endDate,
+		Note:      strings.TrimSpace(input.Note),
+		CreatedBy: actor.UserID,
+	}
+	if err := s.availabilityRepo.CreateBlockedPeriod(period); err != nil {
+		return nil, err
+	}
+	return period, nil
+}
+
+func (s *guideAvailabilityService) Unblock(actor *Actor, guideID, id uuid.UUID) error {
+	if err := s.checkGuide(actor, guideID); err != nil {
+		return err
+	}
+
+	period, err := s.availabilityRepo.GetBlockedPeriod(guideID, id)
+	if err != nil {
+		return errors.New("blocked period not found")
+	}
+	return s.availabilityRepo.DeleteBlockedPeriod(period.ID)
+}
+
+// Available lists the verified guides, within the actor's scope and the
+// filter, who are free on every day from through to.
+func (s *guideAvailabilityService) Available(actor *Actor, limit, offset int, from, to time.Time, filter repository.GuideFilter) ([]domain.Guide, int64, error) {
+	scope, err := s.authz.scope(actor)
+	if err != nil {
+		return nil, 0, err
+	}
+	from, to = toDate(from), toDate(to)
+	if err := checkDateRange(from, to, maxCalendarDays); err != nil {
+		return nil, 0, err
+	}
+
+	verified := domain.GuideStatusVerified
+	filter.Status = &verified
+	filter.AvailableFrom = &from
+	filter.AvailableTo = &to
+	return s.guideRepo.List(limit, offset, scope, filter)
+}
+
+// checkGuide checks that the actor may see and manage the guide's
+// calendar.
+func (s *guideAvailabilityService) checkGuide(actor *Actor, guideID uuid.UUID) error {
+	guide, err := s.guideRepo.GetByID(guideID)
+	if err != nil {
+		return errors.New("guide not found")
+	}
+	return s.authz.checkGuide(actor, guide)
+}
+
+// checkDateRange checks that to is not before from and that the range
+// spans at most maxDays days.
+func checkDateRange(from, to time.Time, maxDays int) error {
+	if to.Before(from) {
+		return errors.New("end date must not be before start date")
+	}
+	if days := int(to.Sub(from).Hours()/24) + 1; days > maxDays {
+		return fmt.Errorf("date range must not exceed %d days", maxDays)
+	}
+	return nil
+}
@@ -0,0 +1,218 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/touros-platform/api/internal/config"
+	"github.com/touros-platform/api/internal/domain"
+	"github.com/touros-platform/api/internal/repository"
+	"github.com/touros-platform/api/internal/storage"
+)
+
+type GuideCertificationService interface {
+	Upload(actor *Actor, guideID uuid.UUID, input *CertificationInput, fileName string, body io.Reader, size int64) (*domain.GuideCertification, error)
+	List(actor *Actor, guideID uuid.UUID) ([]domain.GuideCertification, error)
+	Open(actor *Actor, guideID, id uuid.UUID) (*domain.GuideCertification, io.ReadCloser, error)
+	Delete(actor *Actor, guideID, id uuid.UUID) error
+	Approve(guideID, id, reviewedBy uuid.UUID) (*domain.GuideCertification, error)
+	Reject(guideID, id, reviewedBy uuid.UUID, reason string) (*domain.GuideCertification, error)
+}
+
+// CertificationInput describes the certificate being uploaded.
+type CertificationInput struct {
+	Type              domain.CertificationType
+	Issuer            string
+	CertificateNumber string
+	IssueDate         time.Time
+	ExpiryDate        *time.Time
+}
+
+type guideCertificationService struct {
+	certificationRepo repository.GuideCertificationRepository
+	guideRepo         repository.GuideRepository
+	store             storage.BlobStore
+	maxSize           int64
+	authz             *authorizer
+}
+
+func NewGuideCertificationService(certificationRepo repository.GuideCertificationRepository, guideRepo repository.GuideRepository, permitRepo repository.PermitRepository, store storage.BlobStore, cfg config.StorageConfig) GuideCertificationService {
+	return &guideCertificationService{
+		certificationRepo: certificationRepo,
+		guideRepo:         guideRepo,
+		store:             store,
+		maxSize:           cfg.MaxUploadSize,
+		authz:             newAuthorizer(guideRepo, permitRepo),
+	}
+}
+
+// Upload records a certification for admin review, with the scanned
+// certificate as its document.
+func (s *guideCertificationService) Upload(actor *Actor, guideID uuid.UUID, input *CertificationInput, fileName string, body io.Reader, size int64) (*domain.GuideCertification, error) {
+	if err := s.checkGuide(actor, guideID); err != nil {
+		return nil, err
+	}
+
+	if !input.Type.Valid() {
+		return nil, fmt.Errorf("invalid certification type: %s", input.Type)
+	}
+	issuer := strings.TrimSpace(input.Issuer)
+	if issuer == "" {
+		return nil, errors.New("issuer is required")
+	}
+	now := time.Now()
+	if input.IssueDate.After(now) {
+		return nil, errors.New("issue date must not be in the future")
+	}
+	if input.ExpiryDate != nil {
+		if !input.ExpiryDate.After(input.IssueDate) {
+			return nil, errors.New("expiry date must be after the issue date")
+		}
+		if !input.ExpiryDate.After(now) {
+			return nil, errors.New("certification has already expired")
+		}
+	}
+
+	certification := &domain.GuideCertification{
+		ID:                uuid.New(),
+		GuideID:           guideID,
+		Type:              input.Type,
+		Issuer:            issuer,
+		CertificateNumber: strings.TrimSpace(input.CertificateNumber),
+		IssueDate:         input.IssueDate,
+		ExpiryDate:        input.ExpiryDate,
+		Status:            domain.CertificationPending,
+		Size:              size,
+		UploadedBy:        actor.UserID,
+	}
+	certification.StorageKey = fmt.Sprintf("guides/%s/certifications/%s", guideID, certification.ID)
+
+	stored, err := storeDocument(s.store, certification.StorageKey, fileName, body, size, s.maxSize)
+	if err != nil {
+		return nil, err
+	}
+	certification.FileName = stored.FileName
+	certification.ContentType = stored.ContentType
+	certification.SHA256 = stored.SHA256
+
+	if err := s.certificationRepo.Create(certification); err != nil {
+		s.store.Delete(certification.StorageKey)
+		return nil, err
+	}
+	return certification, nil
+}
+
+func (s *guideCertificationService) List(actor *Actor, guideID uuid.UUID) ([]domain.GuideCertification, error) {
+	if err := s.checkGuide(actor, guideID); err != nil {
+		return nil, err
+	}
+	return s.certificationRepo.ListByGuide(guideID)
+}
+
+// Open returns the certification and its document. The caller closes the
+// file.
+func (s *guideCertificationService) Open(actor *Actor, guideID, id uuid.UUID) (*domain.GuideCertification, io.ReadCloser, error) {
+	if err := s.checkGuide(actor, guideID); err != nil {
+		return nil, nil, err
+	}
+
+	certification, err := s.certificationRepo.GetByID(guideID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	file, err := s.store.Get(certification.StorageKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return certification, file, nil
+}
+
+// Delete removes a certification and its document. Approved certifications
+// can only be removed by an admin.
+func (s *guideCertificationService) Delete(actor *Actor, guideID, id uuid.UUID) error {
+	if err := s.checkGuide(actor, guideID); err != nil {
+		return err
+	}
+
+	certification, err := s.certificationRepo.GetByID(guideID, id)
+	if err != nil {
+		return errors.New("certification not found")
+	}
+	if certification.Status == domain.CertificationApproved && actor.Role != domain.RoleAdmin {
+		return ErrForbidden
+	}
+
+	if err := s.certificationRepo.Delete(certification.ID); err != nil {
+		return err
+	}
+	s.store.Delete(certification.StorageKey)
+	return nil
+}
+
+func (s *guideCertificationService) Approve(guideID, id, reviewedBy uuid.UUID) (*domain.GuideCertification, error) {
+	return s.review(guideID, id, reviewedBy, domain.CertificationApproved, "")
+}
+
+// Reject sends the certification back with a reason. The guide uploads a
+// new one to resubmit.
+func (s *guideCertificationService) Reject(guideID, id, reviewedBy uuid.UUID, reason string) (*domain.GuideCertification, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, errors.New("a reason is required to reject a certification")
+	}
+	return s.review(guideID, id, reviewedBy, domain.CertificationRejected, reason)
+}
+
+func (s *guideCertificationService) review(guideID, id, reviewedBy uuid.UUID, status domain.CertificationStatus, reason string) (*domain.GuideCertification, error) {
+	certification, err := s.certificationRepo.GetByID(guideID, id)
+	if err != nil {
+		return nil, errors.New("certification not found")
+	}
+
+	now := time.Now()
+	if status == domain.CertificationApproved && licenseExpired(certification.ExpiryDate, now) {
+		return nil, errors.New("certification has expired")
+	}
+
+	certification.Status = status
+	certification.RejectionReason = reason
+	certification.ReviewedBy = &reviewedBy
+	certification.ReviewedAt = &now
+
+	if err := s.certificationRepo.Update(certification); err != nil {
+		return nil, err
+	}
+	return certification, nil
+}
+
+// checkGuide checks that the actor may manage the guide's certifications.
+func (s *guideCertificationService) checkGuide(actor *Actor, guideID uuid.UUID) error {
+	guide, err := s.guideRepo.GetByID(guideID)
+	if err != nil {
+		return errors.New("guide not found")
+	}
+	return s.authz.checkGuide(actor, guide)
+}
+
+// missingCertifications returns the required certifications the guide does
+// not hold approved and valid through the given time.
+func missingCertifications(required []domain.RouteCertification, held []domain.GuideCertification, through time.Time) []string {
+	var missing []string
+	for _, rc := range required {
+		found := false
+		for i := range held {
+			if held[i].Type == rc.CertificationType && held[i].ValidThrough(through) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, string(rc.CertificationType))
+		}
+	}
+	return missing
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -11,12 +12,12 @@ import (
 )
 
 type GuideService interface {
-	Create(guide *domain.Guide) error
-	GetByID(id uuid.UUID) (*domain.Guide, error)
+	Create(actor *Actor, guide *domain.Guide) error
+	GetByID(actor *Actor, id uuid.UUID) (*domain.Guide, error)
 	GetByUserID(userID uuid.UUID) (*domain.Guide, error)
-	Update(id uuid.UUID, updates *UpdateGuideRequest) (*domain.Guide, error)
+	Update(actor *Actor, id uuid.UUID, updates *UpdateGuideRequest) (*domain.Guide, error)
 	Delete(id uuid.UUID) error
-	List(limit, offset int, status *domain.GuideStatus, agencyID *uuid.UUID) ([]domain.Guide, int64, error)
+	List(actor *Actor, limit, offset int, filter repository.GuideFilter) ([]domain.Guide, int64, error)
 	Verify(id uuid.UUID, verifiedBy uuid.UUID) error
 	Suspend(id uuid.UUID, verifiedBy uuid.UUID) error
 }
@@ -26,21 +27,52 @@ type UpdateGuideRequest struct {
 	EmergencyContact *string
 	LicenseExpiry    *time.Time
 	AgencyID         *uuid.UUID
+	MaxAltitude      *int
+	Languages        *[]domain.GuideLanguage
+	Regions          *[]domain.GuideRegion
 }
 
 type guideService struct {
 	guideRepo repository.GuideRepository
 	userRepo  repository.UserRepository
+	authz     *authorizer
 }
 
-func NewGuideService(guideRepo repository.GuideRepository, userRepo repository.UserRepository) GuideService {
+func NewGuideService(guideRepo repository.GuideRepository, userRepo repository.UserRepository, permitRepo repository.PermitRepository) GuideService {
 	return &guideService{
 		guideRepo: guideRepo,
 		userRepo:  userRepo,
+		authz:     newAuthorizer(guideRepo, permitRepo),
 	}
 }
 
-func (s *guideService) Create(guide *domain.Guide) error {
+// Create registers a guide profile. Agency users register guides for their
+// own agency and guides only their own profile.
+func (s *guideService) Create(actor *Actor, guide *domain.Guide) error {
+	if !actor.Unrestricted() {
+		if actor.Role != domain.RoleAgency && actor.Role != domain.RoleGuide {
+			return ErrForbidden
+		}
+		if actor.Role == domain.RoleGuide && guide.UserID != actor.UserID {
+			return ErrForbidden
+		}
+		if guide.AgencyID != nil && !sameAgency(actor.AgencyID, guide.AgencyID) {
+			return ErrForbidden
+		}
+		guide.AgencyID = actor.AgencyID
+	}
+
+	languages, err := prepareGuideLanguages(guide.Languages)
+	if err != nil {
+		return err
+	}
+	regions, err := prepareGuideRegions(guide.Regions)
+	if err != nil {
+		return err
+	}
+	guide.Languages = languages
+	guide.Regions = regions
+
 	existing, _ := s.guideRepo.GetByLicenseNumber(guide.LicenseNumber)
 	if existing != nil {
 		return errors.New("guide with this license number already exists")
@@ -54,19 +86,50 @@ func (s *guideService) Create(guide *domain.Guide) error {
 	return s.guideRepo.Create(guide)
 }
 
-func (s *guideService) GetByID(id uuid.UUID) (*domain.Guide, error) {
-	return s.guideRepo.GetByID(id)
+func (s *guideService) GetByID(actor *Actor, id uuid.UUID) (*domain.Guide, error) {
+	guide, err := s.guideRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authz.checkGuide(actor, guide); err != nil {
+		return nil, err
+	}
+	return guide, nil
 }
 
 func (s *guideService) GetByUserID(userID uuid.UUID) (*domain.Guide, error) {
 	return s.guideRepo.GetByUserID(userID)
 }
 
-func (s *guideService) Update(id uuid.UUID, updates *UpdateGuideRequest) (*domain.Guide, error) {
+// Update changes a guide profile. Only admins can move a guide to another
+// agency or record its license expiry and altitude qualification.
+func (s *guideService) Update(actor *Actor, id uuid.UUID, updates *UpdateGuideRequest) (*domain.Guide, error) {
 	guide, err := s.guideRepo.GetByID(id)
 	if err != nil {
 		return nil, err
 	}
+	if err := s.authz.checkGuide(actor, guide); err != nil {
+		return nil, err
+	}
+	if updates.AgencyID != nil && actor.Role != domain.RoleAdmin && !sameAgency(updates.AgencyID, guide.AgencyID) {
+		return nil, ErrForbidden
+	}
+	if (updates.LicenseExpiry != nil || updates.MaxAltitude != nil) && actor.Role != domain.RoleAdmin {
+		return nil, ErrForbidden
+	}
+
+	var languages []domain.GuideLanguage
+	if updates.Languages != nil {
+		if languages, err = prepareGuideLanguages(*updates.Languages); err != nil {
+			return nil, err
+		}
+	}
+	var regions []domain.GuideRegion
+	if updates.Regions != nil {
+		if regions, err = prepareGuideRegions(*updates.Regions); err != nil {
+			return nil, err
+		}
+	}
 
 	if updates.PhoneNumber != nil {
 		guide.PhoneNumber = *updates.PhoneNumber
@@ -80,11 +143,30 @@ func (s *guideService) Update(id uuid.UUID, updates *UpdateGuideRequest) (*domai
 	if updates.AgencyID != nil {
 		guide.AgencyID = updates.AgencyID
 	}
+	if updates.MaxAltitude != nil {
+		if *updates.MaxAltitude < 0 {
+			return nil, errors.New("maximum altitude must not be negative")
+		}
+		guide.MaxAltitudeMeters = *updates.MaxAltitude
+	}
 
 	if err := s.guideRepo.Update(guide); err != nil {
 		return nil, err
 	}
 
+	if updates.Languages != nil {
+		if err := s.guideRepo.ReplaceLanguages(guide.ID, languages); err != nil {
+			return nil, err
+		}
+		guide.Languages = languages
+	}
+	if updates.Regions != nil {
+		if err := s.guideRepo.ReplaceRegions(guide.ID, regions); err != nil {
+			return nil, err
+		}
+		guide.Regions = regions
+	}
+
 	return guide, nil
 }
 
@@ -92,10 +174,21 @@ func (s *guideService) Delete(id uuid.UUID) error {
 	return s.guideRepo.Delete(id)
 }
 
-func (s *guideService) List(limit, offset int, status *domain.GuideStatus, agencyID *uuid.UUID) ([]domain.Guide, int64, error) {
-	return s.guideRepo.List(limit, offset, status, agencyID)
+func (s *guideService) List(actor *Actor, limit, offset int, filter repository.GuideFilter) ([]domain.Guide, int64, error) {
+	scope, err := s.authz.scope(actor)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, certification := range filter.Certifications {
+		if !certification.Valid() {
+			return nil, 0, fmt.Errorf("invalid certification type: %s", certification)
+		}
+	}
+	return s.guideRepo.List(limit, offset, scope, filter)
 }
 
+// Verify marks the guide verified. A guide whose license has expired
+// needs the renewed expiry date recorded first.
 func (s *guideService) Verify(id uuid.UUID, verifiedBy uuid.UUID) error {
 	guide, err := s.guideRepo.GetByID(id)
 	if err != nil {
@@ -103,9 +196,14 @@ func (s *guideService) Verify(id uuid.UUID, verifiedBy uuid.UUID) error {
 	}
 
 	now := time.Now()
+	if licenseExpired(guide.LicenseExpiry, now) {
+		return errors.New("guide license has expired; record the renewed license expiry first")
+	}
+
 	guide.Status = domain.GuideStatusVerified
 	guide.VerifiedAt = &now
 	guide.VerifiedBy = &verifiedBy
+	guide.SuspensionReason = ""
 
 	return s.guideRepo.Update(guide)
 }
@@ -122,24 +220,48 @@ func (s *guideService) Suspend(id uuid.UUID, verifiedBy uuid.UUID) error {
 	return s.guideRepo.Update(guide)
 }
 
-func (s *guideService) CheckLicenseExpiry(guideID uuid.UUID) (bool, error) {
-	guide, err := s.guideRepo.GetByID(guideID)
-	if err != nil {
-		return false, err
-	}
 
-	if guide.LicenseExpiry == nil {
-		return true, nil
+// prepareGuideLanguages lowercases the language codes, checks that they
+// look like ISO 639 codes and drops duplicates.
+func prepareGuideLanguages(languages []domain.GuideLanguage) ([]domain.GuideLanguage, error) {
+	seen := make(map[string]bool, len(languages))
+	prepared := make([]domain.GuideLanguage, 0, len(languages))
+	for _, language := range languages {
+		code := normalizeLanguage(language.Language)
+		if len(code) < 2 || len(code) > 3 || strings.Trim(code, "abcdefghijklmnopqrstuvwxyz") != "" {
+			return nil, fmt.Errorf("invalid language code: %q", language.Language)
+		}
+		if seen[code] {
+			continue
+		}
+		seen[code] = true
+		prepared = append(prepared, domain.GuideLanguage{Language: code})
 	}
+	return prepared, nil
+}
 
-	isExpired := guide.LicenseExpiry.Before(time.Now())
-	if isExpired && guide.Status == domain.GuideStatusVerified {
-		guide.Status = domain.GuideStatusSuspended
-		if err := s.guideRepo.Update(guide); err != nil {
-			return false, fmt.Errorf("failed to suspend guide: %w", err)
+// prepareGuideRegions trims the region names and drops duplicates, keeping
+// the first entry of each region.
+func prepareGuideRegions(regions []domain.GuideRegion) ([]domain.GuideRegion, error) {
+	seen := make(map[string]bool, len(regions))
+	prepared := make([]domain.GuideRegion, 0, len(regions))
+	for _, region := range regions {
+		name := strings.TrimSpace(region.Region)
+		if name == "" {
+			return nil, errors.New("region name is required")
+		}
+		if region.YearsExperience < 0 {
+			return nil, fmt.Errorf("region %s: years of experience must not be negative", name)
 		}
+		if seen[strings.ToLower(name)] {
+			continue
+		}
+		seen[strings.ToLower(name)] = true
+		prepared = append(prepared, domain.GuideRegion{Region: name, YearsExperience: region.YearsExperience})
 	}
-
-	return !isExpired, nil
+	return prepared, nil
 }
 
+func normalizeLanguage(code string) string {
+	return strings.ToLower(strings.TrimSpace(code))
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/touros-platform/api/internal/domain"
+	"github.com/touros-platform/api/internal/repository"
+	"gorm.io/gorm"
+)
+
+// IdentityService resolves an authenticated token into the Actor the
+// request is made for.
+type IdentityService interface {
+	Resolve(claims *Claims) (*Actor, error)
+}
+
+type identityService struct {
+	userRepo   repository.UserRepository
+	guideRepo  repository.GuideRepository
+	agencyRepo repository.AgencyRepository
+}
+
+func NewIdentityService(userRepo repository.UserRepository, guideRepo repository.GuideRepository, agencyRepo repository.AgencyRepository) IdentityService {
+	return &identityService{
+		userRepo:   userRepo,
+		guideRepo:  guideRepo,
+		agencyRepo: agencyRepo,
+	}
+}
+
+// Resolve looks up the guide profile of a guide and confirms the agency of
+// an agency user. A guide who has not created a profile yet, or an agency
+// user who has since left the agency named in their token or whose agency
+// no longer exists, resolves without one. Any other lookup failure is
+// returned.
+func (s *identityService) Resolve(claims *Claims) (*Actor, error) {
+	actor := claims.Actor()
+
+	switch actor.Role {
+	case domain.RoleGuide:
+		guide, err := s.guideRepo.GetByUserID(actor.UserID)
+		if err == nil {
+			actor.GuideID = &guide.ID
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("failed to load guide profile: %w", err)
+		}
+	case domain.RoleAgency:
+		agencyID, err := s.confirmAgency(actor)
+		if err != nil {
+			return nil, err
+		}
+		actor.AgencyID = agencyID
+	}
+
+	return actor, nil
+}
+
+// confirmAgency returns the agency in the actor's token if the user still
+// belongs to it and it still exists, or nil otherwise.
+func (s *identityService) confirmAgency(actor *Actor) (*uuid.UUID, error) {
+	if actor.AgencyID == nil {
+		return nil, nil
+	}
+
+	user, err := s.userRepo.GetByID(actor.UserID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to load user: %w", err)
+	}
+	if !sameAgency(user.AgencyID, actor.AgencyID) {
+		return nil, nil
+	}
+
+	_, err = s.agencyRepo.GetByID(*actor.AgencyID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to load agency: %w", err)
+	}
+	return actor.AgencyID, nil
+}
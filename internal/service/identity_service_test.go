@@ -0,0 +1,57 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/touros-platform/api/internal/domain"
+	"github.com/touros-platform/api/internal/repository"
+	"gorm.io/gorm"
+)
+
+// fakeAgencyRepo knows the agencies in its set.
+type fakeAgencyRepo struct {
+	repository.AgencyRepository
+	agencies map[uuid.UUID]bool
+}
+
+func (r *fakeAgencyRepo) GetByID(id uuid.UUID) (*domain.Agency, error) {
+	if !r.agencies[id] {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &domain.Agency{ID: id}, nil
+}
+
+func TestResolveConfirmsAgencyAgainstUser(t *testing.T) {
+	h := newLoginHarness(t)
+	agencyID := uuid.New()
+	otherID := uuid.New()
+	goneID := uuid.New()
+	identity := NewIdentityService(&fakeUserRepo{h}, nil, &fakeAgencyRepo{
+		agencies: map[uuid.UUID]bool{agencyID: true, otherID: true},
+	})
+
+	member := h.addAgencyUser("member@agency.example.com", agencyID, domain.AgencyRoleClerk)
+	moved := h.addAgencyUser("moved@agency.example.com", otherID, domain.AgencyRoleClerk)
+	orphan := h.addAgencyUser("orphan@agency.example.com", goneID, domain.AgencyRoleClerk)
+
+	tests := []struct {
+		name   string
+		claims *Claims
+		want   *uuid.UUID
+	}{
+		{"member", &Claims{UserID: member.ID, Role: domain.RoleAgency, AgencyID: &agencyID}, &agencyID},
+		{"moved to another agency", &Claims{UserID: moved.ID, Role: domain.RoleAgency, AgencyID: &agencyID}, nil},
+		{"agency deleted", &Claims{UserID: orphan.ID, Role: domain.RoleAgency, AgencyID: &goneID}, nil},
+		{"user deleted", &Claims{UserID: uuid.New(), Role: domain.RoleAgency, AgencyID: &agencyID}, nil},
+	}
+	for _, tt := range tests {
+		actor, err := identity.Resolve(tt.claims)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if !sameOptionalID(actor.AgencyID, tt.want) {
+			t.Errorf("%s: agency %v, want %v", tt.name, actor.AgencyID, tt.want)
+		}
+	}
+}
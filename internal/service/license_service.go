@@ -0,0 +1,345 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/touros-platform/api/internal/config"
+	"github.com/touros-platform/api/internal/domain"
+	"github.com/touros-platform/api/internal/mailer"
+	"github.com/touros-platform/api/internal/repository"
+	"go.uber.org/zap"
+)
+
+type LicenseService interface {
+	CheckExpiries() error
+	UpcomingExpiries(days int) (*LicenseExpiryReport, error)
+}
+
+// LicenseExpiryReport lists the guides and agencies whose license expires
+// within Days, including those that have already expired but are not yet
+// suspended.
+type LicenseExpiryReport struct {
+	GeneratedAt time.Time
+	Days        int
+	Guides      []LicenseExpiry
+	Agencies    []LicenseExpiry
+}
+
+// LicenseExpiry is one license on the report. DaysRemaining is negative for
+// a license that has expired.
+type LicenseExpiry struct {
+	HolderType    domain.LicenseHolderType
+	HolderID      uuid.UUID
+	Name          string
+	LicenseNumber string
+	AgencyID      *uuid.UUID
+	Status        string
+	LicenseExpiry time.Time
+	DaysRemaining int
+}
+
+type licenseService struct {
+	guideRepo    repository.GuideRepository
+	agencyRepo   repository.AgencyRepository
+	reminderRepo repository.LicenseReminderRepository
+	mailer       mailer.Mailer
+	reminderDays []int
+	logger       *zap.Logger
+	now          func() time.Time
+}
+
+func NewLicenseService(
+	guideRepo repository.GuideRepository,
+	agencyRepo repository.AgencyRepository,
+	reminderRepo repository.LicenseReminderRepository,
+	mail mailer.Mailer,
+	cfg config.LicenseConfig,
+	logger *zap.Logger,
+) LicenseService {
+	// Nearest day first, so a license is reminded at the closest applicable
+	// day only.
+	reminderDays := append([]int(nil), cfg.ReminderDays...)
+	sort.Ints(reminderDays)
+
+	return &licenseService{
+		guideRepo:    guideRepo,
+		agencyRepo:   agencyRepo,
+		reminderRepo: reminderRepo,
+		mailer:       mail,
+		reminderDays: reminderDays,
+		logger:       logger,
+		now:          time.Now,
+	}
+}
+
+// CheckExpiries sends reminders for licenses about to expire and suspends
+// verified guides and agencies whose license has expired. It runs daily and
+// is safe to repeat: each reminder is sent once per expiry date.
+func (s *licenseService) CheckExpiries() error {
+	now := s.now()
+	horizon := now.AddDate(0, 0, 1)
+	if n := len(s.reminderDays); n > 0 {
+		horizon = now.AddDate(0, 0, s.reminderDays[n-1]+1)
+	}
+
+	guides, err := s.guideRepo.ListLicenseExpiringBefore(horizon)
+	if err != nil {
+		return fmt.Errorf("failed to list guides with expiring licenses: %w", err)
+	}
+	for i := range guides {
+		if err := s.checkGuide(&guides[i], now); err != nil {
+			s.logger.Error("Failed to check guide license",
+				zap.String("guide_id", guides[i].ID.String()),
+				zap.Error(err),
+			)
+		}
+	}
+
+	agencies, err := s.agencyRepo.ListLicenseExpiringBefore(horizon)
+	if err != nil {
+		return fmt.Errorf("failed to list agencies with expiring licenses: %w", err)
+	}
+	for i := range agencies {
+		if err := s.checkAgency(&agencies[i], now); err != nil {
+			s.logger.Error("Failed to check agency license",
+				zap.String("agency_id", agencies[i].ID.String()),
+				zap.Error(err),
+			)
+		}
+	}
+
+	return nil
+}
+
+func (s *licenseService) checkGuide(guide *domain.Guide, now time.Time) error {
+	expiry := *guide.LicenseExpiry
+
+	if !expiry.After(now) {
+		if guide.Status != domain.GuideStatusVerified {
+			return nil
+		}
+		guide.Status = domain.GuideStatusSuspended
+		guide.SuspensionReason = fmt.Sprintf("License %s expired on %s", guide.LicenseNumber, expiry.Format("2006-01-02"))
+		if err := s.guideRepo.Update(guide); err != nil {
+			return fmt.Errorf("failed to suspend guide: %w", err)
+		}
+
+		s.logger.Warn("Guide suspended for expired license",
+			zap.String("guide_id", guide.ID.String()),
+			zap.String("license_number", guide.LicenseNumber),
+		)
+		s.send(&mailer.Message{
+			To:      guide.User.Email,
+			Subject: "Your Touros guide license has expired",
+			Body: fmt.Sprintf("Hello %s,\n\n"+
+				"Your guide license %s expired on %s, and your guide profile has been suspended. "+
+				"No new permits can be issued to you until the license is renewed and your profile is verified again.\n",
+				guide.User.FullName, guide.LicenseNumber, expiry.Format("2 January 2006")),
+		})
+		if guide.Agency != nil {
+			s.send(&mailer.Message{
+				To:      guide.Agency.ContactEmail,
+				Subject: "Guide suspended: license expired",
+				Body: fmt.Sprintf("Hello %s,\n\n"+
+					"The license %s of your guide %s expired on %s, and the guide has been suspended. "+
+					"No new permits can be issued with this guide until the license is renewed.\n",
+					guide.Agency.Name, guide.LicenseNumber, guide.User.FullName, expiry.Format("2 January 2006")),
+			})
+		}
+		return nil
+	}
+
+	days, ok := s.reminderDay(expiry, now)
+	if !ok {
+		return nil
+	}
+	return s.remind(domain.LicenseHolderGuide, guide.ID, expiry, days, func() error {
+		if err := s.mailer.Send(&mailer.Message{
+			To:      guide.User.Email,
+			Subject: fmt.Sprintf("Your Touros guide license expires in %d days", daysUntil(expiry, now)),
+			Body: fmt.Sprintf("Hello %s,\n\n"+
+				"Your guide license %s expires on %s. Renew it before then; once it expires your guide profile "+
+				"is suspended and no new permits can be issued to you.\n",
+				guide.User.FullName, guide.LicenseNumber, expiry.Format("2 January 2006")),
+		}); err != nil {
+			return err
+		}
+		if guide.Agency != nil {
+			s.send(&mailer.Message{
+				To:      guide.Agency.ContactEmail,
+				Subject: fmt.Sprintf("Guide license expires in %d days", daysUntil(expiry, now)),
+				Body: fmt.Sprintf("Hello %s,\n\n"+
+					"The license %s of your guide %s expires on %s.\n",
+					guide.Agency.Name, guide.LicenseNumber, guide.User.FullName, expiry.Format("2 January 2006")),
+			})
+		}
+		return nil
+	})
+}
+
+func (s *licenseService) checkAgency(agency *domain.Agency, now time.Time) error {
+	expiry := *agency.LicenseExpiry
+
+	if !expiry.After(now) {
+		if agency.Status != domain.AgencyStatusVerified {
+			return nil
+		}
+		agency.Status = domain.AgencyStatusSuspended
+		agency.SuspensionReason = fmt.Sprintf("License %s expired on %s", agency.LicenseNumber, expiry.Format("2006-01-02"))
+		if err := s.agencyRepo.Update(agency); err != nil {
+			return fmt.Errorf("failed to suspend agency: %w", err)
+		}
+
+		s.logger.Warn("Agency suspended for expired license",
+			zap.String("agency_id", agency.ID.String()),
+			zap.String("license_number", agency.LicenseNumber),
+		)
+		s.send(&mailer.Message{
+			To:      agency.ContactEmail,
+			Subject: "Your Touros agency license has expired",
+			Body: fmt.Sprintf("Hello %s,\n\n"+
+				"Your agency license %s expired on %s, and your agency has been suspended. "+
+				"No new permits can be issued for the agency until the license is renewed and the agency is verified again.\n",
+				agency.Name, agency.LicenseNumber, expiry.Format("2 January 2006")),
+		})
+		return nil
+	}
+
+	days, ok := s.reminderDay(expiry, now)
+	if !ok {
+		return nil
+	}
+	return s.remind(domain.LicenseHolderAgency, agency.ID, expiry, days, func() error {
+		return s.mailer.Send(&mailer.Message{
+			To:      agency.ContactEmail,
+			Subject: fmt.Sprintf("Your Touros agency license expires in %d days", daysUntil(expiry, now)),
+			Body: fmt.Sprintf("Hello %s,\n\n"+
+				"Your agency license %s expires on %s. Renew it before then; once it expires the agency "+
+				"is suspended and no new permits can be issued for it.\n",
+				agency.Name, agency.LicenseNumber, expiry.Format("2 January 2006")),
+		})
+	})
+}
+
+// reminderDay returns the nearest reminder day the license has reached. A
+// license first seen 5 days before expiry gets the 7 day reminder only, not
+// every reminder it missed.
+func (s *licenseService) reminderDay(expiry, now time.Time) (int, bool) {
+	remaining := daysUntil(expiry, now)
+	for _, days := range s.reminderDays {
+		if remaining <= days {
+			return days, true
+		}
+	}
+	return 0, false
+}
+
+// remind sends a reminder unless it has been sent already. A reminder that
+// fails to send is released so that the next run retries it.
+func (s *licenseService) remind(holderType domain.LicenseHolderType, holderID uuid.UUID, expiry time.Time, days int, send func() error) error {
+	reminder := &domain.LicenseReminder{
+		HolderType:    holderType,
+		HolderID:      holderID,
+		LicenseExpiry: expiry,
+		DaysBefore:    days,
+		SentAt:        s.now(),
+	}
+	claimed, err := s.reminderRepo.Claim(reminder)
+	if err != nil {
+		return fmt.Errorf("failed to record reminder: %w", err)
+	}
+	if !claimed {
+		return nil
+	}
+
+	if err := send(); err != nil {
+		if releaseErr := s.reminderRepo.Delete(reminder.ID); releaseErr != nil {
+			s.logger.Error("Failed to release unsent reminder", zap.Error(releaseErr))
+		}
+		return fmt.Errorf("failed to send reminder: %w", err)
+	}
+
+	s.logger.Info("License expiry reminder sent",
+		zap.String("holder_type", string(holderType)),
+		zap.String("holder_id", holderID.String()),
+		zap.Int("days_before", days),
+	)
+	return nil
+}
+
+// UpcomingExpiries reports the licenses that expire within the given
+// number of days.
+func (s *licenseService) UpcomingExpiries(days int) (*LicenseExpiryReport, error) {
+	now := s.now()
+	cutoff := now.AddDate(0, 0, days)
+
+	guides, err := s.guideRepo.ListLicenseExpiringBefore(cutoff)
+	if err != nil {
+		return nil, err
+	}
+	agencies, err := s.agencyRepo.ListLicenseExpiringBefore(cutoff)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &LicenseExpiryReport{
+		GeneratedAt: now,
+		Days:        days,
+		Guides:      make([]LicenseExpiry, 0, len(guides)),
+		Agencies:    make([]LicenseExpiry, 0, len(agencies)),
+	}
+	for _, guide := range guides {
+		report.Guides = append(report.Guides, LicenseExpiry{
+			HolderType:    domain.LicenseHolderGuide,
+			HolderID:      guide.ID,
+			Name:          guide.User.FullName,
+			LicenseNumber: guide.LicenseNumber,
+			AgencyID:      guide.AgencyID,
+			Status:        string(guide.Status),
+			LicenseExpiry: *guide.LicenseExpiry,
+			DaysRemaining: daysUntil(*guide.LicenseExpiry, now),
+		})
+	}
+	for _, agency := range agencies {
+		agencyID := agency.ID
+		report.Agencies = append(report.Agencies, LicenseExpiry{
+			HolderType:    domain.LicenseHolderAgency,
+			HolderID:      agency.ID,
+			Name:          agency.Name,
+			LicenseNumber: agency.LicenseNumber,
+			AgencyID:      &agencyID,
+			Status:        string(agency.Status),
+			LicenseExpiry: *agency.LicenseExpiry,
+			DaysRemaining: daysUntil(*agency.LicenseExpiry, now),
+		})
+	}
+	return report, nil
+}
+
+// send delivers a notice without failing the job; delivery problems are
+// logged.
+func (s *licenseService) send(msg *mailer.Message) {
+	if err := s.mailer.Send(msg); err != nil {
+		s.logger.Error("Failed to send email",
+			zap.String("to", msg.To),
+			zap.String("subject", msg.Subject),
+			zap.Error(err),
+		)
+	}
+}
+
+// daysUntil counts whole days to expiry, rounding up, so a license that
+// expires later today has 1 day left. It is negative once expired.
+func daysUntil(expiry, now time.Time) int {
+	return int(math.Ceil(expiry.Sub(now).Hours() / 24))
+}
+
+// licenseExpired reports whether a license with the given expiry has
+// expired. A license without an expiry date never expires.
+func licenseExpired(expiry *time.Time, now time.Time) bool {
+	return expiry != nil && !expiry.After(now)
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/touros-platform/api/internal/config"
+	"github.com/touros-platform/api/internal/domain"
+	"github.com/touros-platform/api/internal/repository"
+)
+
+// LoginThrottledError is returned while an account or client address is
+// locked out after repeated failed logins.
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginThrottledError) Error() string {
+	return fmt.Sprintf("too many failed login attempts, try again in %s", e.RetryAfter)
+}
+
+// loginGuard records login attempts and applies the lockout policy in
+// config.LoginConfig.
+type loginGuard struct {
+	attemptRepo repository.LoginAttemptRepository
+	config      config.LoginConfig
+	now         func() time.Time
+}
+
+func newLoginGuard(attemptRepo repository.LoginAttemptRepository, cfg config.LoginConfig) *loginGuard {
+	return &loginGuard{
+		attemptRepo: attemptRepo,
+		config:      cfg,
+		now:         time.Now,
+	}
+}
+
+// checkIP refuses a client address with too many recent failures.
+func (g *loginGuard) checkIP(email, ip string) error {
+	if g.config.IPMaxFailures <= 0 {
+		return nil
+	}
+
+	failures, err := g.attemptRepo.CountIPFailures(ip, g.now().Add(-g.config.IPWindow))
+	if err != nil {
+		return fmt.Errorf("failed to check login attempts: %w", err)
+	}
+	if failures < int64(g.config.IPMaxFailures) {
+		return nil
+	}
+
+	if err := g.record(email, nil, ip, domain.LoginIPThrottled); err != nil {
+		return err
+	}
+	return &LoginThrottledError{RetryAfter: g.config.IPWindow}
+}
+
+// checkAccount refuses a locked account, whatever the password.
+func (g *loginGuard) checkAccount(user *domain.User, ip string) error {
+	if user.LockedUntil == nil {
+		return nil
+	}
+	wait := user.LockedUntil.Sub(g.now())
+	if wait <= 0 {
+		return nil
+	}
+
+	if err := g.record(user.Email, user, ip, domain.LoginLocked); err != nil {
+		return err
+	}
+	return &LoginThrottledError{RetryAfter: wait.Round(time.Second)}
+}
+
+// fail records a failed attempt and, for a known account, locks it once it
+// has failed too many times in a row.
+func (g *loginGuard) fail(email string, user *domain.User, ip string, result domain.LoginAttemptResult) error {
+	if err := g.record(email, user, ip, result); err != nil {
+		return err
+	}
+	if user == nil {
+		return nil
+	}
+
+	failures, err := g.attemptRepo.AddFailure(user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to count login failure: %w", err)
+	}
+
+	if lockout := g.lockoutFor(failures); lockout > 0 {
+		if err := g.attemptRepo.Lock(user.ID, g.now().Add(lockout)); err != nil {
+			return fmt.Errorf("failed to lock account: %w", err)
+		}
+	}
+	return nil
+}
+
+// succeed records a completed login and clears the account's failures.
+func (g *loginGuard) succeed(user *domain.User, ip string) error {
+	if err := g.record(user.Email, user, ip, domain.LoginSucceeded); err != nil {
+		return err
+	}
+	if user.FailedLoginAttempts == 0 && user.LockedUntil == nil {
+		return nil
+	}
+	return g.attemptRepo.Reset(user.ID)
+}
+
+// lockoutFor returns how long an account with the given number of
+// consecutive failures stays locked: LockoutBase at the threshold, doubling
+// with each further failure, capped at LockoutMax.
+func (g *loginGuard) lockoutFor(failures int) time.Duration {
+	if g.config.LockoutThreshold <= 0 || failures < g.config.LockoutThreshold {
+		return 0
+	}
+
+	lockout := g.config.LockoutBase
+	for i := g.config.LockoutThreshold; i < failures && lockout < g.config.LockoutMax; i++ {
+		lockout *= 2
+	}
+	if lockout > g.config.LockoutMax {
+		lockout = g.config.LockoutMax
+	}
+	return lockout
+}
+
+func (g *loginGuard) record(email string, user *domain.User, ip string, result domain.LoginAttemptResult) error {
+	attempt := &domain.LoginAttempt{
+		Email:     email,
+		IPAddress: ip,
+		Result:    result,
+		CreatedAt: g.now(),
+	}
+	if user != nil {
+		attempt.UserID = &user.ID
+	}
+
+	if err := g.attemptRepo.Create(attempt); err != nil {
+		return fmt.Errorf("failed to record login attempt: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,502 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/touros-platform/api/internal/config"
+	"github.com/touros-platform/api/internal/domain"
+	"github.com/touros-platform/api/internal/repository"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	testPassword = "correct-horse"
+	testMFACode  = "123456"
+)
+
+// loginHarness runs authService against in-memory repositories and a
+// controllable clock.
+type loginHarness struct {
+	t          *testing.T
+	now        time.Time
+	users      map[uuid.UUID]*domain.User
+	attempts   []domain.LoginAttempt
+	mfaEnabled map[uuid.UUID]bool
+	auth       *authService
+}
+
+func newLoginHarness(t *testing.T) *loginHarness {
+	t.Helper()
+
+	h := &loginHarness{
+		t:          t,
+		now:        time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC),
+		users:      make(map[uuid.UUID]*domain.User),
+		mfaEnabled: make(map[uuid.UUID]bool),
+	}
+
+	cfg := &config.Config{
+		JWT: config.JWTConfig{
+			AccessSecret:  "test-access-secret-of-sufficient-length",
+			RefreshSecret: "test-refresh-secret-of-sufficient-length",
+			AccessTTL:     15 * time.Minute,
+			RefreshTTL:    time.Hour,
+		},
+		MFA: config.MFAConfig{ChallengeTTL: 5 * time.Minute},
+		Login: config.LoginConfig{
+			LockoutThreshold: 3,
+			LockoutBase:      time.Minute,
+			LockoutMax:       8 * time.Minute,
+			IPMaxFailures:    10,
+			IPWindow:         15 * time.Minute,
+		},
+	}
+
+	keyring, err := NewTokenKeyring(cfg.JWT)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	auth := NewAuthService(&fakeUserRepo{h}, &fakeTokenRepo{}, &fakeLoginAttemptRepo{h}, &fakeMFAService{h}, keyring, cfg).(*authService)
+	auth.guard.now = func() time.Time { return h.now }
+	h.auth = auth
+	return h
+}
+
+func (h *loginHarness) addUser(email string) *domain.User {
+	h.t.Helper()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(testPassword), bcrypt.MinCost)
+	if err != nil {
+		h.t.Fatal(err)
+	}
+	verified := h.now
+	user := &domain.User{
+		ID:              uuid.New(),
+		Email:           email,
+		PasswordHash:    string(hash),
+		Role:            domain.RoleGuide,
+		IsActive:        true,
+		EmailVerifiedAt: &verified,
+	}
+	h.users[user.ID] = user
+	return user
+}
+
+func (h *loginHarness) advance(d time.Duration) {
+	h.now = h.now.Add(d)
+}
+
+func (h *loginHarness) mustFail(email, password, ip string) {
+	h.t.Helper()
+
+	if _, err := h.auth.Login(email, password, ip); err == nil || isThrottled(err) {
+		h.t.Fatalf("login of %s: got %v, want invalid credentials", email, err)
+	}
+}
+
+func (h *loginHarness) mustSucceed(email, ip string) {
+	h.t.Helper()
+
+	result, err := h.auth.Login(email, testPassword, ip)
+	if err != nil {
+		h.t.Fatalf("login of %s: %v", email, err)
+	}
+	if result.Tokens == nil {
+		h.t.Fatalf("login of %s: no tokens issued", email)
+	}
+}
+
+func (h *loginHarness) mustBeThrottled(err error, retryAfter time.Duration) {
+	h.t.Helper()
+
+	var throttled *LoginThrottledError
+	if !errors.As(err, &throttled) {
+		h.t.Fatalf("got %v, want LoginThrottledError", err)
+	}
+	if throttled.RetryAfter != retryAfter {
+		h.t.Fatalf("RetryAfter = %s, want %s", throttled.RetryAfter, retryAfter)
+	}
+}
+
+func isThrottled(err error) bool {
+	var throttled *LoginThrottledError
+	return errors.As(err, &throttled)
+}
+
+func TestLoginLocksAccountAfterThreshold(t *testing.T) {
+	h := newLoginHarness(t)
+	h.addUser("guide@example.com")
+
+	for i := 0; i < 3; i++ {
+		h.mustFail("guide@example.com", "wrong-password", "10.0.0.1")
+	}
+
+	_, err := h.auth.Login("guide@example.com", testPassword, "10.0.0.1")
+	h.mustBeThrottled(err, time.Minute)
+
+	h.advance(time.Minute)
+	h.mustSucceed("guide@example.com", "10.0.0.1")
+}
+
+func TestLoginLockoutBacksOffExponentially(t *testing.T) {
+	h := newLoginHarness(t)
+	h.addUser("guide@example.com")
+
+	h.mustFail("guide@example.com", "wrong-password", "10.0.0.1")
+	h.mustFail("guide@example.com", "wrong-password", "10.0.0.1")
+
+	for _, want := range []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute, 8 * time.Minute, 8 * time.Minute} {
+		h.mustFail("guide@example.com", "wrong-password", "10.0.0.1")
+
+		_, err := h.auth.Login("guide@example.com", testPassword, "10.0.0.1")
+		h.mustBeThrottled(err, want)
+
+		h.advance(want)
+	}
+}
+
+func TestLoginWhileLockedDoesNotExtendLockout(t *testing.T) {
+	h := newLoginHarness(t)
+	user := h.addUser("guide@example.com")
+
+	for i := 0; i < 3; i++ {
+		h.mustFail("guide@example.com", "wrong-password", "10.0.0.1")
+	}
+	lockedUntil := *user.LockedUntil
+
+	for i := 0; i < 5; i++ {
+		_, err := h.auth.Login("guide@example.com", "wrong-password", "10.0.0.1")
+		if !isThrottled(err) {
+			t.Fatalf("got %v, want LoginThrottledError", err)
+		}
+	}
+
+	if user.FailedLoginAttempts != 3 {
+		t.Fatalf("FailedLoginAttempts = %d, want 3", user.FailedLoginAttempts)
+	}
+	if !user.LockedUntil.Equal(lockedUntil) {
+		t.Fatalf("LockedUntil moved from %s to %s", lockedUntil, user.LockedUntil)
+	}
+}
+
+func TestLoginSuccessResetsFailureCount(t *testing.T) {
+	h := newLoginHarness(t)
+	user := h.addUser("guide@example.com")
+
+	h.mustFail("guide@example.com", "wrong-password", "10.0.0.1")
+	h.mustFail("guide@example.com", "wrong-password", "10.0.0.1")
+	h.mustSucceed("guide@example.com", "10.0.0.1")
+
+	if user.FailedLoginAttempts != 0 {
+		t.Fatalf("FailedLoginAttempts = %d after success, want 0", user.FailedLoginAttempts)
+	}
+
+	h.mustFail("guide@example.com", "wrong-password", "10.0.0.1")
+	h.mustFail("guide@example.com", "wrong-password", "10.0.0.1")
+	h.mustSucceed("guide@example.com", "10.0.0.1")
+}
+
+func TestLoginThrottlesClientAddress(t *testing.T) {
+	h := newLoginHarness(t)
+	h.addUser("guide@example.com")
+
+	// Spraying many accounts from one address trips the address limit even
+	// though no single account reaches its threshold.
+	for i := 0; i < 10; i++ {
+		h.mustFail("unknown@example.com", "wrong-password", "10.0.0.1")
+	}
+
+	_, err := h.auth.Login("guide@example.com", testPassword, "10.0.0.1")
+	h.mustBeThrottled(err, 15*time.Minute)
+
+	h.mustSucceed("guide@example.com", "10.0.0.2")
+
+	h.advance(15 * time.Minute)
+	h.mustSucceed("guide@example.com", "10.0.0.1")
+}
+
+func TestVerifyMFAFailuresCountTowardsLockout(t *testing.T) {
+	h := newLoginHarness(t)
+	user := h.addUser("admin@example.com")
+	h.mfaEnabled[user.ID] = true
+
+	result, err := h.auth.Login("admin@example.com", testPassword, "10.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Challenge == nil {
+		t.Fatal("login of MFA user issued tokens without a challenge")
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := h.auth.VerifyMFA(result.Challenge.Token, "000000", "", "10.0.0.1"); !errors.Is(err, errInvalidMFACode) {
+			t.Fatalf("got %v, want invalid code", err)
+		}
+	}
+
+	_, err = h.auth.VerifyMFA(result.Challenge.Token, testMFACode, "", "10.0.0.1")
+	h.mustBeThrottled(err, time.Minute)
+
+	h.advance(time.Minute)
+	if _, err := h.auth.VerifyMFA(result.Challenge.Token, testMFACode, "", "10.0.0.1"); err != nil {
+		t.Fatal(err)
+	}
+	if user.FailedLoginAttempts != 0 {
+		t.Fatalf("FailedLoginAttempts = %d after success, want 0", user.FailedLoginAttempts)
+	}
+}
+
+func TestLoginRecordsAttempts(t *testing.T) {
+	h := newLoginHarness(t)
+	user := h.addUser("guide@example.com")
+
+	h.mustFail("nobody@example.com", "wrong-password", "10.0.0.1")
+	for i := 0; i < 3; i++ {
+		h.mustFail("guide@example.com", "wrong-password", "10.0.0.1")
+	}
+	if _, err := h.auth.Login("guide@example.com", testPassword, "10.0.0.1"); !isThrottled(err) {
+		t.Fatalf("got %v, want LoginThrottledError", err)
+	}
+	h.advance(time.Minute)
+	h.mustSucceed("guide@example.com", "10.0.0.1")
+
+	want := []domain.LoginAttemptResult{
+		domain.LoginUnknownEmail,
+		domain.LoginBadPassword,
+		domain.LoginBadPassword,
+		domain.LoginBadPassword,
+		domain.LoginLocked,
+		domain.LoginSucceeded,
+	}
+	if len(h.attempts) != len(want) {
+		t.Fatalf("recorded %d attempts, want %d", len(h.attempts), len(want))
+	}
+	for i, attempt := range h.attempts {
+		if attempt.Result != want[i] {
+			t.Errorf("attempt %d: result %s, want %s", i, attempt.Result, want[i])
+		}
+		if attempt.IPAddress != "10.0.0.1" {
+			t.Errorf("attempt %d: ip %q, want 10.0.0.1", i, attempt.IPAddress)
+		}
+	}
+	if h.attempts[0].UserID != nil {
+		t.Error("attempt for unknown email has a user")
+	}
+	if h.attempts[5].UserID == nil || *h.attempts[5].UserID != user.ID {
+		t.Error("successful attempt is not linked to the user")
+	}
+}
+
+func TestUnlockLiftsLockout(t *testing.T) {
+	h := newLoginHarness(t)
+	user := h.addUser("guide@example.com")
+
+	for i := 0; i < 3; i++ {
+		h.mustFail("guide@example.com", "wrong-password", "10.0.0.1")
+	}
+
+	users := NewUserService(&fakeUserRepo{h}, nil, nil, &fakeLoginAttemptRepo{h}, nil)
+	if _, err := users.Unlock(user.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	h.mustSucceed("guide@example.com", "10.0.0.1")
+}
+
+func TestLockoutFor(t *testing.T) {
+	g := newLoginGuard(nil, config.LoginConfig{
+		LockoutThreshold: 5,
+		LockoutBase:      time.Minute,
+		LockoutMax:       time.Hour,
+	})
+
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{0, 0},
+		{4, 0},
+		{5, time.Minute},
+		{6, 2 * time.Minute},
+		{10, 32 * time.Minute},
+		{11, time.Hour},
+		{1000, time.Hour},
+	}
+	for _, tt := range tests {
+		if got := g.lockoutFor(tt.failures); got != tt.want {
+			t.Errorf("lockoutFor(%d) = %s, want %s", tt.failures, got, tt.want)
+		}
+	}
+
+	g.config.LockoutThreshold = 0
+	if got := g.lockoutFor(1000); got != 0 {
+		t.Errorf("lockoutFor with lockout disabled = %s, want 0", got)
+	}
+}
+
+// fakeUserRepo serves the harness users. It returns copies, like the
+// database does, so the service cannot rely on in-memory updates.
+type fakeUserRepo struct {
+	h *loginHarness
+}
+
+var _ repository.UserRepository = (*fakeUserRepo)(nil)
+
+func (r *fakeUserRepo) Create(user *domain.User) error {
+	r.h.users[user.ID] = user
+	return nil
+}
+
+func (r *fakeUserRepo) GetByID(id uuid.UUID) (*domain.User, error) {
+	user, ok := r.h.users[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *user
+	return &copied, nil
+}
+
+func (r *fakeUserRepo) GetByEmail(email string) (*domain.User, error) {
+	for _, user := range r.h.users {
+		if user.Email == email {
+			copied := *user
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeUserRepo) Update(user *domain.User) error {
+	copied := *user
+	r.h.users[user.ID] = &copied
+	return nil
+}
+
+func (r *fakeUserRepo) Delete(id uuid.UUID) error {
+	delete(r.h.users, id)
+	return nil
+}
+
+func (r *fakeUserRepo) List(limit, offset int, role *domain.Role, active *bool) ([]domain.User, int64, error) {
+	return nil, 0, errors.New("not implemented")
+}
+
+func (r *fakeUserRepo) ListByAgency(agencyID uuid.UUID) ([]domain.User, error) {
+	var users []domain.User
+	for _, user := range r.h.users {
+		if user.AgencyID != nil && *user.AgencyID == agencyID {
+			users = append(users, *user)
+		}
+	}
+	return users, nil
+}
+
+type fakeLoginAttemptRepo struct {
+	h *loginHarness
+}
+
+var _ repository.LoginAttemptRepository = (*fakeLoginAttemptRepo)(nil)
+
+func (r *fakeLoginAttemptRepo) Create(attempt *domain.LoginAttempt) error {
+	r.h.attempts = append(r.h.attempts, *attempt)
+	return nil
+}
+
+func (r *fakeLoginAttemptRepo) List(limit, offset int, userID *uuid.UUID, ip *string) ([]domain.LoginAttempt, int64, error) {
+	return nil, 0, errors.New("not implemented")
+}
+
+func (r *fakeLoginAttemptRepo) CountIPFailures(ip string, since time.Time) (int64, error) {
+	var count int64
+	for _, attempt := range r.h.attempts {
+		if attempt.IPAddress != ip || !attempt.CreatedAt.After(since) {
+			continue
+		}
+		switch attempt.Result {
+		case domain.LoginBadPassword, domain.LoginUnknownEmail, domain.LoginBadMFACode:
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *fakeLoginAttemptRepo) AddFailure(userID uuid.UUID) (int, error) {
+	user := r.h.users[userID]
+	user.FailedLoginAttempts++
+	return user.FailedLoginAttempts, nil
+}
+
+func (r *fakeLoginAttemptRepo) Lock(userID uuid.UUID, until time.Time) error {
+	r.h.users[userID].LockedUntil = &until
+	return nil
+}
+
+func (r *fakeLoginAttemptRepo) Reset(userID uuid.UUID) error {
+	user := r.h.users[userID]
+	user.FailedLoginAttempts = 0
+	user.LockedUntil = nil
+	return nil
+}
+
+func (r *fakeLoginAttemptRepo) DeleteBefore(before time.Time) (int64, error) {
+	return 0, errors.New("not implemented")
+}
+
+// fakeTokenRepo accepts refresh tokens and stores nothing.
+type fakeTokenRepo struct {
+	repository.TokenRepository
+}
+
+func (r *fakeTokenRepo) CreateRefreshToken(token *domain.RefreshToken) error {
+	return nil
+}
+
+// fakeMFAService accepts testMFACode for users with MFA enabled.
+type fakeMFAService struct {
+	h *loginHarness
+}
+
+var _ MFAService = (*fakeMFAService)(nil)
+
+func (s *fakeMFAService) Status(userID uuid.UUID) (*MFAStatus, error) {
+	return &MFAStatus{Enabled: s.h.mfaEnabled[userID]}, nil
+}
+
+func (s *fakeMFAService) Enabled(userID uuid.UUID) (bool, error) {
+	return s.h.mfaEnabled[userID], nil
+}
+
+func (s *fakeMFAService) Required(role domain.Role) bool {
+	return false
+}
+
+func (s *fakeMFAService) BeginEnrollment(userID uuid.UUID) (*MFAEnrollment, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (s *fakeMFAService) ConfirmEnrollment(userID uuid.UUID, code string) ([]string, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (s *fakeMFAService) Verify(userID uuid.UUID, code, recoveryCode string) error {
+	if !s.h.mfaEnabled[userID] {
+		return errors.New("MFA is not enabled")
+	}
+	if code != testMFACode {
+		return errInvalidMFACode
+	}
+	return nil
+}
+
+func (s *fakeMFAService) RegenerateRecoveryCodes(userID uuid.UUID, code string) ([]string, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (s *fakeMFAService) Disable(userID uuid.UUID, password, code string) error {
+	return errors.New("not implemented")
+}
@@ -0,0 +1,283 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/touros-platform/api/internal/config"
+	"github.com/touros-platform/api/internal/domain"
+	"github.com/touros-platform/api/internal/repository"
+	"github.com/touros-platform/api/pkg/totp"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	recoveryCodeCount = 10
+	// totpSkew accepts codes from one step either side of the current one to
+	// allow for clock drift on the user's device.
+	totpSkew = 1
+)
+
+var (
+	errInvalidMFACode = errors.New("invalid authentication code")
+	errMFACodeReused  = errors.New("authentication code has already been used")
+)
+
+type MFAService interface {
+	Status(userID uuid.UUID) (*MFAStatus, error)
+	Enabled(userID uuid.UUID) (bool, error)
+	Required(role domain.Role) bool
+	BeginEnrollment(userID uuid.UUID) (*MFAEnrollment, error)
+	ConfirmEnrollment(userID uuid.UUID, code string) ([]string, error)
+	Verify(userID uuid.UUID, code, recoveryCode string) error
+	RegenerateRecoveryCodes(userID uuid.UUID, code string) ([]string, error)
+	Disable(userID uuid.UUID, password, code string) error
+}
+
+type MFAStatus struct {
+	Enabled           bool
+	Required          bool
+	RecoveryCodesLeft int64
+}
+
+// MFAEnrollment is shown to the user once so they can add the secret to an
+// authenticator app, either by scanning URI as a QR code or by typing Secret.
+type MFAEnrollment struct {
+	Secret string
+	URI    string
+}
+
+type mfaService struct {
+	mfaRepo       repository.MFARepository
+	userRepo      repository.UserRepository
+	issuer        string
+	requiredRoles map[domain.Role]bool
+}
+
+func NewMFAService(mfaRepo repository.MFARepository, userRepo repository.UserRepository, cfg config.MFAConfig) MFAService {
+	required := make(map[domain.Role]bool)
+	for _, role := range strings.Split(cfg.RequiredRoles, ",") {
+		if role = strings.TrimSpace(role); role != "" {
+			required[domain.Role(role)] = true
+		}
+	}
+
+	return &mfaService{
+		mfaRepo:       mfaRepo,
+		userRepo:      userRepo,
+		issuer:        cfg.Issuer,
+		requiredRoles: required,
+	}
+}
+
+func (s *mfaService) Status(userID uuid.UUID) (*MFAStatus, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	enabled, err := s.Enabled(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &MFAStatus{
+		Enabled:  enabled,
+		Required: s.Required(user.Role),
+	}
+	if enabled {
+		status.RecoveryCodesLeft, err = s.mfaRepo.CountRecoveryCodes(userID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return status, nil
+}
+
+// Enabled reports whether the user has a confirmed authenticator.
+func (s *mfaService) Enabled(userID uuid.UUID) (bool, error) {
+	factor, err := s.mfaRepo.GetFactor(userID)
+	if err != nil {
+		return false, err
+	}
+	return factor != nil && factor.ConfirmedAt != nil, nil
+}
+
+// Required reports whether policy makes MFA mandatory for the role.
+func (s *mfaService) Required(role domain.Role) bool {
+	return s.requiredRoles[role]
+}
+
+// BeginEnrollment creates a new unconfirmed authenticator secret, replacing
+// any earlier enrollment that was never confirmed.
+func (s *mfaService) BeginEnrollment(userID uuid.UUID) (*MFAEnrollment, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	factor, err := s.mfaRepo.GetFactor(userID)
+	if err != nil {
+		return nil, err
+	}
+	if factor != nil && factor.ConfirmedAt != nil {
+		return nil, errors.New("MFA is already enabled")
+	}
+	if factor == nil {
+		factor = &domain.MFAFactor{UserID: userID}
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate secret: %w", err)
+	}
+	factor.Secret = secret
+	factor.LastUsedStep = 0
+
+	if err := s.mfaRepo.SaveFactor(factor); err != nil {
+		return nil, err
+	}
+
+	return &MFAEnrollment{
+		Secret: secret,
+		URI:    totp.URI(s.issuer, user.Email, secret),
+	}, nil
+}
+
+// ConfirmEnrollment turns MFA on once the user proves their authenticator
+// works, and returns a fresh set of recovery codes to show them once.
+func (s *mfaService) ConfirmEnrollment(userID uuid.UUID, code string) ([]string, error) {
+	factor, err := s.mfaRepo.GetFactor(userID)
+	if err != nil {
+		return nil, err
+	}
+	if factor == nil {
+		return nil, errors.New("MFA enrollment has not been started")
+	}
+	if factor.ConfirmedAt != nil {
+		return nil, errors.New("MFA is already enabled")
+	}
+
+	if err := s.checkCode(factor, code); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	factor.ConfirmedAt = &now
+	if err := s.mfaRepo.SaveFactor(factor); err != nil {
+		return nil, err
+	}
+
+	return s.newRecoveryCodes(userID)
+}
+
+// Verify accepts either a current TOTP code or an unused recovery code.
+func (s *mfaService) Verify(userID uuid.UUID, code, recoveryCode string) error {
+	factor, err := s.mfaRepo.GetFactor(userID)
+	if err != nil {
+		return err
+	}
+	if factor == nil || factor.ConfirmedAt == nil {
+		return errors.New("MFA is not enabled")
+	}
+
+	if recoveryCode != "" {
+		used, err := s.mfaRepo.UseRecoveryCode(userID, hashRecoveryCode(recoveryCode))
+		if err != nil {
+			return err
+		}
+		if !used {
+			return errInvalidMFACode
+		}
+		return nil
+	}
+
+	return s.checkCode(factor, code)
+}
+
+func (s *mfaService) RegenerateRecoveryCodes(userID uuid.UUID, code string) ([]string, error) {
+	if err := s.Verify(userID, code, ""); err != nil {
+		return nil, err
+	}
+	return s.newRecoveryCodes(userID)
+}
+
+// Disable removes the authenticator after checking both the password and a
+// current code. Users whose role requires MFA cannot turn it off.
+func (s *mfaService) Disable(userID uuid.UUID, password, code string) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+
+	if s.Required(user.Role) {
+		return errors.New("MFA is required for your role")
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return errors.New("password is incorrect")
+	}
+
+	if err := s.Verify(userID, code, ""); err != nil {
+		return err
+	}
+
+	return s.mfaRepo.DeleteFactor(userID)
+}
+
+// checkCode validates a TOTP code and consumes its time step.
+func (s *mfaService) checkCode(factor *domain.MFAFactor, code string) error {
+	step, ok := totp.Validate(factor.Secret, code, time.Now(), totpSkew)
+	if !ok {
+		return errInvalidMFACode
+	}
+
+	accepted, err := s.mfaRepo.UseStep(factor.ID, step)
+	if err != nil {
+		return err
+	}
+	if !accepted {
+		return errMFACodeReused
+	}
+	factor.LastUsedStep = step
+	return nil
+}
+
+func (s *mfaService) newRecoveryCodes(userID uuid.UUID) ([]string, error) {
+	plain := make([]string, 0, recoveryCodeCount)
+	records := make([]domain.MFARecoveryCode, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		buf := make([]byte, 8)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+		code := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(buf))[:10]
+		code = code[:5] + "-" + code[5:]
+
+		plain = append(plain, code)
+		records = append(records, domain.MFARecoveryCode{
+			UserID:   userID,
+			CodeHash: hashRecoveryCode(code),
+		})
+	}
+
+	if err := s.mfaRepo.ReplaceRecoveryCodes(userID, records); err != nil {
+		return nil, err
+	}
+	return plain, nil
+}
+
+// hashRecoveryCode normalises case and separators so codes can be typed
+// loosely.
+func hashRecoveryCode(code string) string {
+	code = strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,395 @@
+package service
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/touros-platform/api/internal/config"
+	"github.com/touros-platform/api/internal/domain"
+	"github.com/touros-platform/api/internal/oidc"
+	"github.com/touros-platform/api/internal/repository"
+)
+
+// ErrUnknownProvider is returned for a provider name that is not configured.
+var ErrUnknownProvider = errors.New("unknown identity provider")
+
+var errInvalidOIDCState = errors.New("invalid or expired login state")
+
+type OIDCService interface {
+	Providers() []string
+	Authorize(provider string) (*OIDCAuthorization, error)
+	Callback(provider, code, state, ip string) (*TokenPair, error)
+}
+
+// OIDCAuthorization is where to send the user to log in at the provider.
+// The provider redirects back with State, which completes the login.
+type OIDCAuthorization struct {
+	URL       string
+	State     string
+	ExpiresIn int64
+}
+
+type roleMapping struct {
+	value string
+	role  domain.Role
+}
+
+type oidcProvider struct {
+	config      config.OIDCProviderConfig
+	client      *oidc.Provider
+	roleMap     []roleMapping
+	fallback    domain.Role
+	linkDomains map[string]bool
+}
+
+type oidcService struct {
+	providers    map[string]*oidcProvider
+	names        []string
+	identityRepo repository.ExternalIdentityRepository
+	userRepo     repository.UserRepository
+	agencyRepo   repository.AgencyRepository
+	tokenRepo    repository.TokenRepository
+	authService  AuthService
+	mfaService   MFAService
+	stateTTL     time.Duration
+}
+
+func NewOIDCService(
+	identityRepo repository.ExternalIdentityRepository,
+	userRepo repository.UserRepository,
+	agencyRepo repository.AgencyRepository,
+	tokenRepo repository.TokenRepository,
+	authService AuthService,
+	mfaService MFAService,
+	cfg config.OIDCConfig,
+) (OIDCService, error) {
+	s := &oidcService{
+		providers:    make(map[string]*oidcProvider),
+		identityRepo: identityRepo,
+		userRepo:     userRepo,
+		agencyRepo:   agencyRepo,
+		tokenRepo:    tokenRepo,
+		authService:  authService,
+		mfaService:   mfaService,
+		stateTTL:     cfg.StateTTL,
+	}
+
+	for _, pc := range cfg.Providers {
+		provider := &oidcProvider{
+			config:      pc,
+			fallback:    domain.Role(pc.DefaultRole),
+			linkDomains: make(map[string]bool),
+			client: oidc.NewProvider(oidc.Config{
+				Issuer:       pc.Issuer,
+				ClientID:     pc.ClientID,
+				ClientSecret: pc.ClientSecret,
+				RedirectURL:  pc.RedirectURL,
+				Scopes:       strings.Fields(pc.Scopes),
+			}, nil),
+		}
+		if provider.fallback != "" && !provider.fallback.Valid() {
+			return nil, fmt.Errorf("OIDC provider %s: invalid default role %q", pc.Name, pc.DefaultRole)
+		}
+
+		for _, entry := range strings.Split(pc.RoleMap, ",") {
+			if entry = strings.TrimSpace(entry); entry == "" {
+				continue
+			}
+			// Claim values may themselves contain colons, such as URNs.
+			i := strings.LastIndex(entry, ":")
+			if i <= 0 {
+				return nil, fmt.Errorf("OIDC provider %s: invalid role mapping %q", pc.Name, entry)
+			}
+			role := domain.Role(entry[i+1:])
+			if !role.Valid() {
+				return nil, fmt.Errorf("OIDC provider %s: invalid role %q", pc.Name, role)
+			}
+			provider.roleMap = append(provider.roleMap, roleMapping{value: entry[:i], role: role})
+		}
+
+		for _, domainName := range strings.Split(pc.LinkDomains, ",") {
+			if domainName = strings.ToLower(strings.TrimSpace(domainName)); domainName != "" {
+				provider.linkDomains[domainName] = true
+			}
+		}
+
+		s.providers[pc.Name] = provider
+		s.names = append(s.names, pc.Name)
+	}
+
+	return s, nil
+}
+
+// Providers returns the names of the configured providers.
+func (s *oidcService) Providers() []string {
+	return s.names
+}
+
+// Authorize starts a login at the provider. The state, nonce and PKCE code
+// verifier are kept on the server until the callback.
+func (s *oidcService) Authorize(name string) (*OIDCAuthorization, error) {
+	provider, ok := s.providers[name]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+
+	state, err := oidc.RandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := oidc.RandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := oidc.NewVerifier()
+	if err != nil {
+		return nil, err
+	}
+
+	authURL, err := provider.client.AuthCodeURL(state, nonce, verifier)
+	if err != nil {
+		return nil, err
+	}
+
+	record := &domain.OIDCLoginState{
+		StateHash:    hashOIDCState(state),
+		Provider:     name,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    time.Now().Add(s.stateTTL),
+	}
+	if err := s.identityRepo.CreateLoginState(record); err != nil {
+		return nil, fmt.Errorf("failed to store login state: %w", err)
+	}
+
+	return &OIDCAuthorization{
+		URL:       authURL,
+		State:     state,
+		ExpiresIn: int64(s.stateTTL.Seconds()),
+	}, nil
+}
+
+// Callback completes a login with the code the provider redirected back
+// with. Users are provisioned on their first login, and their role and
+// agency follow the provider's claims on every login.
+func (s *oidcService) Callback(name, code, state, ip string) (*TokenPair, error) {
+	provider, ok := s.providers[name]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+
+	record, err := s.identityRepo.ConsumeLoginState(hashOIDCState(state))
+	if err != nil || record.Provider != name || time.Now().After(record.ExpiresAt) {
+		return nil, errInvalidOIDCState
+	}
+
+	token, err := provider.client.Exchange(code, record.CodeVerifier)
+	if err != nil {
+		return nil, fmt.Errorf("identity provider login failed: %w", err)
+	}
+	if subtle.ConstantTimeCompare([]byte(token.Nonce), []byte(record.Nonce)) != 1 {
+		return nil, errors.New("identity provider login failed: nonce mismatch")
+	}
+
+	role, err := provider.role(token)
+	if err != nil {
+		return nil, err
+	}
+	agencyID, err := s.agencyFor(provider, token)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.resolveUser(provider, token, role, agencyID)
+	if err != nil {
+		return nil, err
+	}
+	return s.authService.StartExternalSession(user, ip)
+}
+
+// resolveUser finds the user linked to the provider account, links an
+// existing user with the same verified email address, or creates one. See
+// checkLink for which existing users may be linked.
+func (s *oidcService) resolveUser(provider *oidcProvider, token *oidc.IDToken, role domain.Role, agencyID *uuid.UUID) (*domain.User, error) {
+	email := strings.TrimSpace(token.String("email"))
+	now := time.Now()
+
+	identity, err := s.identityRepo.GetBySubject(token.Issuer, token.Subject)
+	if err == nil {
+		user, err := s.userRepo.GetByID(identity.UserID)
+		if err != nil {
+			return nil, errors.New("user not found")
+		}
+		if err := s.identityRepo.Touch(identity.ID, email, now); err != nil {
+			return nil, err
+		}
+		if err := s.syncUser(user, role, agencyID); err != nil {
+			return nil, err
+		}
+		return user, nil
+	}
+
+	if email == "" {
+		return nil, errors.New("identity provider did not share an email address")
+	}
+	verified := token.Bool("email_verified")
+
+	user, err := s.userRepo.GetByEmail(email)
+	if err == nil {
+		// Linking on an unverified address would let anyone who can
+		// register that address at the provider take over the account.
+		if !verified {
+			return nil, errors.New("email address is already registered; verify it with the identity provider to link the accounts")
+		}
+		if err := s.checkLink(provider, user); err != nil {
+			return nil, err
+		}
+		if err := s.syncUser(user, role, agencyID); err != nil {
+			return nil, err
+		}
+	} else {
+		user = &domain.User{
+			Email:    email,
+			Role:     role,
+			FullName: fullName(token, email),
+			IsActive: true,
+			AgencyID: agencyID,
+		}
+		if verified {
+			user.EmailVerifiedAt = &now
+		}
+		if err := s.userRepo.Create(user); err != nil {
+			return nil, fmt.Errorf("failed to create user: %w", err)
+		}
+	}
+
+	identity = &domain.ExternalIdentity{
+		UserID:      user.ID,
+		Provider:    provider.config.Name,
+		Issuer:      token.Issuer,
+		Subject:     token.Subject,
+		Email:       email,
+		LastLoginAt: &now,
+	}
+	if err := s.identityRepo.Create(identity); err != nil {
+		return nil, fmt.Errorf("failed to link identity: %w", err)
+	}
+	return user, nil
+}
+
+// checkLink decides whether an existing local user may be linked to a
+// provider account on a first login. Only addresses in the provider's link
+// domains qualify, since linking hands the account to whoever the provider
+// vouches for. Admins and users with MFA enabled are never linked: the
+// provider would replace their role and skip their second factor.
+func (s *oidcService) checkLink(provider *oidcProvider, user *domain.User) error {
+	refused := errors.New("email address is already registered with a local account that cannot be linked to this identity provider")
+
+	at := strings.LastIndex(user.Email, "@")
+	if at < 0 || !provider.linkDomains[strings.ToLower(user.Email[at+1:])] {
+		return refused
+	}
+	if user.Role == domain.RoleAdmin {
+		return refused
+	}
+	enabled, err := s.mfaService.Enabled(user.ID)
+	if err != nil {
+		return err
+	}
+	if enabled {
+		return refused
+	}
+	return nil
+}
+
+// syncUser applies the role and agency from the provider. Sessions issued
+// under the old role or agency are ended, as when an admin changes them.
+func (s *oidcService) syncUser(user *domain.User, role domain.Role, agencyID *uuid.UUID) error {
+	if user.Role == role && sameOptionalID(user.AgencyID, agencyID) {
+		return nil
+	}
+
+	// The agency role is reset too, and an agency's last owner cannot be
+	// moved away.
+	user.Role = role
+	if err := leaveAgency(s.userRepo, user, agencyID); err != nil {
+		return err
+	}
+	if err := s.userRepo.Update(user); err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+	if err := s.tokenRepo.RevokeAllForUser(user.ID, domain.TokenRevokedLogoutAll); err != nil {
+		return fmt.Errorf("failed to end sessions: %w", err)
+	}
+	return nil
+}
+
+// role maps the role claim to a role. The first mapping the user matches
+// wins, so list the most privileged roles first.
+func (p *oidcProvider) role(token *oidc.IDToken) (domain.Role, error) {
+	values := make(map[string]bool)
+	for _, value := range token.Strings(p.config.RoleClaim) {
+		values[value] = true
+	}
+	for _, mapping := range p.roleMap {
+		if values[mapping.value] {
+			return mapping.role, nil
+		}
+	}
+	if p.fallback != "" {
+		return p.fallback, nil
+	}
+	return "", errors.New("no role is mapped for this account")
+}
+
+// agencyFor looks up the agency named by the agency claim, by registration
+// number or ID.
+func (s *oidcService) agencyFor(provider *oidcProvider, token *oidc.IDToken) (*uuid.UUID, error) {
+	if provider.config.AgencyClaim == "" {
+		return nil, nil
+	}
+	value := strings.TrimSpace(token.String(provider.config.AgencyClaim))
+	if value == "" {
+		return nil, nil
+	}
+
+	agency, err := s.agencyRepo.GetByRegistrationNumber(value)
+	if err != nil {
+		id, parseErr := uuid.Parse(value)
+		if parseErr != nil {
+			return nil, fmt.Errorf("agency %q not found", value)
+		}
+		if agency, err = s.agencyRepo.GetByID(id); err != nil {
+			return nil, fmt.Errorf("agency %q not found", value)
+		}
+	}
+	return &agency.ID, nil
+}
+
+func fullName(token *oidc.IDToken, email string) string {
+	if name := strings.TrimSpace(token.String("name")); name != "" {
+		return name
+	}
+	if name := strings.TrimSpace(token.String("given_name") + " " + token.String("family_name")); name != "" {
+		return name
+	}
+	return email
+}
+
+func sameOptionalID(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+func hashOIDCState(state string) string {
+	sum := sha256.Sum256([]byte(state))
+	return hex.EncodeToString(sum[:])
+}
@@ -1,87 +1,225 @@
 package service
 
 import (
-	"encoding/base64"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/touros-platform/api/internal/domain"
 	"github.com/touros-platform/api/internal/repository"
+	"github.com/touros-platform/api/pkg/permitsig"
 )
 
 type PermitService interface {
-	Create(req *CreatePermitRequest) (*domain.Permit, error)
-	GetByID(id uuid.UUID) (*domain.Permit, error)
+	Create(actor *Actor, req *CreatePermitRequest) (*domain.Permit, error)
+	GetByID(actor *Actor, id uuid.UUID) (*domain.Permit, error)
 	GetByPermitNumber(permitNum string) (*domain.Permit, error)
 	ValidatePermit(permitNum string) (*domain.Permit, error)
 	Revoke(id uuid.UUID, revokedBy uuid.UUID) error
-	List(limit, offset int, guideID *uuid.UUID, status *domain.PermitStatus) ([]domain.Permit, int64, error)
+	List(actor *Actor, limit, offset int, guideID *uuid.UUID, status *domain.PermitStatus) ([]domain.Permit, int64, error)
+	VerifyQRCode(token string) (*domain.Permit, error)
+	PublicKeys() permitsig.KeySet
+	AddMember(actor *Actor, permitID uuid.UUID, input *PartyMemberInput) (*domain.PermitMember, error)
+	WithdrawMember(actor *Actor, permitID, memberID uuid.UUID) error
+	SetMemberStatus(actor *Actor, permitID, memberID uuid.UUID, status domain.MemberStatus, note string) (*domain.PermitMember, error)
 }
 
+// CreatePermitRequest issues a permit to a party led by TrekkerID. Members
+// lists the rest of the party; the lead trekker is added automatically.
 type CreatePermitRequest struct {
-	GuideID     uuid.UUID
-	ClientID    uuid.UUID
-	ClientName  string
-	ClientEmail string
-	ClientPhone string
-	StartDate   time.Time
-	EndDate     time.Time
-	Route       string
-	IssuedBy    uuid.UUID
+	GuideID         uuid.UUID
+	TrekkerID       uuid.UUID
+	Members         []PartyMemberInput
+	StartDate       time.Time
+	EndDate         time.Time
+	RouteID         uuid.UUID
+	CustomItinerary string
+	IssuedBy        uuid.UUID
+}
+
+// PartyMemberInput describes a party member. Trekkers are referenced by
+// TrekkerID; porters and support staff by name and identity document.
+type PartyMemberInput struct {
+	Role      domain.PartyRole
+	TrekkerID *uuid.UUID
+	FullName  string
+	Phone     string
+	IDNumber  string
 }
 
 type permitService struct {
-	permitRepo repository.PermitRepository
-	guideRepo  repository.GuideRepository
+	permitRepo        repository.PermitRepository
+	guideRepo         repository.GuideRepository
+	trekkerRepo       repository.TrekkerRepository
+	routeRepo         repository.RouteRepository
+	agencyRepo        repository.AgencyRepository
+	certificationRepo repository.GuideCertificationRepository
+	signer            *PermitSigner
+	authz             *authorizer
 }
 
-func NewPermitService(permitRepo repository.PermitRepository, guideRepo repository.GuideRepository) PermitService {
+func NewPermitService(permitRepo repository.PermitRepository, guideRepo repository.GuideRepository, trekkerRepo repository.TrekkerRepository, routeRepo repository.RouteRepository, agencyRepo repository.AgencyRepository, certificationRepo repository.GuideCertificationRepository, signer *PermitSigner) PermitService {
 	return &permitService{
-		permitRepo: permitRepo,
-		guideRepo:  guideRepo,
+		permitRepo:        permitRepo,
+		guideRepo:         guideRepo,
+		trekkerRepo:       trekkerRepo,
+		routeRepo:         routeRepo,
+		agencyRepo:        agencyRepo,
+		certificationRepo: certificationRepo,
+		signer:            signer,
+		authz:             newAuthorizer(guideRepo, permitRepo),
 	}
 }
 
-func (s *permitService) Create(req *CreatePermitRequest) (*domain.Permit, error) {
+// Create issues a permit. Agency users issue permits for their own guides
+// and trekkers, and the permit belongs to the issuing agency; otherwise it
+// belongs to the guide's agency, or failing that the lead trekker's. A guide
+// leads one party at a time: overlapping permits and blocked periods are
+// refused with GuideUnavailableError. The guide must hold the route's
+// required certifications and be qualified for its highest checkpoint.
+func (s *permitService) Create(actor *Actor, req *CreatePermitRequest) (*domain.Permit, error) {
+	if req.EndDate.Before(req.StartDate) {
+		return nil, errors.New("end date must not be before start date")
+	}
+
 	guide, err := s.guideRepo.GetByID(req.GuideID)
 	if err != nil {
 		return nil, errors.New("guide not found")
 	}
+	if err := s.authz.checkGuide(actor, guide); err != nil {
+		return nil, err
+	}
 
 	if guide.Status != domain.GuideStatusVerified {
 		return nil, errors.New("guide must be verified to issue permits")
 	}
+	// The daily license job suspends expired guides and agencies; until it
+	// runs, refuse them here as well.
+	now := time.Now()
+	if licenseExpired(guide.LicenseExpiry, now) {
+		return nil, errors.New("guide license has expired")
+	}
+
+	trekker, err := s.trekkerRepo.GetByID(req.TrekkerID)
+	if err != nil {
+		return nil, errors.New("trekker not found")
+	}
+	if err := s.authz.checkTrekker(actor, trekker); err != nil {
+		return nil, err
+	}
+
+	agencyID := guide.AgencyID
+	if actor.Role == domain.RoleAgency {
+		agencyID = actor.AgencyID
+	}
+	if agencyID == nil {
+		agencyID = trekker.AgencyID
+	}
+	if agencyID != nil {
+		agency, err := s.agencyRepo.GetByID(*agencyID)
+		if err != nil {
+			return nil, errors.New("agency not found")
+		}
+		if agency.Status == domain.AgencyStatusSuspended {
+			return nil, errors.New("agency is suspended")
+		}
+		if licenseExpired(agency.LicenseExpiry, now) {
+			return nil, errors.New("agency license has expired")
+		}
+	}
+
+	route, err := s.routeRepo.GetByID(req.RouteID)
+	if err != nil {
+		return nil, errors.New("route not found")
+	}
+
+	if !route.IsActive {
+		return nil, errors.New("route is not open for permits")
+	}
+	if len(route.RequiredCertifications) > 0 {
+		held, err := s.certificationRepo.ListByGuide(guide.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load guide certifications: %w", err)
+		}
+		if missing := missingCertifications(route.RequiredCertifications, held, req.EndDate); len(missing) > 0 {
+			return nil, fmt.Errorf("guide lacks certifications this route requires, valid until the trek ends: %s", strings.Join(missing, ", "))
+		}
+	}
+	if highest := route.MaxAltitude(); highest > guide.MaxAltitudeMeters {
+		return nil, fmt.Errorf("route reaches %d m, above the guide's altitude qualification of %d m", highest, guide.MaxAltitudeMeters)
+	}
 
-	permitNumber := s.generatePermitNumber()
-	qrCode := s.generateQRCode(permitNumber)
+	lead := domain.PermitMember{
+		Role:      domain.PartyRoleTrekker,
+		TrekkerID: &trekker.ID,
+		FullName:  trekker.FullName,
+		Phone:     trekker.Phone,
+		Status:    domain.MemberStatusPending,
+	}
+	members := []domain.PermitMember{lead}
+	for i := range req.Members {
+		member, err := s.buildMember(actor, &req.Members[i])
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, *member)
+	}
 
 	permit := &domain.Permit{
-		PermitNumber: permitNumber,
-		GuideID:      req.GuideID,
-		ClientID:     req.ClientID,
-		ClientName:   req.ClientName,
-		ClientEmail:  req.ClientEmail,
-		ClientPhone:  req.ClientPhone,
-		StartDate:    req.StartDate,
-		EndDate:      req.EndDate,
-		Route:        req.Route,
-		Status:       domain.PermitStatusActive,
-		QRCode:       qrCode,
-		IssuedBy:     req.IssuedBy,
-		IssuedAt:     time.Now(),
+		PermitNumber:    s.generatePermitNumber(),
+		GuideID:         req.GuideID,
+		TrekkerID:       trekker.ID,
+		AgencyID:        agencyID,
+		Members:         members,
+		StartDate:       req.StartDate,
+		EndDate:         req.EndDate,
+		RouteID:         route.ID,
+		Route:           *route,
+		CustomItinerary: req.CustomItinerary,
+		Status:          domain.PermitStatusActive,
+		IssuedBy:        req.IssuedBy,
+		IssuedAt:        time.Now(),
 	}
 
+	if err := s.checkParty(permit); err != nil {
+		return nil, err
+	}
+
+	qrCode, err := s.signer.Sign(permit, guide)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign permit: %w", err)
+	}
+	permit.QRCode = qrCode
+
 	if err := s.permitRepo.Create(permit); err != nil {
+		var quotaErr *QuotaExceededError
+		if errors.As(err, &quotaErr) {
+			return nil, quotaErr
+		}
+		var unavailableErr *GuideUnavailableError
+		if errors.As(err, &unavailableErr) {
+			return nil, unavailableErr
+		}
 		return nil, fmt.Errorf("failed to create permit: %w", err)
 	}
 
+	permit.Guide = *guide
+	permit.Trekker = *trekker
+	permit.Route = *route
 	return permit, nil
 }
 
-func (s *permitService) GetByID(id uuid.UUID) (*domain.Permit, error) {
-	return s.permitRepo.GetByID(id)
+func (s *permitService) GetByID(actor *Actor, id uuid.UUID) (*domain.Permit, error) {
+	permit, err := s.permitRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authz.checkPermit(actor, permit); err != nil {
+		return nil, err
+	}
+	return permit, nil
 }
 
 func (s *permitService) GetByPermitNumber(permitNum string) (*domain.Permit, error) {
@@ -127,19 +265,243 @@ func (s *permitService) Revoke(id uuid.UUID, revokedBy uuid.UUID) error {
 	permit.RevokedAt = &now
 	permit.RevokedBy = &revokedBy
 
-	return s.permitRepo.Update(permit)
+	qrCode, err := s.signer.Sign(permit, &permit.Guide)
+	if err != nil {
+		return fmt.Errorf("failed to sign permit: %w", err)
+	}
+	permit.QRCode = qrCode
+
+	return s.permitRepo.Revoke(permit)
 }
 
-func (s *permitService) List(limit, offset int, guideID *uuid.UUID, status *domain.PermitStatus) ([]domain.Permit, int64, error) {
-	return s.permitRepo.List(limit, offset, guideID, status)
+func (s *permitService) List(actor *Actor, limit, offset int, guideID *uuid.UUID, status *domain.PermitStatus) ([]domain.Permit, int64, error) {
+	scope, err := s.authz.scope(actor)
+	if err != nil {
+		return nil, 0, err
+	}
+	return s.permitRepo.List(limit, offset, scope, guideID, status)
 }
 
 func (s *permitService) generatePermitNumber() string {
 	return fmt.Sprintf("TP-%s", uuid.New().String()[:8])
 }
 
-func (s *permitService) generateQRCode(permitNumber string) string {
-	data := fmt.Sprintf("touros:permit:%s", permitNumber)
-	return base64.StdEncoding.EncodeToString([]byte(data))
+// VerifyQRCode checks the signature of a scanned QR payload and then the
+// current state of the permit it names, which catches revocations that an
+// offline verifier cannot see.
+func (s *permitService) VerifyQRCode(token string) (*domain.Permit, error) {
+	payload, _, err := s.signer.Verifier().Parse(token)
+	if err != nil {
+		return nil, errors.New("invalid permit signature")
+	}
+
+	return s.ValidatePermit(payload.PermitNumber)
+}
+
+func (s *permitService) PublicKeys() permitsig.KeySet {
+	return s.signer.KeySet()
+}
+
+// AddMember adds a trekker or support staff member to an active permit and
+// re-signs its QR code with the new party size.
+func (s *permitService) AddMember(actor *Actor, permitID uuid.UUID, input *PartyMemberInput) (*domain.PermitMember, error) {
+	permit, err := s.permitRepo.GetByID(permitID)
+	if err != nil {
+		return nil, errors.New("permit not found")
+	}
+	if err := s.authz.checkPermit(actor, permit); err != nil {
+		return nil, err
+	}
+
+	if permit.Status != domain.PermitStatusActive {
+		return nil, errors.New("permit is not active")
+	}
+
+	member, err := s.buildMember(actor, input)
+	if err != nil {
+		return nil, err
+	}
+	member.PermitID = permit.ID
+
+	permit.Members = append(permit.Members, *member)
+	if err := s.checkParty(permit); err != nil {
+		return nil, err
+	}
+
+	if err := s.permitRepo.AddMember(permit, member); err != nil {
+		var quotaErr *QuotaExceededError
+		if errors.As(err, &quotaErr) {
+			return nil, quotaErr
+		}
+		return nil, fmt.Errorf("failed to add party member: %w", err)
+	}
+
+	if err := s.resign(permit); err != nil {
+		return nil, err
+	}
+
+	return member, nil
 }
 
+// WithdrawMember marks a member as no longer travelling. The lead trekker
+// cannot be withdrawn.
+func (s *permitService) WithdrawMember(actor *Actor, permitID, memberID uuid.UUID) error {
+	permit, err := s.permitRepo.GetByID(permitID)
+	if err != nil {
+		return errors.New("permit not found")
+	}
+	if err := s.authz.checkPermit(actor, permit); err != nil {
+		return err
+	}
+
+	if permit.Status != domain.PermitStatusActive {
+		return errors.New("permit is not active")
+	}
+
+	var member *domain.PermitMember
+	for i := range permit.Members {
+		if permit.Members[i].ID == memberID {
+			member = &permit.Members[i]
+		}
+	}
+	if member == nil {
+		return errors.New("party member not found")
+	}
+	if member.Status == domain.MemberStatusWithdrawn {
+		return errors.New("party member already withdrawn")
+	}
+	if member.TrekkerID != nil && *member.TrekkerID == permit.TrekkerID {
+		return errors.New("lead trekker cannot be withdrawn")
+	}
+
+	now := time.Now()
+	member.Status = domain.MemberStatusWithdrawn
+	member.StatusAt = &now
+
+	if err := s.checkParty(permit); err != nil {
+		return err
+	}
+
+	if err := s.permitRepo.WithdrawMember(permit, member); err != nil {
+		return err
+	}
+
+	return s.resign(permit)
+}
+
+// SetMemberStatus records the validation outcome for a single party member,
+// typically made by checkpoint staff comparing documents against the permit.
+func (s *permitService) SetMemberStatus(actor *Actor, permitID, memberID uuid.UUID, status domain.MemberStatus, note string) (*domain.PermitMember, error) {
+	switch status {
+	case domain.MemberStatusPending, domain.MemberStatusValidated, domain.MemberStatusRejected:
+	default:
+		return nil, fmt.Errorf("invalid member status: %s", status)
+	}
+
+	permit, err := s.permitRepo.GetByID(permitID)
+	if err != nil {
+		return nil, errors.New("permit not found")
+	}
+	if err := s.authz.checkPermit(actor, permit); err != nil {
+		return nil, err
+	}
+
+	member, err := s.permitRepo.GetMember(permitID, memberID)
+	if err != nil {
+		return nil, errors.New("party member not found")
+	}
+
+	if member.Status == domain.MemberStatusWithdrawn {
+		return nil, errors.New("party member has been withdrawn")
+	}
+
+	now := time.Now()
+	member.Status = status
+	member.StatusNote = note
+	member.StatusAt = &now
+	member.StatusBy = &actor.UserID
+
+	if err := s.permitRepo.UpdateMember(member); err != nil {
+		return nil, err
+	}
+
+	return member, nil
+}
+
+func (s *permitService) buildMember(actor *Actor, input *PartyMemberInput) (*domain.PermitMember, error) {
+	if !input.Role.Valid() {
+		return nil, fmt.Errorf("invalid party role: %s", input.Role)
+	}
+
+	member := &domain.PermitMember{
+		Role:     input.Role,
+		FullName: input.FullName,
+		Phone:    input.Phone,
+		IDNumber: input.IDNumber,
+		Status:   domain.MemberStatusPending,
+	}
+
+	if input.Role == domain.PartyRoleTrekker {
+		if input.TrekkerID == nil {
+			return nil, errors.New("trekker_id is required for trekker members")
+		}
+		trekker, err := s.trekkerRepo.GetByID(*input.TrekkerID)
+		if err != nil {
+			return nil, errors.New("trekker not found")
+		}
+		if err := s.authz.checkTrekker(actor, trekker); err != nil {
+			return nil, err
+		}
+		member.TrekkerID = &trekker.ID
+		member.FullName = trekker.FullName
+		if member.Phone == "" {
+			member.Phone = trekker.Phone
+		}
+		return member, nil
+	}
+
+	if input.TrekkerID != nil {
+		return nil, errors.New("support staff cannot reference a trekker")
+	}
+	if input.FullName == "" {
+		return nil, errors.New("full name is required for support staff")
+	}
+	return member, nil
+}
+
+// checkParty rejects duplicate trekkers and enforces the party size rule for
+// the permit's route.
+func (s *permitService) checkParty(permit *domain.Permit) error {
+	seen := make(map[uuid.UUID]bool)
+	for _, m := range permit.Members {
+		if m.TrekkerID == nil || m.Status == domain.MemberStatusWithdrawn {
+			continue
+		}
+		if seen[*m.TrekkerID] {
+			return errors.New("trekker is already a member of this party")
+		}
+		seen[*m.TrekkerID] = true
+	}
+
+	rule := &permit.Route
+	summary := permit.PartySummary()
+	if summary.Trekkers < rule.MinTrekkers {
+		return fmt.Errorf("party must include at least %d trekkers on this route", rule.MinTrekkers)
+	}
+	if summary.Trekkers > rule.MaxTrekkers {
+		return fmt.Errorf("party exceeds the maximum of %d trekkers on this route", rule.MaxTrekkers)
+	}
+	if summary.SupportStaff > rule.MaxSupportStaff {
+		return fmt.Errorf("party exceeds the maximum of %d support staff on this route", rule.MaxSupportStaff)
+	}
+	return nil
+}
+
+func (s *permitService) resign(permit *domain.Permit) error {
+	qrCode, err := s.signer.Sign(permit, &permit.Guide)
+	if err != nil {
+		return fmt.Errorf("failed to sign permit: %w", err)
+	}
+	permit.QRCode = qrCode
+	return s.permitRepo.UpdateQRCode(permit.ID, qrCode)
+}
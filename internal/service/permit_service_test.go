@@ -0,0 +1,133 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/touros-platform/api/internal/config"
+	"github.com/touros-platform/api/internal/domain"
+	"github.com/touros-platform/api/internal/repository"
+	"gorm.io/gorm"
+)
+
+// permitHarness issues permits against the safety harness's guides and
+// permits.
+type permitHarness struct {
+	*safetyHarness
+	routes   map[uuid.UUID]*domain.Route
+	trekkers *fakeTrekkerRepo
+	permits  PermitService
+	admin    *Actor
+}
+
+func newPermitHarness(t *testing.T) *permitHarness {
+	t.Helper()
+
+	signer, err := NewPermitSigner(config.PermitConfig{SigningKeyID: "k1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := &permitHarness{
+		safetyHarness: newSafetyHarness(t),
+		routes:        make(map[uuid.UUID]*domain.Route),
+		trekkers:      &fakeTrekkerRepo{trekkers: make(map[uuid.UUID]*domain.Trekker)},
+		admin:         &Actor{UserID: uuid.New(), Role: domain.RoleAdmin},
+	}
+	h.permits = NewPermitService(&fakePermitRepo{h: h.safetyHarness}, &fakeGuideRepo{h: h.safetyHarness}, h.trekkers,
+		&fakeRouteRepo{routes: h.routes}, nil, nil, signer)
+	return h
+}
+
+func (h *permitHarness) addGuide(maxAltitude int) *domain.Guide {
+	guide := &domain.Guide{
+		ID:                uuid.New(),
+		LicenseNumber:     "GL-" + uuid.NewString()[:8],
+		Status:            domain.GuideStatusVerified,
+		MaxAltitudeMeters: maxAltitude,
+	}
+	h.guides[guide.ID] = guide
+	return guide
+}
+
+func (h *permitHarness) addRoute(altitudes ...int) *domain.Route {
+	route := &domain.Route{
+		ID:              uuid.New(),
+		Code:            "R-" + uuid.NewString()[:8],
+		Name:            "Test Route",
+		DurationDays:    10,
+		MinTrekkers:     1,
+		MaxTrekkers:     15,
+		MaxSupportStaff: 15,
+		IsActive:        true,
+	}
+	for i, altitude := range altitudes {
+		route.Checkpoints = append(route.Checkpoints, domain.RouteCheckpoint{
+			ID:             uuid.New(),
+			RouteID:        route.ID,
+			Sequence:       i + 1,
+			AltitudeMeters: altitude,
+		})
+	}
+	h.routes[route.ID] = route
+	return route
+}
+
+func (h *permitHarness) issue(guide *domain.Guide, route *domain.Route) (*domain.Permit, error) {
+	trekker := &domain.Trekker{FullName: "Test Trekker", PassportNumber: uuid.NewString()[:12], Nationality: "GB"}
+	if err := h.trekkers.Create(trekker); err != nil {
+		h.t.Fatal(err)
+	}
+	start := time.Now().AddDate(0, 1, 0)
+	return h.permits.Create(h.admin, &CreatePermitRequest{
+		GuideID:   guide.ID,
+		TrekkerID: trekker.ID,
+		StartDate: start,
+		EndDate:   start.AddDate(0, 0, route.DurationDays),
+		RouteID:   route.ID,
+		IssuedBy:  h.admin.UserID,
+	})
+}
+
+func TestCreatePermitChecksGuideAltitude(t *testing.T) {
+	h := newPermitHarness(t)
+	route := h.addRoute(1800, 5416, 3500)
+
+	if _, err := h.issue(h.addGuide(5000), route); err == nil || !strings.Contains(err.Error(), "altitude") {
+		t.Fatalf("guide qualified to 5000 m on a 5416 m route: got %v, want altitude error", err)
+	}
+	// A guide without a recorded qualification is not qualified.
+	if _, err := h.issue(h.addGuide(0), route); err == nil {
+		t.Fatal("guide without an altitude qualification was accepted")
+	}
+	if _, err := h.issue(h.addGuide(5416), route); err != nil {
+		t.Fatalf("guide qualified to the route's highest checkpoint: %v", err)
+	}
+
+	// Routes without checkpoints set no altitude.
+	if _, err := h.issue(h.addGuide(0), h.addRoute()); err != nil {
+		t.Fatalf("route without checkpoints: %v", err)
+	}
+}
+
+func (r *fakePermitRepo) Create(permit *domain.Permit) error {
+	permit.ID = uuid.New()
+	copied := *permit
+	r.h.permits[permit.ID] = &copied
+	return nil
+}
+
+type fakeRouteRepo struct {
+	repository.RouteRepository
+	routes map[uuid.UUID]*domain.Route
+}
+
+func (r *fakeRouteRepo) GetByID(id uuid.UUID) (*domain.Route, error) {
+	route, ok := r.routes[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *route
+	return &copied, nil
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/touros-platform/api/internal/config"
+	"github.com/touros-platform/api/internal/domain"
+	"github.com/touros-platform/api/pkg/permitsig"
+)
+
+// PermitSigner signs permit QR payloads with the active Ed25519 key and
+// verifies them against the active and retired keys.
+type PermitSigner struct {
+	keyID      string
+	privateKey ed25519.PrivateKey
+	verifier   *permitsig.Verifier
+	publicKeys []permitsig.PublicKey
+	ephemeral  bool
+}
+
+// NewPermitSigner loads the signing keys from config. When no signing key is
+// configured a random one is generated; permits signed with it stop
+// verifying once the process exits.
+func NewPermitSigner(cfg config.PermitConfig) (*PermitSigner, error) {
+	signer := &PermitSigner{
+		keyID:    cfg.SigningKeyID,
+		verifier: permitsig.NewVerifier(),
+	}
+
+	if cfg.SigningKey == "" {
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate permit signing key: %w", err)
+		}
+		signer.privateKey = key
+		signer.ephemeral = true
+	} else {
+		raw, err := base64.StdEncoding.DecodeString(cfg.SigningKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid PERMIT_SIGNING_KEY: %w", err)
+		}
+		switch len(raw) {
+		case ed25519.SeedSize:
+			signer.privateKey = ed25519.NewKeyFromSeed(raw)
+		case ed25519.PrivateKeySize:
+			signer.privateKey = ed25519.PrivateKey(raw)
+		default:
+			return nil, fmt.Errorf("invalid PERMIT_SIGNING_KEY: expected %d or %d bytes", ed25519.SeedSize, ed25519.PrivateKeySize)
+		}
+	}
+
+	if err := signer.trust(signer.keyID, signer.privateKey.Public().(ed25519.PublicKey)); err != nil {
+		return nil, err
+	}
+
+	if cfg.VerificationKeys != "" {
+		for _, entry := range strings.Split(cfg.VerificationKeys, ",") {
+			kid, encoded, ok := strings.Cut(strings.TrimSpace(entry), ":")
+			if !ok {
+				return nil, fmt.Errorf("invalid PERMIT_VERIFICATION_KEYS entry %q", entry)
+			}
+			pub, err := base64.StdEncoding.DecodeString(encoded)
+			if err != nil {
+				return nil, fmt.Errorf("invalid verification key %q: %w", kid, err)
+			}
+			if kid == signer.keyID {
+				return nil, fmt.Errorf("verification key %q shadows the active signing key", kid)
+			}
+			if err := signer.trust(kid, pub); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return signer, nil
+}
+
+func (s *PermitSigner) trust(keyID string, pub ed25519.PublicKey) error {
+	if err := s.verifier.AddKey(keyID, pub); err != nil {
+		return err
+	}
+	s.publicKeys = append(s.publicKeys, permitsig.PublicKey{
+		KeyID:     keyID,
+		Algorithm: permitsig.Algorithm,
+		PublicKey: base64.StdEncoding.EncodeToString(pub),
+	})
+	return nil
+}
+
+// Ephemeral reports whether the signing key was generated at startup.
+func (s *PermitSigner) Ephemeral() bool {
+	return s.ephemeral
+}
+
+// Sign produces the QR token for permit. The party size lets offline
+// checkpoints count heads without the member list.
+func (s *PermitSigner) Sign(permit *domain.Permit, guide *domain.Guide) (string, error) {
+	party := permit.PartySummary()
+	payload := &permitsig.Payload{
+		PermitNumber: permit.PermitNumber,
+		GuideLicense: guide.LicenseNumber,
+		StartDate:    permit.StartDate.UTC().Truncate(time.Second),
+		EndDate:      permit.EndDate.UTC().Truncate(time.Second),
+		Route:        permit.Route.Name,
+		PartySize:    party.Trekkers + party.SupportStaff,
+		Status:       string(permit.Status),
+		IssuedAt:     permit.IssuedAt.UTC().Truncate(time.Second),
+	}
+	return permitsig.Sign(payload, s.keyID, s.privateKey)
+}
+
+func (s *PermitSigner) Verifier() *permitsig.Verifier {
+	return s.verifier
+}
+
+func (s *PermitSigner) KeySet() permitsig.KeySet {
+	return permitsig.KeySet{Keys: s.publicKeys}
+}
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/touros-platform/api/internal/domain"
+	"github.com/touros-platform/api/internal/repository"
+)
+
+// maxAvailabilityDays bounds the date range of an availability query.
+const maxAvailabilityDays = 31
+
+// QuotaExceededError is returned when issuing a permit or adding a trekker
+// would overbook a quota.
+type QuotaExceededError = repository.QuotaExceededError
+
+type QuotaService interface {
+	Create(quota *domain.Quota) error
+	GetByID(id uuid.UUID) (*domain.Quota, error)
+	Update(id uuid.UUID, updates *UpdateQuotaRequest) (*domain.Quota, error)
+	Delete(id uuid.UUID) error
+	List(limit, offset int, routeID *uuid.UUID, region *string) ([]domain.Quota, int64, error)
+	Availability(routeID uuid.UUID, from, to time.Time) ([]DayAvailability, error)
+}
+
+type UpdateQuotaRequest struct {
+	Period    *domain.QuotaPeriod
+	StartDate *time.Time
+	EndDate   *time.Time
+	Capacity  *int
+	Notes     *string
+}
+
+// DayAvailability is the capacity left for entering a route on one date.
+// Remaining is the smallest remainder across the quotas that apply, or nil
+// when no quota limits the date.
+type DayAvailability struct {
+	Date      time.Time
+	Remaining *int
+	Quotas    []domain.QuotaUsage
+}
+
+type quotaService struct {
+	quotaRepo repository.QuotaRepository
+	routeRepo repository.RouteRepository
+}
+
+func NewQuotaService(quotaRepo repository.QuotaRepository, routeRepo repository.RouteRepository) QuotaService {
+	return &quotaService{
+		quotaRepo: quotaRepo,
+		routeRepo: routeRepo,
+	}
+}
+
+// Create adds a quota on either a single route or every route in a region.
+func (s *quotaService) Create(quota *domain.Quota) error {
+	quota.Region = strings.TrimSpace(quota.Region)
+	if (quota.RouteID == nil) == (quota.Region == "") {
+		return errors.New("quota must target either a route or a region")
+	}
+
+	if quota.RouteID != nil {
+		if _, err := s.routeRepo.GetByID(*quota.RouteID); err != nil {
+			return errors.New("route not found")
+		}
+	}
+
+	quota.StartDate = toDate(quota.StartDate)
+	quota.EndDate = toDate(quota.EndDate)
+	if err := validateQuota(quota); err != nil {
+		return err
+	}
+
+	return s.quotaRepo.Create(quota)
+}
+
+func (s *quotaService) GetByID(id uuid.UUID) (*domain.Quota, error) {
+	return s.quotaRepo.GetByID(id)
+}
+
+// Update changes a quota's limits. Lowering the capacity below what is
+// already reserved is allowed; it only stops further permits being issued.
+func (s *quotaService) Update(id uuid.UUID, updates *UpdateQuotaRequest) (*domain.Quota, error) {
+	quota, err := s.quotaRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if updates.Period != nil {
+		quota.Period = *updates.Period
+	}
+	if updates.StartDate != nil {
+		quota.StartDate = toDate(*updates.StartDate)
+	}
+	if updates.EndDate != nil {
+		quota.EndDate = toDate(*updates.EndDate)
+	}
+	if updates.Capacity != nil {
+		quota.Capacity = *updates.Capacity
+	}
+	if updates.Notes != nil {
+		quota.Notes = *updates.Notes
+	}
+
+	if err := validateQuota(quota); err != nil {
+		return nil, err
+	}
+
+	if err := s.quotaRepo.Update(quota); err != nil {
+		return nil, err
+	}
+
+	return quota, nil
+}
+
+func (s *quotaService) Delete(id uuid.UUID) error {
+	return s.quotaRepo.Delete(id)
+}
+
+func (s *quotaService) List(limit, offset int, routeID *uuid.UUID, region *string) ([]domain.Quota, int64, error) {
+	return s.quotaRepo.List(limit, offset, routeID, region)
+}
+
+// Availability reports, for each entry date from from to to, how many more
+// trekkers can be issued permits for the route under its own quotas and
+// those of its region.
+func (s *quotaService) Availability(routeID uuid.UUID, from, to time.Time) ([]DayAvailability, error) {
+	from, to = toDate(from), toDate(to)
+	if to.Before(from) {
+		return nil, errors.New("end date must not be before start date")
+	}
+	days := int(to.Sub(from).Hours()/24) + 1
+	if days > maxAvailabilityDays {
+		return nil, fmt.Errorf("date range must not exceed %d days", maxAvailabilityDays)
+	}
+
+	route, err := s.routeRepo.GetByID(routeID)
+	if err != nil {
+		return nil, errors.New("route not found")
+	}
+
+	quotas, err := s.quotaRepo.ListApplicable(route.ID, route.Region, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	seasonReserved := make(map[uuid.UUID]int)
+	availability := make([]DayAvailability, 0, days)
+	for date := from; !date.After(to); date = date.AddDate(0, 0, 1) {
+		day := DayAvailability{Date: date, Quotas: []domain.QuotaUsage{}}
+
+		for _, quota := range quotas {
+			if date.Before(quota.StartDate) || date.After(quota.EndDate) {
+				continue
+			}
+
+			reserved, cached := seasonReserved[quota.ID]
+			if !cached {
+				reserved, err = s.quotaRepo.Reserved(&quota, date)
+				if err != nil {
+					return nil, err
+				}
+				if quota.Period == domain.QuotaPeriodSeason {
+					seasonReserved[quota.ID] = reserved
+				}
+			}
+
+			remaining := quota.Capacity - reserved
+			if remaining < 0 {
+				remaining = 0
+			}
+			day.Quotas = append(day.Quotas, domain.QuotaUsage{
+				Quota:     quota,
+				Date:      date,
+				Reserved:  reserved,
+				Remaining: remaining,
+			})
+			if day.Remaining == nil || remaining < *day.Remaining {
+				r := remaining
+				day.Remaining = &r
+			}
+		}
+
+		availability = append(availability, day)
+	}
+
+	return availability, nil
+}
+
+func validateQuota(quota *domain.Quota) error {
+	switch quota.Period {
+	case domain.QuotaPeriodDaily, domain.QuotaPeriodSeason:
+	default:
+		return fmt.Errorf("invalid quota period: %s", quota.Period)
+	}
+	if quota.StartDate.IsZero() || quota.EndDate.IsZero() {
+		return errors.New("start and end dates are required")
+	}
+	if quota.EndDate.Before(quota.StartDate) {
+		return errors.New("end date must not be before start date")
+	}
+	if quota.Capacity < 0 {
+		return errors.New("capacity must not be negative")
+	}
+	return nil
+}
+
+// toDate truncates t to its calendar date at UTC midnight, the form quota
+// dates are stored and compared in.
+func toDate(t time.Time) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
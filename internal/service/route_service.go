@@ -0,0 +1,230 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/touros-platform/api/internal/domain"
+	"github.com/touros-platform/api/internal/repository"
+)
+
+type RouteService interface {
+	Create(route *domain.Route) error
+	GetByID(id uuid.UUID) (*domain.Route, error)
+	Update(id uuid.UUID, updates *UpdateRouteRequest) (*domain.Route, error)
+	Delete(id uuid.UUID) error
+	List(limit, offset int, region *string, activeOnly bool) ([]domain.Route, int64, error)
+}
+
+type UpdateRouteRequest struct {
+	Code                   *string
+	Name                   *string
+	Region                 *string
+	Description            *string
+	DurationDays           *int
+	MinTrekkers            *int
+	MaxTrekkers            *int
+	MaxSupportStaff        *int
+	IsActive               *bool
+	Checkpoints            *[]domain.RouteCheckpoint
+	RequiredCertifications *[]domain.RouteCertification
+}
+
+type routeService struct {
+	routeRepo repository.RouteRepository
+}
+
+func NewRouteService(routeRepo repository.RouteRepository) RouteService {
+	return &routeService{
+		routeRepo: routeRepo,
+	}
+}
+
+// Create adds a route to the catalog. Checkpoints are numbered in the order
+// given.
+func (s *routeService) Create(route *domain.Route) error {
+	route.Code = strings.ToUpper(strings.TrimSpace(route.Code))
+	if err := validateRoute(route); err != nil {
+		return err
+	}
+	if err := prepareCheckpoints(route.Checkpoints, route.DurationDays); err != nil {
+		return err
+	}
+	required, err := prepareRequiredCertifications(route.RequiredCertifications)
+	if err != nil {
+		return err
+	}
+	route.RequiredCertifications = required
+
+	existing, _ := s.routeRepo.GetByCode(route.Code)
+	if existing != nil {
+		return errors.New("route with this code already exists")
+	}
+
+	return s.routeRepo.Create(route)
+}
+
+func (s *routeService) GetByID(id uuid.UUID) (*domain.Route, error) {
+	return s.routeRepo.GetByID(id)
+}
+
+func (s *routeService) Update(id uuid.UUID, updates *UpdateRouteRequest) (*domain.Route, error) {
+	route, err := s.routeRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if updates.Code != nil {
+		code := strings.ToUpper(strings.TrimSpace(*updates.Code))
+		existing, _ := s.routeRepo.GetByCode(code)
+		if existing != nil && existing.ID != route.ID {
+			return nil, errors.New("route with this code already exists")
+		}
+		route.Code = code
+	}
+	if updates.Name != nil {
+		route.Name = *updates.Name
+	}
+	if updates.Region != nil {
+		route.Region = *updates.Region
+	}
+	if updates.Description != nil {
+		route.Description = *updates.Description
+	}
+	if updates.DurationDays != nil {
+		route.DurationDays = *updates.DurationDays
+	}
+	if updates.MinTrekkers != nil {
+		route.MinTrekkers = *updates.MinTrekkers
+	}
+	if updates.MaxTrekkers != nil {
+		route.MaxTrekkers = *updates.MaxTrekkers
+	}
+	if updates.MaxSupportStaff != nil {
+		route.MaxSupportStaff = *updates.MaxSupportStaff
+	}
+	if updates.IsActive != nil {
+		route.IsActive = *updates.IsActive
+	}
+
+	if err := validateRoute(route); err != nil {
+		return nil, err
+	}
+
+	checkpoints := route.Checkpoints
+	if updates.Checkpoints != nil {
+		checkpoints = *updates.Checkpoints
+	}
+	if err := prepareCheckpoints(checkpoints, route.DurationDays); err != nil {
+		return nil, err
+	}
+	var required []domain.RouteCertification
+	if updates.RequiredCertifications != nil {
+		if required, err = prepareRequiredCertifications(*updates.RequiredCertifications); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.routeRepo.Update(route); err != nil {
+		return nil, err
+	}
+
+	if updates.Checkpoints != nil {
+		if err := s.routeRepo.ReplaceCheckpoints(route.ID, checkpoints); err != nil {
+			return nil, err
+		}
+		route.Checkpoints = checkpoints
+	}
+	if updates.RequiredCertifications != nil {
+		if err := s.routeRepo.ReplaceRequiredCertifications(route.ID, required); err != nil {
+			return nil, err
+		}
+		route.RequiredCertifications = required
+	}
+
+	return route, nil
+}
+
+func (s *routeService) Delete(id uuid.UUID) error {
+	return s.routeRepo.Delete(id)
+}
+
+func (s *routeService) List(limit, offset int, region *string, activeOnly bool) ([]domain.Route, int64, error) {
+	return s.routeRepo.List(limit, offset, region, activeOnly)
+}
+
+func validateRoute(route *domain.Route) error {
+	if route.Code == "" {
+		return errors.New("route code is required")
+	}
+	if route.Name == "" {
+		return errors.New("route name is required")
+	}
+	if route.Region == "" {
+		return errors.New("route region is required")
+	}
+	if route.DurationDays < 1 {
+		return errors.New("route duration must be at least 1 day")
+	}
+	if route.MinTrekkers < 1 {
+		return errors.New("minimum trekkers must be at least 1")
+	}
+	if route.MaxTrekkers < route.MinTrekkers {
+		return errors.New("maximum trekkers must not be below the minimum")
+	}
+	if route.MaxSupportStaff < 0 {
+		return errors.New("maximum support staff must not be negative")
+	}
+	return nil
+}
+
+// prepareCheckpoints numbers checkpoints in order and checks that they are
+// reachable in sequence within the route duration.
+func prepareCheckpoints(checkpoints []domain.RouteCheckpoint, durationDays int) error {
+	prevDay := 0
+	for i := range checkpoints {
+		cp := &checkpoints[i]
+		cp.Sequence = i + 1
+
+		if cp.Name == "" {
+			return fmt.Errorf("checkpoint %d: name is required", cp.Sequence)
+		}
+		if cp.Latitude < -90 || cp.Latitude > 90 || cp.Longitude < -180 || cp.Longitude > 180 {
+			return fmt.Errorf("checkpoint %d: coordinates out of range", cp.Sequence)
+		}
+		if cp.ExpectedDayOffset < prevDay {
+			return fmt.Errorf("checkpoint %d: expected day must not be before the previous checkpoint", cp.Sequence)
+		}
+		if cp.ExpectedDayOffset > durationDays {
+			return fmt.Errorf("checkpoint %d: expected day is beyond the route duration", cp.Sequence)
+		}
+		if cp.RadiusMeters == 0 {
+			cp.RadiusMeters = 500
+		}
+		if cp.RadiusMeters < 0 {
+			return fmt.Errorf("checkpoint %d: radius must be positive", cp.Sequence)
+		}
+		prevDay = cp.ExpectedDayOffset
+	}
+	return nil
+}
+
+// prepareRequiredCertifications checks the certification types and drops
+// duplicates.
+func prepareRequiredCertifications(required []domain.RouteCertification) ([]domain.RouteCertification, error) {
+	seen := make(map[domain.CertificationType]bool, len(required))
+	prepared := make([]domain.RouteCertification, 0, len(required))
+	for _, rc := range required {
+		if !rc.CertificationType.Valid() {
+			return nil, fmt.Errorf("invalid certification type: %s", rc.CertificationType)
+		}
+		if seen[rc.CertificationType] {
+			continue
+		}
+		seen[rc.CertificationType] = true
+		prepared = append(prepared, rc)
+	}
+	return prepared, nil
+}
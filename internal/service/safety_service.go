@@ -2,26 +2,32 @@ package service
 
 import (
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/touros-platform/api/internal/domain"
+	"github.com/touros-platform/api/internal/events"
 	"github.com/touros-platform/api/internal/repository"
+	"go.uber.org/zap"
 )
 
 type SafetyService interface {
-	CreateCheckIn(req *CreateCheckInRequest) (*domain.SafetyCheckIn, error)
-	GetCheckInByID(id uuid.UUID) (*domain.SafetyCheckIn, error)
-	ListCheckIns(guideID uuid.UUID, limit, offset int) ([]domain.SafetyCheckIn, int64, error)
-	CreateIncident(req *CreateIncidentRequest) (*domain.Incident, error)
-	GetIncidentByID(id uuid.UUID) (*domain.Incident, error)
-	UpdateIncident(id uuid.UUID, req *UpdateIncidentRequest) (*domain.Incident, error)
-	ListIncidents(limit, offset int, status *domain.IncidentStatus, guideID *uuid.UUID) ([]domain.Incident, int64, error)
-	GetActiveSOS(guideID uuid.UUID) ([]domain.Incident, error)
+	CreateCheckIn(actor *Actor, req *CreateCheckInRequest) (*domain.SafetyCheckIn, error)
+	GetCheckInByID(actor *Actor, id uuid.UUID) (*domain.SafetyCheckIn, error)
+	ListCheckIns(actor *Actor, guideID uuid.UUID, limit, offset int) ([]domain.SafetyCheckIn, int64, error)
+	CreateIncident(actor *Actor, req *CreateIncidentRequest) (*domain.Incident, error)
+	GetIncidentByID(actor *Actor, id uuid.UUID) (*domain.Incident, error)
+	UpdateIncident(actor *Actor, id uuid.UUID, req *UpdateIncidentRequest) (*domain.Incident, error)
+	ListIncidents(actor *Actor, limit, offset int, status *domain.IncidentStatus, guideID *uuid.UUID) ([]domain.Incident, int64, error)
+	GetActiveSOS(actor *Actor, guideID uuid.UUID) ([]domain.Incident, error)
+	StreamFilter(actor *Actor, filter events.Filter) (events.Filter, error)
 }
 
+// CreateCheckInRequest records a check-in for GuideID. Guides leave it nil
+// to check in themselves; agency users and admins name the guide.
 type CreateCheckInRequest struct {
-	GuideID   uuid.UUID
+	GuideID   *uuid.UUID
 	PermitID  *uuid.UUID
 	Latitude  float64
 	Longitude float64
@@ -29,9 +35,11 @@ type CreateCheckInRequest struct {
 	Notes     string
 }
 
+// CreateIncidentRequest reports an incident for GuideID. Guides leave it nil
+// to report for themselves; agency users and admins name the guide.
 type CreateIncidentRequest struct {
 	IncidentType string
-	GuideID      uuid.UUID
+	GuideID      *uuid.UUID
 	PermitID     *uuid.UUID
 	Latitude     float64
 	Longitude    float64
@@ -49,28 +57,43 @@ type safetyService struct {
 	checkInRepo  repository.SafetyCheckInRepository
 	incidentRepo repository.IncidentRepository
 	guideRepo    repository.GuideRepository
+	permitRepo   repository.PermitRepository
+	publisher    events.Publisher
+	authz        *authorizer
+	logger       *zap.Logger
 }
 
 func NewSafetyService(
 	checkInRepo repository.SafetyCheckInRepository,
 	incidentRepo repository.IncidentRepository,
 	guideRepo repository.GuideRepository,
+	permitRepo repository.PermitRepository,
+	publisher events.Publisher,
+	logger *zap.Logger,
 ) SafetyService {
 	return &safetyService{
 		checkInRepo:  checkInRepo,
 		incidentRepo: incidentRepo,
 		guideRepo:    guideRepo,
+		permitRepo:   permitRepo,
+		publisher:    publisher,
+		authz:        newAuthorizer(guideRepo, permitRepo),
+		logger:       logger,
 	}
 }
 
-func (s *safetyService) CreateCheckIn(req *CreateCheckInRequest) (*domain.SafetyCheckIn, error) {
-	_, err := s.guideRepo.GetByID(req.GuideID)
+func (s *safetyService) CreateCheckIn(actor *Actor, req *CreateCheckInRequest) (*domain.SafetyCheckIn, error) {
+	guide, err := s.guideFor(actor, req.GuideID)
 	if err != nil {
-		return nil, errors.New("guide not found")
+		return nil, err
+	}
+	permitAgencyID, err := s.permitAgency(actor, req.PermitID)
+	if err != nil {
+		return nil, err
 	}
 
 	checkIn := &domain.SafetyCheckIn{
-		GuideID:     req.GuideID,
+		GuideID:     guide.ID,
 		PermitID:    req.PermitID,
 		Latitude:    req.Latitude,
 		Longitude:   req.Longitude,
@@ -83,25 +106,78 @@ func (s *safetyService) CreateCheckIn(req *CreateCheckInRequest) (*domain.Safety
 		return nil, err
 	}
 
-	if err := s.guideRepo.UpdateLastCheckIn(req.GuideID); err != nil {
-		return nil, err
+	// The check-in is saved at this point. Failures below are logged rather
+	// than returned, so that a client retrying the request does not record
+	// the same check-in twice.
+	if err := s.guideRepo.UpdateLastCheckIn(guide.ID); err != nil {
+		s.logger.Error("Failed to update last check-in",
+			zap.String("guide_id", guide.ID.String()),
+			zap.Error(err),
+		)
+	}
+
+	s.publisher.Publish(events.NewCheckInEvent(checkIn, guide.AgencyID, permitAgencyID))
+
+	if err := s.resolveOverdueIncidents(checkIn, guide.AgencyID); err != nil {
+		s.logger.Error("Failed to resolve overdue check-in incidents",
+			zap.String("guide_id", guide.ID.String()),
+			zap.String("check_in_id", checkIn.ID.String()),
+			zap.Error(err),
+		)
 	}
 
 	return checkIn, nil
 }
 
-func (s *safetyService) GetCheckInByID(id uuid.UUID) (*domain.SafetyCheckIn, error) {
-	return s.checkInRepo.GetByID(id)
+// resolveOverdueIncidents closes any overdue check-in incident raised by the
+// check-in monitor once the guide has been heard from again.
+func (s *safetyService) resolveOverdueIncidents(checkIn *domain.SafetyCheckIn, guideAgencyID *uuid.UUID) error {
+	incidents, err := s.incidentRepo.GetActiveByGuideIDAndType(checkIn.GuideID, domain.IncidentTypeOverdue)
+	if err != nil {
+		return err
+	}
+
+	for i := range incidents {
+		incident := &incidents[i]
+		incident.Status = domain.IncidentStatusResolved
+		incident.ResolvedAt = &checkIn.CheckInTime
+		incident.ResolutionNotes = fmt.Sprintf("Guide checked in at %s (%.5f, %.5f)",
+			checkIn.CheckInTime.Format(time.RFC3339), checkIn.Latitude, checkIn.Longitude)
+		if err := s.incidentRepo.Update(incident); err != nil {
+			return err
+		}
+		s.publisher.Publish(events.NewIncidentEvent(events.IncidentResolved, incident, guideAgencyID, s.incidentPermitAgency(incident)))
+	}
+
+	return nil
 }
 
-func (s *safetyService) ListCheckIns(guideID uuid.UUID, limit, offset int) ([]domain.SafetyCheckIn, int64, error) {
+func (s *safetyService) GetCheckInByID(actor *Actor, id uuid.UUID) (*domain.SafetyCheckIn, error) {
+	checkIn, err := s.checkInRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkGuideID(actor, checkIn.GuideID); err != nil {
+		return nil, err
+	}
+	return checkIn, nil
+}
+
+func (s *safetyService) ListCheckIns(actor *Actor, guideID uuid.UUID, limit, offset int) ([]domain.SafetyCheckIn, int64, error) {
+	if err := s.checkGuideID(actor, guideID); err != nil {
+		return nil, 0, err
+	}
 	return s.checkInRepo.ListByGuideID(guideID, limit, offset)
 }
 
-func (s *safetyService) CreateIncident(req *CreateIncidentRequest) (*domain.Incident, error) {
-	_, err := s.guideRepo.GetByID(req.GuideID)
+func (s *safetyService) CreateIncident(actor *Actor, req *CreateIncidentRequest) (*domain.Incident, error) {
+	guide, err := s.guideFor(actor, req.GuideID)
 	if err != nil {
-		return nil, errors.New("guide not found")
+		return nil, err
+	}
+	permitAgencyID, err := s.permitAgency(actor, req.PermitID)
+	if err != nil {
+		return nil, err
 	}
 
 	incidentType := domain.IncidentType(req.IncidentType)
@@ -115,7 +191,7 @@ func (s *safetyService) CreateIncident(req *CreateIncidentRequest) (*domain.Inci
 
 	incident := &domain.Incident{
 		IncidentType: incidentType,
-		GuideID:      req.GuideID,
+		GuideID:      guide.ID,
 		PermitID:     req.PermitID,
 		Status:       domain.IncidentStatusOpen,
 		Latitude:     req.Latitude,
@@ -129,18 +205,30 @@ func (s *safetyService) CreateIncident(req *CreateIncidentRequest) (*domain.Inci
 		return nil, err
 	}
 
+	s.publisher.Publish(events.NewIncidentEvent(events.IncidentCreated, incident, guide.AgencyID, permitAgencyID))
+
 	return incident, nil
 }
 
-func (s *safetyService) GetIncidentByID(id uuid.UUID) (*domain.Incident, error) {
-	return s.incidentRepo.GetByID(id)
+func (s *safetyService) GetIncidentByID(actor *Actor, id uuid.UUID) (*domain.Incident, error) {
+	incident, err := s.incidentRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authz.checkIncident(actor, incident); err != nil {
+		return nil, err
+	}
+	return incident, nil
 }
 
-func (s *safetyService) UpdateIncident(id uuid.UUID, req *UpdateIncidentRequest) (*domain.Incident, error) {
+func (s *safetyService) UpdateIncident(actor *Actor, id uuid.UUID, req *UpdateIncidentRequest) (*domain.Incident, error) {
 	incident, err := s.incidentRepo.GetByID(id)
 	if err != nil {
 		return nil, err
 	}
+	if err := s.authz.checkIncident(actor, incident); err != nil {
+		return nil, err
+	}
 
 	if req.Status != nil {
 		incident.Status = *req.Status
@@ -161,13 +249,112 @@ func (s *safetyService) UpdateIncident(id uuid.UUID, req *UpdateIncidentRequest)
 		return nil, err
 	}
 
+	eventType := events.IncidentUpdated
+	if incident.Status == domain.IncidentStatusResolved || incident.Status == domain.IncidentStatusClosed {
+		eventType = events.IncidentResolved
+	}
+	s.publisher.Publish(events.NewIncidentEvent(eventType, incident, incident.Guide.AgencyID, s.incidentPermitAgency(incident)))
+
 	return incident, nil
 }
 
-func (s *safetyService) ListIncidents(limit, offset int, status *domain.IncidentStatus, guideID *uuid.UUID) ([]domain.Incident, int64, error) {
-	return s.incidentRepo.List(limit, offset, status, guideID)
+func (s *safetyService) ListIncidents(actor *Actor, limit, offset int, status *domain.IncidentStatus, guideID *uuid.UUID) ([]domain.Incident, int64, error) {
+	scope, err := s.authz.scope(actor)
+	if err != nil {
+		return nil, 0, err
+	}
+	return s.incidentRepo.List(limit, offset, scope, status, guideID)
 }
 
-func (s *safetyService) GetActiveSOS(guideID uuid.UUID) ([]domain.Incident, error) {
+func (s *safetyService) GetActiveSOS(actor *Actor, guideID uuid.UUID) ([]domain.Incident, error) {
+	if err := s.checkGuideID(actor, guideID); err != nil {
+		return nil, err
+	}
 	return s.incidentRepo.GetActiveSOSByGuideID(guideID)
 }
+
+// StreamFilter narrows a live event subscription to the actor's scope:
+// agency users receive their agency's events and guides their own.
+func (s *safetyService) StreamFilter(actor *Actor, filter events.Filter) (events.Filter, error) {
+	scope, err := s.authz.scope(actor)
+	if err != nil {
+		return filter, err
+	}
+
+	if scope.AgencyID != nil {
+		if filter.AgencyID != nil && *filter.AgencyID != *scope.AgencyID {
+			return filter, ErrForbidden
+		}
+		filter.AgencyID = scope.AgencyID
+	}
+	if scope.GuideID != nil {
+		if filter.GuideID != nil && *filter.GuideID != *scope.GuideID {
+			return filter, ErrForbidden
+		}
+		filter.GuideID = scope.GuideID
+	}
+	return filter, nil
+}
+
+// guideFor returns the guide a check-in or incident is filed for: the
+// caller's own profile for guides, or the named guide for anyone else.
+func (s *safetyService) guideFor(actor *Actor, guideID *uuid.UUID) (*domain.Guide, error) {
+	if guideID == nil {
+		if actor.Role != domain.RoleGuide {
+			return nil, errors.New("guide_id is required")
+		}
+		if actor.GuideID == nil {
+			return nil, errors.New("guide profile not found")
+		}
+		guideID = actor.GuideID
+	}
+
+	guide, err := s.guideRepo.GetByID(*guideID)
+	if err != nil {
+		return nil, errors.New("guide not found")
+	}
+	if err := s.authz.checkGuide(actor, guide); err != nil {
+		return nil, err
+	}
+	return guide, nil
+}
+
+// permitAgency checks an optional permit reference is in the actor's scope
+// and returns the permit's agency, which live events are published to.
+func (s *safetyService) permitAgency(actor *Actor, permitID *uuid.UUID) (*uuid.UUID, error) {
+	if permitID == nil {
+		return nil, nil
+	}
+	permit, err := s.permitRepo.GetByID(*permitID)
+	if err != nil {
+		return nil, errors.New("permit not found")
+	}
+	if err := s.authz.checkPermit(actor, permit); err != nil {
+		return nil, err
+	}
+	return permit.AgencyID, nil
+}
+
+// incidentPermitAgency returns the agency of the incident's permit, or nil
+// when it has none.
+func (s *safetyService) incidentPermitAgency(incident *domain.Incident) *uuid.UUID {
+	if incident.PermitID == nil {
+		return nil
+	}
+	if incident.Permit != nil {
+		return incident.Permit.AgencyID
+	}
+	permit, err := s.permitRepo.GetByID(*incident.PermitID)
+	if err != nil {
+		return nil
+	}
+	return permit.AgencyID
+}
+
+func (s *safetyService) checkGuideID(actor *Actor, guideID uuid.UUID) error {
+	guide, err := s.guideRepo.GetByID(guideID)
+	if err != nil {
+		return errors.New("guide not found")
+	}
+	return s.authz.checkGuide(actor, guide)
+}
@@ -0,0 +1,333 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/touros-platform/api/internal/config"
+	"github.com/touros-platform/api/internal/domain"
+	"github.com/touros-platform/api/internal/repository"
+)
+
+// APIKeyPrefix starts every API key so that keys can be told apart from
+// JWTs in the Authorization header, and spotted by secret scanners.
+const APIKeyPrefix = "tsk_"
+
+// apiKeyTouchInterval limits how often last-used tracking writes to the
+// database for a busy key.
+const apiKeyTouchInterval = time.Minute
+
+var errInvalidAPIKey = errors.New("invalid API key")
+
+type ServiceAccountService interface {
+	Create(actor *Actor, account *domain.ServiceAccount) error
+	GetByID(actor *Actor, id uuid.UUID) (*domain.ServiceAccount, error)
+	Delete(actor *Actor, id uuid.UUID) error
+	List(actor *Actor, limit, offset int) ([]domain.ServiceAccount, int64, error)
+	CreateKey(actor *Actor, accountID uuid.UUID, scopes []string, expiresAt *time.Time) (*domain.APIKey, string, error)
+	ListKeys(actor *Actor, accountID uuid.UUID) ([]domain.APIKey, error)
+	RotateKey(actor *Actor, accountID, keyID uuid.UUID) (*domain.APIKey, string, error)
+	RevokeKey(actor *Actor, accountID, keyID uuid.UUID) error
+	Authenticate(key, ip string) (*Actor, error)
+}
+
+type serviceAccountService struct {
+	accountRepo repository.ServiceAccountRepository
+	agencyRepo  repository.AgencyRepository
+	userRepo    repository.UserRepository
+	config      config.APIKeyConfig
+}
+
+func NewServiceAccountService(accountRepo repository.ServiceAccountRepository, agencyRepo repository.AgencyRepository, userRepo repository.UserRepository, cfg config.APIKeyConfig) ServiceAccountService {
+	return &serviceAccountService{
+		accountRepo: accountRepo,
+		agencyRepo:  agencyRepo,
+		userRepo:    userRepo,
+		config:      cfg,
+	}
+}
+
+// IsAPIKey reports whether a bearer credential is an API key rather than a
+// JWT.
+func IsAPIKey(credential string) bool {
+	return strings.HasPrefix(credential, APIKeyPrefix)
+}
+
+// Create registers a service account. Agency owners create accounts for
+// their own agency; admins name the agency.
+func (s *serviceAccountService) Create(actor *Actor, account *domain.ServiceAccount) error {
+	if actor.Role == domain.RoleAgency {
+		if account.AgencyID != uuid.Nil && !sameAgency(actor.AgencyID, &account.AgencyID) {
+			return ErrForbidden
+		}
+		if actor.AgencyID == nil {
+			return errors.New("user is not assigned to an agency")
+		}
+		account.AgencyID = *actor.AgencyID
+	}
+	if account.AgencyID == uuid.Nil {
+		return errors.New("agency_id is required")
+	}
+	if err := s.checkManager(actor, account.AgencyID); err != nil {
+		return err
+	}
+	if _, err := s.agencyRepo.GetByID(account.AgencyID); err != nil {
+		return errors.New("agency not found")
+	}
+
+	account.IsActive = true
+	account.CreatedBy = actor.UserID
+	return s.accountRepo.Create(account)
+}
+
+func (s *serviceAccountService) GetByID(actor *Actor, id uuid.UUID) (*domain.ServiceAccount, error) {
+	if actor.Role != domain.RoleAdmin && actor.Role != domain.RoleAgency {
+		return nil, ErrForbidden
+	}
+
+	account, err := s.accountRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkManager(actor, account.AgencyID); err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+// Delete removes the account and revokes all of its keys.
+func (s *serviceAccountService) Delete(actor *Actor, id uuid.UUID) error {
+	if _, err := s.GetByID(actor, id); err != nil {
+		return err
+	}
+	return s.accountRepo.Delete(id)
+}
+
+func (s *serviceAccountService) List(actor *Actor, limit, offset int) ([]domain.ServiceAccount, int64, error) {
+	var scope repository.Scope
+	if actor.Role != domain.RoleAdmin {
+		if actor.Role != domain.RoleAgency {
+			return nil, 0, ErrForbidden
+		}
+		if actor.AgencyID == nil {
+			return nil, 0, errors.New("user is not assigned to an agency")
+		}
+		if err := s.checkManager(actor, *actor.AgencyID); err != nil {
+			return nil, 0, err
+		}
+		scope.AgencyID = actor.AgencyID
+	}
+	return s.accountRepo.List(limit, offset, scope)
+}
+
+// CreateKey issues a new key for the account and returns it in plain text
+// along with its record. The plain key cannot be retrieved again.
+func (s *serviceAccountService) CreateKey(actor *Actor, accountID uuid.UUID, scopes []string, expiresAt *time.Time) (*domain.APIKey, string, error) {
+	account, err := s.GetByID(actor, accountID)
+	if err != nil {
+		return nil, "", err
+	}
+	if !account.IsActive {
+		return nil, "", errors.New("service account is not active")
+	}
+
+	normalized, err := normalizeScopes(scopes)
+	if err != nil {
+		return nil, "", err
+	}
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return nil, "", errors.New("expires_at must be in the future")
+	}
+
+	key, plain, err := newAPIKey(account.ID, normalized, expiresAt, actor.UserID)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := s.accountRepo.CreateKey(key); err != nil {
+		return nil, "", err
+	}
+	return key, plain, nil
+}
+
+func (s *serviceAccountService) ListKeys(actor *Actor, accountID uuid.UUID) ([]domain.APIKey, error) {
+	if _, err := s.GetByID(actor, accountID); err != nil {
+		return nil, err
+	}
+	return s.accountRepo.ListKeys(accountID)
+}
+
+// RotateKey issues a replacement key with the same scopes and expiry. The
+// old key keeps working for the configured grace period so that the
+// integration can switch over.
+func (s *serviceAccountService) RotateKey(actor *Actor, accountID, keyID uuid.UUID) (*domain.APIKey, string, error) {
+	account, err := s.GetByID(actor, accountID)
+	if err != nil {
+		return nil, "", err
+	}
+	if !account.IsActive {
+		return nil, "", errors.New("service account is not active")
+	}
+
+	old, err := s.accountRepo.GetKey(accountID, keyID)
+	if err != nil {
+		return nil, "", errors.New("API key not found")
+	}
+	now := time.Now()
+	if !old.Usable(now) {
+		return nil, "", errors.New("API key is expired or revoked")
+	}
+	if old.RotatedTo != nil {
+		return nil, "", errors.New("API key has already been rotated")
+	}
+
+	next, plain, err := newAPIKey(account.ID, old.ScopeList(), old.ExpiresAt, actor.UserID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	graceEnd := now.Add(s.config.RotationGrace)
+	if old.ExpiresAt == nil || graceEnd.Before(*old.ExpiresAt) {
+		old.ExpiresAt = &graceEnd
+	}
+
+	if err := s.accountRepo.RotateKey(old, next); err != nil {
+		return nil, "", err
+	}
+	return next, plain, nil
+}
+
+func (s *serviceAccountService) RevokeKey(actor *Actor, accountID, keyID uuid.UUID) error {
+	if _, err := s.GetByID(actor, accountID); err != nil {
+		return err
+	}
+	if _, err := s.accountRepo.GetKey(accountID, keyID); err != nil {
+		return errors.New("API key not found")
+	}
+	return s.accountRepo.RevokeKey(keyID)
+}
+
+// Authenticate checks an API key and returns the service account it
+// belongs to as an agency Actor limited to the key's scopes.
+func (s *serviceAccountService) Authenticate(key, ip string) (*Actor, error) {
+	prefix, ok := splitAPIKey(key)
+	if !ok {
+		return nil, errInvalidAPIKey
+	}
+
+	record, err := s.accountRepo.GetKeyByPrefix(prefix)
+	if err != nil {
+		return nil, errInvalidAPIKey
+	}
+	if subtle.ConstantTimeCompare([]byte(record.KeyHash), []byte(hashAPIKey(key))) != 1 {
+		return nil, errInvalidAPIKey
+	}
+
+	now := time.Now()
+	if !record.Usable(now) {
+		return nil, errors.New("API key is expired or revoked")
+	}
+
+	account, err := s.accountRepo.GetByID(record.ServiceAccountID)
+	if err != nil || !account.IsActive {
+		return nil, errors.New("service account is not active")
+	}
+	if account.Agency != nil && account.Agency.Status == domain.AgencyStatusSuspended {
+		return nil, errors.New("agency is suspended")
+	}
+
+	if record.LastUsedAt == nil || now.Sub(*record.LastUsedAt) >= apiKeyTouchInterval || record.LastUsedIP != ip {
+		if err := s.accountRepo.TouchKey(record.ID, now, ip); err != nil {
+			return nil, fmt.Errorf("failed to record API key use: %w", err)
+		}
+	}
+
+	return &Actor{
+		UserID:         account.ID,
+		Role:           domain.RoleAgency,
+		AgencyID:       &account.AgencyID,
+		ServiceAccount: true,
+		Scopes:         record.ScopeList(),
+	}, nil
+}
+
+// checkManager allows admins and the agency's owners, but not service
+// accounts, to manage the agency's service accounts. Their keys outlive any
+// one member, so managers and clerks cannot issue them.
+func (s *serviceAccountService) checkManager(actor *Actor, agencyID uuid.UUID) error {
+	if actor.ServiceAccount {
+		return ErrForbidden
+	}
+	return checkAgencyRole(s.userRepo, actor, agencyID, domain.AgencyRoleOwner)
+}
+
+func normalizeScopes(scopes []string) ([]string, error) {
+	if len(scopes) == 0 {
+		return nil, errors.New("at least one scope is required")
+	}
+
+	seen := make(map[string]bool, len(scopes))
+	normalized := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		scope = strings.ToLower(strings.TrimSpace(scope))
+		if !domain.ValidAPIKeyScope(scope) {
+			return nil, fmt.Errorf("invalid scope: %s", scope)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			normalized = append(normalized, scope)
+		}
+	}
+	sort.Strings(normalized)
+	return normalized, nil
+}
+
+// newAPIKey generates a key of the form tsk_<prefix>_<secret>. The prefix
+// identifies the key in the database and in listings.
+func newAPIKey(accountID uuid.UUID, scopes []string, expiresAt *time.Time, createdBy uuid.UUID) (*domain.APIKey, string, error) {
+	prefix := make([]byte, 6)
+	secret := make([]byte, 32)
+	if _, err := rand.Read(prefix); err != nil {
+		return nil, "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	if _, err := rand.Read(secret); err != nil {
+		return nil, "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+
+	keyPrefix := hex.EncodeToString(prefix)
+	plain := APIKeyPrefix + keyPrefix + "_" + hex.EncodeToString(secret)
+
+	return &domain.APIKey{
+		ServiceAccountID: accountID,
+		Prefix:           keyPrefix,
+		KeyHash:          hashAPIKey(plain),
+		Scopes:           strings.Join(scopes, " "),
+		ExpiresAt:        expiresAt,
+		CreatedBy:        createdBy,
+	}, plain, nil
+}
+
+func splitAPIKey(key string) (string, bool) {
+	if !IsAPIKey(key) {
+		return "", false
+	}
+	prefix, secret, ok := strings.Cut(strings.TrimPrefix(key, APIKeyPrefix), "_")
+	if !ok || prefix == "" || secret == "" {
+		return "", false
+	}
+	return prefix, true
+}
+
+// hashAPIKey hashes the whole key. Keys carry 256 bits of randomness, so an
+// unsalted SHA-256 is enough to make a leaked table useless.
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
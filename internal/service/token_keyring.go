@@ -0,0 +1,206 @@
+package service
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/touros-platform/api/internal/config"
+	"github.com/touros-platform/api/pkg/jwk"
+)
+
+// minRSAKeyBits is the smallest RSA key accepted for signing or
+// verification.
+const minRSAKeyBits = 2048
+
+type verificationKey struct {
+	method jwt.SigningMethod
+	key    crypto.PublicKey
+}
+
+// TokenKeyring signs access tokens and verifies them. With a signing key
+// configured, tokens are signed with RS256 or EdDSA and carry the key ID in
+// their kid header, and every public key is published as a JWKS so that
+// other services can verify tokens without holding a secret. Retired keys
+// keep verifying until the tokens they signed have expired. Without a
+// signing key, tokens are signed with HS256 and the access secret.
+type TokenKeyring struct {
+	method     jwt.SigningMethod
+	keyID      string
+	signingKey interface{}
+	keys       map[string]verificationKey
+	keySet     jwk.Set
+}
+
+// NewTokenKeyring loads the access token keys from config.
+func NewTokenKeyring(cfg config.JWTConfig) (*TokenKeyring, error) {
+	keyring := &TokenKeyring{
+		keys:   make(map[string]verificationKey),
+		keySet: jwk.Set{Keys: []jwk.Key{}},
+	}
+
+	if cfg.SigningKeyFile == "" {
+		keyring.method = jwt.SigningMethodHS256
+		keyring.signingKey = []byte(cfg.AccessSecret)
+		return keyring, nil
+	}
+
+	if cfg.SigningKeyID == "" {
+		return nil, errors.New("JWT_SIGNING_KEY_ID must be set with JWT_SIGNING_KEY_FILE")
+	}
+	private, err := loadPrivateKey(cfg.SigningKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT_SIGNING_KEY_FILE: %w", err)
+	}
+
+	var public crypto.PublicKey
+	switch key := private.(type) {
+	case *rsa.PrivateKey:
+		keyring.method = jwt.SigningMethodRS256
+		public = &key.PublicKey
+	case ed25519.PrivateKey:
+		keyring.method = jwt.SigningMethodEdDSA
+		public = key.Public()
+	default:
+		return nil, errors.New("invalid JWT_SIGNING_KEY_FILE: expected an RSA or Ed25519 private key")
+	}
+	keyring.keyID = cfg.SigningKeyID
+	keyring.signingKey = private
+
+	if err := keyring.trust(keyring.keyID, public); err != nil {
+		return nil, err
+	}
+
+	if cfg.VerificationKeys != "" {
+		for _, entry := range strings.Split(cfg.VerificationKeys, ",") {
+			kid, path, ok := strings.Cut(strings.TrimSpace(entry), ":")
+			if !ok || kid == "" {
+				return nil, fmt.Errorf("invalid JWT_VERIFICATION_KEYS entry %q", entry)
+			}
+			if _, exists := keyring.keys[kid]; exists {
+				return nil, fmt.Errorf("verification key %q is listed twice or shadows the active signing key", kid)
+			}
+			pub, err := loadPublicKey(path)
+			if err != nil {
+				return nil, fmt.Errorf("invalid verification key %q: %w", kid, err)
+			}
+			if err := keyring.trust(kid, pub); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return keyring, nil
+}
+
+func (k *TokenKeyring) trust(keyID string, pub crypto.PublicKey) error {
+	var method jwt.SigningMethod
+	switch key := pub.(type) {
+	case *rsa.PublicKey:
+		if key.N.BitLen() < minRSAKeyBits {
+			return fmt.Errorf("RSA key %q is shorter than %d bits", keyID, minRSAKeyBits)
+		}
+		method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		method = jwt.SigningMethodEdDSA
+	default:
+		return fmt.Errorf("key %q is not an RSA or Ed25519 public key", keyID)
+	}
+
+	published, err := jwk.NewKey(keyID, pub)
+	if err != nil {
+		return err
+	}
+	k.keys[keyID] = verificationKey{method: method, key: pub}
+	k.keySet.Keys = append(k.keySet.Keys, published)
+	return nil
+}
+
+// Algorithm names the algorithm new access tokens are signed with.
+func (k *TokenKeyring) Algorithm() string {
+	return k.method.Alg()
+}
+
+// Sign signs claims with the active key.
+func (k *TokenKeyring) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(k.method, claims)
+	if k.keyID != "" {
+		token.Header["kid"] = k.keyID
+	}
+	return token.SignedString(k.signingKey)
+}
+
+// Keyfunc selects the key to verify a token with. HS256 tokens are only
+// accepted while no signing key is configured; asymmetric tokens must name a
+// known key and use that key's algorithm.
+func (k *TokenKeyring) Keyfunc(token *jwt.Token) (interface{}, error) {
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); ok {
+		if k.keyID != "" || token.Method.Alg() != jwt.SigningMethodHS256.Alg() {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return k.signingKey, nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	key, ok := k.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key: %q", kid)
+	}
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	return key.key, nil
+}
+
+// KeySet returns the public keys as a JSON Web Key Set. It is empty while
+// tokens are signed with HS256.
+func (k *TokenKeyring) KeySet() jwk.Set {
+	return k.keySet
+}
+
+func loadPrivateKey(path string) (crypto.PrivateKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		return x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	return nil, fmt.Errorf("unexpected PEM block %q", block.Type)
+}
+
+func loadPublicKey(path string) (crypto.PublicKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	switch block.Type {
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	case "PUBLIC KEY":
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	}
+	return nil, fmt.Errorf("unexpected PEM block %q", block.Type)
+}
+
+func readPEM(path string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+	return block, nil
+}
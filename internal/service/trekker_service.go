@@ -0,0 +1,192 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/touros-platform/api/internal/domain"
+	"github.com/touros-platform/api/internal/repository"
+)
+
+type TrekkerService interface {
+	Create(actor *Actor, trekker *domain.Trekker) error
+	GetByID(actor *Actor, id uuid.UUID) (*domain.Trekker, error)
+	Update(actor *Actor, id uuid.UUID, updates *UpdateTrekkerRequest) (*domain.Trekker, error)
+	Delete(id uuid.UUID) error
+	List(actor *Actor, limit, offset int, filter repository.TrekkerFilter) ([]domain.Trekker, int64, error)
+	ListPermits(actor *Actor, id uuid.UUID, limit, offset int) ([]domain.Permit, int64, error)
+}
+
+type UpdateTrekkerRequest struct {
+	FullName              *string
+	Email                 *string
+	Phone                 *string
+	PassportNumber        *string
+	Nationality           *string
+	DateOfBirth           *time.Time
+	InsuranceProvider     *string
+	InsurancePolicyNumber *string
+	InsuranceExpiry       *time.Time
+	EmergencyContacts     *[]domain.EmergencyContact
+}
+
+type trekkerService struct {
+	trekkerRepo repository.TrekkerRepository
+	permitRepo  repository.PermitRepository
+	userRepo    repository.UserRepository
+	authz       *authorizer
+}
+
+func NewTrekkerService(trekkerRepo repository.TrekkerRepository, permitRepo repository.PermitRepository, guideRepo repository.GuideRepository, userRepo repository.UserRepository) TrekkerService {
+	return &trekkerService{
+		trekkerRepo: trekkerRepo,
+		permitRepo:  permitRepo,
+		userRepo:    userRepo,
+		authz:       newAuthorizer(guideRepo, permitRepo),
+	}
+}
+
+// Create registers a trekker on behalf of the actor. The trekker is attached
+// to the registering user's agency, if any, and its passport must be new to
+// that agency; another agency may register the same traveller.
+func (s *trekkerService) Create(actor *Actor, trekker *domain.Trekker) error {
+	trekker.PassportNumber = normalizePassportNumber(trekker.PassportNumber)
+	trekker.Nationality = strings.ToUpper(strings.TrimSpace(trekker.Nationality))
+
+	trekker.CreatedBy = &actor.UserID
+	if actor.ServiceAccount {
+		// Service accounts have no user record; the account's agency is
+		// already on the actor.
+		trekker.AgencyID = actor.AgencyID
+	} else {
+		user, err := s.userRepo.GetByID(actor.UserID)
+		if err != nil {
+			return errors.New("user not found")
+		}
+		trekker.AgencyID = user.AgencyID
+	}
+
+	if err := s.checkPassport(trekker.AgencyID, trekker.Nationality, trekker.PassportNumber, uuid.Nil); err != nil {
+		return err
+	}
+
+	return s.trekkerRepo.Create(trekker)
+}
+
+func (s *trekkerService) GetByID(actor *Actor, id uuid.UUID) (*domain.Trekker, error) {
+	trekker, err := s.trekkerRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authz.checkTrekker(actor, trekker); err != nil {
+		return nil, err
+	}
+	return trekker, nil
+}
+
+func (s *trekkerService) Update(actor *Actor, id uuid.UUID, updates *UpdateTrekkerRequest) (*domain.Trekker, error) {
+	trekker, err := s.trekkerRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authz.checkTrekker(actor, trekker); err != nil {
+		return nil, err
+	}
+
+	if updates.FullName != nil {
+		trekker.FullName = *updates.FullName
+	}
+	if updates.Email != nil {
+		trekker.Email = *updates.Email
+	}
+	if updates.Phone != nil {
+		trekker.Phone = *updates.Phone
+	}
+	if updates.PassportNumber != nil {
+		trekker.PassportNumber = normalizePassportNumber(*updates.PassportNumber)
+	}
+	if updates.Nationality != nil {
+		trekker.Nationality = strings.ToUpper(strings.TrimSpace(*updates.Nationality))
+	}
+	if updates.DateOfBirth != nil {
+		trekker.DateOfBirth = updates.DateOfBirth
+	}
+	if updates.InsuranceProvider != nil {
+		trekker.InsuranceProvider = *updates.InsuranceProvider
+	}
+	if updates.InsurancePolicyNumber != nil {
+		trekker.InsurancePolicyNumber = *updates.InsurancePolicyNumber
+	}
+	if updates.InsuranceExpiry != nil {
+		trekker.InsuranceExpiry = updates.InsuranceExpiry
+	}
+
+	if updates.PassportNumber != nil || updates.Nationality != nil {
+		if err := s.checkPassport(trekker.AgencyID, trekker.Nationality, trekker.PassportNumber, trekker.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.trekkerRepo.Update(trekker); err != nil {
+		return nil, err
+	}
+
+	if updates.EmergencyContacts != nil {
+		contacts := *updates.EmergencyContacts
+		if err := s.trekkerRepo.ReplaceEmergencyContacts(trekker.ID, contacts); err != nil {
+			return nil, err
+		}
+		trekker.EmergencyContacts = contacts
+	}
+
+	return trekker, nil
+}
+
+func (s *trekkerService) Delete(id uuid.UUID) error {
+	return s.trekkerRepo.Delete(id)
+}
+
+func (s *trekkerService) List(actor *Actor, limit, offset int, filter repository.TrekkerFilter) ([]domain.Trekker, int64, error) {
+	scope, err := s.authz.scope(actor)
+	if err != nil {
+		return nil, 0, err
+	}
+	filter.PassportNumber = normalizePassportNumber(filter.PassportNumber)
+	filter.Nationality = strings.ToUpper(filter.Nationality)
+	return s.trekkerRepo.List(limit, offset, scope, filter)
+}
+
+// ListPermits returns the trekker's permit history, newest trek first.
+// Agency users and guides only see the permits within their own scope.
+func (s *trekkerService) ListPermits(actor *Actor, id uuid.UUID, limit, offset int) ([]domain.Permit, int64, error) {
+	if _, err := s.GetByID(actor, id); err != nil {
+		return nil, 0, errors.New("trekker not found")
+	}
+	scope, err := s.authz.scope(actor)
+	if err != nil {
+		return nil, 0, err
+	}
+	return s.permitRepo.ListByTrekkerID(id, scope, limit, offset)
+}
+
+// checkPassport refuses a passport already registered by the agency.
+func (s *trekkerService) checkPassport(agencyID *uuid.UUID, nationality, passportNumber string, self uuid.UUID) error {
+	if passportNumber == "" {
+		return errors.New("passport number is required")
+	}
+	if len(nationality) != 2 {
+		return errors.New("nationality must be an ISO 3166-1 alpha-2 country code")
+	}
+
+	existing, _ := s.trekkerRepo.GetByPassport(agencyID, nationality, passportNumber)
+	if existing != nil && existing.ID != self {
+		return errors.New("trekker with this passport already exists in this agency")
+	}
+	return nil
+}
+
+func normalizePassportNumber(passportNumber string) string {
+	return strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(passportNumber), " ", ""))
+}
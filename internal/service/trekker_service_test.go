@@ -0,0 +1,126 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/touros-platform/api/internal/domain"
+	"github.com/touros-platform/api/internal/repository"
+	"gorm.io/gorm"
+)
+
+// fakeTrekkerRepo keeps trekkers in memory.
+type fakeTrekkerRepo struct {
+	repository.TrekkerRepository
+	trekkers map[uuid.UUID]*domain.Trekker
+}
+
+func (r *fakeTrekkerRepo) Create(trekker *domain.Trekker) error {
+	trekker.ID = uuid.New()
+	copied := *trekker
+	r.trekkers[trekker.ID] = &copied
+	return nil
+}
+
+func (r *fakeTrekkerRepo) GetByID(id uuid.UUID) (*domain.Trekker, error) {
+	trekker, ok := r.trekkers[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *trekker
+	return &copied, nil
+}
+
+func (r *fakeTrekkerRepo) GetByPassport(agencyID *uuid.UUID, nationality, passportNumber string) (*domain.Trekker, error) {
+	for _, trekker := range r.trekkers {
+		if sameOptionalID(trekker.AgencyID, agencyID) && trekker.Nationality == nationality && trekker.PassportNumber == passportNumber {
+			copied := *trekker
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeTrekkerRepo) Update(trekker *domain.Trekker) error {
+	copied := *trekker
+	r.trekkers[trekker.ID] = &copied
+	return nil
+}
+
+type trekkerHarness struct {
+	*loginHarness
+	trekkers TrekkerService
+}
+
+func newTrekkerHarness(t *testing.T) *trekkerHarness {
+	h := &trekkerHarness{loginHarness: newLoginHarness(t)}
+	repo := &fakeTrekkerRepo{trekkers: make(map[uuid.UUID]*domain.Trekker)}
+	h.trekkers = NewTrekkerService(repo, nil, nil, &fakeUserRepo{h.loginHarness})
+	return h
+}
+
+func (h *trekkerHarness) agencyActor(email string, agencyID uuid.UUID) *Actor {
+	user := h.addAgencyUser(email, agencyID, domain.AgencyRoleClerk)
+	return &Actor{UserID: user.ID, Role: domain.RoleAgency, AgencyID: &agencyID}
+}
+
+func (h *trekkerHarness) register(actor *Actor, passportNumber string) (*domain.Trekker, error) {
+	trekker := &domain.Trekker{FullName: "Test Trekker", PassportNumber: passportNumber, Nationality: "gb"}
+	return trekker, h.trekkers.Create(actor, trekker)
+}
+
+func TestTrekkerPassportIsUniquePerAgency(t *testing.T) {
+	h := newTrekkerHarness(t)
+	first := h.agencyActor("first@agency.example.com", uuid.New())
+	second := h.agencyActor("second@agency.example.com", uuid.New())
+
+	mine, err := h.register(first, "123 456 789")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.register(first, "123456789"); err == nil {
+		t.Fatal("agency registered the same passport twice")
+	}
+
+	// The second agency cannot see the first agency's record, so it
+	// registers the traveller itself.
+	theirs, err := h.register(second, "123456789")
+	if err != nil {
+		t.Fatalf("second agency registering the same passport: %v", err)
+	}
+	if theirs.ID == mine.ID || !sameOptionalID(theirs.AgencyID, second.AgencyID) {
+		t.Fatal("second agency's trekker is not its own record")
+	}
+	if _, err := h.trekkers.GetByID(second, mine.ID); err != ErrForbidden {
+		t.Fatalf("second agency reading the first agency's trekker: got %v, want ErrForbidden", err)
+	}
+	if _, err := h.trekkers.GetByID(second, theirs.ID); err != nil {
+		t.Fatalf("second agency reading its own trekker: %v", err)
+	}
+}
+
+func TestTrekkerUpdateChecksPassportWithinAgency(t *testing.T) {
+	h := newTrekkerHarness(t)
+	first := h.agencyActor("first@agency.example.com", uuid.New())
+	second := h.agencyActor("second@agency.example.com", uuid.New())
+
+	if _, err := h.register(first, "AA111"); err != nil {
+		t.Fatal(err)
+	}
+	other, err := h.register(first, "BB222")
+	if err != nil {
+		t.Fatal(err)
+	}
+	theirs, err := h.register(second, "CC333")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	taken := "AA111"
+	if _, err := h.trekkers.Update(first, other.ID, &UpdateTrekkerRequest{PassportNumber: &taken}); err == nil {
+		t.Fatal("trekker took a passport already registered in its agency")
+	}
+	if _, err := h.trekkers.Update(second, theirs.ID, &UpdateTrekkerRequest{PassportNumber: &taken}); err != nil {
+		t.Fatalf("trekker of another agency taking the passport: %v", err)
+	}
+}
@@ -0,0 +1,352 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/touros-platform/api/internal/domain"
+	"github.com/touros-platform/api/internal/repository"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// minPasswordLength matches the length required at login.
+const minPasswordLength = 8
+
+type UserService interface {
+	Create(user *domain.User, password string) error
+	GetByID(id uuid.UUID) (*domain.User, error)
+	Update(id uuid.UUID, updates *UpdateUserRequest) (*domain.User, error)
+	SetRole(id uuid.UUID, role domain.Role, actorID uuid.UUID) (*domain.User, error)
+	SetActive(id uuid.UUID, active bool, actorID uuid.UUID) (*domain.User, error)
+	Delete(id uuid.UUID, actorID uuid.UUID) error
+	List(limit, offset int, role *domain.Role, active *bool) ([]domain.User, int64, error)
+	UpdateProfile(id uuid.UUID, updates *UpdateProfileRequest) (*domain.User, error)
+	ChangePassword(id uuid.UUID, currentPassword, newPassword string) error
+	Unlock(id uuid.UUID) (*domain.User, error)
+	LoginAttempts(id uuid.UUID, limit, offset int) ([]domain.LoginAttempt, int64, error)
+}
+
+// UpdateUserRequest holds the account fields an admin may change. A nil
+// AgencyID leaves the agency unchanged; ClearAgency removes the user from
+// their agency.
+type UpdateUserRequest struct {
+	Email       *string
+	FullName    *string
+	AgencyID    *uuid.UUID
+	ClearAgency bool
+}
+
+// UpdateProfileRequest holds the fields users may change on their own
+// account.
+type UpdateProfileRequest struct {
+	FullName *string
+}
+
+type userService struct {
+	userRepo         repository.UserRepository
+	agencyRepo       repository.AgencyRepository
+	tokenRepo        repository.TokenRepository
+	loginAttemptRepo repository.LoginAttemptRepository
+	accountService   AccountService
+}
+
+func NewUserService(
+	userRepo repository.UserRepository,
+	agencyRepo repository.AgencyRepository,
+	tokenRepo repository.TokenRepository,
+	loginAttemptRepo repository.LoginAttemptRepository,
+	accountService AccountService,
+) UserService {
+	return &userService{
+		userRepo:         userRepo,
+		agencyRepo:       agencyRepo,
+		tokenRepo:        tokenRepo,
+		loginAttemptRepo: loginAttemptRepo,
+		accountService:   accountService,
+	}
+}
+
+// Create adds a user account with the given initial password and emails the
+// user a link to verify their address before they can log in.
+func (s *userService) Create(user *domain.User, password string) error {
+	user.Email = strings.TrimSpace(user.Email)
+	if !user.Role.Valid() {
+		return fmt.Errorf("invalid role: %s", user.Role)
+	}
+
+	existing, _ := s.userRepo.GetByEmail(user.Email)
+	if existing != nil {
+		return errors.New("user with this email already exists")
+	}
+
+	if err := s.checkAgency(user.AgencyID); err != nil {
+		return err
+	}
+	// An agency's first account is set up by an admin and runs the agency;
+	// further staff are invited by it.
+	if user.Role == domain.RoleAgency && user.AgencyID != nil && user.AgencyRole == "" {
+		user.AgencyRole = domain.AgencyRoleOwner
+	}
+
+	if len(password) < minPasswordLength {
+		return fmt.Errorf("password must be at least %d characters", minPasswordLength)
+	}
+	hash, err := HashPassword(password)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+	user.PasswordHash = hash
+	user.IsActive = true
+
+	if err := s.userRepo.Create(user); err != nil {
+		return err
+	}
+
+	return s.accountService.SendVerification(user)
+}
+
+func (s *userService) GetByID(id uuid.UUID) (*domain.User, error) {
+	return s.userRepo.GetByID(id)
+}
+
+func (s *userService) Update(id uuid.UUID, updates *UpdateUserRequest) (*domain.User, error) {
+	user, err := s.userRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if updates.AgencyID != nil && updates.ClearAgency {
+		return nil, errors.New("cannot set and clear the agency at once")
+	}
+
+	emailChanged := false
+	agencyChanged := false
+	if updates.Email != nil {
+		email := strings.TrimSpace(*updates.Email)
+		existing, _ := s.userRepo.GetByEmail(email)
+		if existing != nil && existing.ID != user.ID {
+			return nil, errors.New("user with this email already exists")
+		}
+		emailChanged = email != user.Email
+		user.Email = email
+	}
+	if updates.FullName != nil {
+		user.FullName = *updates.FullName
+	}
+	if updates.AgencyID != nil {
+		if err := s.checkAgency(updates.AgencyID); err != nil {
+			return nil, err
+		}
+		if !sameOptionalID(user.AgencyID, updates.AgencyID) {
+			if err := leaveAgency(s.userRepo, user, updates.AgencyID); err != nil {
+				return nil, err
+			}
+			agencyChanged = true
+		}
+	}
+	if updates.ClearAgency && user.AgencyID != nil {
+		if err := leaveAgency(s.userRepo, user, nil); err != nil {
+			return nil, err
+		}
+		agencyChanged = true
+	}
+
+	// A new address has to be verified again before the user can log in.
+	if emailChanged {
+		user.EmailVerifiedAt = nil
+	}
+
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+
+	// Access tokens carry the agency that scopes what the user can see, so
+	// sessions issued for the old agency must end.
+	if agencyChanged {
+		if err := s.tokenRepo.RevokeAllForUser(user.ID, domain.TokenRevokedLogoutAll); err != nil {
+			return nil, fmt.Errorf("failed to revoke sessions: %w", err)
+		}
+	}
+
+	if emailChanged {
+		if err := s.accountService.SendVerification(user); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.userRepo.GetByID(user.ID)
+}
+
+// SetRole assigns a role. Admins cannot change their own role, so the last
+// admin cannot lock everyone out by accident. A user leaving the agency role
+// leaves their agency too, unless they are its last owner; a user given the
+// agency role joins their agency as a clerk.
+func (s *userService) SetRole(id uuid.UUID, role domain.Role, actorID uuid.UUID) (*domain.User, error) {
+	if !role.Valid() {
+		return nil, fmt.Errorf("invalid role: %s", role)
+	}
+	if id == actorID {
+		return nil, errors.New("cannot change your own role")
+	}
+
+	user, err := s.userRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case user.Role == domain.RoleAgency && role != domain.RoleAgency:
+		if err := leaveAgency(s.userRepo, user, nil); err != nil {
+			return nil, err
+		}
+	case user.Role != domain.RoleAgency && role == domain.RoleAgency:
+		if user.AgencyID != nil && !user.AgencyRole.Valid() {
+			user.AgencyRole = domain.AgencyRoleClerk
+		}
+	}
+
+	user.Role = role
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+
+	// Access tokens carry the role, so sessions issued under the old role
+	// must not outlive the change.
+	if err := s.tokenRepo.RevokeAllForUser(user.ID, domain.TokenRevokedLogoutAll); err != nil {
+		return nil, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	return user, nil
+}
+
+// SetActive activates or deactivates an account. Deactivating ends all of
+// the user's sessions.
+func (s *userService) SetActive(id uuid.UUID, active bool, actorID uuid.UUID) (*domain.User, error) {
+	if id == actorID && !active {
+		return nil, errors.New("cannot deactivate your own account")
+	}
+
+	user, err := s.userRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	user.IsActive = active
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+
+	if !active {
+		if err := s.tokenRepo.RevokeAllForUser(user.ID, domain.TokenRevokedLogoutAll); err != nil {
+			return nil, fmt.Errorf("failed to revoke sessions: %w", err)
+		}
+	}
+
+	return user, nil
+}
+
+func (s *userService) Delete(id uuid.UUID, actorID uuid.UUID) error {
+	if id == actorID {
+		return errors.New("cannot delete your own account")
+	}
+
+	if _, err := s.userRepo.GetByID(id); err != nil {
+		return err
+	}
+
+	if err := s.tokenRepo.RevokeAllForUser(id, domain.TokenRevokedLogoutAll); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	return s.userRepo.Delete(id)
+}
+
+func (s *userService) List(limit, offset int, role *domain.Role, active *bool) ([]domain.User, int64, error) {
+	return s.userRepo.List(limit, offset, role, active)
+}
+
+func (s *userService) UpdateProfile(id uuid.UUID, updates *UpdateProfileRequest) (*domain.User, error) {
+	user, err := s.userRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if updates.FullName != nil {
+		if strings.TrimSpace(*updates.FullName) == "" {
+			return nil, errors.New("full name is required")
+		}
+		user.FullName = *updates.FullName
+	}
+
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// ChangePassword replaces the user's password after checking the current
+// one, then ends every session so other devices must sign in again.
+func (s *userService) ChangePassword(id uuid.UUID, currentPassword, newPassword string) error {
+	user, err := s.userRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(currentPassword)); err != nil {
+		return errors.New("current password is incorrect")
+	}
+
+	if len(newPassword) < minPasswordLength {
+		return fmt.Errorf("password must be at least %d characters", minPasswordLength)
+	}
+	if newPassword == currentPassword {
+		return errors.New("new password must differ from the current password")
+	}
+
+	hash, err := HashPassword(newPassword)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+	user.PasswordHash = hash
+
+	if err := s.userRepo.Update(user); err != nil {
+		return err
+	}
+
+	if err := s.tokenRepo.RevokeAllForUser(user.ID, domain.TokenRevokedLogoutAll); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	return nil
+}
+
+// Unlock lifts a lockout caused by failed logins and clears the failure
+// count.
+func (s *userService) Unlock(id uuid.UUID) (*domain.User, error) {
+	if _, err := s.userRepo.GetByID(id); err != nil {
+		return nil, err
+	}
+
+	if err := s.loginAttemptRepo.Reset(id); err != nil {
+		return nil, fmt.Errorf("failed to unlock account: %w", err)
+	}
+
+	return s.userRepo.GetByID(id)
+}
+
+func (s *userService) LoginAttempts(id uuid.UUID, limit, offset int) ([]domain.LoginAttempt, int64, error) {
+	return s.loginAttemptRepo.List(limit, offset, &id, nil)
+}
+
+func (s *userService) checkAgency(agencyID *uuid.UUID) error {
+	if agencyID == nil {
+		return nil
+	}
+	if _, err := s.agencyRepo.GetByID(*agencyID); err != nil {
+		return errors.New("agency not found")
+	}
+	return nil
+}
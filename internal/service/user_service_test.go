@@ -0,0 +1,98 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/touros-platform/api/internal/domain"
+)
+
+func newTestUserService(h *loginHarness) UserService {
+	return NewUserService(&fakeUserRepo{h}, nil, newSessionTokenRepo(), &fakeLoginAttemptRepo{h}, nil)
+}
+
+func (h *loginHarness) addAgencyUser(email string, agencyID uuid.UUID, role domain.AgencyRole) *domain.User {
+	user := h.addUser(email)
+	user.Role = domain.RoleAgency
+	user.AgencyID = &agencyID
+	user.AgencyRole = role
+	return user
+}
+
+func TestSetRoleKeepsLastAgencyOwner(t *testing.T) {
+	h := newLoginHarness(t)
+	users := newTestUserService(h)
+	agencyID := uuid.New()
+	owner := h.addAgencyUser("owner@agency.example.com", agencyID, domain.AgencyRoleOwner)
+
+	if _, err := users.SetRole(owner.ID, domain.RoleGuide, uuid.New()); err == nil {
+		t.Fatal("last owner left the agency role")
+	}
+	if h.users[owner.ID].Role != domain.RoleAgency {
+		t.Fatal("refused role change was saved")
+	}
+
+	h.addAgencyUser("second@agency.example.com", agencyID, domain.AgencyRoleOwner)
+	updated, err := users.SetRole(owner.ID, domain.RoleGuide, uuid.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.AgencyID != nil || updated.AgencyRole != "" {
+		t.Fatalf("user leaving the agency role kept agency %v as %q", updated.AgencyID, updated.AgencyRole)
+	}
+}
+
+func TestSetRoleToAgencyAssignsAgencyRole(t *testing.T) {
+	h := newLoginHarness(t)
+	users := newTestUserService(h)
+	agencyID := uuid.New()
+	user := h.addUser("guide@example.com")
+	user.AgencyID = &agencyID
+
+	updated, err := users.SetRole(user.ID, domain.RoleAgency, uuid.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.AgencyRole != domain.AgencyRoleClerk {
+		t.Fatalf("AgencyRole = %q, want %q", updated.AgencyRole, domain.AgencyRoleClerk)
+	}
+}
+
+func TestUpdateClearsAgency(t *testing.T) {
+	h := newLoginHarness(t)
+	users := newTestUserService(h)
+	agencyID := uuid.New()
+	h.addAgencyUser("owner@agency.example.com", agencyID, domain.AgencyRoleOwner)
+	clerk := h.addAgencyUser("clerk@agency.example.com", agencyID, domain.AgencyRoleClerk)
+
+	// A nil agency leaves it unchanged.
+	updated, err := users.Update(clerk.ID, &UpdateUserRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.AgencyID == nil || *updated.AgencyID != agencyID {
+		t.Fatal("update without an agency removed the user from their agency")
+	}
+
+	if _, err := users.Update(clerk.ID, &UpdateUserRequest{AgencyID: &agencyID, ClearAgency: true}); err == nil {
+		t.Fatal("setting and clearing the agency at once was accepted")
+	}
+
+	updated, err = users.Update(clerk.ID, &UpdateUserRequest{ClearAgency: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.AgencyID != nil || updated.AgencyRole != "" {
+		t.Fatalf("cleared user kept agency %v as %q", updated.AgencyID, updated.AgencyRole)
+	}
+}
+
+func TestUpdateClearAgencyKeepsLastOwner(t *testing.T) {
+	h := newLoginHarness(t)
+	users := newTestUserService(h)
+	owner := h.addAgencyUser("owner@agency.example.com", uuid.New(), domain.AgencyRoleOwner)
+
+	if _, err := users.Update(owner.ID, &UpdateUserRequest{ClearAgency: true}); err == nil {
+		t.Fatal("last owner was removed from the agency")
+	}
+}
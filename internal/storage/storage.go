@@ -0,0 +1,48 @@
+// Package storage keeps uploaded files behind the BlobStore interface, on
+// the local filesystem or in an S3-compatible object store such as MinIO.
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/touros-platform/api/internal/config"
+)
+
+// ErrNotFound is returned by Get for a key that does not exist.
+var ErrNotFound = errors.New("storage: blob not found")
+
+// BlobStore stores opaque blobs under slash separated keys. Deleting a key
+// that does not exist is not an error.
+type BlobStore interface {
+	Put(key string, body io.Reader, size int64, contentType string) error
+	Get(key string) (io.ReadCloser, error)
+	Delete(key string) error
+}
+
+// New returns the store selected by cfg.Driver: "local" or "s3".
+func New(cfg config.StorageConfig) (BlobStore, error) {
+	switch cfg.Driver {
+	case "local", "":
+		return NewLocalStore(cfg.LocalDir)
+	case "s3":
+		return NewS3Store(cfg)
+	default:
+		return nil, fmt.Errorf("unknown storage driver: %s", cfg.Driver)
+	}
+}
+
+// validKey rejects keys that could escape the store's root or bucket.
+func validKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return false
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,148 @@
+// Package jwk encodes the public keys that verify TourOS access tokens as a
+// JSON Web Key Set (RFC 7517), as served at /.well-known/jwks.json, and
+// decodes key sets published by identity providers. It depends only on the
+// standard library so that checkpoint apps and partner services can embed it
+// to load the key set.
+package jwk
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+const (
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+var ErrUnsupportedKey = errors.New("jwk: unsupported key type")
+
+// Key is a public key in JWK form. RSA keys use N and E, Ed25519 keys use
+// Crv and X, and EC keys Crv, X and Y.
+type Key struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	Y         string `json:"y,omitempty"`
+}
+
+// Set is a JSON Web Key Set.
+type Set struct {
+	Keys []Key `json:"keys"`
+}
+
+// NewKey encodes an RSA or Ed25519 public key for signature verification.
+func NewKey(keyID string, pub crypto.PublicKey) (Key, error) {
+	switch pub := pub.(type) {
+	case *rsa.PublicKey:
+		return Key{
+			KeyType:   "RSA",
+			KeyID:     keyID,
+			Use:       "sig",
+			Algorithm: AlgorithmRS256,
+			N:         base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			E:         base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}, nil
+	case ed25519.PublicKey:
+		return Key{
+			KeyType:   "OKP",
+			KeyID:     keyID,
+			Use:       "sig",
+			Algorithm: AlgorithmEdDSA,
+			Curve:     "Ed25519",
+			X:         base64.RawURLEncoding.EncodeToString(pub),
+		}, nil
+	}
+	return Key{}, ErrUnsupportedKey
+}
+
+// PublicKey decodes the key into an *rsa.PublicKey, *ecdsa.PublicKey or
+// ed25519.PublicKey.
+func (k Key) PublicKey() (crypto.PublicKey, error) {
+	switch k.KeyType {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("jwk: invalid modulus for %q: %w", k.KeyID, err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("jwk: invalid exponent for %q: %w", k.KeyID, err)
+		}
+		exponent := new(big.Int).SetBytes(e)
+		if !exponent.IsInt64() || exponent.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("jwk: invalid exponent for %q", k.KeyID)
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+	case "OKP":
+		if k.Curve != "Ed25519" {
+			return nil, ErrUnsupportedKey
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("jwk: invalid public key for %q", k.KeyID)
+		}
+		return ed25519.PublicKey(x), nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Curve {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, ErrUnsupportedKey
+		}
+		x, errX := base64.RawURLEncoding.DecodeString(k.X)
+		y, errY := base64.RawURLEncoding.DecodeString(k.Y)
+		if errX != nil || errY != nil {
+			return nil, fmt.Errorf("jwk: invalid public key for %q", k.KeyID)
+		}
+		pub := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !curve.IsOnCurve(pub.X, pub.Y) {
+			return nil, fmt.Errorf("jwk: invalid public key for %q", k.KeyID)
+		}
+		return pub, nil
+	}
+	return nil, ErrUnsupportedKey
+}
+
+// Parse decodes a key set and returns its signature keys by key ID. Keys of
+// unsupported types are skipped.
+func Parse(data []byte) (map[string]crypto.PublicKey, error) {
+	var set Set
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("jwk: failed to decode key set: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		pub, err := k.PublicKey()
+		if errors.Is(err, ErrUnsupportedKey) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		keys[k.KeyID] = pub
+	}
+	return keys, nil
+}
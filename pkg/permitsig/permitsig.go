@@ -0,0 +1,188 @@
+// Package permitsig signs and verifies the payload carried in TourOS permit
+// QR codes. It depends only on the standard library so that checkpoint apps
+// can embed it and verify permits without network access.
+//
+// A signed permit token has the form
+//
+//	TP1.<key id>.<base64url payload>.<base64url signature>
+//
+// where the Ed25519 signature covers everything before the last dot. The key
+// ID selects the public key to verify with, which allows signing keys to be
+// rotated while permits issued under older keys remain verifiable.
+//
+// Offline verification can only attest to the permit as it was when signed.
+// A permit revoked after issuance keeps verifying until the checkpoint
+// refreshes its data, so devices should sync whenever they are online.
+package permitsig
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// Version is the token prefix for the current payload format.
+	Version = "TP1"
+	// Algorithm is the signature algorithm advertised for public keys.
+	Algorithm = "Ed25519"
+
+	statusActive = "active"
+)
+
+var (
+	ErrMalformed    = errors.New("permitsig: malformed token")
+	ErrUnknownKey   = errors.New("permitsig: unknown signing key")
+	ErrBadSignature = errors.New("permitsig: invalid signature")
+	ErrNotActive    = errors.New("permitsig: permit is not active")
+	ErrNotYetValid  = errors.New("permitsig: permit has not yet started")
+	ErrExpired      = errors.New("permitsig: permit has expired")
+)
+
+// Payload is the permit data embedded in the QR code.
+type Payload struct {
+	PermitNumber string    `json:"pn"`
+	GuideLicense string    `json:"gl"`
+	StartDate    time.Time `json:"sd"`
+	EndDate      time.Time `json:"ed"`
+	Route        string    `json:"rt"`
+	PartySize    int       `json:"ps,omitempty"`
+	Status       string    `json:"st"`
+	IssuedAt     time.Time `json:"iat"`
+}
+
+// PublicKey is the published form of a verification key.
+type PublicKey struct {
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	PublicKey string `json:"public_key"`
+}
+
+// KeySet is the document served by the permit public key endpoint.
+type KeySet struct {
+	Keys []PublicKey `json:"keys"`
+}
+
+// Sign encodes payload and signs it with key, tagging the token with keyID.
+func Sign(payload *Payload, keyID string, key ed25519.PrivateKey) (string, error) {
+	if keyID == "" || strings.Contains(keyID, ".") {
+		return "", fmt.Errorf("permitsig: invalid key id %q", keyID)
+	}
+	if len(key) != ed25519.PrivateKeySize {
+		return "", errors.New("permitsig: invalid private key")
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("permitsig: failed to encode payload: %w", err)
+	}
+
+	signed := Version + "." + keyID + "." + base64.RawURLEncoding.EncodeToString(data)
+	signature := ed25519.Sign(key, []byte(signed))
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// Verifier checks permit tokens against a set of trusted public keys.
+type Verifier struct {
+	keys map[string]ed25519.PublicKey
+}
+
+func NewVerifier() *Verifier {
+	return &Verifier{keys: make(map[string]ed25519.PublicKey)}
+}
+
+// AddKey trusts pub for tokens carrying keyID.
+func (v *Verifier) AddKey(keyID string, pub ed25519.PublicKey) error {
+	if keyID == "" {
+		return errors.New("permitsig: empty key id")
+	}
+	if len(pub) != ed25519.PublicKeySize {
+		return fmt.Errorf("permitsig: invalid public key for %q", keyID)
+	}
+	v.keys[keyID] = pub
+	return nil
+}
+
+// LoadKeySet trusts every key in a JSON key set as served by the API.
+func (v *Verifier) LoadKeySet(data []byte) error {
+	var set KeySet
+	if err := json.Unmarshal(data, &set); err != nil {
+		return fmt.Errorf("permitsig: failed to decode key set: %w", err)
+	}
+	for _, k := range set.Keys {
+		if k.Algorithm != Algorithm {
+			continue
+		}
+		pub, err := base64.StdEncoding.DecodeString(k.PublicKey)
+		if err != nil {
+			return fmt.Errorf("permitsig: invalid public key for %q: %w", k.KeyID, err)
+		}
+		if err := v.AddKey(k.KeyID, pub); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Parse checks the token signature and returns the payload and key ID
+// without looking at the permit status or dates.
+func (v *Verifier) Parse(token string) (*Payload, string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 4 || parts[0] != Version {
+		return nil, "", ErrMalformed
+	}
+
+	keyID := parts[1]
+	pub, ok := v.keys[keyID]
+	if !ok {
+		return nil, keyID, ErrUnknownKey
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[3])
+	if err != nil {
+		return nil, keyID, ErrMalformed
+	}
+
+	signed := token[:len(token)-len(parts[3])-1]
+	if !ed25519.Verify(pub, []byte(signed), signature) {
+		return nil, keyID, ErrBadSignature
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, keyID, ErrMalformed
+	}
+
+	var payload Payload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, keyID, ErrMalformed
+	}
+
+	return &payload, keyID, nil
+}
+
+// Verify checks the token signature and that the permit is active and valid
+// at the given time. The payload is returned alongside status and date
+// errors so callers can still show what was scanned.
+func (v *Verifier) Verify(token string, at time.Time) (*Payload, error) {
+	payload, _, err := v.Parse(token)
+	if err != nil {
+		return nil, err
+	}
+
+	if payload.Status != statusActive {
+		return payload, ErrNotActive
+	}
+	if at.Before(payload.StartDate) {
+		return payload, ErrNotYetValid
+	}
+	if at.After(payload.EndDate) {
+		return payload, ErrExpired
+	}
+
+	return payload, nil
+}
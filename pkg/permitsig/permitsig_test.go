@@ -0,0 +1,247 @@
+package permitsig
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testKeyID = "k1"
+
+func newTestKey(t *testing.T) (ed25519.PublicKey, ed25519.PrivateKey) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	return pub, priv
+}
+
+func newTestVerifier(t *testing.T, keyID string, pub ed25519.PublicKey) *Verifier {
+	t.Helper()
+	verifier := NewVerifier()
+	if err := verifier.AddKey(keyID, pub); err != nil {
+		t.Fatalf("failed to add key: %v", err)
+	}
+	return verifier
+}
+
+func testPayload() *Payload {
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	return &Payload{
+		PermitNumber: "TP-2026-000123",
+		GuideLicense: "GL-4411",
+		StartDate:    start,
+		EndDate:      start.AddDate(0, 0, 14),
+		Route:        "Annapurna Circuit",
+		Status:       statusActive,
+		IssuedAt:     start.AddDate(0, 0, -7),
+	}
+}
+
+func signTestToken(t *testing.T, payload *Payload, priv ed25519.PrivateKey) string {
+	t.Helper()
+	token, err := Sign(payload, testKeyID, priv)
+	if err != nil {
+		t.Fatalf("failed to sign: %v", err)
+	}
+	return token
+}
+
+// signRaw signs an arbitrary payload segment, so that tests can produce
+// correctly signed tokens whose payload does not decode.
+func signRaw(keyID, payload string, priv ed25519.PrivateKey) string {
+	signed := Version + "." + keyID + "." + payload
+	return signed + "." + base64.RawURLEncoding.EncodeToString(ed25519.Sign(priv, []byte(signed)))
+}
+
+func TestSignParseVerifyRoundTrip(t *testing.T) {
+	pub, priv := newTestKey(t)
+	verifier := newTestVerifier(t, testKeyID, pub)
+	payload := testPayload()
+
+	token := signTestToken(t, payload, priv)
+	if !strings.HasPrefix(token, Version+"."+testKeyID+".") {
+		t.Fatalf("token %q does not start with the version and key id", token)
+	}
+
+	parsed, keyID, err := verifier.Parse(token)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if keyID != testKeyID {
+		t.Fatalf("expected key id %q, got %q", testKeyID, keyID)
+	}
+	if parsed.PermitNumber != payload.PermitNumber || parsed.GuideLicense != payload.GuideLicense ||
+		parsed.Route != payload.Route || parsed.Status != payload.Status ||
+		!parsed.StartDate.Equal(payload.StartDate) || !parsed.EndDate.Equal(payload.EndDate) ||
+		!parsed.IssuedAt.Equal(payload.IssuedAt) {
+		t.Fatalf("parsed payload %+v does not match signed payload %+v", parsed, payload)
+	}
+
+	verified, err := verifier.Verify(token, payload.StartDate.AddDate(0, 0, 3))
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if verified.PermitNumber != payload.PermitNumber {
+		t.Fatalf("unexpected permit number %q", verified.PermitNumber)
+	}
+}
+
+func TestVerifyChecksStatusAndDates(t *testing.T) {
+	pub, priv := newTestKey(t)
+	verifier := newTestVerifier(t, testKeyID, pub)
+	payload := testPayload()
+	token := signTestToken(t, payload, priv)
+
+	if _, err := verifier.Verify(token, payload.StartDate.Add(-time.Hour)); !errors.Is(err, ErrNotYetValid) {
+		t.Fatalf("expected ErrNotYetValid before the start date, got %v", err)
+	}
+	if _, err := verifier.Verify(token, payload.EndDate.Add(time.Hour)); !errors.Is(err, ErrExpired) {
+		t.Fatalf("expected ErrExpired after the end date, got %v", err)
+	}
+}
+
+// A revoked permit is re-signed with status revoked. The new QR code still
+// carries a valid signature, so checkpoints can show what was scanned, but it
+// no longer verifies as an active permit.
+func TestRevokedPermitVerifiesAsRevoked(t *testing.T) {
+	pub, priv := newTestKey(t)
+	verifier := newTestVerifier(t, testKeyID, pub)
+	payload := testPayload()
+	payload.Status = "revoked"
+	token := signTestToken(t, payload, priv)
+
+	parsed, _, err := verifier.Parse(token)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if parsed.Status != "revoked" {
+		t.Fatalf("expected status revoked, got %q", parsed.Status)
+	}
+
+	scanned, err := verifier.Verify(token, payload.StartDate.AddDate(0, 0, 3))
+	if !errors.Is(err, ErrNotActive) {
+		t.Fatalf("expected ErrNotActive for a revoked permit, got %v", err)
+	}
+	if scanned == nil || scanned.PermitNumber != payload.PermitNumber || scanned.Status != "revoked" {
+		t.Fatalf("expected the revoked payload alongside ErrNotActive, got %+v", scanned)
+	}
+}
+
+func TestParseRejectsTamperedPayload(t *testing.T) {
+	pub, priv := newTestKey(t)
+	verifier := newTestVerifier(t, testKeyID, pub)
+	token := signTestToken(t, testPayload(), priv)
+
+	tampered := testPayload()
+	tampered.Route = "Everest Base Camp"
+	data, err := json.Marshal(tampered)
+	if err != nil {
+		t.Fatalf("failed to encode payload: %v", err)
+	}
+	parts := strings.Split(token, ".")
+	parts[2] = base64.RawURLEncoding.EncodeToString(data)
+
+	if _, _, err := verifier.Parse(strings.Join(parts, ".")); !errors.Is(err, ErrBadSignature) {
+		t.Fatalf("expected ErrBadSignature, got %v", err)
+	}
+}
+
+func TestParseRejectsTamperedSignature(t *testing.T) {
+	pub, priv := newTestKey(t)
+	verifier := newTestVerifier(t, testKeyID, pub)
+	token := signTestToken(t, testPayload(), priv)
+
+	parts := strings.Split(token, ".")
+	signature, err := base64.RawURLEncoding.DecodeString(parts[3])
+	if err != nil {
+		t.Fatalf("failed to decode signature: %v", err)
+	}
+	signature[0] ^= 0xff
+	parts[3] = base64.RawURLEncoding.EncodeToString(signature)
+
+	if _, _, err := verifier.Parse(strings.Join(parts, ".")); !errors.Is(err, ErrBadSignature) {
+		t.Fatalf("expected ErrBadSignature, got %v", err)
+	}
+}
+
+func TestParseRejectsSignatureFromAnotherKey(t *testing.T) {
+	pub, _ := newTestKey(t)
+	_, otherPriv := newTestKey(t)
+	verifier := newTestVerifier(t, testKeyID, pub)
+
+	token := signTestToken(t, testPayload(), otherPriv)
+	if _, _, err := verifier.Parse(token); !errors.Is(err, ErrBadSignature) {
+		t.Fatalf("expected ErrBadSignature, got %v", err)
+	}
+}
+
+func TestParseRejectsUnknownKey(t *testing.T) {
+	pub, priv := newTestKey(t)
+	verifier := newTestVerifier(t, "k0", pub)
+	token := signTestToken(t, testPayload(), priv)
+
+	_, keyID, err := verifier.Parse(token)
+	if !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("expected ErrUnknownKey, got %v", err)
+	}
+	if keyID != testKeyID {
+		t.Fatalf("expected the unknown key id %q to be reported, got %q", testKeyID, keyID)
+	}
+}
+
+func TestParseRejectsMalformedTokens(t *testing.T) {
+	pub, priv := newTestKey(t)
+	verifier := newTestVerifier(t, testKeyID, pub)
+	token := signTestToken(t, testPayload(), priv)
+	parts := strings.Split(token, ".")
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"empty", ""},
+		{"version only", Version},
+		{"missing signature", strings.Join(parts[:3], ".")},
+		{"extra segment", token + ".extra"},
+		{"wrong version", "TP2." + strings.Join(parts[1:], ".")},
+		{"signature not base64", strings.Join(parts[:3], ".") + ".!!!"},
+		{"payload not base64", signRaw(testKeyID, "%%%", priv)},
+		{"payload not JSON", signRaw(testKeyID, base64.RawURLEncoding.EncodeToString([]byte("not json")), priv)},
+	}
+	for _, tt := range tests {
+		if _, _, err := verifier.Parse(tt.token); !errors.Is(err, ErrMalformed) {
+			t.Errorf("%s: expected ErrMalformed, got %v", tt.name, err)
+		}
+	}
+}
+
+func TestSignRejectsInvalidKeyID(t *testing.T) {
+	_, priv := newTestKey(t)
+	for _, keyID := range []string{"", "k.1"} {
+		if _, err := Sign(testPayload(), keyID, priv); err == nil {
+			t.Errorf("expected key id %q to be refused", keyID)
+		}
+	}
+}
+
+func TestLoadKeySet(t *testing.T) {
+	pub, priv := newTestKey(t)
+	set := fmt.Sprintf(`{"keys":[{"kid":%q,"alg":%q,"public_key":%q},{"kid":"other","alg":"RS256","public_key":"ignored"}]}`,
+		testKeyID, Algorithm, base64.StdEncoding.EncodeToString(pub))
+
+	verifier := NewVerifier()
+	if err := verifier.LoadKeySet([]byte(set)); err != nil {
+		t.Fatalf("LoadKeySet failed: %v", err)
+	}
+	if _, _, err := verifier.Parse(signTestToken(t, testPayload(), priv)); err != nil {
+		t.Fatalf("Parse with a loaded key failed: %v", err)
+	}
+}
@@ -0,0 +1,143 @@
+package totp
+
+import (
+	"testing"
+	"time"
+)
+
+// rfcSecret is the RFC 6238 Appendix B SHA1 key "12345678901234567890" in
+// base32.
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// The RFC lists eight digit codes; six digit codes are their last six
+// digits.
+var rfcVectors = []struct {
+	unix int64
+	code string
+}{
+	{59, "287082"},
+	{1111111109, "081804"},
+	{1111111111, "050471"},
+	{1234567890, "005924"},
+	{2000000000, "279037"},
+	{20000000000, "353130"},
+}
+
+func TestCodeAtRFC6238Vectors(t *testing.T) {
+	for _, tt := range rfcVectors {
+		code, err := CodeAt(rfcSecret, Step(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("T=%d: %v", tt.unix, err)
+		}
+		if code != tt.code {
+			t.Errorf("T=%d: code %s, want %s", tt.unix, code, tt.code)
+		}
+	}
+}
+
+func TestValidateRFC6238Vectors(t *testing.T) {
+	for _, tt := range rfcVectors {
+		now := time.Unix(tt.unix, 0)
+		step, ok := Validate(rfcSecret, tt.code, now, 0)
+		if !ok {
+			t.Errorf("T=%d: code %s refused", tt.unix, tt.code)
+			continue
+		}
+		if step != Step(now) {
+			t.Errorf("T=%d: matched step %d, want %d", tt.unix, step, Step(now))
+		}
+	}
+}
+
+func TestValidateSkewWindow(t *testing.T) {
+	now := time.Unix(1111111109, 0)
+	current := Step(now)
+
+	tests := []struct {
+		offset int64
+		ok     bool
+	}{
+		{-2, false},
+		{-1, true},
+		{0, true},
+		{1, true},
+		{2, false},
+	}
+	for _, tt := range tests {
+		code, err := CodeAt(rfcSecret, current+tt.offset)
+		if err != nil {
+			t.Fatal(err)
+		}
+		step, ok := Validate(rfcSecret, code, now, 1)
+		if ok != tt.ok {
+			t.Errorf("code of step %+d: accepted = %t, want %t", tt.offset, ok, tt.ok)
+			continue
+		}
+		if ok && step != current+tt.offset {
+			t.Errorf("code of step %+d: matched step %d, want %d", tt.offset, step, current+tt.offset)
+		}
+	}
+}
+
+func TestValidateStepBoundary(t *testing.T) {
+	// T=59 is the last second of step 1; the code expires at T=60.
+	code, err := CodeAt(rfcSecret, Step(time.Unix(59, 0)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := Validate(rfcSecret, code, time.Unix(59, 0), 0); !ok {
+		t.Fatal("code refused in the last second of its step")
+	}
+	if _, ok := Validate(rfcSecret, code, time.Unix(60, 0), 0); ok {
+		t.Fatal("code accepted in the next step without skew")
+	}
+	if _, ok := Validate(rfcSecret, code, time.Unix(60, 0), 1); !ok {
+		t.Fatal("code refused one step late with a skew of one")
+	}
+}
+
+func TestValidateRejectsWrongCode(t *testing.T) {
+	now := time.Unix(1111111109, 0)
+	for _, code := range []string{"000000", "081805", "81804", "0818040", "abcdef", ""} {
+		if _, ok := Validate(rfcSecret, code, now, 1); ok {
+			t.Errorf("code %q accepted", code)
+		}
+	}
+	if _, ok := Validate("not base32!", "081804", now, 1); ok {
+		t.Error("code accepted for an invalid secret")
+	}
+}
+
+func TestValidateReportsStepOfReusedCode(t *testing.T) {
+	// Validate has no memory: a code presented twice in the same step
+	// matches the same step both times, which is how callers detect the
+	// replay. T=1111111080 to 1111111109 is one step.
+	now := time.Unix(1111111109, 0)
+	first, ok := Validate(rfcSecret, "081804", time.Unix(1111111080, 0), 1)
+	if !ok {
+		t.Fatal("code refused")
+	}
+	second, ok := Validate(rfcSecret, "081804", now, 1)
+	if !ok {
+		t.Fatal("code refused the second time")
+	}
+	if second != first || first != Step(now) {
+		t.Fatalf("reused code matched step %d, then %d, want %d", first, second, Step(now))
+	}
+
+	// Replayed in the next step, within the skew, it still matches its own
+	// earlier step rather than the current one.
+	later, ok := Validate(rfcSecret, "081804", now.Add(time.Second), 1)
+	if !ok {
+		t.Fatal("code refused one step late")
+	}
+	if later != first {
+		t.Fatalf("code replayed a step late matched step %d, want %d", later, first)
+	}
+}
+
+func TestValidateIgnoresSpaces(t *testing.T) {
+	if _, ok := Validate(rfcSecret, "081 804", time.Unix(1111111109, 0), 0); !ok {
+		t.Fatal("code with a space refused")
+	}
+}
@@ -8,8 +8,11 @@ echo "1. Hash the password using bcrypt"
 echo "2. Insert into users table with role='admin'"
 echo ""
 echo "Example SQL:"
-echo "INSERT INTO users (id, email, password_hash, role, full_name, is_active, created_at, updated_at)"
-echo "VALUES (gen_random_uuid(), 'admin@touros.gov.np', '<bcrypt_hash>', 'admin', 'System Admin', true, NOW(), NOW());"
+echo "INSERT INTO users (id, email, password_hash, role, full_name, is_active, email_verified_at, created_at, updated_at)"
+echo "VALUES (gen_random_uuid(), 'admin@touros.gov.np', '<bcrypt_hash>', 'admin', 'System Admin', true, NOW(), NOW(), NOW());"
 echo ""
 echo "You can use Go code or a tool like https://bcrypt-generator.com/ to generate the hash"
+echo ""
+echo "Only the first admin needs to be created this way. Further users are managed by"
+echo "admins through the /api/v1/users endpoints."
 